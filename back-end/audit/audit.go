@@ -0,0 +1,189 @@
+// Package audit grava a trilha de auditoria das alterações feitas pela API. Cada empresa
+// tem uma cadeia própria: o hash de cada evento cobre o conteúdo do evento e o hash do
+// anterior, então qualquer alteração ou remoção é detectada por Verify.
+package audit
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+const localsKey = "auditEntry"
+
+// Entry é a descrição da alteração informada pelo handler para o evento da requisição.
+// Campos vazios são completados pelo middleware a partir da rota e do método.
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	CompanyID  *uuid.UUID
+	Before     interface{}
+	After      interface{}
+}
+
+// Annotate registra a alteração feita pelo handler; o middleware de auditoria grava o
+// evento depois da resposta
+func Annotate(c *fiber.Ctx, entry Entry) {
+	c.Locals(localsKey, &entry)
+}
+
+// FromContext devolve a anotação do handler, se houver
+func FromContext(c *fiber.Ctx) *Entry {
+	entry, _ := c.Locals(localsKey).(*Entry)
+	return entry
+}
+
+// Snapshot converte um valor qualquer no JSON gravado em before/after, no mesmo formato
+// devolvido pela API. Valores que não são objetos ficam em {"value": ...}.
+func Snapshot(value interface{}) models.JSONB {
+	if value == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var snapshot models.JSONB
+	if err := json.Unmarshal(data, &snapshot); err == nil {
+		return snapshot
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded == nil {
+		return nil
+	}
+	return models.JSONB{"value": decoded}
+}
+
+// ChainKey devolve a cadeia da empresa
+func ChainKey(companyID *uuid.UUID) string {
+	if companyID == nil {
+		return models.AuditGlobalChain
+	}
+	return companyID.String()
+}
+
+// ComputeHash calcula o hash do evento a partir de PrevHash e de todos os campos, exceto
+// ID e Hash. before/after entram na forma canônica (chaves ordenadas) do JSON decodificado,
+// que é a mesma antes e depois de passar pelo banco.
+func ComputeHash(event *models.AuditEvent) string {
+	payload, _ := json.Marshal(struct {
+		ChainKey   string       `json:"chainKey"`
+		Seq        int64        `json:"seq"`
+		CompanyID  *uuid.UUID   `json:"companyId"`
+		ActorID    *uuid.UUID   `json:"actorId"`
+		ActorEmail string       `json:"actorEmail"`
+		Action     string       `json:"action"`
+		Method     string       `json:"method"`
+		Path       string       `json:"path"`
+		StatusCode int          `json:"statusCode"`
+		EntityType string       `json:"entityType"`
+		EntityID   string       `json:"entityId"`
+		Before     models.JSONB `json:"before"`
+		After      models.JSONB `json:"after"`
+		IPAddress  string       `json:"ipAddress"`
+		RequestID  string       `json:"requestId"`
+		CreatedAt  string       `json:"createdAt"`
+	}{
+		event.ChainKey, event.Seq, event.CompanyID, event.ActorID, event.ActorEmail,
+		event.Action, event.Method, event.Path, event.StatusCode, event.EntityType, event.EntityID,
+		event.Before, event.After, event.IPAddress, event.RequestID,
+		event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(append([]byte(event.PrevHash+"\n"), payload...))
+	return hex.EncodeToString(sum[:])
+}
+
+// Record grava o evento no fim da cadeia da empresa. Um advisory lock por cadeia serializa
+// as gravações concorrentes, garantindo sequência e encadeamento sem lacunas.
+func Record(db *sqlx.DB, event *models.AuditEvent) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	event.ChainKey = ChainKey(event.CompanyID)
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", "audit_events:"+event.ChainKey); err != nil {
+		return err
+	}
+
+	var last struct {
+		Seq  int64  `db:"seq"`
+		Hash string `db:"hash"`
+	}
+	err = tx.Get(&last, "SELECT seq, hash FROM audit_events WHERE chain_key = $1 ORDER BY seq DESC LIMIT 1", event.ChainKey)
+	if err == sql.ErrNoRows {
+		last.Hash = models.AuditGenesisHash
+	} else if err != nil {
+		return err
+	}
+
+	// O banco guarda microssegundos; o hash usa o valor já truncado
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	event.Seq = last.Seq + 1
+	event.PrevHash = last.Hash
+	event.Hash = ComputeHash(event)
+
+	err = tx.Get(&event.ID, `
+		INSERT INTO audit_events (chain_key, seq, company_id, actor_id, actor_email, action, method, path,
+			status_code, entity_type, entity_id, before_data, after_data, ip_address, request_id,
+			prev_hash, hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id
+	`, event.ChainKey, event.Seq, event.CompanyID, event.ActorID, event.ActorEmail, event.Action, event.Method, event.Path,
+		event.StatusCode, event.EntityType, event.EntityID, event.Before, event.After, event.IPAddress, event.RequestID,
+		event.PrevHash, event.Hash, event.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// VerifyEvents percorre os eventos da cadeia, ordenados por seq, e recalcula os hashes. A
+// verificação para no primeiro evento adulterado, fora de sequência (evento removido) ou com
+// encadeamento quebrado.
+func VerifyEvents(chainKey string, events []models.AuditEvent) *models.AuditChainVerification {
+	result := &models.AuditChainVerification{ChainKey: chainKey, Valid: true}
+	prevHash := models.AuditGenesisHash
+	expectedSeq := int64(1)
+
+	for i := range events {
+		event := &events[i]
+		result.Events++
+
+		reason := ""
+		switch {
+		case event.Seq != expectedSeq:
+			reason = "sequência interrompida: evento ausente"
+		case event.PrevHash != prevHash:
+			reason = "hash anterior não confere"
+		case ComputeHash(event) != event.Hash:
+			reason = "conteúdo do evento alterado"
+		}
+		if reason != "" {
+			seq := event.Seq
+			result.Valid = false
+			result.BrokenAtSeq = &seq
+			result.Reason = reason
+			return result
+		}
+
+		prevHash = event.Hash
+		expectedSeq++
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// chain monta uma cadeia válida de n eventos, encadeados como Record
+func chain(n int) []models.AuditEvent {
+	events := []models.AuditEvent{}
+	prevHash := models.AuditGenesisHash
+	createdAt := time.Date(2025, 10, 17, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		event := models.AuditEvent{
+			ID:         int64(i),
+			ChainKey:   models.AuditGlobalChain,
+			Seq:        int64(i),
+			ActorEmail: "admin@example.com",
+			Action:     models.AuditActionUpdate,
+			Method:     "PUT",
+			Path:       "/api/v1/teams/1",
+			StatusCode: 200,
+			EntityType: "teams",
+			EntityID:   "1",
+			Before:     models.JSONB{"name": "Plataforma"},
+			After:      models.JSONB{"name": "Plataforma " + string(rune('A'+i))},
+			PrevHash:   prevHash,
+			CreatedAt:  createdAt.Add(time.Duration(i) * time.Second),
+		}
+		event.Hash = ComputeHash(&event)
+		prevHash = event.Hash
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestVerifyEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		events     func() []models.AuditEvent
+		wantValid  bool
+		wantEvents int64
+		wantBroken int64
+		wantReason string
+	}{
+		{
+			name:       "cadeia vazia",
+			events:     func() []models.AuditEvent { return nil },
+			wantValid:  true,
+			wantEvents: 0,
+		},
+		{
+			name:       "cadeia íntegra",
+			events:     func() []models.AuditEvent { return chain(3) },
+			wantValid:  true,
+			wantEvents: 3,
+		},
+		{
+			name: "conteúdo alterado",
+			events: func() []models.AuditEvent {
+				events := chain(3)
+				events[1].After = models.JSONB{"name": "Outro"}
+				return events
+			},
+			wantEvents: 2,
+			wantBroken: 2,
+			wantReason: "conteúdo do evento alterado",
+		},
+		{
+			name: "evento removido",
+			events: func() []models.AuditEvent {
+				events := chain(3)
+				return append(events[:1], events[2])
+			},
+			wantEvents: 2,
+			wantBroken: 3,
+			wantReason: "sequência interrompida: evento ausente",
+		},
+		{
+			name: "evento alterado com o hash recalculado",
+			events: func() []models.AuditEvent {
+				events := chain(3)
+				events[1].StatusCode = 500
+				events[1].Hash = ComputeHash(&events[1])
+				return events
+			},
+			wantEvents: 3,
+			wantBroken: 3,
+			wantReason: "hash anterior não confere",
+		},
+		{
+			name: "primeiro evento fora da gênese",
+			events: func() []models.AuditEvent {
+				events := chain(2)
+				events[0].PrevHash = events[1].Hash
+				return events
+			},
+			wantEvents: 1,
+			wantBroken: 1,
+			wantReason: "hash anterior não confere",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := VerifyEvents(models.AuditGlobalChain, tt.events())
+
+			if result.Valid != tt.wantValid {
+				t.Fatalf("valid = %v, want %v (reason %q)", result.Valid, tt.wantValid, result.Reason)
+			}
+			if result.Events != tt.wantEvents {
+				t.Errorf("events = %d, want %d", result.Events, tt.wantEvents)
+			}
+			if tt.wantValid {
+				if result.BrokenAtSeq != nil {
+					t.Errorf("brokenAtSeq = %d, want nil", *result.BrokenAtSeq)
+				}
+				return
+			}
+			if result.BrokenAtSeq == nil || *result.BrokenAtSeq != tt.wantBroken {
+				t.Errorf("brokenAtSeq = %v, want %d", result.BrokenAtSeq, tt.wantBroken)
+			}
+			if result.Reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", result.Reason, tt.wantReason)
+			}
+		})
+	}
+}
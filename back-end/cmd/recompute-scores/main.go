@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/config"
+	"tivix-performance-tracker-backend/database"
+)
+
+// Recalcula developers.latest_performance_score a partir dos relatórios enviados.
+//
+//	go run cmd/recompute-scores/main.go -company <uuid>
+//	go run cmd/recompute-scores/main.go            # todas as empresas
+func main() {
+	companyFlag := flag.String("company", "", "ID da empresa (vazio = todas)")
+	flag.Parse()
+
+	var companyID *uuid.UUID
+	if *companyFlag != "" {
+		parsed, err := uuid.Parse(*companyFlag)
+		if err != nil {
+			log.Fatalf("❌ ID da empresa inválido: %v", err)
+		}
+		companyID = &parsed
+	}
+
+	config.LoadConfig()
+
+	database.Connect()
+
+	log.Println("🔄 Recalculando pontuações mais recentes...")
+
+	updated, err := database.RecomputeLatestPerformanceScores(companyID)
+	if err != nil {
+		log.Fatalf("❌ Erro ao recalcular pontuações: %v", err)
+	}
+
+	log.Printf("✅ Pontuação recalculada para %d desenvolvedor(es)", updated)
+}
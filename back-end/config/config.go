@@ -16,6 +16,19 @@ type Config struct {
 	Environment string
 	JWTSecret  string
 	CORSOrigin string
+
+	// APIURL é o endereço público da API (callback do SSO)
+	APIURL string
+
+	// Envio de emails: MailTransport é "smtp", "file" ou "log"
+	AppURL        string
+	MailTransport string
+	MailFrom      string
+	MailFileDir   string
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
 }
 
 func LoadConfig() *Config {
@@ -31,6 +44,17 @@ func LoadConfig() *Config {
 		Environment: getEnv("ENVIRONMENT", "development"),
 		JWTSecret:   getEnv("JWT_SECRET", "default-secret-change-in-production"),
 		CORSOrigin:  getEnv("CORS_ORIGIN", "http://localhost:5173"),
+
+		APIURL: getEnv("API_URL", "http://localhost:8080"),
+
+		AppURL:        getEnv("APP_URL", getEnv("CORS_ORIGIN", "http://localhost:5173")),
+		MailTransport: getEnv("MAIL_TRANSPORT", "log"),
+		MailFrom:      getEnv("MAIL_FROM", "Tivix Performance Tracker <no-reply@tivix.com.br>"),
+		MailFileDir:   getEnv("MAIL_FILE_DIR", "tmp/mail"),
+		SMTPHost:      getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:      getEnv("SMTP_PORT", "587"),
+		SMTPUsername:  getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:  getEnv("SMTP_PASSWORD", ""),
 	}
 }
 
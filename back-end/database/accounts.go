@@ -0,0 +1,276 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// Os repositórios de login e credenciais usam a conexão DB mesmo dentro de uma requisição com
+// transação de escopo: é a exceção explícita às políticas de RLS, já que o papel tivix_app
+// não tem acesso a sessões, tokens, 2FA e links de senha.
+
+// recordSecurityEvent grava o evento de segurança, quando informado
+func recordSecurityEvent(q sqlx.Execer, event *models.SecurityEvent) error {
+	if event == nil {
+		return nil
+	}
+	details := event.Details
+	if details == nil {
+		details = models.JSONB{}
+	}
+	_, err := q.Exec(`
+		INSERT INTO security_events (event_type, user_id, company_id, email, ip_address, details, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, event.EventType, event.UserID, event.CompanyID, event.Email, event.IPAddress, details, event.CreatedBy)
+	return err
+}
+
+// ownTx roda fn em uma transação própria da conexão DB, confirmada ao final
+func ownTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// seconds converte a duração para o parâmetro dos intervalos em SQL
+func seconds(d time.Duration) int {
+	return int(d.Seconds())
+}
+
+type accountRepository struct{}
+
+func (accountRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := DB.GetContext(ctx, &count, "SELECT COUNT(*) FROM users")
+	return count, err
+}
+
+func (accountRepository) Get(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := DB.GetContext(ctx, &user, "SELECT * FROM users WHERE id = $1", id); err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (accountRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	err := DB.GetContext(ctx, &user, `
+		SELECT * FROM users
+		WHERE email = $1 AND NOT EXISTS (SELECT 1 FROM service_accounts sa WHERE sa.user_id = users.id)
+	`, email)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (accountRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var exists bool
+	err := DB.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", email)
+	return exists, err
+}
+
+func (accountRepository) Create(ctx context.Context, user *models.User) error {
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO users (id, email, password, name, role, company_id, needs_password_change, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, user.ID, user.Email, user.Password, user.Name, user.Role, user.CompanyID, user.NeedsPasswordChange, user.IsActive, user.CreatedAt, user.UpdatedAt)
+	return err
+}
+
+func (accountRepository) SetPassword(ctx context.Context, id uuid.UUID, hashedPassword string, clearPasswordChange bool) error {
+	query := `UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP`
+	if clearPasswordChange {
+		query += `, needs_password_change = false`
+	}
+	query += ` WHERE id = $2`
+
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.Exec(query, hashedPassword, id)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return repository.ErrNotFound
+		}
+		return RevokeUserSessions(tx, id, models.SessionRevokedPasswordChange)
+	})
+}
+
+type loginThrottleRepository struct{}
+
+func (loginThrottleRepository) Get(ctx context.Context, key string) (*repository.LoginThrottle, error) {
+	var state struct {
+		FailedAttempts   int     `db:"failed_attempts"`
+		LockSeconds      float64 `db:"lock_seconds"`
+		SinceLastFailure float64 `db:"since_last_failure"`
+	}
+	err := DB.GetContext(ctx, &state, `
+		SELECT failed_attempts,
+		       GREATEST(COALESCE(EXTRACT(EPOCH FROM (locked_until - CURRENT_TIMESTAMP)), 0), 0) AS lock_seconds,
+		       COALESCE(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - last_failed_at)), 0) AS since_last_failure
+		FROM login_throttles
+		WHERE email = $1
+	`, key)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return &repository.LoginThrottle{
+		FailedAttempts:   state.FailedAttempts,
+		LockedFor:        time.Duration(state.LockSeconds * float64(time.Second)),
+		SinceLastFailure: time.Duration(state.SinceLastFailure * float64(time.Second)),
+	}, nil
+}
+
+func (loginThrottleRepository) RecordFailure(ctx context.Context, key string, lockEvent *models.SecurityEvent) (int, bool, error) {
+	var failedAttempts int
+	var locked bool
+	err := ownTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(&failedAttempts, `
+			INSERT INTO login_throttles (email, failed_attempts, last_failed_at, updated_at)
+			VALUES ($1, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+			ON CONFLICT (email) DO UPDATE
+			SET failed_attempts = login_throttles.failed_attempts + 1,
+			    last_failed_at = CURRENT_TIMESTAMP,
+			    updated_at = CURRENT_TIMESTAMP
+			RETURNING failed_attempts
+		`, key)
+		if err != nil || failedAttempts < models.LoginLockoutAfter {
+			return err
+		}
+
+		locked = true
+		_, err = tx.Exec(`
+			UPDATE login_throttles
+			SET failed_attempts = 0, locked_until = CURRENT_TIMESTAMP + $1 * INTERVAL '1 second', updated_at = CURRENT_TIMESTAMP
+			WHERE email = $2
+		`, seconds(models.LoginLockoutDuration), key)
+		if err != nil {
+			return err
+		}
+
+		if lockEvent != nil {
+			if lockEvent.Details == nil {
+				lockEvent.Details = models.JSONB{}
+			}
+			lockEvent.Details["failedAttempts"] = failedAttempts
+		}
+		return recordSecurityEvent(tx, lockEvent)
+	})
+	return failedAttempts, locked, err
+}
+
+func (loginThrottleRepository) Clear(ctx context.Context, key string, event *models.SecurityEvent) error {
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec("DELETE FROM login_throttles WHERE email = $1", key); err != nil {
+			return err
+		}
+		return recordSecurityEvent(tx, event)
+	})
+}
+
+type passwordResetRepository struct{}
+
+func (passwordResetRepository) Create(ctx context.Context, userID uuid.UUID, tokenHash, ip string, perHour int) error {
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		// Serializa pedidos simultâneos do mesmo usuário
+		if _, err := tx.Exec("SELECT id FROM users WHERE id = $1 FOR UPDATE", userID); err != nil {
+			return err
+		}
+
+		var recent int
+		err := tx.Get(&recent, `
+			SELECT COUNT(*) FROM password_reset_tokens
+			WHERE user_id = $1 AND created_at > CURRENT_TIMESTAMP - INTERVAL '1 hour'
+		`, userID)
+		if err != nil {
+			return err
+		}
+		if recent >= perHour {
+			return repository.ErrRateLimited
+		}
+
+		_, err = tx.Exec(`
+			UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1 AND used_at IS NULL
+		`, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, requested_ip)
+			VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second', $4)
+		`, userID, tokenHash, seconds(models.PasswordResetTTL), ip)
+		return err
+	})
+}
+
+func (passwordResetRepository) User(ctx context.Context, tokenHash string) (*models.User, error) {
+	var user models.User
+	err := DB.GetContext(ctx, &user, `
+		SELECT u.* FROM users u
+		INNER JOIN password_reset_tokens t ON t.user_id = u.id
+		WHERE t.token_hash = $1 AND t.used_at IS NULL AND t.expires_at > CURRENT_TIMESTAMP
+	`, tokenHash)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (passwordResetRepository) Reset(ctx context.Context, tokenHash, hashedPassword string, event *models.SecurityEvent) error {
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		// O link é consumido primeiro: em pedidos simultâneos apenas um encontra a linha
+		var userID uuid.UUID
+		err := tx.Get(&userID, `
+			UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+			RETURNING user_id
+		`, tokenHash)
+		if err == sql.ErrNoRows {
+			return repository.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE users
+			SET password = $1, needs_password_change = false, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+		`, hashedPassword, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1 AND used_at IS NULL
+		`, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := RevokeUserSessions(tx, userID, models.SessionRevokedPasswordChange); err != nil {
+			return err
+		}
+		return recordSecurityEvent(tx, event)
+	})
+}
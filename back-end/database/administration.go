@@ -0,0 +1,396 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// duplicate traduz a violação de chave única do Postgres para o erro dos repositórios
+func duplicate(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return repository.ErrDuplicate
+	}
+	return err
+}
+
+const companyRoleSelect = `
+	SELECT r.id, r.company_id, r.name, r.description, r.permissions,
+	       (SELECT COUNT(*) FROM user_role_assignments ra WHERE ra.role_id = r.id) AS user_count,
+	       r.created_by, r.updated_by, r.created_at, r.updated_at
+	FROM company_roles r`
+
+type companyRoleRepository struct{}
+
+func (companyRoleRepository) List(ctx context.Context, companyID *uuid.UUID) ([]models.CompanyRole, error) {
+	args := queryArgs{}
+	query := companyRoleSelect + ` WHERE 1=1` + companyFilter(repository.ScopeFrom(ctx), "r.company_id", &args)
+	if companyID != nil {
+		query += ` AND r.company_id = ` + args.add(*companyID)
+	}
+	query += ` ORDER BY r.name`
+
+	roles := []models.CompanyRole{}
+	err := sqlx.Select(conn(ctx), &roles, query, args...)
+	return roles, err
+}
+
+func (companyRoleRepository) Get(ctx context.Context, id uuid.UUID) (*models.CompanyRole, error) {
+	args := queryArgs{id}
+	query := companyRoleSelect + ` WHERE r.id = $1` + companyFilter(repository.ScopeFrom(ctx), "r.company_id", &args)
+
+	var role models.CompanyRole
+	if err := sqlx.Get(conn(ctx), &role, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &role, nil
+}
+
+func (companyRoleRepository) ForUser(ctx context.Context, userID uuid.UUID) (*models.CompanyRole, error) {
+	args := queryArgs{userID}
+	query := companyRoleSelect + `
+		INNER JOIN user_role_assignments a ON a.role_id = r.id
+		WHERE a.user_id = $1` + companyFilter(repository.ScopeFrom(ctx), "r.company_id", &args)
+
+	var role models.CompanyRole
+	err := sqlx.Get(conn(ctx), &role, query, args...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (companyRoleRepository) Create(ctx context.Context, role *models.CompanyRole) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&role.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	err := sqlx.Get(conn(ctx), &role.ID, `
+		INSERT INTO company_roles (company_id, name, description, permissions, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING id
+	`, role.CompanyID, role.Name, role.Description, role.Permissions, role.CreatedBy)
+	return duplicate(err)
+}
+
+func (companyRoleRepository) Update(ctx context.Context, role *models.CompanyRole) error {
+	args := queryArgs{role.Name, role.Description, role.Permissions, role.UpdatedBy, role.ID}
+	query := `
+		UPDATE company_roles
+		SET name = $1, description = $2, permissions = $3, updated_by = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5` + companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	result, err := conn(ctx).Exec(query, args...)
+	if err != nil {
+		return duplicate(err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (companyRoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := queryArgs{id}
+	query := `DELETE FROM company_roles WHERE id = $1` + companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	result, err := conn(ctx).Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (companyRoleRepository) Assign(ctx context.Context, userID uuid.UUID, roleID *uuid.UUID, assignedBy uuid.UUID) error {
+	if roleID == nil {
+		_, err := conn(ctx).Exec("DELETE FROM user_role_assignments WHERE user_id = $1", userID)
+		return err
+	}
+
+	_, err := conn(ctx).Exec(`
+		INSERT INTO user_role_assignments (user_id, role_id, assigned_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET role_id = EXCLUDED.role_id, assigned_by = EXCLUDED.assigned_by, assigned_at = CURRENT_TIMESTAMP
+	`, userID, *roleID, assignedBy)
+	return err
+}
+
+const serviceAccountSelect = `
+	SELECT u.id, sa.company_id, u.name, sa.description, u.role, u.is_active,
+	       (SELECT COUNT(*) FROM api_tokens t
+	        WHERE t.user_id = u.id AND t.revoked_at IS NULL AND t.expires_at > CURRENT_TIMESTAMP) AS active_tokens,
+	       sa.created_by, sa.created_at, sa.updated_at
+	FROM service_accounts sa
+	INNER JOIN users u ON u.id = sa.user_id`
+
+type serviceAccountRepository struct{}
+
+func (serviceAccountRepository) List(ctx context.Context, companyID *uuid.UUID) ([]models.ServiceAccount, error) {
+	args := queryArgs{}
+	query := serviceAccountSelect + ` WHERE 1=1` + companyFilter(repository.ScopeFrom(ctx), "sa.company_id", &args)
+	if companyID != nil {
+		query += ` AND sa.company_id = ` + args.add(*companyID)
+	}
+	query += ` ORDER BY u.name`
+
+	accounts := []models.ServiceAccount{}
+	err := sqlx.Select(conn(ctx), &accounts, query, args...)
+	return accounts, err
+}
+
+func (serviceAccountRepository) Get(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	args := queryArgs{id}
+	query := serviceAccountSelect + ` WHERE sa.user_id = $1` + companyFilter(repository.ScopeFrom(ctx), "sa.company_id", &args)
+
+	var account models.ServiceAccount
+	if err := sqlx.Get(conn(ctx), &account, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &account, nil
+}
+
+func (serviceAccountRepository) Create(ctx context.Context, account *models.ServiceAccount, user *models.User) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&account.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	return inTx(ctx, func(q sqlx.Ext) error {
+		_, err := q.Exec(`
+			INSERT INTO users (id, email, password, name, role, company_id, needs_password_change, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, false, true)
+		`, user.ID, user.Email, user.Password, account.Name, account.Role, account.CompanyID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.Exec(`
+			INSERT INTO service_accounts (user_id, company_id, description, created_by)
+			VALUES ($1, $2, $3, $4)
+		`, user.ID, account.CompanyID, account.Description, account.CreatedBy)
+		return err
+	})
+}
+
+func (serviceAccountRepository) Update(ctx context.Context, account *models.ServiceAccount) error {
+	args := queryArgs{account.Description, account.ID}
+	query := `
+		UPDATE service_accounts SET description = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2` + companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	return inTx(ctx, func(q sqlx.Ext) error {
+		result, err := q.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return repository.ErrNotFound
+		}
+
+		_, err = q.Exec(`
+			UPDATE users SET name = $1, role = $2, is_active = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4
+		`, account.Name, account.Role, account.IsActive, account.ID)
+		return err
+	})
+}
+
+func (serviceAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := queryArgs{id}
+	query := `
+		DELETE FROM users
+		WHERE id = $1 AND EXISTS (SELECT 1 FROM service_accounts sa WHERE sa.user_id = users.id` +
+		companyFilter(repository.ScopeFrom(ctx), "sa.company_id", &args) + `)`
+
+	result, err := conn(ctx).Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+const invitationColumns = `id, user_id, company_id, email, name, role, status,
+	status = 'pending' AND expires_at <= CURRENT_TIMESTAMP AS expired,
+	expires_at, invited_by, send_count, last_sent_at, accepted_at, revoked_at, revoked_by,
+	created_at, updated_at`
+
+// invitationRepository gerencia os convites com a transação de escopo; Preview e Accept usam a
+// conexão DB, já que o convidado ainda não tem login
+type invitationRepository struct{}
+
+func (invitationRepository) List(ctx context.Context, filter repository.InvitationFilter) ([]models.UserInvitation, error) {
+	args := queryArgs{}
+	query := `SELECT ` + invitationColumns + ` FROM user_invitations WHERE 1=1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+	if filter.CompanyID != nil {
+		query += ` AND company_id = ` + args.add(*filter.CompanyID)
+	}
+	if filter.Status != "" {
+		query += ` AND status = ` + args.add(filter.Status)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	invitations := []models.UserInvitation{}
+	err := sqlx.Select(conn(ctx), &invitations, query, args...)
+	return invitations, err
+}
+
+func (invitationRepository) Get(ctx context.Context, id uuid.UUID) (*models.UserInvitation, error) {
+	args := queryArgs{id}
+	query := `SELECT ` + invitationColumns + ` FROM user_invitations WHERE id = $1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	var invitation models.UserInvitation
+	if err := sqlx.Get(conn(ctx), &invitation, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &invitation, nil
+}
+
+func (invitationRepository) Create(ctx context.Context, invitation *models.UserInvitation, pendingUser *models.User, tokenHash string) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&invitation.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	return inTx(ctx, func(q sqlx.Ext) error {
+		err := q.QueryRowx(`
+			INSERT INTO users (email, password, name, role, company_id, needs_password_change, is_active)
+			VALUES ($1, $2, $3, $4, $5, true, false)
+			RETURNING id
+		`, invitation.Email, pendingUser.Password, invitation.Name, invitation.Role, invitation.CompanyID).Scan(&pendingUser.ID)
+		if err != nil {
+			return err
+		}
+
+		return q.QueryRowx(`
+			INSERT INTO user_invitations (user_id, company_id, email, name, role, token_hash, expires_at, invited_by)
+			VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP + $7 * INTERVAL '1 second', $8)
+			RETURNING `+invitationColumns,
+			pendingUser.ID, invitation.CompanyID, invitation.Email, invitation.Name, invitation.Role, tokenHash,
+			seconds(models.InvitationTTL), invitation.InvitedBy,
+		).StructScan(invitation)
+	})
+}
+
+func (invitationRepository) Renew(ctx context.Context, invitation *models.UserInvitation, tokenHash string) error {
+	args := queryArgs{tokenHash, seconds(models.InvitationTTL), invitation.ID}
+	query := `
+		UPDATE user_invitations
+		SET token_hash = $1, expires_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 second',
+		    send_count = send_count + 1, last_sent_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = 'pending'` + companyFilter(repository.ScopeFrom(ctx), "company_id", &args) + `
+		RETURNING ` + invitationColumns
+
+	return notFound(sqlx.Get(conn(ctx), invitation, query, args...))
+}
+
+func (invitationRepository) Revoke(ctx context.Context, invitation *models.UserInvitation, revokedBy uuid.UUID) error {
+	args := queryArgs{revokedBy, invitation.ID}
+	query := `
+		UPDATE user_invitations
+		SET status = 'revoked', revoked_at = CURRENT_TIMESTAMP, revoked_by = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = 'pending'` + companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	return inTx(ctx, func(q sqlx.Ext) error {
+		result, err := q.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return repository.ErrNotFound
+		}
+
+		// A conta pendente nunca foi usada; sem o convite ela não tem como ser ativada
+		if invitation.UserID != nil {
+			if _, err := q.Exec("DELETE FROM users WHERE id = $1 AND is_active = false", *invitation.UserID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (invitationRepository) EmailNames(ctx context.Context, invitation *models.UserInvitation) (string, string, error) {
+	var names struct {
+		CompanyName string `db:"company_name"`
+		InviterName string `db:"inviter_name"`
+	}
+	err := sqlx.Get(conn(ctx), &names, `
+		SELECT c.name AS company_name, COALESCE(u.name, 'Um administrador') AS inviter_name
+		FROM companies c
+		LEFT JOIN users u ON u.id = $2
+		WHERE c.id = $1
+	`, invitation.CompanyID, invitation.InvitedBy)
+	return names.CompanyName, names.InviterName, notFound(err)
+}
+
+func (invitationRepository) Preview(ctx context.Context, tokenHash string) (*models.InvitationPreview, error) {
+	var preview models.InvitationPreview
+	err := DB.GetContext(ctx, &preview, `
+		SELECT i.email, i.name, i.role, c.name AS company_name, i.expires_at
+		FROM user_invitations i
+		INNER JOIN companies c ON c.id = i.company_id
+		WHERE i.token_hash = $1 AND i.status = 'pending' AND i.expires_at > CURRENT_TIMESTAMP
+		  AND i.user_id IS NOT NULL
+	`, tokenHash)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &preview, nil
+}
+
+func (invitationRepository) Accept(ctx context.Context, tokenHash, hashedPassword string) (*models.User, error) {
+	var user models.User
+	err := ownTx(ctx, func(tx *sqlx.Tx) error {
+		var invitation struct {
+			ID     uuid.UUID `db:"id"`
+			UserID uuid.UUID `db:"user_id"`
+		}
+		err := tx.Get(&invitation, `
+			SELECT id, user_id
+			FROM user_invitations
+			WHERE token_hash = $1 AND status = 'pending' AND expires_at > CURRENT_TIMESTAMP
+			  AND user_id IS NOT NULL
+			FOR UPDATE
+		`, tokenHash)
+		if err != nil {
+			return notFound(err)
+		}
+
+		err = tx.Get(&user, `
+			UPDATE users
+			SET password = $1, is_active = true, needs_password_change = false, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+			RETURNING *
+		`, hashedPassword, invitation.UserID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE user_invitations
+			SET status = 'accepted', accepted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, invitation.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+const apiTokenColumns = `id, user_id, name, token_prefix, scopes, expires_at, last_used_at, last_used_ip,
+	created_by, revoked_at, revoked_by, created_at`
+
+type apiTokenRepository struct{}
+
+func (apiTokenRepository) List(ctx context.Context, userID uuid.UUID) ([]models.APIToken, error) {
+	tokens := []models.APIToken{}
+	err := DB.SelectContext(ctx, &tokens, `
+		SELECT `+apiTokenColumns+`
+		FROM api_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	return tokens, err
+}
+
+func (apiTokenRepository) Get(ctx context.Context, id, ownerID uuid.UUID) (*models.APIToken, error) {
+	var token models.APIToken
+	err := DB.GetContext(ctx, &token, `
+		SELECT `+apiTokenColumns+`
+		FROM api_tokens
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, id, ownerID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (apiTokenRepository) Create(ctx context.Context, token *models.APIToken, tokenHash string, event *models.SecurityEvent) error {
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(token, `
+			INSERT INTO api_tokens (id, user_id, name, token_prefix, token_hash, scopes, expires_at, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING `+apiTokenColumns,
+			token.ID, token.UserID, token.Name, token.Prefix, tokenHash, token.Scopes, token.ExpiresAt, token.CreatedBy,
+		)
+		if err != nil {
+			return err
+		}
+		return recordSecurityEvent(tx, event)
+	})
+}
+
+func (apiTokenRepository) Revoke(ctx context.Context, token *models.APIToken, revokedBy uuid.UUID, event *models.SecurityEvent) error {
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(token, `
+			UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP, revoked_by = $3
+			WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+			RETURNING `+apiTokenColumns,
+			token.ID, token.UserID, revokedBy,
+		)
+		if err == sql.ErrNoRows {
+			return repository.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return recordSecurityEvent(tx, event)
+	})
+}
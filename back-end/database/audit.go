@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+const auditEventColumns = `id, chain_key, seq, company_id, actor_id, actor_email, action, method, path, status_code,
+	entity_type, entity_id, before_data, after_data, ip_address, request_id, prev_hash, hash, created_at`
+
+// auditRepository grava os eventos com a própria transação de audit.Record, independente da
+// transação da requisição: a alteração desfeita também fica registrada
+type auditRepository struct{}
+
+func (auditRepository) Record(ctx context.Context, event *models.AuditEvent) error {
+	return audit.Record(DB, event)
+}
+
+func (auditRepository) List(ctx context.Context, filter repository.AuditFilter) ([]models.AuditEvent, error) {
+	args := queryArgs{}
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events WHERE 1=1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+	if filter.CompanyID != nil {
+		query += ` AND company_id = ` + args.add(*filter.CompanyID)
+	}
+	if filter.EntityType != "" {
+		query += ` AND entity_type = ` + args.add(filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query += ` AND entity_id = ` + args.add(filter.EntityID)
+	}
+	if filter.Action != "" {
+		query += ` AND action = ` + args.add(filter.Action)
+	}
+	if filter.Method != "" {
+		query += ` AND method = ` + args.add(filter.Method)
+	}
+	if filter.ActorID != nil {
+		query += ` AND actor_id = ` + args.add(*filter.ActorID)
+	}
+	if filter.From != nil {
+		query += ` AND created_at >= ` + args.add(filter.From.UTC())
+	}
+	if filter.To != nil {
+		query += ` AND created_at < ` + args.add(filter.To.UTC())
+	}
+	if filter.BeforeID > 0 {
+		query += ` AND id < ` + args.add(filter.BeforeID)
+	}
+	query += ` ORDER BY id DESC LIMIT ` + args.add(filter.Limit)
+
+	events := []models.AuditEvent{}
+	err := sqlx.Select(conn(ctx), &events, query, args...)
+	return events, err
+}
+
+func (auditRepository) Chain(ctx context.Context, chainKey string) ([]models.AuditEvent, error) {
+	args := queryArgs{chainKey}
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events WHERE chain_key = $1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args) + ` ORDER BY seq`
+
+	events := []models.AuditEvent{}
+	err := sqlx.Select(conn(ctx), &events, query, args...)
+	return events, err
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+const calibrationSessionColumns = `id, cycle_id, company_id, name, status, created_by, committed_at, committed_by,
+	created_at, updated_at`
+
+type calibrationRepository struct{}
+
+func (calibrationRepository) ListSessions(ctx context.Context, cycleID uuid.UUID) ([]models.CalibrationSession, error) {
+	args := queryArgs{cycleID}
+	query := `SELECT ` + calibrationSessionColumns + ` FROM calibration_sessions WHERE cycle_id = $1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args) + ` ORDER BY created_at DESC`
+
+	sessions := []models.CalibrationSession{}
+	err := sqlx.Select(conn(ctx), &sessions, query, args...)
+	return sessions, err
+}
+
+func (calibrationRepository) GetSession(ctx context.Context, id uuid.UUID) (*models.CalibrationSession, error) {
+	args := queryArgs{id}
+	query := `SELECT ` + calibrationSessionColumns + ` FROM calibration_sessions WHERE id = $1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	var session models.CalibrationSession
+	if err := sqlx.Get(conn(ctx), &session, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &session, nil
+}
+
+func (calibrationRepository) HasOpenSession(ctx context.Context, cycleID uuid.UUID) (bool, error) {
+	var exists bool
+	err := sqlx.Get(conn(ctx), &exists,
+		"SELECT EXISTS(SELECT 1 FROM calibration_sessions WHERE cycle_id = $1 AND status = $2)",
+		cycleID, models.CalibrationSessionOpen,
+	)
+	return exists, err
+}
+
+func (calibrationRepository) CreateSession(ctx context.Context, session *models.CalibrationSession) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&session.CompanyID) {
+		return repository.ErrNotFound
+	}
+	return sqlx.Get(conn(ctx), session, `
+		INSERT INTO calibration_sessions (cycle_id, company_id, name, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+calibrationSessionColumns,
+		session.CycleID, session.CompanyID, session.Name, session.CreatedBy,
+	)
+}
+
+func (calibrationRepository) Adjustments(ctx context.Context, sessionID uuid.UUID) ([]models.CalibrationAdjustment, error) {
+	args := queryArgs{sessionID}
+	query := `
+		SELECT a.id, a.session_id, a.report_id, pr.developer_id, d.name AS developer_name,
+		       a.original_score, a.proposed_score, a.justification, a.proposed_by, a.created_at, a.updated_at
+		FROM calibration_adjustments a
+		INNER JOIN performance_reports pr ON pr.id = a.report_id
+		INNER JOIN developers d ON d.id = pr.developer_id
+		WHERE a.session_id = $1` +
+		teamFilter(repository.ScopeFrom(ctx), "d.team_id", &args) + `
+		ORDER BY d.name ASC
+	`
+
+	adjustments := []models.CalibrationAdjustment{}
+	err := sqlx.Select(conn(ctx), &adjustments, query, args...)
+	return adjustments, err
+}
+
+func (calibrationRepository) Scores(ctx context.Context, cycle *models.ReviewCycle, sessionID uuid.UUID) ([]models.CalibrationScore, error) {
+	args := queryArgs{cycle.ID, sessionID, cycle.Month, cycle.CompanyID}
+	query := `
+		SELECT pr.id AS report_id, d.id AS developer_id, d.name AS developer_name, d.team_id, t.name AS team_name,
+		       COALESCE(a.evaluator_id, pr.submitted_by) AS evaluator_id, u.name AS evaluator_name,
+		       pr.weighted_average_score AS score, pr.calibrated_score, ca.proposed_score
+		FROM performance_reports pr
+		INNER JOIN developers d ON d.id = pr.developer_id
+		LEFT JOIN teams t ON t.id = d.team_id
+		LEFT JOIN review_cycle_assignments a ON a.cycle_id = $1 AND a.developer_id = d.id
+		LEFT JOIN users u ON u.id = COALESCE(a.evaluator_id, pr.submitted_by)
+		LEFT JOIN calibration_adjustments ca ON ca.session_id = $2 AND ca.report_id = pr.id
+		WHERE pr.month = $3
+		  AND d.company_id = $4
+		  AND pr.status IN ` + PublishedReportStatuses +
+		cycleTeamsFilter("d.team_id", "$1") +
+		teamFilter(repository.ScopeFrom(ctx), "d.team_id", &args) + `
+		ORDER BY u.name ASC NULLS LAST, d.name ASC
+	`
+
+	scores := []models.CalibrationScore{}
+	err := sqlx.Select(conn(ctx), &scores, query, args...)
+	return scores, err
+}
+
+func (calibrationRepository) CycleReport(ctx context.Context, cycle *models.ReviewCycle, reportID uuid.UUID) (*models.PerformanceReport, error) {
+	args := queryArgs{reportID, cycle.Month, cycle.CompanyID}
+	query := `
+		SELECT ` + PrefixedPerformanceReportColumns + `
+		FROM performance_reports pr
+		INNER JOIN developers d ON d.id = pr.developer_id
+		WHERE pr.id = $1 AND pr.month = $2 AND d.company_id = $3` +
+		teamFilter(repository.ScopeFrom(ctx), "d.team_id", &args)
+
+	var report models.PerformanceReport
+	if err := sqlx.Get(conn(ctx), &report, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &report, nil
+}
+
+func (calibrationRepository) Propose(ctx context.Context, adjustment *models.CalibrationAdjustment) error {
+	return sqlx.Get(conn(ctx), &adjustment.ID, `
+		INSERT INTO calibration_adjustments (session_id, report_id, original_score, proposed_score, justification, proposed_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (session_id, report_id) DO UPDATE
+		SET original_score = EXCLUDED.original_score,
+		    proposed_score = EXCLUDED.proposed_score,
+		    justification = EXCLUDED.justification,
+		    proposed_by = EXCLUDED.proposed_by
+		RETURNING id
+	`, adjustment.SessionID, adjustment.ReportID, adjustment.OriginalScore, adjustment.ProposedScore,
+		adjustment.Justification, adjustment.ProposedBy)
+}
+
+func (calibrationRepository) RemoveAdjustment(ctx context.Context, sessionID, reportID uuid.UUID) error {
+	args := queryArgs{sessionID, reportID}
+	query := `DELETE FROM calibration_adjustments WHERE session_id = $1 AND report_id = $2
+		AND report_id IN (SELECT pr.id FROM performance_reports pr WHERE pr.id = $2` +
+		developerTeamFilter(repository.ScopeFrom(ctx), "pr.developer_id", &args) + `)`
+
+	result, err := conn(ctx).Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (calibrationRepository) Commit(ctx context.Context, sessionID, actor uuid.UUID) (*models.CalibrationSession, []models.CalibrationAdjustment, error) {
+	scope := repository.ScopeFrom(ctx)
+	var session models.CalibrationSession
+	adjustments := []models.CalibrationAdjustment{}
+
+	err := inTx(ctx, func(q sqlx.Ext) error {
+		// Bloqueia a sessão para evitar confirmações simultâneas
+		args := queryArgs{sessionID}
+		query := `SELECT ` + calibrationSessionColumns + ` FROM calibration_sessions WHERE id = $1` +
+			companyFilter(scope, "company_id", &args) + ` FOR UPDATE`
+		if err := sqlx.Get(q, &session, query, args...); err != nil {
+			return notFound(err)
+		}
+		if session.Status != models.CalibrationSessionOpen {
+			return repository.ErrCalibrationCommitted
+		}
+
+		err := sqlx.Select(q, &adjustments, `
+			SELECT id, session_id, report_id, original_score, proposed_score, justification, proposed_by, created_at, updated_at
+			FROM calibration_adjustments
+			WHERE session_id = $1
+		`, session.ID)
+		if err != nil {
+			return err
+		}
+		if len(adjustments) == 0 {
+			return repository.ErrNoAdjustments
+		}
+
+		for _, adjustment := range adjustments {
+			existing, companyID, err := LockReportForChange(q, adjustment.ReportID)
+			if err != nil {
+				return err
+			}
+			// Relatórios de outra empresa ou de times fora do escopo são tratados como inexistentes
+			if !scope.OwnsCompany(companyID) {
+				return repository.ErrNotFound
+			}
+			if err := developerInScope(q, scope, existing.DeveloperID); err != nil {
+				return err
+			}
+
+			// A proposta foi feita sobre uma nota que não existe mais
+			if existing.WeightedAverageScore != adjustment.OriginalScore || !models.IsReportPublished(existing.Status) {
+				return &repository.StaleAdjustmentError{ReportID: existing.ID}
+			}
+
+			var updated models.PerformanceReport
+			err = sqlx.Get(q, &updated, `
+				UPDATE performance_reports
+				SET calibrated_score = $1, calibration_adjustment_id = $2
+				WHERE id = $3
+				RETURNING `+PerformanceReportColumns,
+				adjustment.ProposedScore, adjustment.ID, existing.ID,
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := RecordReportRevision(q, updated.ID, companyID, "calibrate", actor, existing, &updated); err != nil {
+				return err
+			}
+		}
+
+		return sqlx.Get(q, &session, `
+			UPDATE calibration_sessions
+			SET status = $1, committed_at = CURRENT_TIMESTAMP, committed_by = $2
+			WHERE id = $3
+			RETURNING `+calibrationSessionColumns,
+			models.CalibrationSessionCommitted, actor, session.ID,
+		)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &session, adjustments, nil
+}
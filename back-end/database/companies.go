@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+const companyColumns = `id, name, description, is_active, created_at, updated_at`
+
+type companyRepository struct{}
+
+// companyScopeFilter restringe a empresa à do escopo, exceto com AllCompanies
+func companyScopeFilter(scope repository.Scope, args *queryArgs) string {
+	return companyFilter(scope, "id", args)
+}
+
+// companySortColumns são as colunas dos campos de repository.CompanySorting
+var companySortColumns = map[string]string{
+	"name":      "name",
+	"createdAt": "created_at",
+}
+
+func (companyRepository) List(ctx context.Context, filter repository.CompanyFilter, page repository.PageRequest) (*repository.Page[models.Company], error) {
+	args := queryArgs{}
+	from := `FROM companies WHERE 1=1` + companyScopeFilter(repository.ScopeFrom(ctx), &args)
+	if filter.IsActive != nil {
+		from += ` AND is_active = ` + args.add(*filter.IsActive)
+	}
+
+	return listPage(ctx, repository.CompanySorting, companySortColumns, companyColumns, from, args, page)
+}
+
+func (companyRepository) Get(ctx context.Context, id uuid.UUID) (*models.Company, error) {
+	args := queryArgs{id}
+	query := `SELECT ` + companyColumns + ` FROM companies WHERE id = $1` +
+		companyScopeFilter(repository.ScopeFrom(ctx), &args)
+
+	var company models.Company
+	if err := sqlx.Get(conn(ctx), &company, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &company, nil
+}
+
+func (companyRepository) NameExists(ctx context.Context, name string, exceptID *uuid.UUID) (bool, error) {
+	args := queryArgs{name}
+	query := `SELECT EXISTS(SELECT 1 FROM companies WHERE name = $1`
+	if exceptID != nil {
+		query += ` AND id != ` + args.add(*exceptID)
+	}
+	query += `)`
+
+	var exists bool
+	err := sqlx.Get(conn(ctx), &exists, query, args...)
+	return exists, err
+}
+
+func (companyRepository) IsActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := queryArgs{id}
+	query := `SELECT EXISTS(SELECT 1 FROM companies WHERE id = $1 AND is_active = true` +
+		companyScopeFilter(repository.ScopeFrom(ctx), &args) + `)`
+
+	var active bool
+	err := sqlx.Get(conn(ctx), &active, query, args...)
+	return active, err
+}
+
+func (companyRepository) Create(ctx context.Context, company *models.Company) error {
+	if !repository.ScopeFrom(ctx).AllCompanies {
+		return repository.ErrNotFound
+	}
+
+	_, err := conn(ctx).Exec(`
+		INSERT INTO companies (id, name, description, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, company.ID, company.Name, company.Description, company.IsActive, company.CreatedAt, company.UpdatedAt)
+	return err
+}
+
+func (companyRepository) Update(ctx context.Context, id uuid.UUID, update repository.CompanyUpdate) (*models.Company, error) {
+	setParts := []string{}
+	args := queryArgs{}
+	if update.Name != nil {
+		setParts = append(setParts, "name = "+args.add(*update.Name))
+	}
+	if update.Description != nil {
+		setParts = append(setParts, "description = "+args.add(*update.Description))
+	}
+	if update.IsActive != nil {
+		setParts = append(setParts, "is_active = "+args.add(*update.IsActive))
+	}
+	if len(setParts) == 0 {
+		return nil, repository.ErrNoChanges
+	}
+	setParts = append(setParts, "updated_at = "+args.add(time.Now()))
+
+	query := `UPDATE companies SET ` + strings.Join(setParts, ", ") + ` WHERE id = ` + args.add(id) +
+		companyScopeFilter(repository.ScopeFrom(ctx), &args) +
+		` RETURNING ` + companyColumns
+
+	var company models.Company
+	if err := conn(ctx).QueryRowx(query, args...).StructScan(&company); err != nil {
+		return nil, notFound(err)
+	}
+	return &company, nil
+}
+
+func (companyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := queryArgs{id}
+	query := `DELETE FROM companies WHERE id = $1` + companyScopeFilter(repository.ScopeFrom(ctx), &args)
+
+	result, err := conn(ctx).Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (companyRepository) CountUsers(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	err := sqlx.Get(conn(ctx), &count, "SELECT COUNT(*) FROM users WHERE company_id = $1", id)
+	return count, err
+}
+
+func (companyRepository) RecomputeScores(ctx context.Context, id uuid.UUID) (int, error) {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&id) {
+		return 0, repository.ErrNotFound
+	}
+	return RecomputeLatestPerformanceScores(&id)
+}
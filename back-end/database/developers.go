@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+const developerColumns = `id, name, role, latest_performance_score, team_id, company_id, user_id, archived_at, created_at, updated_at`
+
+type developerRepository struct{}
+
+// developerScopeFilter restringe desenvolvedores à empresa do escopo e, sem AllTeams, aos
+// times do usuário
+func developerScopeFilter(scope repository.Scope, args *queryArgs) string {
+	return companyFilter(scope, "company_id", args) + teamFilter(scope, "team_id", args)
+}
+
+// developerInScope devolve repository.ErrNotFound se o desenvolvedor está fora do escopo
+func developerInScope(q sqlx.Queryer, scope repository.Scope, developerID uuid.UUID) error {
+	args := queryArgs{developerID}
+	query := `SELECT EXISTS(SELECT 1 FROM developers WHERE id = $1` + developerScopeFilter(scope, &args) + `)`
+
+	var accessible bool
+	if err := sqlx.Get(q, &accessible, query, args...); err != nil {
+		return err
+	}
+	if !accessible {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// updateDeveloper aplica o SET informado ao desenvolvedor do escopo e devolve o registro atualizado
+func updateDeveloper(ctx context.Context, id uuid.UUID, set string, args queryArgs) (*models.Developer, error) {
+	query := `UPDATE developers SET ` + set + ` WHERE id = ` + args.add(id) +
+		developerScopeFilter(repository.ScopeFrom(ctx), &args) +
+		` RETURNING ` + developerColumns
+
+	var developer models.Developer
+	if err := conn(ctx).QueryRowx(query, args...).StructScan(&developer); err != nil {
+		return nil, notFound(err)
+	}
+	return &developer, nil
+}
+
+// developerSortColumns são as colunas dos campos de repository.DeveloperSorting; sem data de
+// arquivamento o desenvolvedor ordena como a data zero, a mesma usada no cursor
+var developerSortColumns = map[string]string{
+	"name":                   "name",
+	"role":                   "role",
+	"latestPerformanceScore": "latest_performance_score",
+	"createdAt":              "created_at",
+	"archivedAt":             "COALESCE(archived_at, '0001-01-01')",
+}
+
+func (developerRepository) List(ctx context.Context, filter repository.DeveloperFilter, page repository.PageRequest) (*repository.Page[models.Developer], error) {
+	args := queryArgs{}
+	from := `FROM developers WHERE 1=1` + developerScopeFilter(repository.ScopeFrom(ctx), &args)
+
+	if filter.TeamID != nil {
+		from += ` AND team_id = ` + args.add(*filter.TeamID)
+	}
+	if filter.Role != "" {
+		from += ` AND role = ` + args.add(filter.Role)
+	}
+	from += scoreRangeFilter("latest_performance_score", filter.MinScore, filter.MaxScore, &args)
+
+	if filter.ArchivedOnly {
+		from += " AND archived_at IS NOT NULL"
+	} else if !filter.IncludeArchived {
+		from += " AND archived_at IS NULL"
+	}
+
+	return listPage(ctx, repository.DeveloperSorting, developerSortColumns, developerColumns, from, args, page)
+}
+
+func (developerRepository) Get(ctx context.Context, id uuid.UUID) (*models.Developer, error) {
+	args := queryArgs{id}
+	query := `SELECT ` + developerColumns + ` FROM developers WHERE id = $1` +
+		developerScopeFilter(repository.ScopeFrom(ctx), &args)
+
+	var developer models.Developer
+	if err := sqlx.Get(conn(ctx), &developer, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &developer, nil
+}
+
+func (developerRepository) GetByUser(ctx context.Context, userID uuid.UUID) (*models.Developer, error) {
+	// O próprio vínculo dá acesso ao desenvolvedor, mesmo sem time; vale apenas a empresa
+	args := queryArgs{userID}
+	query := `SELECT ` + developerColumns + ` FROM developers WHERE user_id = $1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	var developer models.Developer
+	if err := sqlx.Get(conn(ctx), &developer, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &developer, nil
+}
+
+func (developerRepository) Create(ctx context.Context, developer *models.Developer) error {
+	scope := repository.ScopeFrom(ctx)
+	if !scope.OwnsCompany(developer.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	q := conn(ctx)
+	// Sem teams:all, o desenvolvedor precisa entrar em um dos times do usuário
+	if developer.TeamID != nil {
+		if err := teamInScope(q, scope, *developer.TeamID); err != nil {
+			return err
+		}
+	} else if !scope.AllTeams {
+		return repository.ErrNotFound
+	}
+
+	return q.QueryRowx(`
+		INSERT INTO developers (name, role, team_id, company_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+developerColumns,
+		developer.Name, developer.Role, developer.TeamID, developer.CompanyID,
+	).StructScan(developer)
+}
+
+func (developerRepository) Update(ctx context.Context, id uuid.UUID, update repository.DeveloperUpdate) (*models.Developer, error) {
+	if update.TeamID != nil {
+		if err := teamInScope(conn(ctx), repository.ScopeFrom(ctx), *update.TeamID); err != nil {
+			return nil, err
+		}
+	}
+
+	setParts := []string{}
+	args := queryArgs{}
+	if update.Name != nil {
+		setParts = append(setParts, "name = "+args.add(*update.Name))
+	}
+	if update.Role != nil {
+		setParts = append(setParts, "role = "+args.add(*update.Role))
+	}
+	if update.TeamID != nil {
+		setParts = append(setParts, "team_id = "+args.add(*update.TeamID))
+	}
+	if len(setParts) == 0 {
+		return nil, repository.ErrNoChanges
+	}
+
+	return updateDeveloper(ctx, id, strings.Join(setParts, ", "), args)
+}
+
+func (developerRepository) SetUser(ctx context.Context, id uuid.UUID, userID *uuid.UUID) (*models.Developer, error) {
+	return updateDeveloper(ctx, id, "user_id = $1", queryArgs{userID})
+}
+
+func (developerRepository) IsUserLinked(ctx context.Context, userID, exceptDeveloperID uuid.UUID) (bool, error) {
+	var linked bool
+	err := sqlx.Get(conn(ctx), &linked,
+		"SELECT EXISTS(SELECT 1 FROM developers WHERE user_id = $1 AND id != $2)",
+		userID, exceptDeveloperID,
+	)
+	return linked, err
+}
+
+func (developerRepository) SetArchived(ctx context.Context, id uuid.UUID, archivedAt *time.Time) (*models.Developer, error) {
+	return updateDeveloper(ctx, id, "archived_at = $1", queryArgs{archivedAt})
+}
+
+func (developerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := queryArgs{id}
+	query := `DELETE FROM developers WHERE id = $1` + developerScopeFilter(repository.ScopeFrom(ctx), &args)
+
+	result, err := conn(ctx).Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+type mfaRepository struct{}
+
+func loadUserMFA(q sqlx.Queryer, userID uuid.UUID, forUpdate bool) (*models.UserMFA, error) {
+	query := "SELECT secret, enabled_at, last_used_step FROM user_mfa WHERE user_id = $1"
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	var mfa models.UserMFA
+	if err := sqlx.Get(q, &mfa, query, userID); err != nil {
+		return nil, notFound(err)
+	}
+	return &mfa, nil
+}
+
+// replaceRecoveryCodes descarta os códigos de recuperação anteriores e grava os novos hashes
+func replaceRecoveryCodes(q sqlx.Execer, userID uuid.UUID, codeHashes []string) error {
+	if _, err := q.Exec("DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := q.Exec("INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mfaRepository) Get(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error) {
+	return loadUserMFA(DB, userID, false)
+}
+
+func (mfaRepository) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var enabled bool
+	err := DB.GetContext(ctx, &enabled,
+		"SELECT EXISTS(SELECT 1 FROM user_mfa WHERE user_id = $1 AND enabled_at IS NOT NULL)", userID)
+	return enabled, err
+}
+
+func (mfaRepository) RecoveryCodesRemaining(ctx context.Context, userID uuid.UUID) (int, error) {
+	var remaining int
+	err := DB.GetContext(ctx, &remaining,
+		"SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL", userID)
+	return remaining, err
+}
+
+func (mfaRepository) Enroll(ctx context.Context, userID uuid.UUID, secret string) error {
+	result, err := DB.ExecContext(ctx, `
+		INSERT INTO user_mfa (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled_at = NULL, last_used_step = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE user_mfa.enabled_at IS NULL
+	`, userID, secret)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrMFAEnabled
+	}
+	return nil
+}
+
+func (mfaRepository) Enable(ctx context.Context, userID uuid.UUID, code string, recoveryCodeHashes []string, event *models.SecurityEvent) error {
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		mfa, err := loadUserMFA(tx, userID, true)
+		if err != nil {
+			return err
+		}
+		if mfa.EnabledAt != nil {
+			return repository.ErrMFAEnabled
+		}
+
+		step, valid := utils.ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep)
+		if !valid {
+			return repository.ErrInvalidCode
+		}
+
+		_, err = tx.Exec(`
+			UPDATE user_mfa SET enabled_at = CURRENT_TIMESTAMP, last_used_step = $1, updated_at = CURRENT_TIMESTAMP
+			WHERE user_id = $2
+		`, step, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+			return err
+		}
+		return recordSecurityEvent(tx, event)
+	})
+}
+
+func (mfaRepository) VerifyCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	valid := false
+	err := ownTx(ctx, func(tx *sqlx.Tx) error {
+		mfa, err := loadUserMFA(tx, userID, true)
+		if err == repository.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var step int64
+		step, valid = utils.ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep)
+		if !valid {
+			return nil
+		}
+		_, err = tx.Exec("UPDATE user_mfa SET last_used_step = $1, updated_at = CURRENT_TIMESTAMP WHERE user_id = $2", step, userID)
+		return err
+	})
+	return valid && err == nil, err
+}
+
+func (mfaRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	result, err := DB.ExecContext(ctx, `
+		UPDATE mfa_recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func (mfaRepository) Remove(ctx context.Context, userID uuid.UUID, event *models.SecurityEvent) error {
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec("DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM mfa_challenges WHERE user_id = $1", userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM user_mfa WHERE user_id = $1", userID); err != nil {
+			return err
+		}
+		return recordSecurityEvent(tx, event)
+	})
+}
+
+func (mfaRepository) CreateChallenge(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO mfa_challenges (user_id, token_hash, expires_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second')
+	`, userID, tokenHash, seconds(models.MFAChallengeTTL))
+	return err
+}
+
+func (mfaRepository) Challenge(ctx context.Context, tokenHash string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	err := DB.GetContext(ctx, &challenge, `
+		SELECT id, user_id, attempts
+		FROM mfa_challenges
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP AND attempts < $2
+	`, tokenHash, models.MFAChallengeMaxTries)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &challenge, nil
+}
+
+func (mfaRepository) FailChallenge(ctx context.Context, id uuid.UUID) error {
+	_, err := DB.ExecContext(ctx, "UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1", id)
+	return err
+}
+
+func (mfaRepository) CompleteChallenge(ctx context.Context, id uuid.UUID, event *models.SecurityEvent) error {
+	return ownTx(ctx, func(tx *sqlx.Tx) error {
+		// Em confirmações simultâneas do mesmo desafio apenas uma encontra a linha pendente
+		result, err := tx.Exec("UPDATE mfa_challenges SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL", id)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return repository.ErrNotFound
+		}
+		return recordSecurityEvent(tx, event)
+	})
+}
+
+// securityPolicyRepository usa a transação de escopo: company_security_policies tem política
+// de RLS por empresa
+type securityPolicyRepository struct{}
+
+func (securityPolicyRepository) Get(ctx context.Context, companyID uuid.UUID) (*models.CompanySecurityPolicy, error) {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&companyID) {
+		return nil, repository.ErrNotFound
+	}
+
+	policy := models.CompanySecurityPolicy{CompanyID: companyID}
+	err := sqlx.Get(conn(ctx), &policy, "SELECT * FROM company_security_policies WHERE company_id = $1", companyID)
+	if err == sql.ErrNoRows {
+		return &policy, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (securityPolicyRepository) Save(ctx context.Context, policy *models.CompanySecurityPolicy) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&policy.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	return sqlx.Get(conn(ctx), policy, `
+		INSERT INTO company_security_policies (company_id, require_mfa, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (company_id) DO UPDATE
+		SET require_mfa = EXCLUDED.require_mfa, updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+		RETURNING *
+	`, policy.CompanyID, policy.RequireMFA, policy.UpdatedBy)
+}
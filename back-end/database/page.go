@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/repository"
+)
+
+// listPage executa uma listagem paginada por cursor. from traz a tabela e as condições já
+// filtradas (FROM ... WHERE ...); sortColumns traduz os campos de ordenação para colunas.
+// O total ignora o cursor, e a busca traz um item a mais para saber se há próxima página.
+func listPage[T any](ctx context.Context, sorting repository.Sorting[T], sortColumns map[string]string, columns, from string, args queryArgs, page repository.PageRequest) (*repository.Page[T], error) {
+	q := conn(ctx)
+
+	var total int
+	if err := sqlx.Get(q, &total, `SELECT COUNT(*) `+from, args...); err != nil {
+		return nil, err
+	}
+
+	sort, desc := sorting.Order(page)
+	column := sortColumns[sort]
+	direction, comparison := " ASC", " > "
+	if desc {
+		direction, comparison = " DESC", " < "
+	}
+
+	pageArgs := append(queryArgs{}, args...)
+	query := `SELECT ` + columns + ` ` + from
+	if page.After != nil {
+		query += ` AND (` + column + `, id)` + comparison +
+			`(` + pageArgs.add(page.After.Value) + `, ` + pageArgs.add(page.After.ID) + `)`
+	}
+	query += ` ORDER BY ` + column + direction + `, id` + direction
+	if page.Limit > 0 {
+		query += ` LIMIT ` + strconv.Itoa(page.Limit+1)
+	}
+
+	var items []T
+	if err := sqlx.Select(q, &items, query, pageArgs...); err != nil {
+		return nil, err
+	}
+	return sorting.NewPage(items, total, page), nil
+}
+
+// scoreRangeFilter limita a coluna de nota ao intervalo informado (inclusive)
+func scoreRangeFilter(column string, min, max *float64, args *queryArgs) string {
+	filter := ""
+	if min != nil {
+		filter += ` AND ` + column + ` >= ` + args.add(*min)
+	}
+	if max != nil {
+		filter += ` AND ` + column + ` <= ` + args.add(*max)
+	}
+	return filter
+}
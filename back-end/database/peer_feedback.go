@@ -0,0 +1,244 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// FeedbackRoundColumns são as colunas de feedback_rounds na ordem dos campos de models.FeedbackRound
+const FeedbackRoundColumns = `id, company_id, developer_id, month, template_version_id, status, anonymize,
+	min_respondents, created_by, closed_at, created_at, updated_at`
+
+// LoadPeerFeedbackSummary agrega as respostas enviadas de uma rodada
+func LoadPeerFeedbackSummary(q sqlx.Queryer, round *models.FeedbackRound) (*models.PeerFeedbackSummary, error) {
+	var nominated int
+	if err := sqlx.Get(q, &nominated, "SELECT COUNT(*) FROM feedback_nominations WHERE round_id = $1", round.ID); err != nil {
+		return nil, err
+	}
+
+	responses := []models.PeerResponse{}
+	err := sqlx.Select(q, &responses, `
+		SELECT u.name AS reviewer_name, n.question_scores, n.category_scores,
+		       n.weighted_average_score, COALESCE(n.comments, '') AS comments
+		FROM feedback_nominations n
+		INNER JOIN users u ON u.id = n.reviewer_id
+		WHERE n.round_id = $1 AND n.submitted_at IS NOT NULL
+	`, round.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := models.SummarizePeerFeedback(round, nominated, responses)
+	return &summary, nil
+}
+
+// PeerFeedbackForReport retorna o agregado da rodada do mesmo desenvolvedor/mês do relatório,
+// ou nil quando não houve rodada
+func PeerFeedbackForReport(q sqlx.Queryer, report *models.PerformanceReport) (*models.PeerFeedbackSummary, error) {
+	var round models.FeedbackRound
+	err := sqlx.Get(q, &round, `
+		SELECT `+FeedbackRoundColumns+`
+		FROM feedback_rounds
+		WHERE developer_id = $1 AND month = $2
+	`, report.DeveloperID, report.Month)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return LoadPeerFeedbackSummary(q, &round)
+}
+
+type feedbackRoundRepository struct{}
+
+func (feedbackRoundRepository) List(ctx context.Context, filter repository.FeedbackRoundFilter) ([]models.FeedbackRound, error) {
+	scope := repository.ScopeFrom(ctx)
+	args := queryArgs{}
+	query := `SELECT ` + FeedbackRoundColumns + ` FROM feedback_rounds WHERE 1=1` +
+		companyFilter(scope, "company_id", &args) +
+		developerTeamFilter(scope, "developer_id", &args)
+	if filter.DeveloperID != nil {
+		query += ` AND developer_id = ` + args.add(*filter.DeveloperID)
+	}
+	if filter.Month != "" {
+		query += ` AND month = ` + args.add(filter.Month)
+	}
+	query += ` ORDER BY month DESC, created_at DESC`
+
+	rounds := []models.FeedbackRound{}
+	err := sqlx.Select(conn(ctx), &rounds, query, args...)
+	return rounds, err
+}
+
+func (feedbackRoundRepository) Get(ctx context.Context, id uuid.UUID) (*models.FeedbackRound, error) {
+	scope := repository.ScopeFrom(ctx)
+	args := queryArgs{id}
+	query := `SELECT ` + FeedbackRoundColumns + ` FROM feedback_rounds WHERE id = $1` +
+		companyFilter(scope, "company_id", &args) +
+		developerTeamFilter(scope, "developer_id", &args)
+
+	var round models.FeedbackRound
+	if err := sqlx.Get(conn(ctx), &round, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &round, nil
+}
+
+func (feedbackRoundRepository) ExistsForMonth(ctx context.Context, developerID uuid.UUID, month string) (bool, error) {
+	var exists bool
+	err := sqlx.Get(conn(ctx), &exists,
+		"SELECT EXISTS(SELECT 1 FROM feedback_rounds WHERE developer_id = $1 AND month = $2)",
+		developerID, month,
+	)
+	return exists, err
+}
+
+func (feedbackRoundRepository) Create(ctx context.Context, round *models.FeedbackRound) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&round.CompanyID) {
+		return repository.ErrNotFound
+	}
+	return sqlx.Get(conn(ctx), round, `
+		INSERT INTO feedback_rounds (company_id, developer_id, month, template_version_id, anonymize, min_respondents, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+FeedbackRoundColumns,
+		round.CompanyID, round.DeveloperID, round.Month, round.TemplateVersionID, round.Anonymize,
+		round.MinRespondents, round.CreatedBy,
+	)
+}
+
+func (feedbackRoundRepository) Close(ctx context.Context, round *models.FeedbackRound) error {
+	args := queryArgs{models.FeedbackRoundClosed, round.ID}
+	query := `
+		UPDATE feedback_rounds
+		SET status = $1, closed_at = CURRENT_TIMESTAMP
+		WHERE id = $2` + companyFilter(repository.ScopeFrom(ctx), "company_id", &args) + `
+		RETURNING ` + FeedbackRoundColumns
+
+	return notFound(sqlx.Get(conn(ctx), round, query, args...))
+}
+
+func (feedbackRoundRepository) Summary(ctx context.Context, round *models.FeedbackRound) (*models.PeerFeedbackSummary, error) {
+	return LoadPeerFeedbackSummary(conn(ctx), round)
+}
+
+func (feedbackRoundRepository) ForReport(ctx context.Context, report *models.PerformanceReport) (*models.PeerFeedbackSummary, error) {
+	return PeerFeedbackForReport(conn(ctx), report)
+}
+
+func (feedbackRoundRepository) DeveloperAccount(ctx context.Context, companyID, developerID uuid.UUID) (*uuid.UUID, error) {
+	var userID *uuid.UUID
+	err := sqlx.Get(conn(ctx), &userID,
+		"SELECT user_id FROM developers WHERE id = $1 AND company_id = $2",
+		developerID, companyID,
+	)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return userID, nil
+}
+
+func (feedbackRoundRepository) Nominations(ctx context.Context, roundID uuid.UUID) ([]models.FeedbackNomination, error) {
+	nominations := []models.FeedbackNomination{}
+	err := sqlx.Select(conn(ctx), &nominations, `
+		SELECT n.id, n.round_id, n.reviewer_id, u.name AS reviewer_name, n.submitted_at, n.created_at
+		FROM feedback_nominations n
+		INNER JOIN users u ON u.id = n.reviewer_id
+		WHERE n.round_id = $1
+		ORDER BY u.name ASC
+	`, roundID)
+	return nominations, err
+}
+
+func (feedbackRoundRepository) Nominate(ctx context.Context, roundID uuid.UUID, reviewerIDs []uuid.UUID) error {
+	return inTx(ctx, func(q sqlx.Ext) error {
+		for _, reviewerID := range reviewerIDs {
+			if _, err := q.Exec(`
+				INSERT INTO feedback_nominations (round_id, reviewer_id)
+				VALUES ($1, $2)
+				ON CONFLICT (round_id, reviewer_id) DO NOTHING
+			`, roundID, reviewerID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (feedbackRoundRepository) Nomination(ctx context.Context, roundID, reviewerID uuid.UUID) (*models.FeedbackNomination, error) {
+	var nomination models.FeedbackNomination
+	err := sqlx.Get(conn(ctx), &nomination, `
+		SELECT n.id, n.round_id, n.reviewer_id, u.name AS reviewer_name, n.submitted_at, n.created_at
+		FROM feedback_nominations n
+		INNER JOIN users u ON u.id = n.reviewer_id
+		WHERE n.round_id = $1 AND n.reviewer_id = $2
+	`, roundID, reviewerID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &nomination, nil
+}
+
+func (feedbackRoundRepository) RemoveNomination(ctx context.Context, roundID, reviewerID uuid.UUID) error {
+	result, err := conn(ctx).Exec(
+		"DELETE FROM feedback_nominations WHERE round_id = $1 AND reviewer_id = $2",
+		roundID, reviewerID,
+	)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (feedbackRoundRepository) Requests(ctx context.Context, reviewerID uuid.UUID) ([]models.FeedbackRequest, error) {
+	requests := []models.FeedbackRequest{}
+	err := sqlx.Select(conn(ctx), &requests, `
+		SELECT r.id AS round_id, r.developer_id, d.name AS developer_name, r.month, r.status,
+		       r.template_version_id, n.submitted_at
+		FROM feedback_nominations n
+		INNER JOIN feedback_rounds r ON r.id = n.round_id
+		INNER JOIN developers d ON d.id = r.developer_id
+		WHERE n.reviewer_id = $1
+		ORDER BY (n.submitted_at IS NULL) DESC, r.month DESC
+	`, reviewerID)
+	return requests, err
+}
+
+func (feedbackRoundRepository) GetForReviewer(ctx context.Context, id, reviewerID uuid.UUID) (*models.FeedbackRound, error) {
+	var round models.FeedbackRound
+	err := sqlx.Get(conn(ctx), &round, `
+		SELECT `+FeedbackRoundColumns+`
+		FROM feedback_rounds
+		WHERE id = $1
+		  AND EXISTS (SELECT 1 FROM feedback_nominations WHERE round_id = $1 AND reviewer_id = $2)
+	`, id, reviewerID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &round, nil
+}
+
+func (feedbackRoundRepository) SubmitResponse(ctx context.Context, roundID, reviewerID uuid.UUID, response models.PeerResponse) error {
+	result, err := conn(ctx).Exec(`
+		UPDATE feedback_nominations
+		SET question_scores = $1, category_scores = $2, weighted_average_score = $3,
+		    comments = $4, submitted_at = CURRENT_TIMESTAMP
+		WHERE round_id = $5 AND reviewer_id = $6
+	`, response.QuestionScores, response.CategoryScores, response.WeightedAverageScore, response.Comments, roundID, reviewerID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// PerformanceReportColumns são as colunas padrão de um relatório
+const PerformanceReportColumns = `id, developer_id, month, question_scores, category_scores,
+	weighted_average_score, highlights, points_to_develop, template_version_id,
+	status, submitted_at, submitted_by, acknowledged_at, acknowledged_by, locked_at, locked_by,
+	calibrated_score, calibration_adjustment_id, created_at, updated_at`
+
+// PrefixedPerformanceReportColumns é PerformanceReportColumns qualificado com o alias "pr",
+// para consultas com JOIN
+const PrefixedPerformanceReportColumns = `pr.id, pr.developer_id, pr.month, pr.question_scores, pr.category_scores,
+	pr.weighted_average_score, pr.highlights, pr.points_to_develop, pr.template_version_id,
+	pr.status, pr.submitted_at, pr.submitted_by, pr.acknowledged_at, pr.acknowledged_by, pr.locked_at, pr.locked_by,
+	pr.calibrated_score, pr.calibration_adjustment_id, pr.created_at, pr.updated_at`
+
+// PublishedReportStatuses filtra relatórios já enviados (exclui rascunhos)
+const PublishedReportStatuses = `('submitted', 'acknowledged', 'locked')`
+
+// reportSnapshot serializa o relatório no mesmo formato JSON retornado pela API
+func reportSnapshot(report *models.PerformanceReport) interface{} {
+	if report == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil
+	}
+
+	var snapshot models.JSONB
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// RecordReportRevision grava uma nova revisão do relatório na mesma transação da alteração
+func RecordReportRevision(q sqlx.Execer, reportID uuid.UUID, companyID *uuid.UUID, action string, changedBy uuid.UUID, oldReport, newReport *models.PerformanceReport) error {
+	_, err := q.Exec(`
+		INSERT INTO performance_report_revisions (report_id, revision, action, company_id, changed_by, old_data, new_data)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6
+		FROM performance_report_revisions WHERE report_id = $1
+	`, reportID, action, companyID, changedBy, reportSnapshot(oldReport), reportSnapshot(newReport))
+	return err
+}
+
+// LockReportForChange carrega o relatório com bloqueio de linha e a empresa do desenvolvedor.
+// Não verifica o escopo: quem chama compara a empresa com a do usuário.
+func LockReportForChange(q sqlx.Queryer, reportID uuid.UUID) (*models.PerformanceReport, *uuid.UUID, error) {
+	var report models.PerformanceReport
+	err := sqlx.Get(q, &report, `
+		SELECT `+PerformanceReportColumns+`
+		FROM performance_reports
+		WHERE id = $1
+		FOR UPDATE
+	`, reportID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var companyID *uuid.UUID
+	if err := sqlx.Get(q, &companyID, "SELECT company_id FROM developers WHERE id = $1", report.DeveloperID); err != nil {
+		return nil, nil, err
+	}
+	return &report, companyID, nil
+}
+
+type reportRepository struct{}
+
+// listReports lista os relatórios visíveis no escopo que atendem ao filtro informado
+func listReports(ctx context.Context, filter string, args queryArgs, orderBy string) ([]models.PerformanceReport, error) {
+	query := `SELECT ` + PerformanceReportColumns + ` FROM performance_reports WHERE 1=1` + filter +
+		reportScopeFilter(repository.ScopeFrom(ctx), "status", "developer_id", &args) +
+		` ORDER BY ` + orderBy
+
+	var reports []models.PerformanceReport
+	err := sqlx.Select(conn(ctx), &reports, query, args...)
+	return reports, err
+}
+
+// reportSortColumns são as colunas dos campos de repository.ReportSorting
+var reportSortColumns = map[string]string{
+	"month":                "month",
+	"weightedAverageScore": "weighted_average_score",
+	"createdAt":            "created_at",
+}
+
+func (reportRepository) List(ctx context.Context, filter repository.ReportFilter, page repository.PageRequest) (*repository.Page[models.PerformanceReport], error) {
+	args := queryArgs{}
+	from := `FROM performance_reports WHERE 1=1` +
+		reportScopeFilter(repository.ScopeFrom(ctx), "status", "developer_id", &args)
+
+	if filter.TeamID != nil {
+		from += ` AND developer_id IN (SELECT fd.id FROM developers fd WHERE fd.team_id = ` + args.add(*filter.TeamID) + `)`
+	}
+	if filter.DeveloperID != nil {
+		from += ` AND developer_id = ` + args.add(*filter.DeveloperID)
+	}
+	if filter.Status != "" {
+		from += ` AND status = ` + args.add(filter.Status)
+	}
+	if filter.MonthFrom != "" {
+		from += ` AND month >= ` + args.add(filter.MonthFrom)
+	}
+	if filter.MonthTo != "" {
+		from += ` AND month <= ` + args.add(filter.MonthTo)
+	}
+	from += scoreRangeFilter("weighted_average_score", filter.MinScore, filter.MaxScore, &args)
+
+	return listPage(ctx, repository.ReportSorting, reportSortColumns, PerformanceReportColumns, from, args, page)
+}
+
+func (reportRepository) ListByDeveloper(ctx context.Context, developerID uuid.UUID) ([]models.PerformanceReport, error) {
+	return listReports(ctx, " AND developer_id = $1", queryArgs{developerID}, "month DESC, created_at DESC")
+}
+
+func (reportRepository) ListByMonth(ctx context.Context, month string) ([]models.PerformanceReport, error) {
+	return listReports(ctx, " AND month = $1", queryArgs{month}, "weighted_average_score DESC, created_at DESC")
+}
+
+func (reportRepository) Get(ctx context.Context, id uuid.UUID) (*models.PerformanceReport, error) {
+	args := queryArgs{id}
+	query := `SELECT ` + PerformanceReportColumns + ` FROM performance_reports WHERE id = $1` +
+		reportScopeFilter(repository.ScopeFrom(ctx), "status", "developer_id", &args)
+
+	var report models.PerformanceReport
+	if err := sqlx.Get(conn(ctx), &report, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &report, nil
+}
+
+func (reportRepository) GetForMonth(ctx context.Context, developerID uuid.UUID, month string) (*models.PerformanceReport, error) {
+	args := queryArgs{developerID, month}
+	query := `SELECT ` + PerformanceReportColumns + ` FROM performance_reports WHERE developer_id = $1 AND month = $2` +
+		reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args)
+
+	var report models.PerformanceReport
+	if err := sqlx.Get(conn(ctx), &report, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &report, nil
+}
+
+func (reportRepository) ListPublished(ctx context.Context, developerID uuid.UUID) ([]models.PerformanceReport, error) {
+	args := queryArgs{developerID}
+	query := `
+		SELECT ` + PerformanceReportColumns + `
+		FROM performance_reports
+		WHERE developer_id = $1 AND status IN ` + PublishedReportStatuses +
+		reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args) + `
+		ORDER BY month DESC
+	`
+
+	reports := []models.PerformanceReport{}
+	err := sqlx.Select(conn(ctx), &reports, query, args...)
+	return reports, err
+}
+
+func (reportRepository) Trend(ctx context.Context, developerID uuid.UUID, months int) ([]models.PerformanceTrendPoint, error) {
+	args := queryArgs{developerID, months}
+	query := `
+		SELECT month, weighted_average_score, calibrated_score, category_scores
+		FROM (
+			SELECT month, weighted_average_score, calibrated_score, category_scores
+			FROM performance_reports
+			WHERE developer_id = $1 AND status IN ` + PublishedReportStatuses +
+		reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args) + `
+			ORDER BY month DESC
+			LIMIT $2
+		) latest
+		ORDER BY month ASC
+	`
+
+	points := []models.PerformanceTrendPoint{}
+	err := sqlx.Select(conn(ctx), &points, query, args...)
+	return points, err
+}
+
+func (reportRepository) Months(ctx context.Context) ([]string, error) {
+	scope := repository.ScopeFrom(ctx)
+	args := queryArgs{}
+	query := `
+		SELECT DISTINCT month
+		FROM performance_reports
+		WHERE status IN ` + PublishedReportStatuses +
+		reportCompanyFilter(scope, "developer_id", &args) +
+		reportTeamFilter(scope, "developer_id", &args) + `
+		ORDER BY month DESC
+	`
+
+	var months []string
+	err := sqlx.Select(conn(ctx), &months, query, args...)
+	return months, err
+}
+
+func (reportRepository) Stats(ctx context.Context) (*models.PerformanceStats, error) {
+	scope := repository.ScopeFrom(ctx)
+	args := queryArgs{}
+	query := `
+		SELECT
+			COUNT(*) AS total_reports,
+			COALESCE(ROUND(AVG(COALESCE(calibrated_score, weighted_average_score))::numeric, 2), 0) AS average_score,
+			COALESCE(MAX(COALESCE(calibrated_score, weighted_average_score)), 0) AS highest_score,
+			COALESCE(MIN(COALESCE(calibrated_score, weighted_average_score)), 0) AS lowest_score
+		FROM performance_reports
+		WHERE status IN ` + PublishedReportStatuses +
+		reportCompanyFilter(scope, "developer_id", &args) +
+		reportTeamFilter(scope, "developer_id", &args)
+
+	var stats models.PerformanceStats
+	if err := sqlx.Get(conn(ctx), &stats, query, args...); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (reportRepository) ExistsForMonth(ctx context.Context, developerID uuid.UUID, month string, exceptID *uuid.UUID) (bool, error) {
+	args := queryArgs{developerID, month}
+	query := `SELECT EXISTS(SELECT 1 FROM performance_reports WHERE developer_id = $1 AND month = $2`
+	if exceptID != nil {
+		query += ` AND id != ` + args.add(*exceptID)
+	}
+	query += reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args) + `)`
+
+	var exists bool
+	err := sqlx.Get(conn(ctx), &exists, query, args...)
+	return exists, err
+}
+
+func (reportRepository) Create(ctx context.Context, report *models.PerformanceReport) error {
+	q := conn(ctx)
+	if err := developerInScope(q, repository.ScopeFrom(ctx), report.DeveloperID); err != nil {
+		return err
+	}
+
+	return q.QueryRowx(`
+		INSERT INTO performance_reports (developer_id, month, question_scores, category_scores,
+		                               weighted_average_score, highlights, points_to_develop, template_version_id,
+		                               status, submitted_at, submitted_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING `+PerformanceReportColumns,
+		report.DeveloperID,
+		report.Month,
+		report.QuestionScores,
+		report.CategoryScores,
+		report.WeightedAverageScore,
+		report.Highlights,
+		report.PointsToDevelop,
+		report.TemplateVersionID,
+		report.Status,
+		report.SubmittedAt,
+		report.SubmittedBy,
+	).StructScan(report)
+}
+
+func (reportRepository) LockForChange(ctx context.Context, id uuid.UUID) (*models.PerformanceReport, *uuid.UUID, error) {
+	report, companyID, err := LockReportForChange(conn(ctx), id)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+
+	// Relatórios de outra empresa são tratados como inexistentes
+	if !repository.ScopeFrom(ctx).OwnsCompany(companyID) {
+		return nil, nil, repository.ErrNotFound
+	}
+	return report, companyID, nil
+}
+
+func (reportRepository) Update(ctx context.Context, report *models.PerformanceReport) error {
+	args := queryArgs{
+		report.Month,
+		report.QuestionScores,
+		report.CategoryScores,
+		report.WeightedAverageScore,
+		report.Highlights,
+		report.PointsToDevelop,
+		report.TemplateVersionID,
+		report.CalibratedScore,
+		report.CalibrationAdjustmentID,
+		report.ID,
+	}
+	query := `
+		UPDATE performance_reports
+		SET month = $1, question_scores = $2, category_scores = $3, weighted_average_score = $4,
+		    highlights = $5, points_to_develop = $6, template_version_id = $7,
+		    calibrated_score = $8, calibration_adjustment_id = $9
+		WHERE id = $10` + reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args) + `
+		RETURNING ` + PerformanceReportColumns
+
+	return notFound(conn(ctx).QueryRowx(query, args...).StructScan(report))
+}
+
+func (reportRepository) UpdateStatus(ctx context.Context, report *models.PerformanceReport, status string, actor uuid.UUID) error {
+	// Cada status tem sua própria coluna de data e autor
+	var stampColumns string
+	switch status {
+	case models.ReportStatusSubmitted:
+		stampColumns = "submitted_at = CURRENT_TIMESTAMP, submitted_by = $6"
+	case models.ReportStatusAcknowledged:
+		stampColumns = "acknowledged_at = CURRENT_TIMESTAMP, acknowledged_by = $6"
+	case models.ReportStatusLocked:
+		stampColumns = "locked_at = CURRENT_TIMESTAMP, locked_by = $6"
+	}
+
+	args := queryArgs{
+		status,
+		report.CategoryScores,
+		report.WeightedAverageScore,
+		report.TemplateVersionID,
+		report.ID,
+		actor,
+	}
+	query := `
+		UPDATE performance_reports
+		SET status = $1, category_scores = $2, weighted_average_score = $3, template_version_id = $4,
+		    ` + stampColumns + `
+		WHERE id = $5` + reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args) + `
+		RETURNING ` + PerformanceReportColumns
+
+	return notFound(conn(ctx).QueryRowx(query, args...).StructScan(report))
+}
+
+func (reportRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := queryArgs{id}
+	query := `DELETE FROM performance_reports WHERE id = $1` +
+		reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args)
+
+	result, err := conn(ctx).Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (reportRepository) LockByDeveloper(ctx context.Context, developerID uuid.UUID) ([]models.PerformanceReport, error) {
+	args := queryArgs{developerID}
+	query := `SELECT ` + PerformanceReportColumns + ` FROM performance_reports WHERE developer_id = $1` +
+		reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args) + ` FOR UPDATE`
+
+	var reports []models.PerformanceReport
+	err := sqlx.Select(conn(ctx), &reports, query, args...)
+	return reports, err
+}
+
+func (reportRepository) DeleteByDeveloper(ctx context.Context, developerID uuid.UUID) error {
+	args := queryArgs{developerID}
+	query := `DELETE FROM performance_reports WHERE developer_id = $1` +
+		reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args)
+
+	_, err := conn(ctx).Exec(query, args...)
+	return err
+}
+
+func (reportRepository) RecordRevision(ctx context.Context, reportID uuid.UUID, companyID *uuid.UUID, action string, changedBy uuid.UUID, oldReport, newReport *models.PerformanceReport) error {
+	return RecordReportRevision(conn(ctx), reportID, companyID, action, changedBy, oldReport, newReport)
+}
+
+func (reportRepository) Revisions(ctx context.Context, reportID uuid.UUID) ([]models.PerformanceReportRevision, error) {
+	args := queryArgs{reportID}
+	query := `
+		SELECT r.id, r.report_id, r.revision, r.action, r.company_id, r.changed_by,
+		       u.name AS changed_by_name, r.old_data, r.new_data, r.created_at
+		FROM performance_report_revisions r
+		LEFT JOIN users u ON u.id = r.changed_by
+		WHERE r.report_id = $1` + companyFilter(repository.ScopeFrom(ctx), "r.company_id", &args) + `
+		ORDER BY r.revision ASC
+	`
+
+	revisions := []models.PerformanceReportRevision{}
+	err := sqlx.Select(conn(ctx), &revisions, query, args...)
+	return revisions, err
+}
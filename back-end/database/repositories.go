@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/repository"
+)
+
+// NewRepositories devolve a implementação em PostgreSQL dos repositórios, usando a conexão DB
+// ou, quando o contexto carrega uma, a transação aberta por Transactions
+func NewRepositories() *repository.Repositories {
+	return &repository.Repositories{
+		Developers:       developerRepository{},
+		Teams:            teamRepository{},
+		Reports:          reportRepository{},
+		Users:            userRepository{},
+		Companies:        companyRepository{},
+		Templates:        templateRepository{},
+		ReviewCycles:     reviewCycleRepository{},
+		Calibration:      calibrationRepository{},
+		FeedbackRounds:   feedbackRoundRepository{},
+		SelfAssessments:  selfAssessmentRepository{},
+		Roles:            companyRoleRepository{},
+		ServiceAccounts:  serviceAccountRepository{},
+		SecurityPolicies: securityPolicyRepository{},
+		Invitations:      invitationRepository{},
+		Accounts:         accountRepository{},
+		Sessions:         sessionRepository{},
+		LoginThrottles:   loginThrottleRepository{},
+		PasswordResets:   passwordResetRepository{},
+		MFA:              mfaRepository{},
+		SSO:              ssoRepository{},
+		APITokens:        apiTokenRepository{},
+		Audit:            auditRepository{},
+		Transactions:     tenantTransactor{},
+	}
+}
+
+type txKey struct{}
+
+// tenantTransactor abre as transações com BeginTenant: o papel da API e as políticas de RLS
+// reforçam o escopo aplicado nas consultas dos repositórios
+type tenantTransactor struct{}
+
+func (tenantTransactor) Begin(ctx context.Context, readOnly bool) (context.Context, repository.Tx, error) {
+	scope := repository.ScopeFrom(ctx)
+	tx, err := BeginTenant(ctx, TenantScope{CompanyID: scope.CompanyID, Bypass: scope.AllCompanies}, readOnly)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return context.WithValue(ctx, txKey{}, tx), tx, nil
+}
+
+// conn devolve a transação do contexto ou, fora de uma, a conexão DB
+func conn(ctx context.Context) sqlx.Ext {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return DB
+}
+
+// inTx roda fn na transação do contexto ou em uma transação própria, confirmada ao final
+func inTx(ctx context.Context, fn func(q sqlx.Ext) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(tx)
+	}
+
+	tx, err := DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// notFound traduz sql.ErrNoRows para o erro dos repositórios
+func notFound(err error) error {
+	if err == sql.ErrNoRows {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// queryArgs acumula os argumentos de uma consulta montada em partes
+type queryArgs []interface{}
+
+// add acrescenta o valor e devolve o placeholder correspondente
+func (a *queryArgs) add(value interface{}) string {
+	*a = append(*a, value)
+	return "$" + strconv.Itoa(len(*a))
+}
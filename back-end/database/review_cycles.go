@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// ReviewCycleColumns são as colunas de review_cycles na ordem dos campos de models.ReviewCycle
+const ReviewCycleColumns = `id, company_id, name, month, opens_at, closes_at, template_version_id, status,
+	created_by, opened_at, closed_at, created_at, updated_at`
+
+// cycleTeamsFilter restringe a coluna de time informada aos times do ciclo (placeholder), ou a
+// todos quando o ciclo não tem times definidos
+func cycleTeamsFilter(teamColumn, cyclePlaceholder string) string {
+	return ` AND (NOT EXISTS (SELECT 1 FROM review_cycle_teams WHERE cycle_id = ` + cyclePlaceholder + `)
+		OR ` + teamColumn + ` IN (SELECT team_id FROM review_cycle_teams WHERE cycle_id = ` + cyclePlaceholder + `))`
+}
+
+// ReviewCycleForMonth retorna o ciclo da empresa para o mês, ou nil quando não existe
+func ReviewCycleForMonth(q sqlx.Queryer, companyID *uuid.UUID, month string) (*models.ReviewCycle, error) {
+	if companyID == nil {
+		return nil, nil
+	}
+
+	var cycle models.ReviewCycle
+	err := sqlx.Get(q, &cycle, `
+		SELECT `+ReviewCycleColumns+`
+		FROM review_cycles
+		WHERE company_id = $1 AND month = $2
+	`, *companyID, month)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cycle, nil
+}
+
+// loadReviewCycleTeams preenche os times incluídos no ciclo
+func loadReviewCycleTeams(q sqlx.Queryer, cycle *models.ReviewCycle) error {
+	cycle.TeamIDs = []uuid.UUID{}
+	return sqlx.Select(q, &cycle.TeamIDs, "SELECT team_id FROM review_cycle_teams WHERE cycle_id = $1", cycle.ID)
+}
+
+// reviewCycleStampColumns é a coluna de data carimbada ao entrar em cada status
+var reviewCycleStampColumns = map[string]string{
+	models.ReviewCycleOpen:   "opened_at",
+	models.ReviewCycleClosed: "closed_at",
+}
+
+type reviewCycleRepository struct{}
+
+func (reviewCycleRepository) List(ctx context.Context, status string) ([]models.ReviewCycle, error) {
+	args := queryArgs{}
+	query := `SELECT ` + ReviewCycleColumns + ` FROM review_cycles WHERE 1=1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+	if status != "" {
+		query += ` AND status = ` + args.add(status)
+	}
+	query += ` ORDER BY month DESC`
+
+	cycles := []models.ReviewCycle{}
+	if err := sqlx.Select(conn(ctx), &cycles, query, args...); err != nil {
+		return nil, err
+	}
+	for i := range cycles {
+		if err := loadReviewCycleTeams(conn(ctx), &cycles[i]); err != nil {
+			return nil, err
+		}
+	}
+	return cycles, nil
+}
+
+func (reviewCycleRepository) Get(ctx context.Context, id uuid.UUID) (*models.ReviewCycle, error) {
+	args := queryArgs{id}
+	query := `SELECT ` + ReviewCycleColumns + ` FROM review_cycles WHERE id = $1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	var cycle models.ReviewCycle
+	if err := sqlx.Get(conn(ctx), &cycle, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	if err := loadReviewCycleTeams(conn(ctx), &cycle); err != nil {
+		return nil, err
+	}
+	return &cycle, nil
+}
+
+func (reviewCycleRepository) ForMonth(ctx context.Context, companyID *uuid.UUID, month string) (*models.ReviewCycle, error) {
+	if companyID != nil && !repository.ScopeFrom(ctx).OwnsCompany(companyID) {
+		return nil, nil
+	}
+	return ReviewCycleForMonth(conn(ctx), companyID, month)
+}
+
+func (reviewCycleRepository) Create(ctx context.Context, cycle *models.ReviewCycle) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&cycle.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	teamIDs := cycle.TeamIDs
+	return inTx(ctx, func(q sqlx.Ext) error {
+		err := sqlx.Get(q, cycle, `
+			INSERT INTO review_cycles (company_id, name, month, opens_at, closes_at, template_version_id, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING `+ReviewCycleColumns,
+			cycle.CompanyID, cycle.Name, cycle.Month, cycle.OpensAt, cycle.ClosesAt, cycle.TemplateVersionID, cycle.CreatedBy,
+		)
+		if err != nil {
+			return err
+		}
+
+		cycle.TeamIDs = []uuid.UUID{}
+		for _, teamID := range teamIDs {
+			if _, err := q.Exec(`
+				INSERT INTO review_cycle_teams (cycle_id, team_id)
+				VALUES ($1, $2)
+				ON CONFLICT (cycle_id, team_id) DO NOTHING
+			`, cycle.ID, teamID); err != nil {
+				return err
+			}
+			cycle.TeamIDs = append(cycle.TeamIDs, teamID)
+		}
+		return nil
+	})
+}
+
+func (reviewCycleRepository) UpdateStatus(ctx context.Context, cycle *models.ReviewCycle, status string) error {
+	args := queryArgs{status, cycle.ID}
+	query := `UPDATE review_cycles SET status = $1`
+	if column, ok := reviewCycleStampColumns[status]; ok {
+		query += `, ` + column + ` = CURRENT_TIMESTAMP`
+	}
+	query += ` WHERE id = $2` + companyFilter(repository.ScopeFrom(ctx), "company_id", &args) +
+		` RETURNING ` + ReviewCycleColumns
+
+	teamIDs := cycle.TeamIDs
+	if err := sqlx.Get(conn(ctx), cycle, query, args...); err != nil {
+		return notFound(err)
+	}
+	cycle.TeamIDs = teamIDs
+	return nil
+}
+
+func (reviewCycleRepository) Assignments(ctx context.Context, cycleID uuid.UUID) ([]models.ReviewCycleAssignment, error) {
+	assignments := []models.ReviewCycleAssignment{}
+	err := sqlx.Select(conn(ctx), &assignments,
+		"SELECT developer_id, evaluator_id FROM review_cycle_assignments WHERE cycle_id = $1",
+		cycleID,
+	)
+	return assignments, err
+}
+
+func (reviewCycleRepository) IsEligibleEvaluator(ctx context.Context, companyID, userID uuid.UUID) (bool, error) {
+	var eligible bool
+	err := sqlx.Get(conn(ctx), &eligible, `
+		SELECT EXISTS(
+			SELECT 1 FROM users
+			WHERE id = $1 AND company_id = $2 AND is_active = true AND role IN ('admin', 'company_admin', 'manager')
+		)
+	`, userID, companyID)
+	return eligible, err
+}
+
+func (reviewCycleRepository) Assign(ctx context.Context, cycleID uuid.UUID, assignments []models.ReviewCycleAssignment) error {
+	return inTx(ctx, func(q sqlx.Ext) error {
+		for _, assignment := range assignments {
+			if _, err := q.Exec(`
+				INSERT INTO review_cycle_assignments (cycle_id, developer_id, evaluator_id)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (cycle_id, developer_id) DO UPDATE SET evaluator_id = EXCLUDED.evaluator_id
+			`, cycleID, assignment.DeveloperID, assignment.EvaluatorID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (reviewCycleRepository) Completion(ctx context.Context, cycle *models.ReviewCycle) ([]models.CycleDeveloperStatus, error) {
+	args := queryArgs{cycle.ID, cycle.Month, cycle.CompanyID}
+	query := `
+		SELECT d.id AS developer_id, d.name AS developer_name, d.team_id, t.name AS team_name,
+		       COALESCE(a.evaluator_id, pr.submitted_by) AS evaluator_id, u.name AS evaluator_name,
+		       pr.id AS report_id, pr.status AS report_status
+		FROM developers d
+		LEFT JOIN teams t ON t.id = d.team_id
+		LEFT JOIN review_cycle_assignments a ON a.cycle_id = $1 AND a.developer_id = d.id
+		LEFT JOIN performance_reports pr ON pr.developer_id = d.id AND pr.month = $2
+		LEFT JOIN users u ON u.id = COALESCE(a.evaluator_id, pr.submitted_by)
+		WHERE d.company_id = $3
+		  AND d.archived_at IS NULL` +
+		cycleTeamsFilter("d.team_id", "$1") +
+		teamFilter(repository.ScopeFrom(ctx), "d.team_id", &args) + `
+		ORDER BY t.name ASC NULLS LAST, d.name ASC
+	`
+
+	statuses := []models.CycleDeveloperStatus{}
+	err := sqlx.Select(conn(ctx), &statuses, query, args...)
+	return statuses, err
+}
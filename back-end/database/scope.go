@@ -0,0 +1,77 @@
+package database
+
+import (
+	"tivix-performance-tracker-backend/repository"
+)
+
+// userTeamsSubquery devolve os times do usuário identificado pelo placeholder: os atribuídos
+// em user_teams e os dos desenvolvedores vinculados à conta
+func userTeamsSubquery(placeholder string) string {
+	return `SELECT ut.team_id FROM user_teams ut WHERE ut.user_id = ` + placeholder + `
+		UNION SELECT ud.team_id FROM developers ud WHERE ud.user_id = ` + placeholder + ` AND ud.team_id IS NOT NULL`
+}
+
+// companyFilter restringe a coluna de empresa informada à empresa do escopo. Sem empresa no
+// escopo a comparação com NULL não seleciona nenhuma linha.
+func companyFilter(scope repository.Scope, companyColumn string, args *queryArgs) string {
+	if scope.AllCompanies {
+		return ""
+	}
+	return ` AND ` + companyColumn + ` = ` + args.add(scope.CompanyID)
+}
+
+// teamFilter restringe a coluna de time informada aos times do usuário quando o escopo não
+// possui AllTeams
+func teamFilter(scope repository.Scope, teamColumn string, args *queryArgs) string {
+	if scope.AllTeams {
+		return ""
+	}
+	return ` AND ` + teamColumn + ` IN (` + userTeamsSubquery(args.add(scope.UserID)) + `)`
+}
+
+// developerTeamFilter restringe a coluna de desenvolvedor informada aos desenvolvedores dos
+// times do usuário quando o escopo não possui AllTeams
+func developerTeamFilter(scope repository.Scope, developerColumn string, args *queryArgs) string {
+	if scope.AllTeams {
+		return ""
+	}
+	return ` AND ` + developerColumn + ` IN (
+		SELECT td.id FROM developers td
+		WHERE td.team_id IN (` + userTeamsSubquery(args.add(scope.UserID)) + `)
+	)`
+}
+
+// reportCompanyFilter restringe relatórios (pela coluna do desenvolvedor) à empresa do escopo
+func reportCompanyFilter(scope repository.Scope, developerColumn string, args *queryArgs) string {
+	if scope.AllCompanies {
+		return ""
+	}
+	return ` AND ` + developerColumn + ` IN (SELECT cd.id FROM developers cd WHERE cd.company_id = ` + args.add(scope.CompanyID) + `)`
+}
+
+// reportTeamFilter restringe relatórios (pela coluna do desenvolvedor) aos times do usuário.
+// Vale para quem não possui teams:all e para quem só possui reports:read:own-team.
+func reportTeamFilter(scope repository.Scope, developerColumn string, args *queryArgs) string {
+	if scope.AllTeamReports {
+		return ""
+	}
+	return ` AND ` + developerColumn + ` IN (
+		SELECT td.id FROM developers td
+		WHERE td.team_id IN (` + userTeamsSubquery(args.add(scope.UserID)) + `)
+	)`
+}
+
+// draftFilter esconde os rascunhos quando o escopo não possui Drafts
+func draftFilter(scope repository.Scope, statusColumn string) string {
+	if scope.Drafts {
+		return ""
+	}
+	return ` AND ` + statusColumn + ` <> 'draft'`
+}
+
+// reportScopeFilter combina os filtros de rascunho, empresa e times dos relatórios
+func reportScopeFilter(scope repository.Scope, statusColumn, developerColumn string, args *queryArgs) string {
+	return draftFilter(scope, statusColumn) +
+		reportCompanyFilter(scope, developerColumn, args) +
+		reportTeamFilter(scope, developerColumn, args)
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"github.com/google/uuid"
+)
+
+// RecomputeLatestPerformanceScores recalcula latest_performance_score de todos os
+// desenvolvedores da empresa (ou de todas as empresas, se companyID for nil).
+// Normalmente o trigger da migração 030 mantém o valor; isto corrige dados legados.
+func RecomputeLatestPerformanceScores(companyID *uuid.UUID) (int, error) {
+	var developerIDs []uuid.UUID
+	var err error
+	if companyID != nil {
+		err = DB.Select(&developerIDs, "SELECT id FROM developers WHERE company_id = $1", *companyID)
+	} else {
+		err = DB.Select(&developerIDs, "SELECT id FROM developers")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := DB.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, developerID := range developerIDs {
+		if _, err := tx.Exec("SELECT refresh_developer_latest_score($1)", developerID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(developerIDs), nil
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+const selfAssessmentColumns = `id, developer_id, month, template_version_id, question_scores, category_scores,
+	weighted_average_score, comments, submitted_by, created_at, updated_at`
+
+type selfAssessmentRepository struct{}
+
+func (selfAssessmentRepository) ListByDeveloper(ctx context.Context, developerID uuid.UUID) ([]models.SelfAssessment, error) {
+	args := queryArgs{developerID}
+	query := `SELECT ` + selfAssessmentColumns + ` FROM self_assessments WHERE developer_id = $1` +
+		reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args) + ` ORDER BY month DESC`
+
+	assessments := []models.SelfAssessment{}
+	err := sqlx.Select(conn(ctx), &assessments, query, args...)
+	return assessments, err
+}
+
+func (selfAssessmentRepository) Get(ctx context.Context, developerID uuid.UUID, month string) (*models.SelfAssessment, error) {
+	args := queryArgs{developerID, month}
+	query := `SELECT ` + selfAssessmentColumns + ` FROM self_assessments WHERE developer_id = $1 AND month = $2` +
+		reportCompanyFilter(repository.ScopeFrom(ctx), "developer_id", &args)
+
+	var assessment models.SelfAssessment
+	if err := sqlx.Get(conn(ctx), &assessment, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &assessment, nil
+}
+
+func (selfAssessmentRepository) Save(ctx context.Context, assessment *models.SelfAssessment) error {
+	// Apenas a empresa é verificada: o desenvolvedor logado grava a própria autoavaliação
+	args := queryArgs{assessment.DeveloperID}
+	query := `SELECT EXISTS(SELECT 1 FROM developers WHERE id = $1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args) + `)`
+
+	var accessible bool
+	if err := sqlx.Get(conn(ctx), &accessible, query, args...); err != nil {
+		return err
+	}
+	if !accessible {
+		return repository.ErrNotFound
+	}
+
+	return sqlx.Get(conn(ctx), assessment, `
+		INSERT INTO self_assessments (developer_id, month, template_version_id, question_scores, category_scores,
+		                              weighted_average_score, comments, submitted_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (developer_id, month) DO UPDATE
+		SET template_version_id = EXCLUDED.template_version_id,
+		    question_scores = EXCLUDED.question_scores,
+		    category_scores = EXCLUDED.category_scores,
+		    weighted_average_score = EXCLUDED.weighted_average_score,
+		    comments = EXCLUDED.comments,
+		    submitted_by = EXCLUDED.submitted_by
+		RETURNING `+selfAssessmentColumns,
+		assessment.DeveloperID,
+		assessment.Month,
+		assessment.TemplateVersionID,
+		assessment.QuestionScores,
+		assessment.CategoryScores,
+		assessment.WeightedAverageScore,
+		assessment.Comments,
+		assessment.SubmittedBy,
+	)
+}
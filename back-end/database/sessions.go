@@ -0,0 +1,293 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// customRoleJoin traz o papel personalizado do usuário (alias cr), desde que seja da mesma empresa
+const customRoleJoin = `LEFT JOIN user_role_assignments ra ON ra.user_id = u.id
+		LEFT JOIN company_roles cr ON cr.id = ra.role_id AND cr.company_id = u.company_id`
+
+// effectivePermissions devolve as permissões do papel personalizado, quando houver, ou as do papel padrão
+func effectivePermissions(role string, hasCustomRole bool, customPermissions []string) []string {
+	if hasCustomRole {
+		return customPermissions
+	}
+	return models.RolePermissions[role]
+}
+
+// sessionRepository abre e consulta sessões e tokens fora da transação de escopo: o papel
+// tivix_app não tem acesso a essas tabelas
+type sessionRepository struct{}
+
+func (sessionRepository) ActiveSession(ctx context.Context, sessionID, userID uuid.UUID) (*repository.SessionUser, error) {
+	var companyRequiresMFA, mfaEnabled, hasCustomRole bool
+	var customPermissions pq.StringArray
+	user := repository.SessionUser{UserID: userID}
+	err := DB.QueryRowContext(ctx, `
+		SELECT u.email, u.role, u.company_id, u.is_active, u.needs_password_change,
+		       COALESCE(p.require_mfa, FALSE),
+		       EXISTS(SELECT 1 FROM user_mfa m WHERE m.user_id = u.id AND m.enabled_at IS NOT NULL),
+		       cr.id IS NOT NULL, cr.permissions
+		FROM auth_sessions s
+		INNER JOIN users u ON u.id = s.user_id
+		LEFT JOIN company_security_policies p ON p.company_id = u.company_id
+		`+customRoleJoin+`
+		WHERE s.id = $1 AND s.user_id = $2
+		  AND s.revoked_at IS NULL AND s.expires_at > CURRENT_TIMESTAMP
+	`, sessionID, userID).Scan(
+		&user.Email,
+		&user.Role,
+		&user.CompanyID,
+		&user.IsActive,
+		&user.NeedsPasswordChange,
+		&companyRequiresMFA,
+		&mfaEnabled,
+		&hasCustomRole,
+		&customPermissions,
+	)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	user.MFAEnrollmentRequired = companyRequiresMFA && !mfaEnabled && models.RoleRequiresMFA(user.Role)
+	user.Permissions = effectivePermissions(user.Role, hasCustomRole, customPermissions)
+	return &user, nil
+}
+
+func (sessionRepository) APITokenUser(ctx context.Context, token, ip string) (*repository.SessionUser, error) {
+	var tokenID uuid.UUID
+	var scopes pq.StringArray
+	var companyRequiresMFA, mfaEnabled, isServiceAccount, hasCustomRole bool
+	var customPermissions pq.StringArray
+	user := repository.SessionUser{}
+	err := DB.QueryRowContext(ctx, `
+		SELECT t.id, t.scopes, u.id, u.email, u.role, u.company_id, u.is_active, u.needs_password_change,
+		       COALESCE(p.require_mfa, FALSE),
+		       EXISTS(SELECT 1 FROM user_mfa m WHERE m.user_id = u.id AND m.enabled_at IS NOT NULL),
+		       EXISTS(SELECT 1 FROM service_accounts sa WHERE sa.user_id = u.id),
+		       cr.id IS NOT NULL, cr.permissions
+		FROM api_tokens t
+		INNER JOIN users u ON u.id = t.user_id
+		LEFT JOIN company_security_policies p ON p.company_id = u.company_id
+		`+customRoleJoin+`
+		WHERE t.token_hash = $1 AND t.revoked_at IS NULL AND t.expires_at > CURRENT_TIMESTAMP
+	`, utils.HashToken(token)).Scan(
+		&tokenID,
+		&scopes,
+		&user.UserID,
+		&user.Email,
+		&user.Role,
+		&user.CompanyID,
+		&user.IsActive,
+		&user.NeedsPasswordChange,
+		&companyRequiresMFA,
+		&mfaEnabled,
+		&isServiceAccount,
+		&hasCustomRole,
+		&customPermissions,
+	)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	// Contas de serviço não têm segundo fator; a política vale apenas para tokens pessoais
+	user.MFAEnrollmentRequired = !isServiceAccount && companyRequiresMFA && !mfaEnabled && models.RoleRequiresMFA(user.Role)
+	user.Permissions = effectivePermissions(user.Role, hasCustomRole, customPermissions)
+	user.APITokenID = &tokenID
+	user.APITokenScopes = scopes
+
+	// Grava o último uso no máximo uma vez por minuto para não escrever a cada requisição
+	_, err = DB.ExecContext(ctx, `
+		UPDATE api_tokens SET last_used_at = CURRENT_TIMESTAMP, last_used_ip = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`, tokenID, ip)
+	if err != nil {
+		log.Printf("Error updating API token last use: %v", err)
+	}
+
+	return &user, nil
+}
+
+// insertRefreshToken gera um novo refresh token para a sessão e grava apenas o hash
+func insertRefreshToken(tx *sqlx.Tx, sessionID uuid.UUID, ttl time.Duration) (string, error) {
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second')
+	`, sessionID, hash, seconds(ttl))
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (sessionRepository) Start(ctx context.Context, user *models.User, userAgent, ip string, ttl time.Duration) (*repository.StartedSession, error) {
+	started := repository.StartedSession{User: *user}
+	err := ownTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(&started.SessionID, `
+			INSERT INTO auth_sessions (user_id, user_agent, ip_address, expires_at)
+			VALUES ($1, $2, $3, CURRENT_TIMESTAMP + $4 * INTERVAL '1 second')
+			RETURNING id
+		`, user.ID, userAgent, ip, seconds(ttl))
+		if err != nil {
+			return err
+		}
+
+		started.RefreshToken, err = insertRefreshToken(tx, started.SessionID, ttl)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &started, nil
+}
+
+func (sessionRepository) Refresh(ctx context.Context, tokenHash, userAgent, ip string, ttl time.Duration) (*repository.StartedSession, error) {
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var stored struct {
+		ID            uuid.UUID `db:"id"`
+		SessionID     uuid.UUID `db:"session_id"`
+		UserID        uuid.UUID `db:"user_id"`
+		Used          bool      `db:"used"`
+		Expired       bool      `db:"expired"`
+		SessionActive bool      `db:"session_active"`
+	}
+	err = tx.Get(&stored, `
+		SELECT rt.id, rt.session_id, s.user_id,
+		       rt.used_at IS NOT NULL AS used,
+		       rt.expires_at <= CURRENT_TIMESTAMP AS expired,
+		       s.revoked_at IS NULL AND s.expires_at > CURRENT_TIMESTAMP AS session_active
+		FROM refresh_tokens rt
+		INNER JOIN auth_sessions s ON s.id = rt.session_id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt, s
+	`, tokenHash)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	// Token já trocado apresentado de novo: provável vazamento, encerra a sessão
+	if stored.Used {
+		if stored.SessionActive {
+			log.Printf("Refresh token reuse detected for session %s (user %s)", stored.SessionID, stored.UserID)
+			if err := revokeSession(tx, stored.SessionID, models.SessionRevokedTokenReuse); err != nil {
+				return nil, err
+			}
+			if err := tx.Commit(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, repository.ErrTokenReused
+	}
+
+	if stored.Expired || !stored.SessionActive {
+		return nil, repository.ErrSessionExpired
+	}
+
+	started := repository.StartedSession{SessionID: stored.SessionID}
+	if err := tx.Get(&started.User, "SELECT * FROM users WHERE id = $1", stored.UserID); err != nil {
+		return nil, err
+	}
+	if !started.User.IsActive {
+		return nil, repository.ErrInactiveUser
+	}
+
+	if _, err := tx.Exec("UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1", stored.ID); err != nil {
+		return nil, err
+	}
+
+	started.RefreshToken, err = insertRefreshToken(tx, stored.SessionID, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE auth_sessions
+		SET last_seen_at = CURRENT_TIMESTAMP, expires_at = CURRENT_TIMESTAMP + $1 * INTERVAL '1 second',
+		    ip_address = $2, user_agent = $3
+		WHERE id = $4
+	`, seconds(ttl), ip, userAgent, stored.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &started, nil
+}
+
+// revokeSession encerra uma sessão específica
+func revokeSession(q sqlx.Execer, sessionID uuid.UUID, reason string) error {
+	_, err := q.Exec(`
+		UPDATE auth_sessions
+		SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $1
+		WHERE id = $2 AND revoked_at IS NULL
+	`, reason, sessionID)
+	return err
+}
+
+func (sessionRepository) Revoke(ctx context.Context, sessionID uuid.UUID, reason string) error {
+	return revokeSession(DB, sessionID, reason)
+}
+
+func (sessionRepository) RevokeOwn(ctx context.Context, sessionID, userID uuid.UUID) error {
+	result, err := DB.ExecContext(ctx, `
+		UPDATE auth_sessions
+		SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $1
+		WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL
+	`, models.SessionRevokedByUser, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (sessionRepository) RevokeAll(ctx context.Context, userID uuid.UUID, reason string) (int64, error) {
+	result, err := DB.ExecContext(ctx, `
+		UPDATE auth_sessions
+		SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`, reason, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const authSessionColumns = `id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at,
+	revoked_at, revoked_reason`
+
+func (sessionRepository) ListActive(ctx context.Context, userID uuid.UUID) ([]models.AuthSession, error) {
+	sessions := []models.AuthSession{}
+	err := DB.SelectContext(ctx, &sessions, `
+		SELECT `+authSessionColumns+`
+		FROM auth_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY last_seen_at DESC
+	`, userID)
+	return sessions, err
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// ssoRepository lê as configurações e conduz o login com a conexão DB, já que o login ocorre
+// antes de existir um escopo; a gravação das configurações usa a transação de escopo
+type ssoRepository struct{}
+
+func (ssoRepository) Config(ctx context.Context, companyID uuid.UUID) (*models.CompanySSOConfig, error) {
+	var config models.CompanySSOConfig
+	if err := DB.GetContext(ctx, &config, "SELECT * FROM company_sso_configs WHERE company_id = $1", companyID); err != nil {
+		return nil, notFound(err)
+	}
+	return &config, nil
+}
+
+func (ssoRepository) SaveConfig(ctx context.Context, config *models.CompanySSOConfig) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&config.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	return sqlx.Get(conn(ctx), config, `
+		INSERT INTO company_sso_configs (company_id, issuer, client_id, client_secret, allowed_email_domains, default_role, is_enabled, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (company_id) DO UPDATE
+		SET issuer = EXCLUDED.issuer, client_id = EXCLUDED.client_id, client_secret = EXCLUDED.client_secret,
+		    allowed_email_domains = EXCLUDED.allowed_email_domains, default_role = EXCLUDED.default_role,
+		    is_enabled = EXCLUDED.is_enabled, updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+		RETURNING *
+	`, config.CompanyID, config.Issuer, config.ClientID, config.ClientSecret, config.AllowedEmailDomains,
+		config.DefaultRole, config.IsEnabled, config.UpdatedBy)
+}
+
+func (ssoRepository) DeleteConfig(ctx context.Context, companyID uuid.UUID) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(&companyID) {
+		return repository.ErrNotFound
+	}
+
+	result, err := conn(ctx).Exec("DELETE FROM company_sso_configs WHERE company_id = $1", companyID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (ssoRepository) CompanyForDomain(ctx context.Context, domain string) (uuid.UUID, error) {
+	var companyID uuid.UUID
+	err := DB.GetContext(ctx, &companyID, `
+		SELECT s.company_id
+		FROM company_sso_configs s
+		INNER JOIN companies c ON c.id = s.company_id
+		WHERE s.is_enabled = true AND c.is_active = true AND $1 = ANY(s.allowed_email_domains)
+		LIMIT 1
+	`, domain)
+	return companyID, notFound(err)
+}
+
+func (ssoRepository) StartLogin(ctx context.Context, companyID uuid.UUID, stateHash, nonce, codeVerifier string) error {
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO oidc_login_states (company_id, state_hash, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP + $5 * INTERVAL '1 second')
+	`, companyID, stateHash, nonce, codeVerifier, seconds(models.SSOLoginStateTTL))
+	return err
+}
+
+func (ssoRepository) ConsumeState(ctx context.Context, stateHash string) (*models.SSOLoginState, error) {
+	var state models.SSOLoginState
+	err := DB.GetContext(ctx, &state, `
+		UPDATE oidc_login_states
+		SET completed_at = CURRENT_TIMESTAMP
+		WHERE state_hash = $1 AND completed_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING id, company_id, nonce, code_verifier
+	`, stateHash)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &state, nil
+}
+
+func (ssoRepository) IssueLoginCode(ctx context.Context, loginID, userID uuid.UUID, codeHash string) error {
+	_, err := DB.ExecContext(ctx,
+		"UPDATE oidc_login_states SET user_id = $1, login_code_hash = $2 WHERE id = $3",
+		userID, codeHash, loginID,
+	)
+	return err
+}
+
+func (ssoRepository) ExchangeLoginCode(ctx context.Context, codeHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := DB.GetContext(ctx, &userID, `
+		UPDATE oidc_login_states
+		SET login_code_used_at = CURRENT_TIMESTAMP
+		WHERE login_code_hash = $1 AND login_code_used_at IS NULL
+		  AND completed_at > CURRENT_TIMESTAMP - $2 * INTERVAL '1 second'
+		RETURNING user_id
+	`, codeHash, seconds(models.SSOLoginCodeTTL))
+	return userID, notFound(err)
+}
+
+func (ssoRepository) Provision(ctx context.Context, identity repository.SSOIdentity, newUser *models.User, event *models.SecurityEvent) (*models.User, error) {
+	var user models.User
+	err := ownTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(&user, `
+			SELECT u.* FROM users u
+			INNER JOIN user_identities i ON i.user_id = u.id
+			WHERE i.issuer = $1 AND i.subject = $2
+		`, identity.Issuer, identity.Subject)
+		switch {
+		case err == nil:
+			if _, err := tx.Exec(`
+				UPDATE user_identities SET email = $1, last_login_at = CURRENT_TIMESTAMP
+				WHERE issuer = $2 AND subject = $3
+			`, newUser.Email, identity.Issuer, identity.Subject); err != nil {
+				return err
+			}
+
+		case err == sql.ErrNoRows:
+			err = tx.Get(&user, `
+				SELECT * FROM users
+				WHERE LOWER(email) = $1 AND NOT EXISTS (SELECT 1 FROM service_accounts sa WHERE sa.user_id = users.id)
+			`, newUser.Email)
+			if err == sql.ErrNoRows {
+				_, err = tx.Exec(`
+					INSERT INTO users (id, email, password, name, role, company_id, needs_password_change, is_active, created_at, updated_at)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				`, newUser.ID, newUser.Email, newUser.Password, newUser.Name, newUser.Role, newUser.CompanyID,
+					newUser.NeedsPasswordChange, newUser.IsActive, newUser.CreatedAt, newUser.UpdatedAt)
+				if err != nil {
+					return err
+				}
+				user = *newUser
+				if err := recordSecurityEvent(tx, event); err != nil {
+					return err
+				}
+			} else if err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(`
+				INSERT INTO user_identities (user_id, issuer, subject, email)
+				VALUES ($1, $2, $3, $4)
+			`, user.ID, identity.Issuer, identity.Subject, newUser.Email); err != nil {
+				return err
+			}
+
+		default:
+			return err
+		}
+
+		if user.CompanyID == nil || newUser.CompanyID == nil || *user.CompanyID != *newUser.CompanyID {
+			return repository.ErrOtherCompany
+		}
+		if !user.IsActive {
+			return repository.ErrInactiveUser
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+const teamColumns = `id, name, description, color, company_id, created_at, updated_at`
+
+type teamRepository struct{}
+
+// teamScopeFilter restringe times à empresa do escopo e, sem AllTeams, aos times do usuário
+func teamScopeFilter(scope repository.Scope, idColumn, companyColumn string, args *queryArgs) string {
+	return companyFilter(scope, companyColumn, args) + teamFilter(scope, idColumn, args)
+}
+
+// teamInScope devolve repository.ErrNotFound se o time não é da empresa do escopo ou, sem
+// AllTeams, não está entre os times do usuário
+func teamInScope(q sqlx.Queryer, scope repository.Scope, teamID uuid.UUID) error {
+	args := queryArgs{teamID}
+	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE id = $1` + teamScopeFilter(scope, "id", "company_id", &args) + `)`
+
+	var accessible bool
+	if err := sqlx.Get(q, &accessible, query, args...); err != nil {
+		return err
+	}
+	if !accessible {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// teamSortColumns são as colunas dos campos de repository.TeamSorting
+var teamSortColumns = map[string]string{
+	"name":      "name",
+	"createdAt": "created_at",
+}
+
+func (teamRepository) List(ctx context.Context, page repository.PageRequest) (*repository.Page[models.Team], error) {
+	args := queryArgs{}
+	from := `FROM teams WHERE 1=1` + companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	return listPage(ctx, repository.TeamSorting, teamSortColumns, teamColumns, from, args, page)
+}
+
+func (teamRepository) Get(ctx context.Context, id uuid.UUID) (*models.Team, error) {
+	args := queryArgs{id}
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE id = $1` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	var team models.Team
+	if err := sqlx.Get(conn(ctx), &team, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &team, nil
+}
+
+func (teamRepository) GetAccessible(ctx context.Context, id uuid.UUID) (*models.Team, error) {
+	args := queryArgs{id}
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE id = $1` +
+		teamScopeFilter(repository.ScopeFrom(ctx), "id", "company_id", &args)
+
+	var team models.Team
+	if err := sqlx.Get(conn(ctx), &team, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &team, nil
+}
+
+func (teamRepository) Create(ctx context.Context, team *models.Team) error {
+	scope := repository.ScopeFrom(ctx)
+	if !scope.OwnsCompany(team.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	return inTx(ctx, func(q sqlx.Ext) error {
+		err := q.QueryRowx(`
+			INSERT INTO teams (name, description, color, company_id)
+			VALUES ($1, $2, $3, $4)
+			RETURNING `+teamColumns,
+			team.Name, team.Description, team.Color, team.CompanyID,
+		).StructScan(team)
+		if err != nil {
+			return err
+		}
+
+		// Sem teams:all, quem cria o time é atribuído a ele para continuar enxergando-o
+		if !scope.AllTeams {
+			_, err = q.Exec("INSERT INTO user_teams (user_id, team_id, assigned_by) VALUES ($1, $2, $1)", scope.UserID, team.ID)
+		}
+		return err
+	})
+}
+
+func (teamRepository) Update(ctx context.Context, id uuid.UUID, update repository.TeamUpdate) (*models.Team, error) {
+	setParts := []string{}
+	args := queryArgs{}
+	if update.Name != nil {
+		setParts = append(setParts, "name = "+args.add(*update.Name))
+	}
+	if update.Description != nil {
+		setParts = append(setParts, "description = "+args.add(*update.Description))
+	}
+	if update.Color != nil {
+		setParts = append(setParts, "color = "+args.add(*update.Color))
+	}
+	if len(setParts) == 0 {
+		return nil, repository.ErrNoChanges
+	}
+
+	query := `UPDATE teams SET ` + strings.Join(setParts, ", ") + ` WHERE id = ` + args.add(id) +
+		teamScopeFilter(repository.ScopeFrom(ctx), "id", "company_id", &args) +
+		` RETURNING ` + teamColumns
+
+	var team models.Team
+	if err := conn(ctx).QueryRowx(query, args...).StructScan(&team); err != nil {
+		return nil, notFound(err)
+	}
+	return &team, nil
+}
+
+func (teamRepository) Delete(ctx context.Context, id uuid.UUID) (*models.Team, []uuid.UUID, error) {
+	var team models.Team
+	unassignedDevelopers := []uuid.UUID{}
+
+	err := inTx(ctx, func(q sqlx.Ext) error {
+		args := queryArgs{id}
+		query := `SELECT ` + teamColumns + ` FROM teams WHERE id = $1` +
+			teamScopeFilter(repository.ScopeFrom(ctx), "id", "company_id", &args) + ` FOR UPDATE`
+		if err := sqlx.Get(q, &team, query, args...); err != nil {
+			return notFound(err)
+		}
+
+		// Primeiro, remove a associação dos desenvolvedores com o time
+		if err := sqlx.Select(q, &unassignedDevelopers, "UPDATE developers SET team_id = NULL WHERE team_id = $1 RETURNING id", id); err != nil {
+			return err
+		}
+
+		_, err := q.Exec("DELETE FROM teams WHERE id = $1", id)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &team, unassignedDevelopers, nil
+}
+
+func (teamRepository) ListManagers(ctx context.Context, teamID uuid.UUID) ([]models.TeamManager, error) {
+	args := queryArgs{teamID}
+	query := `
+		SELECT ut.user_id, ut.team_id, u.name, u.email, u.role, ut.assigned_by, ut.assigned_at
+		FROM user_teams ut
+		INNER JOIN users u ON u.id = ut.user_id
+		INNER JOIN teams t ON t.id = ut.team_id
+		WHERE ut.team_id = $1` + teamScopeFilter(repository.ScopeFrom(ctx), "t.id", "t.company_id", &args) + `
+		ORDER BY u.name
+	`
+
+	managers := []models.TeamManager{}
+	err := sqlx.Select(conn(ctx), &managers, query, args...)
+	return managers, err
+}
+
+func (teamRepository) IsEligibleManager(ctx context.Context, teamID, userID uuid.UUID) (bool, error) {
+	// Admins e desenvolvedores não recebem times: os primeiros já veem tudo e os
+	// outros acessam apenas os próprios relatórios
+	args := queryArgs{userID, teamID}
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM users u
+			INNER JOIN teams t ON t.company_id = u.company_id
+			WHERE u.id = $1 AND t.id = $2 AND u.role IN ('company_admin', 'manager', 'user')` +
+		teamScopeFilter(repository.ScopeFrom(ctx), "t.id", "t.company_id", &args) + `
+		)
+	`
+
+	var eligible bool
+	err := sqlx.Get(conn(ctx), &eligible, query, args...)
+	return eligible, err
+}
+
+func (teamRepository) AddManager(ctx context.Context, teamID, userID, assignedBy uuid.UUID) error {
+	q := conn(ctx)
+	if err := teamInScope(q, repository.ScopeFrom(ctx), teamID); err != nil {
+		return err
+	}
+
+	_, err := q.Exec(`
+		INSERT INTO user_teams (user_id, team_id, assigned_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, team_id) DO NOTHING
+	`, userID, teamID, assignedBy)
+	return err
+}
+
+func (teamRepository) RemoveManager(ctx context.Context, teamID, userID uuid.UUID) error {
+	q := conn(ctx)
+	if err := teamInScope(q, repository.ScopeFrom(ctx), teamID); err != nil {
+		return err
+	}
+
+	result, err := q.Exec("DELETE FROM user_teams WHERE user_id = $1 AND team_id = $2", userID, teamID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// LoadEvaluationTemplateVersion carrega uma versão específica do template com categorias e perguntas
+func LoadEvaluationTemplateVersion(q sqlx.Queryer, versionID uuid.UUID) (*models.EvaluationTemplate, error) {
+	var template models.EvaluationTemplate
+	err := q.QueryRowx(`
+		SELECT t.id, t.name, COALESCE(t.description, '') AS description, t.company_id, t.is_active,
+		       v.id AS version_id, v.version, v.score_min, v.score_max, t.created_at, t.updated_at
+		FROM evaluation_template_versions v
+		INNER JOIN evaluation_templates t ON t.id = v.template_id
+		WHERE v.id = $1
+	`, versionID).StructScan(&template)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadEvaluationCategories(q, &template); err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// LoadActiveEvaluationTemplate retorna a versão mais recente do template ativo da empresa,
+// recorrendo ao template global quando a empresa não possui um próprio
+func LoadActiveEvaluationTemplate(q sqlx.Queryer, companyID *uuid.UUID) (*models.EvaluationTemplate, error) {
+	var versionID uuid.UUID
+	err := q.QueryRowx(`
+		SELECT v.id
+		FROM evaluation_templates t
+		INNER JOIN evaluation_template_versions v ON v.template_id = t.id
+		WHERE t.is_active = true AND (t.company_id = $1 OR t.company_id IS NULL)
+		ORDER BY (t.company_id IS NULL) ASC, v.version DESC
+		LIMIT 1
+	`, companyID).Scan(&versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadEvaluationTemplateVersion(q, versionID)
+}
+
+// LoadLatestEvaluationTemplate retorna a versão mais recente de um template
+func LoadLatestEvaluationTemplate(q sqlx.Queryer, templateID uuid.UUID) (*models.EvaluationTemplate, error) {
+	var versionID uuid.UUID
+	err := q.QueryRowx(`
+		SELECT id FROM evaluation_template_versions
+		WHERE template_id = $1
+		ORDER BY version DESC
+		LIMIT 1
+	`, templateID).Scan(&versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadEvaluationTemplateVersion(q, versionID)
+}
+
+func loadEvaluationCategories(q sqlx.Queryer, template *models.EvaluationTemplate) error {
+	rows, err := q.Queryx(`
+		SELECT id, key, label, weight, position
+		FROM evaluation_categories
+		WHERE template_version_id = $1
+		ORDER BY position ASC
+	`, template.VersionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	categoryIndex := make(map[uuid.UUID]int)
+	template.Categories = []models.EvaluationCategory{}
+	for rows.Next() {
+		var category models.EvaluationCategory
+		if err := rows.StructScan(&category); err != nil {
+			return err
+		}
+		category.Questions = []models.EvaluationQuestion{}
+		categoryIndex[category.ID] = len(template.Categories)
+		template.Categories = append(template.Categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	questionRows, err := q.Queryx(`
+		SELECT id, category_id, key, label, weight, position
+		FROM evaluation_questions
+		WHERE template_version_id = $1
+		ORDER BY position ASC
+	`, template.VersionID)
+	if err != nil {
+		return err
+	}
+	defer questionRows.Close()
+
+	for questionRows.Next() {
+		var question models.EvaluationQuestion
+		var categoryID uuid.UUID
+		if err := questionRows.Scan(&question.ID, &categoryID, &question.Key, &question.Label, &question.Weight, &question.Position); err != nil {
+			return err
+		}
+		if idx, ok := categoryIndex[categoryID]; ok {
+			template.Categories[idx].Questions = append(template.Categories[idx].Questions, question)
+		}
+	}
+
+	return questionRows.Err()
+}
+
+// insertEvaluationTemplateVersion grava uma nova versão do template, numerada a partir da última
+func insertEvaluationTemplateVersion(q sqlx.Ext, templateID uuid.UUID, version repository.TemplateVersion) error {
+	var versionID uuid.UUID
+	err := q.QueryRowx(`
+		INSERT INTO evaluation_template_versions (template_id, version, score_min, score_max)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3 FROM evaluation_template_versions WHERE template_id = $1
+		RETURNING id
+	`, templateID, version.ScoreMin, version.ScoreMax).Scan(&versionID)
+	if err != nil {
+		return err
+	}
+
+	for i, category := range version.Categories {
+		var categoryID uuid.UUID
+		err := q.QueryRowx(`
+			INSERT INTO evaluation_categories (template_version_id, key, label, weight, position)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, versionID, category.Key, category.Label, category.Weight, i+1).Scan(&categoryID)
+		if err != nil {
+			return err
+		}
+
+		for j, question := range category.Questions {
+			_, err := q.Exec(`
+				INSERT INTO evaluation_questions (template_version_id, category_id, key, label, weight, position)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, versionID, categoryID, question.Key, question.Label, question.Weight, j+1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lockTemplate bloqueia o template (serializando a numeração de versões) e verifica se o escopo
+// pode alterá-lo
+func lockTemplate(ctx context.Context, q sqlx.Queryer, id uuid.UUID) error {
+	var companyID *uuid.UUID
+	if err := q.QueryRowx("SELECT company_id FROM evaluation_templates WHERE id = $1 FOR UPDATE", id).Scan(&companyID); err != nil {
+		return notFound(err)
+	}
+	if !repository.ScopeFrom(ctx).OwnsCompany(companyID) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// templateVisible esconde os templates de outras empresas; os globais são visíveis a todos
+func templateVisible(ctx context.Context, template *models.EvaluationTemplate) error {
+	if template.CompanyID != nil && !repository.ScopeFrom(ctx).OwnsCompany(template.CompanyID) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// activateTemplate marca o template como o único ativo da empresa
+func activateTemplate(q sqlx.Execer, companyID, id uuid.UUID) error {
+	if _, err := q.Exec("UPDATE evaluation_templates SET is_active = false WHERE company_id = $1 AND id != $2", companyID, id); err != nil {
+		return err
+	}
+	_, err := q.Exec("UPDATE evaluation_templates SET is_active = true WHERE id = $1", id)
+	return err
+}
+
+type templateRepository struct{}
+
+func (templateRepository) Version(ctx context.Context, versionID uuid.UUID) (*models.EvaluationTemplate, error) {
+	template, err := LoadEvaluationTemplateVersion(conn(ctx), versionID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return template, templateVisible(ctx, template)
+}
+
+func (templateRepository) Active(ctx context.Context, companyID *uuid.UUID) (*models.EvaluationTemplate, error) {
+	if companyID != nil && !repository.ScopeFrom(ctx).OwnsCompany(companyID) {
+		return nil, repository.ErrNotFound
+	}
+	template, err := LoadActiveEvaluationTemplate(conn(ctx), companyID)
+	return template, notFound(err)
+}
+
+func (templateRepository) Latest(ctx context.Context, id uuid.UUID) (*models.EvaluationTemplate, error) {
+	template, err := LoadLatestEvaluationTemplate(conn(ctx), id)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return template, templateVisible(ctx, template)
+}
+
+func (templateRepository) ListByCompany(ctx context.Context, companyID uuid.UUID) ([]models.EvaluationTemplate, error) {
+	templates := []models.EvaluationTemplate{}
+	if !repository.ScopeFrom(ctx).OwnsCompany(&companyID) {
+		return templates, nil
+	}
+
+	var ids []uuid.UUID
+	err := sqlx.Select(conn(ctx), &ids, `
+		SELECT id FROM evaluation_templates
+		WHERE company_id = $1
+		ORDER BY is_active DESC, created_at DESC
+	`, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		template, err := LoadLatestEvaluationTemplate(conn(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	return templates, nil
+}
+
+func (templateRepository) Create(ctx context.Context, template *models.EvaluationTemplate, version repository.TemplateVersion, activate bool) (*models.EvaluationTemplate, error) {
+	if !repository.ScopeFrom(ctx).OwnsCompany(template.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+
+	var created *models.EvaluationTemplate
+	err := inTx(ctx, func(q sqlx.Ext) error {
+		var id uuid.UUID
+		err := q.QueryRowx(`
+			INSERT INTO evaluation_templates (name, description, company_id, is_active)
+			VALUES ($1, $2, $3, false)
+			RETURNING id
+		`, template.Name, template.Description, template.CompanyID).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		if err := insertEvaluationTemplateVersion(q, id, version); err != nil {
+			return err
+		}
+		if activate && template.CompanyID != nil {
+			if err := activateTemplate(q, *template.CompanyID, id); err != nil {
+				return err
+			}
+		}
+
+		created, err = LoadLatestEvaluationTemplate(q, id)
+		return err
+	})
+	return created, err
+}
+
+func (templateRepository) Update(ctx context.Context, id uuid.UUID, update repository.TemplateUpdate) (*models.EvaluationTemplate, error) {
+	if update.Name == nil && update.Description == nil && update.Version == nil {
+		return nil, repository.ErrNoChanges
+	}
+
+	var updated *models.EvaluationTemplate
+	err := inTx(ctx, func(q sqlx.Ext) error {
+		if err := lockTemplate(ctx, q, id); err != nil {
+			return err
+		}
+
+		if update.Name != nil || update.Description != nil {
+			_, err := q.Exec(`
+				UPDATE evaluation_templates
+				SET name = COALESCE($1, name), description = COALESCE($2, description)
+				WHERE id = $3
+			`, update.Name, update.Description, id)
+			if err != nil {
+				return err
+			}
+		}
+
+		if update.Version != nil {
+			if err := insertEvaluationTemplateVersion(q, id, *update.Version); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		updated, err = LoadLatestEvaluationTemplate(q, id)
+		return err
+	})
+	return updated, err
+}
+
+func (templateRepository) Activate(ctx context.Context, companyID, id uuid.UUID) error {
+	return inTx(ctx, func(q sqlx.Ext) error {
+		if err := lockTemplate(ctx, q, id); err != nil {
+			return err
+		}
+		return activateTemplate(q, companyID, id)
+	})
+}
+
+func (templateRepository) InUse(ctx context.Context, id uuid.UUID) (bool, error) {
+	var inUse bool
+	err := sqlx.Get(conn(ctx), &inUse, `
+		WITH versions AS (
+			SELECT id FROM evaluation_template_versions WHERE template_id = $1
+		)
+		SELECT EXISTS(SELECT 1 FROM performance_reports WHERE template_version_id IN (SELECT id FROM versions))
+		    OR EXISTS(SELECT 1 FROM review_cycles WHERE template_version_id IN (SELECT id FROM versions))
+		    OR EXISTS(SELECT 1 FROM self_assessments WHERE template_version_id IN (SELECT id FROM versions))
+		    OR EXISTS(SELECT 1 FROM feedback_rounds WHERE template_version_id IN (SELECT id FROM versions))
+	`, id)
+	return inUse, err
+}
+
+func (templateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return inTx(ctx, func(q sqlx.Ext) error {
+		if err := lockTemplate(ctx, q, id); err != nil {
+			return err
+		}
+		_, err := q.Exec("DELETE FROM evaluation_templates WHERE id = $1", id)
+		return err
+	})
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// AppRole é o papel que a API assume nas transações com escopo de empresa (criado pela
+// migração 027). Ao contrário do dono das tabelas, usado nas migrações e nos fluxos de
+// autenticação, ele está sujeito às políticas de row-level security.
+const AppRole = "tivix_app"
+
+// TenantScope é o escopo aplicado pelas políticas de RLS em uma transação
+type TenantScope struct {
+	CompanyID *uuid.UUID
+	// Bypass libera as linhas de todas as empresas (admins com companies:all)
+	Bypass bool
+}
+
+// BeginTenant abre uma transação com o papel da API e o escopo informado. Tudo é feito com
+// SET LOCAL, então nada sobra na conexão quando ela volta ao pool.
+func BeginTenant(ctx context.Context, scope TenantScope, readOnly bool) (*sqlx.Tx, error) {
+	tx, err := DB.BeginTxx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return nil, err
+	}
+
+	companyID := ""
+	if scope.CompanyID != nil {
+		companyID = scope.CompanyID.String()
+	}
+	bypass := "off"
+	if scope.Bypass {
+		bypass = "on"
+	}
+
+	if _, err := tx.Exec("SET LOCAL ROLE " + AppRole); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if _, err := tx.Exec("SELECT set_config('app.company_id', $1, true), set_config('app.bypass_rls', $2, true)", companyID, bypass); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// RevokeUserSessions encerra todas as sessões ativas do usuário; os access tokens emitidos
+// para elas deixam de ser aceitos imediatamente
+func RevokeUserSessions(q sqlx.Execer, userID uuid.UUID, reason string) error {
+	_, err := q.Exec(`
+		UPDATE auth_sessions
+		SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`, reason, userID)
+	return err
+}
+
+// userRepository roda na transação com escopo de empresa; o papel tivix_app só alcança as
+// colunas de auth_sessions usadas para encerrar as sessões (migração 028)
+type userRepository struct{}
+
+// userSortColumns são as colunas dos campos de repository.UserSorting
+var userSortColumns = map[string]string{
+	"name":      "name",
+	"email":     "email",
+	"role":      "role",
+	"createdAt": "created_at",
+}
+
+func (userRepository) List(ctx context.Context, filter repository.UserFilter, page repository.PageRequest) (*repository.Page[models.User], error) {
+	args := queryArgs{}
+	from := `
+		FROM users
+		WHERE NOT EXISTS (SELECT 1 FROM service_accounts sa WHERE sa.user_id = users.id)` +
+		companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+	if filter.Role != "" {
+		from += ` AND role = ` + args.add(filter.Role)
+	}
+	if filter.IsActive != nil {
+		from += ` AND is_active = ` + args.add(*filter.IsActive)
+	}
+
+	columns := `id, email, name, role, company_id, needs_password_change, is_active, created_at, updated_at`
+	return listPage(ctx, repository.UserSorting, userSortColumns, columns, from, args, page)
+}
+
+func (userRepository) Get(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	scope := repository.ScopeFrom(ctx)
+	args := queryArgs{id}
+	query := `SELECT * FROM users WHERE id = $1`
+	// O próprio usuário sempre está no escopo, mesmo sem empresa
+	if id != scope.UserID {
+		query += companyFilter(scope, "company_id", &args)
+	}
+
+	var user models.User
+	if err := sqlx.Get(conn(ctx), &user, query, args...); err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (userRepository) EmailExists(ctx context.Context, email string, exceptID *uuid.UUID) (bool, error) {
+	args := queryArgs{email}
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1`
+	if exceptID != nil {
+		query += ` AND id != ` + args.add(*exceptID)
+	}
+	query += `)`
+
+	var exists bool
+	err := sqlx.Get(conn(ctx), &exists, query, args...)
+	return exists, err
+}
+
+func (userRepository) Create(ctx context.Context, user *models.User) error {
+	if !repository.ScopeFrom(ctx).OwnsCompany(user.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	_, err := conn(ctx).Exec(`
+		INSERT INTO users (id, email, password, name, role, company_id, needs_password_change, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, user.ID, user.Email, user.Password, user.Name, user.Role, user.CompanyID, user.NeedsPasswordChange, user.IsActive, user.CreatedAt, user.UpdatedAt)
+	return err
+}
+
+func (userRepository) Update(ctx context.Context, id uuid.UUID, update repository.UserUpdate) (*models.User, error) {
+	scope := repository.ScopeFrom(ctx)
+	if update.CompanyID != nil && !scope.OwnsCompany(update.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+
+	setParts := []string{}
+	args := queryArgs{}
+	if update.Name != nil {
+		setParts = append(setParts, "name = "+args.add(*update.Name))
+	}
+	if update.Email != nil {
+		setParts = append(setParts, "email = "+args.add(*update.Email))
+	}
+	if update.Role != nil {
+		setParts = append(setParts, "role = "+args.add(*update.Role))
+	}
+	if update.CompanyID != nil {
+		setParts = append(setParts, "company_id = "+args.add(*update.CompanyID))
+	}
+	if update.IsActive != nil {
+		setParts = append(setParts, "is_active = "+args.add(*update.IsActive))
+	}
+	if len(setParts) == 0 {
+		return nil, repository.ErrNoChanges
+	}
+	setParts = append(setParts, "updated_at = "+args.add(time.Now()))
+
+	query := `UPDATE users SET ` + strings.Join(setParts, ", ") + ` WHERE id = ` + args.add(id) +
+		companyFilter(scope, "company_id", &args) + ` RETURNING *`
+
+	var user models.User
+	err := inTx(ctx, func(q sqlx.Ext) error {
+		if err := q.QueryRowx(query, args...).StructScan(&user); err != nil {
+			return notFound(err)
+		}
+		if update.RevokeSessions != "" {
+			return RevokeUserSessions(q, id, update.RevokeSessions)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := queryArgs{id}
+	query := `DELETE FROM users WHERE id = $1` + companyFilter(repository.ScopeFrom(ctx), "company_id", &args)
+
+	result, err := conn(ctx).Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -7,15 +7,16 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
-	"tivix-performance-tracker-backend/database"
+	"tivix-performance-tracker-backend/middleware"
 	"tivix-performance-tracker-backend/models"
 )
 
 // CreateAdminUser cria o primeiro usuário administrador (apenas se não houver usuários no sistema)
 func CreateAdminUser(c *fiber.Ctx) error {
+	accounts := middleware.Repositories(c).Accounts
+
 	// Verificar se já existem usuários no sistema
-	var userCount int
-	err := database.DB.Get(&userCount, "SELECT COUNT(*) FROM users")
+	userCount, err := accounts.Count(c.UserContext())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
@@ -87,12 +88,7 @@ func CreateAdminUser(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		INSERT INTO users (id, email, password, name, role, company_id, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`
-	_, err = database.DB.Exec(query, user.ID, user.Email, user.Password, user.Name, user.Role, user.CompanyID, user.IsActive, user.CreatedAt, user.UpdatedAt)
-	if err != nil {
+	if err := accounts.Create(c.UserContext(), &user); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
 			"message": "Erro ao criar usuário administrador",
@@ -113,8 +109,7 @@ func CreateAdminUser(c *fiber.Ctx) error {
 
 // CheckInitialization verifica se o sistema já foi inicializado
 func CheckInitialization(c *fiber.Ctx) error {
-	var userCount int
-	err := database.DB.Get(&userCount, "SELECT COUNT(*) FROM users")
+	userCount, err := middleware.Repositories(c).Accounts.Count(c.UserContext())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
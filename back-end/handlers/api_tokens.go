@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// apiTokenOwner identifica o dono do token nos eventos de segurança
+type apiTokenOwner struct {
+	UserID    uuid.UUID
+	CompanyID *uuid.UUID
+	Email     string
+}
+
+// parseCreateAPITokenRequest lê e valida o corpo da criação de token;
+// quando ok é false a resposta de erro já foi enviada
+func parseCreateAPITokenRequest(c *fiber.Ctx) (*models.CreateAPITokenRequest, bool) {
+	var req models.CreateAPITokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+		return nil, false
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+	return &req, true
+}
+
+// issueAPIToken grava um novo token para o dono e devolve o segredo, que não pode ser recuperado depois
+func issueAPIToken(c *fiber.Ctx, owner apiTokenOwner, req *models.CreateAPITokenRequest) (*models.CreatedAPIToken, error) {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	expiresInDays := models.APITokenDefaultTTLDays
+	if req.ExpiresInDays != nil {
+		expiresInDays = *req.ExpiresInDays
+	}
+
+	// Escopos repetidos são gravados uma única vez
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, scope := range req.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	token, prefix, hash, err := utils.GenerateAPIToken(models.APITokenBrand)
+	if err != nil {
+		return nil, err
+	}
+
+	created := models.CreatedAPIToken{Token: token}
+	created.APIToken = models.APIToken{
+		ID:        uuid.New(),
+		UserID:    owner.UserID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		Scopes:    pq.StringArray(scopes),
+		ExpiresAt: time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour),
+		CreatedBy: &user.UserID,
+	}
+
+	event := securityEvent(c, models.SecurityEventAPITokenCreated, &owner.UserID, owner.CompanyID, owner.Email, &user.UserID, models.JSONB{
+		"tokenId": created.ID,
+		"prefix":  created.Prefix,
+		"scopes":  scopes,
+	})
+	if err := middleware.Repositories(c).APITokens.Create(c.UserContext(), &created.APIToken, hash, event); err != nil {
+		return nil, err
+	}
+
+	// A auditoria guarda só os metadados; o segredo não sai da resposta
+	audit.Annotate(c, audit.Entry{
+		EntityType: "api-tokens",
+		EntityID:   created.ID.String(),
+		CompanyID:  owner.CompanyID,
+		After:      created.APIToken,
+	})
+	return &created, nil
+}
+
+// revokeAPIToken revoga o token indicado no parâmetro tokenParam, se pertencer ao dono; quando ok é false a resposta de erro já foi enviada
+func revokeAPIToken(c *fiber.Ctx, owner apiTokenOwner, tokenParam string) (*models.APIToken, bool) {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	tokenID, err := uuid.Parse(c.Params(tokenParam))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID do token inválido",
+		})
+		return nil, false
+	}
+
+	tokens := middleware.Repositories(c).APITokens
+
+	var before models.APIToken
+	token, err := tokens.Get(c.UserContext(), tokenID, owner.UserID)
+	if err == nil {
+		before = *token
+		event := securityEvent(c, models.SecurityEventAPITokenRevoked, &owner.UserID, owner.CompanyID, owner.Email, &user.UserID, models.JSONB{
+			"tokenId": token.ID,
+			"prefix":  token.Prefix,
+		})
+		err = tokens.Revoke(c.UserContext(), token, user.UserID, event)
+	}
+	if err == repository.ErrNotFound {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Token não encontrado ou já revogado",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error revoking API token: %v", err)
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao revogar token",
+		})
+		return nil, false
+	}
+
+	audit.Annotate(c, audit.Entry{
+		Action:     models.AuditActionUpdate,
+		EntityType: "api-tokens",
+		EntityID:   token.ID.String(),
+		CompanyID:  owner.CompanyID,
+		Before:     before,
+		After:      token,
+	})
+	return token, true
+}
+
+// ListMyAPITokens lista os tokens pessoais do usuário logado
+func ListMyAPITokens(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	tokens, err := middleware.Repositories(c).APITokens.List(c.UserContext(), user.UserID)
+	if err != nil {
+		log.Printf("Error querying API tokens: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar tokens",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   tokens,
+	})
+}
+
+// CreateMyAPIToken cria um token pessoal com os mesmos acessos do usuário, limitados pelos
+// escopos. A rota exige api_tokens:create, que os desenvolvedores não têm: eles só acessam
+// as rotas /me, que não aceitam tokens de API.
+func CreateMyAPIToken(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	req, ok := parseCreateAPITokenRequest(c)
+	if !ok {
+		return nil
+	}
+
+	created, err := issueAPIToken(c, apiTokenOwner{UserID: user.UserID, CompanyID: user.CompanyID, Email: user.Email}, req)
+	if err != nil {
+		log.Printf("Error creating API token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao criar token",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Token criado. Copie-o agora: ele não será exibido novamente",
+		"data":    created,
+	})
+}
+
+// RevokeMyAPIToken revoga um token pessoal do usuário logado
+func RevokeMyAPIToken(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	token, ok := revokeAPIToken(c, apiTokenOwner{UserID: user.UserID, CompanyID: user.CompanyID, Email: user.Email}, "id")
+	if !ok {
+		return nil
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Token revogado com sucesso",
+		"data":    token,
+	})
+}
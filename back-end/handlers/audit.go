@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// parseAuditTime aceita datas (YYYY-MM-DD) ou RFC3339; endOfDay avança datas simples para o
+// fim do dia, para que ?to=2025-10-17 inclua o próprio dia
+func parseAuditTime(value string, endOfDay bool) (time.Time, error) {
+	if parsed, err := time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			return parsed.AddDate(0, 0, 1), nil
+		}
+		return parsed, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// auditCompanyScope resolve a empresa consultada: admins podem informar ?companyId
+// (ou nenhuma, para todas), os demais ficam na própria empresa.
+// Quando ok é false a resposta de erro já foi enviada.
+func auditCompanyScope(c *fiber.Ctx) (*uuid.UUID, bool) {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	if user.HasPermission(models.PermCompaniesAll) {
+		value := c.Query("companyId")
+		if value == "" {
+			return nil, true
+		}
+		companyID, err := uuid.Parse(value)
+		if err != nil {
+			c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "ID da empresa inválido",
+			})
+			return nil, false
+		}
+		return &companyID, true
+	}
+
+	if user.CompanyID == nil {
+		c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Usuário deve estar associado a uma empresa",
+		})
+		return nil, false
+	}
+	return user.CompanyID, true
+}
+
+// ListAuditEvents lista os eventos de auditoria, do mais recente para o mais antigo.
+// Filtros: entityType, entityId, actorId, action, method, from, to e companyId (admin).
+// Paginação por cursor: ?limit=50&cursor=<nextCursor da página anterior>.
+func ListAuditEvents(c *fiber.Ctx) error {
+	companyID, ok := auditCompanyScope(c)
+	if !ok {
+		return nil
+	}
+
+	filter := repository.AuditFilter{
+		CompanyID:  companyID,
+		EntityType: c.Query("entityType"),
+		EntityID:   c.Query("entityId"),
+		Action:     c.Query("action"),
+		Method:     c.Query("method"),
+	}
+	if value := c.Query("actorId"); value != "" {
+		actorID, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "ID do autor inválido",
+			})
+		}
+		filter.ActorID = &actorID
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := parseAuditTime(value, false)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Data inicial inválida (use YYYY-MM-DD ou RFC3339)",
+			})
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := parseAuditTime(value, true)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Data final inválida (use YYYY-MM-DD ou RFC3339)",
+			})
+		}
+		filter.To = &to
+	}
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || cursor <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Cursor inválido",
+			})
+		}
+		filter.BeforeID = cursor
+	}
+
+	limit := c.QueryInt("limit", models.AuditDefaultPageSize)
+	if limit <= 0 || limit > models.AuditMaxPageSize {
+		limit = models.AuditMaxPageSize
+	}
+	// Um item a mais indica se existe próxima página
+	filter.Limit = limit + 1
+
+	events, err := middleware.Repositories(c).Audit.List(c.UserContext(), filter)
+	if err != nil {
+		log.Printf("Error querying audit events: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar eventos de auditoria",
+		})
+	}
+
+	var nextCursor *string
+	if len(events) > limit {
+		events = events[:limit]
+		cursor := strconv.FormatInt(events[limit-1].ID, 10)
+		nextCursor = &cursor
+	}
+
+	return c.JSON(fiber.Map{
+		"status":     "success",
+		"data":       events,
+		"nextCursor": nextCursor,
+	})
+}
+
+// VerifyAuditChain recalcula a cadeia de hashes da empresa e indica o primeiro evento
+// adulterado ou removido. Admins podem verificar qualquer empresa ou a cadeia global
+// (sem ?companyId).
+func VerifyAuditChain(c *fiber.Ctx) error {
+	companyID, ok := auditCompanyScope(c)
+	if !ok {
+		return nil
+	}
+
+	chainKey := audit.ChainKey(companyID)
+	events, err := middleware.Repositories(c).Audit.Chain(c.UserContext(), chainKey)
+	if err != nil {
+		log.Printf("Error verifying audit chain: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao verificar a trilha de auditoria",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   audit.VerifyEvents(chainKey, events),
+	})
+}
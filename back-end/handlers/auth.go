@@ -1,18 +1,17 @@
 package handlers
 
 import (
-	"database/sql"
-	"fmt"
-	"strings"
+	"log"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
-	"tivix-performance-tracker-backend/database"
+	"tivix-performance-tracker-backend/audit"
 	"tivix-performance-tracker-backend/middleware"
 	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
 	"tivix-performance-tracker-backend/utils"
 )
 
@@ -36,19 +35,21 @@ func Register(c *fiber.Ctx) error {
 		})
 	}
 
-	var existingUser models.User
-	err := database.DB.Get(&existingUser, "SELECT id FROM users WHERE email = $1", req.Email)
-	if err == nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error":   true,
-			"message": "Email já está em uso",
-		})
-	} else if err != sql.ErrNoRows {
+	accounts := middleware.Repositories(c).Accounts
+
+	emailExists, err := accounts.EmailExists(c.UserContext(), req.Email)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
 			"message": "Erro interno do servidor",
 		})
 	}
+	if emailExists {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Email já está em uso",
+		})
+	}
 
 	user := models.User{
 		ID:        uuid.New(),
@@ -71,19 +72,14 @@ func Register(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		INSERT INTO users (id, email, password, name, role, company_id, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`
-	_, err = database.DB.Exec(query, user.ID, user.Email, user.Password, user.Name, user.Role, user.CompanyID, user.IsActive, user.CreatedAt, user.UpdatedAt)
-	if err != nil {
+	if err := accounts.Create(c.UserContext(), &user); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
 			"message": "Erro ao criar usuário",
 		})
 	}
 
-	token, err := middleware.GenerateJWT(user)
+	session, err := startSession(c, user)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
@@ -94,10 +90,7 @@ func Register(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"error":   false,
 		"message": "Usuário criado com sucesso",
-		"data": models.LoginResponse{
-			Token: token,
-			User:  user,
-		},
+		"data":    session,
 	})
 }
 
@@ -119,9 +112,26 @@ func Login(c *fiber.Ctx) error {
 		})
 	}
 
-	var user models.User
-	err := database.DB.Get(&user, "SELECT * FROM users WHERE email = $1", req.Email)
-	if err == sql.ErrNoRows {
+	// Contas com muitas falhas recentes esperam o atraso progressivo ou o fim do bloqueio
+	retryAfter, locked, err := checkLoginThrottle(c, req.Email)
+	if err != nil {
+		log.Printf("Error checking login throttle: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+	if locked || retryAfter > 0 {
+		return loginThrottledResponse(c, retryAfter, locked)
+	}
+
+	// Contas de serviço não fazem login; só se autenticam por tokens de API
+	found, err := middleware.Repositories(c).Accounts.GetByEmail(c.UserContext(), req.Email)
+	if err == repository.ErrNotFound {
+		// Emails inexistentes também contam, para não revelar quais contas existem
+		if err := recordLoginFailure(c, req.Email, nil); err != nil {
+			log.Printf("Error recording login failure: %v", err)
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error":   true,
 			"message": "Credenciais inválidas",
@@ -132,6 +142,7 @@ func Login(c *fiber.Ctx) error {
 			"message": "Erro interno do servidor",
 		})
 	}
+	user := *found
 
 	if !user.IsActive {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -141,13 +152,33 @@ func Login(c *fiber.Ctx) error {
 	}
 
 	if err := user.CheckPassword(req.Password); err != nil {
+		if err := recordLoginFailure(c, req.Email, &user); err != nil {
+			log.Printf("Error recording login failure: %v", err)
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error":   true,
 			"message": "Credenciais inválidas",
 		})
 	}
 
-	token, err := middleware.GenerateJWT(user)
+	if err := clearLoginFailures(c, req.Email); err != nil {
+		log.Printf("Error clearing login failures: %v", err)
+	}
+
+	// Com 2FA ativo a senha só libera um desafio; o JWT sai em /auth/mfa/verify
+	mfaEnabled, err := userHasMFA(c, user.ID)
+	if err != nil {
+		log.Printf("Error querying MFA: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+	if mfaEnabled {
+		return respondMFAChallenge(c, user.ID)
+	}
+
+	session, err := startSession(c, user)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
@@ -158,18 +189,14 @@ func Login(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":  "success",
 		"message": "Login realizado com sucesso",
-		"data": models.LoginResponse{
-			Token: token,
-			User:  user,
-		},
+		"data":    session,
 	})
 }
 
 func GetProfile(c *fiber.Ctx) error {
 	userClaims := c.Locals("user").(*middleware.JWTClaims)
 
-	var user models.User
-	err := database.DB.Get(&user, "SELECT * FROM users WHERE id = $1", userClaims.UserID)
+	user, err := middleware.Repositories(c).Users.Get(c.UserContext(), userClaims.UserID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error":   true,
@@ -183,41 +210,6 @@ func GetProfile(c *fiber.Ctx) error {
 	})
 }
 
-func RefreshToken(c *fiber.Ctx) error {
-	userClaims := c.Locals("user").(*middleware.JWTClaims)
-
-	var user models.User
-	err := database.DB.Get(&user, "SELECT * FROM users WHERE id = $1", userClaims.UserID)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Usuário não encontrado",
-		})
-	}
-
-	if !user.IsActive {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error":   true,
-			"message": "Usuário inativo",
-		})
-	}
-
-	token, err := middleware.GenerateJWT(user)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Erro ao gerar token",
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"status": "success",
-		"data": fiber.Map{
-			"token": token,
-		},
-	})
-}
-
 func CreateUser(c *fiber.Ctx) error {
 	user := c.Locals("user").(*middleware.JWTClaims)
 	
@@ -238,9 +230,17 @@ func CreateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	// Ninguém cria ou promove um usuário a um papel com permissões que não possui
+	if !user.CanGrantRole(req.Role) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Você não pode conceder um papel com permissões que não possui",
+		})
+	}
+
 	// Verificar regras de empresa
 	var finalCompanyID *uuid.UUID
-	if user.Role == "admin" {
+	if user.HasPermission(models.PermCompaniesAll) {
 		// Admin pode especificar qualquer empresa (obrigatório agora)
 		if req.CompanyID == nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -249,8 +249,8 @@ func CreateUser(c *fiber.Ctx) error {
 			})
 		}
 		finalCompanyID = req.CompanyID
-	} else if user.Role == "manager" {
-		// Manager só pode criar usuários na sua própria empresa
+	} else {
+		// Demais usuários com permissão de gestão só criam usuários na sua própria empresa
 		if user.CompanyID == nil {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"status":  "error",
@@ -258,16 +258,12 @@ func CreateUser(c *fiber.Ctx) error {
 			})
 		}
 		finalCompanyID = user.CompanyID
-	} else {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Apenas admins e managers podem criar usuários",
-		})
 	}
 
+	repos := middleware.Repositories(c)
+
 	// Verificar se a empresa existe
-	var companyExists bool
-	err := database.DB.Get(&companyExists, "SELECT EXISTS(SELECT 1 FROM companies WHERE id = $1 AND is_active = true)", *finalCompanyID)
+	companyExists, err := repos.Companies.IsActive(c.UserContext(), *finalCompanyID)
 	if err != nil || !companyExists {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"status":  "error",
@@ -282,19 +278,19 @@ func CreateUser(c *fiber.Ctx) error {
 		})
 	}
 
-	var existingUser models.User
-	existingUserErr := database.DB.Get(&existingUser, "SELECT id FROM users WHERE email = $1", req.Email)
-	if existingUserErr == nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Email já está em uso",
-		})
-	} else if existingUserErr != sql.ErrNoRows {
+	emailExists, err := repos.Users.EmailExists(c.UserContext(), req.Email, nil)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Erro interno do servidor",
 		})
 	}
+	if emailExists {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Email já está em uso",
+		})
+	}
 
 	newUser := models.User{
 		ID:                  uuid.New(),
@@ -315,18 +311,15 @@ func CreateUser(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		INSERT INTO users (id, email, password, name, role, company_id, needs_password_change, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`
-	_, err = database.DB.Exec(query, newUser.ID, newUser.Email, newUser.Password, newUser.Name, newUser.Role, newUser.CompanyID, newUser.NeedsPasswordChange, newUser.IsActive, newUser.CreatedAt, newUser.UpdatedAt)
-	if err != nil {
+	if err := repos.Users.Create(c.UserContext(), &newUser); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Erro ao criar usuário",
 		})
 	}
 
+	audit.Annotate(c, audit.Entry{EntityType: "users", EntityID: newUser.ID.String(), CompanyID: newUser.CompanyID, After: newUser})
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"status": "success",
 		"data":   newUser,
@@ -360,14 +353,16 @@ func SetNewPassword(c *fiber.Ctx) error {
 
 	userClaims := c.Locals("user").(*middleware.JWTClaims)
 
-	var user models.User
-	err := database.DB.Get(&user, "SELECT * FROM users WHERE id = $1", userClaims.UserID)
+	accounts := middleware.Repositories(c).Accounts
+
+	found, err := accounts.Get(c.UserContext(), userClaims.UserID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Usuário não encontrado",
 		})
 	}
+	user := *found
 
 	if !user.NeedsPasswordChange {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -383,13 +378,7 @@ func SetNewPassword(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		UPDATE users 
-		SET password = $1, needs_password_change = false, updated_at = $2 
-		WHERE id = $3
-	`
-	_, err = database.DB.Exec(query, user.Password, time.Now(), user.ID)
-	if err != nil {
+	if err := accounts.SetPassword(c.UserContext(), user.ID, user.Password, true); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Erro ao atualizar senha",
@@ -399,7 +388,7 @@ func SetNewPassword(c *fiber.Ctx) error {
 	user.NeedsPasswordChange = false
 	user.UpdatedAt = time.Now()
 
-	token, err := middleware.GenerateJWT(user)
+	session, err := startSession(c, user)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -409,10 +398,7 @@ func SetNewPassword(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "success",
-		"data": models.LoginResponse{
-			Token: token,
-			User:  user,
-		},
+		"data":   session,
 	})
 }
 
@@ -443,14 +429,16 @@ func ChangePassword(c *fiber.Ctx) error {
 
 	userClaims := c.Locals("user").(*middleware.JWTClaims)
 
-	var user models.User
-	err := database.DB.Get(&user, "SELECT * FROM users WHERE id = $1", userClaims.UserID)
+	accounts := middleware.Repositories(c).Accounts
+
+	found, err := accounts.Get(c.UserContext(), userClaims.UserID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Usuário não encontrado",
 		})
 	}
+	user := *found
 
 	if err := user.CheckPassword(req.CurrentPassword); err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -466,54 +454,63 @@ func ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `UPDATE users SET password = $1, updated_at = $2 WHERE id = $3`
-	_, err = database.DB.Exec(query, user.Password, time.Now(), user.ID)
-	if err != nil {
+	if err := accounts.SetPassword(c.UserContext(), user.ID, user.Password, false); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Erro ao atualizar senha",
 		})
 	}
 
+	// Todas as sessões foram encerradas; o usuário atual recebe uma nova
+	session, err := startSession(c, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao gerar token",
+		})
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":  "success",
 		"message": "Senha alterada com sucesso",
+		"data":    session,
 	})
 }
 
 func ListUsers(c *fiber.Ctx) error {
 	user := c.Locals("user").(*middleware.JWTClaims)
-	var users []models.User
-
-	var query string
-	var args []interface{}
-
-	if user.Role == "admin" {
-		// Admins podem ver todos os usuários
-		query = `
-			SELECT id, email, name, role, company_id, needs_password_change, is_active, created_at, updated_at 
-			FROM users 
-			ORDER BY created_at DESC
-		`
-	} else {
-		// Managers e usuários só podem ver usuários da sua empresa
-		if user.CompanyID == nil {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Usuário deve estar associado a uma empresa",
-			})
-		}
-		
-		query = `
-			SELECT id, email, name, role, company_id, needs_password_change, is_active, created_at, updated_at 
-			FROM users 
-			WHERE company_id = $1
-			ORDER BY created_at DESC
-		`
-		args = append(args, *user.CompanyID)
+
+	// Managers e usuários só podem ver usuários da sua empresa
+	if !user.HasPermission(models.PermCompaniesAll) && user.CompanyID == nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Usuário deve estar associado a uma empresa",
+		})
+	}
+
+	page, err := parsePage(c, repository.UserSorting)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	filter := repository.UserFilter{Role: c.Query("role")}
+	if _, ok := models.RolePermissions[filter.Role]; filter.Role != "" && !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Papel inválido",
+		})
+	}
+	if filter.IsActive, err = parseBoolFilter(c, "isActive"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
 	}
 
-	err := database.DB.Select(&users, query, args...)
+	users, err := middleware.Repositories(c).Users.List(c.UserContext(), filter, page)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -521,10 +518,9 @@ func ListUsers(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"status": "success",
-		"data":   users,
-	})
+	response := pageResponse(users)
+	response["status"] = "success"
+	return c.Status(fiber.StatusOK).JSON(response)
 }
 
 func UpdateUser(c *fiber.Ctx) error {
@@ -554,10 +550,12 @@ func UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verificar se o usuário existe e se pode ser editado
-	var existingUser models.User
-	err = database.DB.Get(&existingUser, "SELECT * FROM users WHERE id = $1", userID)
-	if err == sql.ErrNoRows {
+	repos := middleware.Repositories(c)
+
+	// Verificar se o usuário existe e se pode ser editado; usuários de outra empresa são
+	// tratados como inexistentes
+	existingUser, err := repos.Users.Get(c.UserContext(), userID)
+	if err == repository.ErrNotFound {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Usuário não encontrado",
@@ -569,48 +567,30 @@ func UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verificar permissões de edição
-	if currentUser.Role != "admin" {
-		// Managers só podem editar usuários da sua própria empresa
-		if currentUser.CompanyID == nil || existingUser.CompanyID == nil || *currentUser.CompanyID != *existingUser.CompanyID {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Sem permissão para editar este usuário",
-			})
-		}
-		
-		// Managers não podem editar admins
-		if existingUser.Role == "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Managers não podem editar administradores",
-			})
-		}
-		
-		// Managers não podem promover usuários a admin
-		if req.Role != nil && *req.Role == "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Managers não podem promover usuários a administrador",
-			})
-		}
+	// Ninguém edita um usuário com permissões que não possui, nem o promove a um papel assim
+	targetPermissions, err := userPermissions(c, existingUser)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar permissões do usuário",
+		})
 	}
-
-	// Construir query de update dinamicamente
-	updates := []string{}
-	args := []interface{}{}
-	argCount := 1
-
-	if req.Name != nil {
-		updates = append(updates, fmt.Sprintf("name = $%d", argCount))
-		args = append(args, *req.Name)
-		argCount++
+	if !currentUser.HasAllPermissions(targetPermissions...) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Você não pode editar um usuário com permissões que não possui",
+		})
+	}
+	if req.Role != nil && !currentUser.CanGrantRole(*req.Role) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Você não pode conceder um papel com permissões que não possui",
+		})
 	}
 
 	if req.Email != nil {
 		// Verificar se email já existe em outro usuário
-		var emailExists bool
-		err := database.DB.Get(&emailExists, "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND id != $2)", *req.Email, userID)
+		emailExists, err := repos.Users.EmailExists(c.UserContext(), *req.Email, &userID)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"status":  "error",
@@ -623,21 +603,11 @@ func UpdateUser(c *fiber.Ctx) error {
 				"message": "Email já está em uso",
 			})
 		}
-
-		updates = append(updates, fmt.Sprintf("email = $%d", argCount))
-		args = append(args, *req.Email)
-		argCount++
-	}
-
-	if req.Role != nil {
-		updates = append(updates, fmt.Sprintf("role = $%d", argCount))
-		args = append(args, *req.Role)
-		argCount++
 	}
 
 	if req.CompanyID != nil {
 		// Apenas admin pode mudar a empresa do usuário
-		if currentUser.Role != "admin" {
+		if !currentUser.HasPermission(models.PermCompaniesAll) {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"status":  "error",
 				"message": "Apenas administradores podem alterar a empresa do usuário",
@@ -645,52 +615,46 @@ func UpdateUser(c *fiber.Ctx) error {
 		}
 
 		// Verificar se a empresa existe
-		var companyExists bool
-		err := database.DB.Get(&companyExists, "SELECT EXISTS(SELECT 1 FROM companies WHERE id = $1 AND is_active = true)", *req.CompanyID)
+		companyExists, err := repos.Companies.IsActive(c.UserContext(), *req.CompanyID)
 		if err != nil || !companyExists {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"status":  "error",
 				"message": "Empresa não encontrada ou inativa",
 			})
 		}
-
-		updates = append(updates, fmt.Sprintf("company_id = $%d", argCount))
-		args = append(args, *req.CompanyID)
-		argCount++
 	}
 
-	if req.IsActive != nil {
-		// Apenas admin pode ativar/desativar usuários
-		if currentUser.Role != "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Apenas administradores podem ativar/desativar usuários",
-			})
-		}
+	// Apenas admin pode ativar/desativar usuários
+	if req.IsActive != nil && !currentUser.HasPermission(models.PermCompaniesAll) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Apenas administradores podem ativar/desativar usuários",
+		})
+	}
 
-		updates = append(updates, fmt.Sprintf("is_active = $%d", argCount))
-		args = append(args, *req.IsActive)
-		argCount++
+	// Desativação e mudança de papel ou empresa encerram as sessões do usuário
+	update := repository.UserUpdate{
+		Name:      req.Name,
+		Email:     req.Email,
+		Role:      req.Role,
+		CompanyID: req.CompanyID,
+		IsActive:  req.IsActive,
+	}
+	if req.IsActive != nil && !*req.IsActive && existingUser.IsActive {
+		update.RevokeSessions = models.SessionRevokedDeactivated
+	} else if req.Role != nil && *req.Role != existingUser.Role {
+		update.RevokeSessions = models.SessionRevokedRoleChange
+	} else if req.CompanyID != nil && (existingUser.CompanyID == nil || *req.CompanyID != *existingUser.CompanyID) {
+		update.RevokeSessions = models.SessionRevokedCompanyChange
 	}
 
-	if len(updates) == 0 {
+	updatedUser, err := repos.Users.Update(c.UserContext(), userID, update)
+	if err == repository.ErrNoChanges {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Nenhum campo foi fornecido para atualização",
 		})
 	}
-
-	// Adicionar updated_at
-	updates = append(updates, fmt.Sprintf("updated_at = $%d", argCount))
-	args = append(args, time.Now())
-	argCount++
-
-	// Adicionar ID para WHERE clause
-	args = append(args, userID)
-
-	query := fmt.Sprintf("UPDATE users SET %s WHERE id = $%d", strings.Join(updates, ", "), argCount)
-	
-	_, err = database.DB.Exec(query, args...)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -698,15 +662,13 @@ func UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
-	// Buscar usuário atualizado
-	var updatedUser models.User
-	err = database.DB.Get(&updatedUser, "SELECT * FROM users WHERE id = $1", userID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Erro ao buscar usuário atualizado",
-		})
-	}
+	audit.Annotate(c, audit.Entry{
+		EntityType: "users",
+		EntityID:   updatedUser.ID.String(),
+		CompanyID:  updatedUser.CompanyID,
+		Before:     existingUser,
+		After:      updatedUser,
+	})
 
 	return c.JSON(fiber.Map{
 		"status": "success",
@@ -728,10 +690,11 @@ func DeleteUser(c *fiber.Ctx) error {
 	// Obter usuário atual das claims do JWT
 	currentUser := c.Locals("user").(*middleware.JWTClaims)
 
-	// Buscar o usuário a ser excluído
-	var userToDelete models.User
-	err = database.DB.Get(&userToDelete, "SELECT * FROM users WHERE id = $1", userUUID)
-	if err == sql.ErrNoRows {
+	repos := middleware.Repositories(c)
+
+	// Buscar o usuário a ser excluído; usuários de outra empresa são tratados como inexistentes
+	userToDelete, err := repos.Users.Get(c.UserContext(), userUUID)
+	if err == repository.ErrNotFound {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Usuário não encontrado",
@@ -743,23 +706,19 @@ func DeleteUser(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verificar permissões de exclusão
-	if currentUser.Role != "admin" {
-		// Managers só podem excluir usuários da sua própria empresa
-		if currentUser.CompanyID == nil || userToDelete.CompanyID == nil || *currentUser.CompanyID != *userToDelete.CompanyID {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Sem permissão para excluir este usuário",
-			})
-		}
-		
-		// Managers não podem excluir admins ou outros managers
-		if userToDelete.Role == "admin" || userToDelete.Role == "manager" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Sem permissão para excluir administradores ou gerentes",
-			})
-		}
+	// Ninguém exclui um usuário com permissões que não possui
+	targetPermissions, err := userPermissions(c, userToDelete)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar permissões do usuário",
+		})
+	}
+	if !currentUser.HasAllPermissions(targetPermissions...) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Você não pode excluir um usuário com permissões que não possui",
+		})
 	}
 
 	// Verificar se o usuário está tentando excluir a si mesmo
@@ -774,14 +733,15 @@ func DeleteUser(c *fiber.Ctx) error {
 	// Por exemplo, verificar se o usuário criou algum relatório ou outro dado importante
 
 	// Executar a exclusão
-	_, err = database.DB.Exec("DELETE FROM users WHERE id = $1", userUUID)
-	if err != nil {
+	if err := repos.Users.Delete(c.UserContext(), userUUID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Erro ao excluir usuário",
 		})
 	}
 
+	audit.Annotate(c, audit.Entry{EntityType: "users", EntityID: userToDelete.ID.String(), CompanyID: userToDelete.CompanyID, Before: userToDelete})
+
 	return c.JSON(fiber.Map{
 		"status":  "success",
 		"message": "Usuário excluído com sucesso",
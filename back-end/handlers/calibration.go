@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// requireScopedCalibrationSession carrega a sessão do parâmetro :id; quando ok é false
+// a resposta de erro já foi enviada
+func requireScopedCalibrationSession(c *fiber.Ctx) (*models.CalibrationSession, bool) {
+	sessionUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+		return nil, false
+	}
+
+	session, err := middleware.Repositories(c).Calibration.GetSession(c.UserContext(), sessionUUID)
+	if err == repository.ErrNotFound {
+		c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Sessão de calibração não encontrada",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying calibration session: %v", err)
+		c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar sessão de calibração",
+		})
+		return nil, false
+	}
+	return session, true
+}
+
+// requireOpenCalibrationSession é requireScopedCalibrationSession para sessões ainda abertas
+func requireOpenCalibrationSession(c *fiber.Ctx) (*models.CalibrationSession, bool) {
+	session, ok := requireScopedCalibrationSession(c)
+	if !ok {
+		return nil, false
+	}
+	if session.Status != models.CalibrationSessionOpen {
+		c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Sessão de calibração já foi confirmada",
+		})
+		return nil, false
+	}
+	return session, true
+}
+
+// CreateCalibrationSession abre uma sessão de calibração para o ciclo do parâmetro :id
+func CreateCalibrationSession(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	cycle, ok := requireScopedReviewCycle(c)
+	if !ok {
+		return nil
+	}
+
+	if cycle.Status == models.ReviewCyclePlanned {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "O ciclo de avaliação ainda não foi aberto",
+		})
+	}
+
+	var req models.CreateCalibrationSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	exists, err := repos.Calibration.HasOpenSession(c.UserContext(), cycle.ID)
+	if err != nil {
+		log.Printf("Error checking calibration session: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao verificar sessão existente",
+		})
+	}
+	if exists {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Já existe uma sessão de calibração aberta para este ciclo",
+		})
+	}
+
+	session := models.CalibrationSession{
+		CycleID:   cycle.ID,
+		CompanyID: cycle.CompanyID,
+		Name:      req.Name,
+		CreatedBy: &user.UserID,
+	}
+	if err := repos.Calibration.CreateSession(c.UserContext(), &session); err != nil {
+		log.Printf("Error creating calibration session: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao criar sessão de calibração",
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"data":    session,
+	})
+}
+
+// ListCalibrationSessions lista as sessões do ciclo do parâmetro :id
+func ListCalibrationSessions(c *fiber.Ctx) error {
+	cycle, ok := requireScopedReviewCycle(c)
+	if !ok {
+		return nil
+	}
+
+	sessions, err := middleware.Repositories(c).Calibration.ListSessions(c.UserContext(), cycle.ID)
+	if err != nil {
+		log.Printf("Error querying calibration sessions: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar sessões de calibração",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    sessions,
+	})
+}
+
+// GetCalibrationSession retorna a sessão e os ajustes propostos
+func GetCalibrationSession(c *fiber.Ctx) error {
+	session, ok := requireScopedCalibrationSession(c)
+	if !ok {
+		return nil
+	}
+
+	adjustments, err := middleware.Repositories(c).Calibration.Adjustments(c.UserContext(), session.ID)
+	if err != nil {
+		log.Printf("Error querying calibration adjustments: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar ajustes da sessão",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"session":     session,
+			"adjustments": adjustments,
+		},
+	})
+}
+
+// GetCalibrationDistribution retorna a distribuição das notas enviadas do ciclo por avaliador
+// e por time, com a média antes e depois dos ajustes propostos na sessão
+func GetCalibrationDistribution(c *fiber.Ctx) error {
+	session, ok := requireScopedCalibrationSession(c)
+	if !ok {
+		return nil
+	}
+
+	repos := middleware.Repositories(c)
+	cycle, err := repos.ReviewCycles.Get(c.UserContext(), session.CycleID)
+	if err != nil {
+		log.Printf("Error querying review cycle: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar ciclo de avaliação",
+		})
+	}
+
+	scores, err := repos.Calibration.Scores(c.UserContext(), cycle, session.ID)
+	if err != nil {
+		log.Printf("Error querying calibration scores: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao calcular distribuição das notas",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    models.BuildCalibrationDistribution(scores),
+	})
+}
+
+// ProposeCalibrationAdjustment cria ou substitui a proposta de nota calibrada de um relatório
+// enviado do ciclo. A nota do gerente é guardada como original_score.
+func ProposeCalibrationAdjustment(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	session, ok := requireOpenCalibrationSession(c)
+	if !ok {
+		return nil
+	}
+
+	reportUUID, err := uuid.Parse(c.Params("reportId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID do relatório inválido",
+		})
+	}
+
+	var req models.ProposeCalibrationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+	cycle, err := repos.ReviewCycles.Get(ctx, session.CycleID)
+	if err != nil {
+		log.Printf("Error querying review cycle: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar ciclo de avaliação",
+		})
+	}
+
+	// O relatório precisa ser do mês do ciclo, da empresa da sessão e já ter sido enviado
+	report, err := repos.Calibration.CycleReport(ctx, cycle, reportUUID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Relatório não encontrado no ciclo",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying performance report: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar relatório",
+		})
+	}
+	if !models.IsReportPublished(report.Status) {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Apenas relatórios enviados podem ser calibrados",
+		})
+	}
+
+	scoreMin, scoreMax := models.DefaultScoreMin, models.DefaultScoreMax
+	if report.TemplateVersionID != nil {
+		template, err := repos.Templates.Version(ctx, *report.TemplateVersionID)
+		if err != nil {
+			log.Printf("Error loading evaluation template: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao carregar template de avaliação",
+			})
+		}
+		scoreMin, scoreMax = template.ScoreMin, template.ScoreMax
+	}
+	if *req.ProposedScore < scoreMin || *req.ProposedScore > scoreMax {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Nota proposta fora da escala do template",
+		})
+	}
+
+	adjustment := models.CalibrationAdjustment{
+		SessionID:     session.ID,
+		ReportID:      report.ID,
+		DeveloperID:   report.DeveloperID,
+		OriginalScore: report.WeightedAverageScore,
+		ProposedScore: *req.ProposedScore,
+		Justification: req.Justification,
+		ProposedBy:    &user.UserID,
+	}
+	if err := repos.Calibration.Propose(ctx, &adjustment); err != nil {
+		log.Printf("Error saving calibration adjustment: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao salvar ajuste",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"id":            adjustment.ID,
+			"reportId":      report.ID,
+			"originalScore": report.WeightedAverageScore,
+			"proposedScore": *req.ProposedScore,
+		},
+	})
+}
+
+// RemoveCalibrationAdjustment retira a proposta de ajuste de um relatório
+func RemoveCalibrationAdjustment(c *fiber.Ctx) error {
+	session, ok := requireOpenCalibrationSession(c)
+	if !ok {
+		return nil
+	}
+
+	reportUUID, err := uuid.Parse(c.Params("reportId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID do relatório inválido",
+		})
+	}
+
+	err = middleware.Repositories(c).Calibration.RemoveAdjustment(c.UserContext(), session.ID, reportUUID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Ajuste não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error deleting calibration adjustment: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao remover ajuste",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Ajuste removido com sucesso",
+	})
+}
+
+// CommitCalibrationSession aplica os ajustes propostos aos relatórios: a nota calibrada é
+// gravada ao lado da original e cada ajuste gera uma revisão "calibrate" no histórico
+func CommitCalibrationSession(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	session, ok := requireOpenCalibrationSession(c)
+	if !ok {
+		return nil
+	}
+
+	committed, adjustments, err := middleware.Repositories(c).Calibration.Commit(c.UserContext(), session.ID, user.UserID)
+	var stale *repository.StaleAdjustmentError
+	switch {
+	case err == repository.ErrCalibrationCommitted:
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Sessão de calibração já foi confirmada",
+		})
+	case err == repository.ErrNoAdjustments:
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Nenhum ajuste proposto nesta sessão",
+		})
+	case err == repository.ErrNotFound:
+		// Algum ajuste é de relatório fora da empresa ou dos times do usuário
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Sessão contém ajustes de relatórios fora do seu alcance",
+		})
+	case errors.As(err, &stale):
+		// A proposta foi feita sobre uma nota que não existe mais
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": stale.Error(),
+		})
+	case err != nil:
+		log.Printf("Error committing calibration session: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao confirmar sessão de calibração",
+		})
+	}
+
+	// Os ajustes aplicados acompanham a sessão; as notas anteriores ficam nas revisões
+	audit.Annotate(c, audit.Entry{
+		Action:     models.AuditActionUpdate,
+		EntityType: "calibration-sessions",
+		EntityID:   committed.ID.String(),
+		CompanyID:  &committed.CompanyID,
+		Before:     session,
+		After: fiber.Map{
+			"session":     committed,
+			"adjustments": adjustments,
+		},
+	})
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"session":     committed,
+			"adjustments": len(adjustments),
+		},
+	})
+}
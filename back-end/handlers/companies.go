@@ -1,20 +1,18 @@
 package handlers
 
 import (
-	"database/sql"
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
-	"tivix-performance-tracker-backend/database"
 	"tivix-performance-tracker-backend/middleware"
 	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
 )
 
 func CreateCompany(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
 	var req models.CreateCompanyRequest
 
 	if err := c.BodyParser(&req); err != nil {
@@ -33,19 +31,19 @@ func CreateCompany(c *fiber.Ctx) error {
 	}
 
 	// Verificar se já existe uma empresa com o mesmo nome
-	var existingCompany models.Company
-	err := database.DB.Get(&existingCompany, "SELECT id FROM companies WHERE name = $1", req.Name)
-	if err == nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Já existe uma empresa com esse nome",
-		})
-	} else if err != sql.ErrNoRows {
+	nameExists, err := repos.Companies.NameExists(c.UserContext(), req.Name, nil)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Erro interno do servidor",
 		})
 	}
+	if nameExists {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Já existe uma empresa com esse nome",
+		})
+	}
 
 	company := models.Company{
 		ID:          uuid.New(),
@@ -56,12 +54,7 @@ func CreateCompany(c *fiber.Ctx) error {
 		UpdatedAt:   time.Now(),
 	}
 
-	query := `
-		INSERT INTO companies (id, name, description, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
-	_, err = database.DB.Exec(query, company.ID, company.Name, company.Description, company.IsActive, company.CreatedAt, company.UpdatedAt)
-	if err != nil {
+	if err := repos.Companies.Create(c.UserContext(), &company); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Erro ao criar empresa",
@@ -76,37 +69,31 @@ func CreateCompany(c *fiber.Ctx) error {
 
 func GetAllCompanies(c *fiber.Ctx) error {
 	user := c.Locals("user").(*middleware.JWTClaims)
-	var companies []models.Company
-
-	var query string
-	var args []interface{}
-
-	if user.Role == "admin" {
-		// Admins podem ver todas as empresas
-		query = `
-			SELECT id, name, description, is_active, created_at, updated_at
-			FROM companies
-			ORDER BY name ASC
-		`
-	} else {
-		// Managers e usuários só podem ver sua própria empresa
-		if user.CompanyID == nil {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Usuário deve estar associado a uma empresa",
-			})
-		}
-		
-		query = `
-			SELECT id, name, description, is_active, created_at, updated_at
-			FROM companies
-			WHERE id = $1
-			ORDER BY name ASC
-		`
-		args = append(args, *user.CompanyID)
+
+	// Managers e usuários só podem ver sua própria empresa
+	if !user.HasPermission(models.PermCompaniesAll) && user.CompanyID == nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Usuário deve estar associado a uma empresa",
+		})
 	}
 
-	err := database.DB.Select(&companies, query, args...)
+	page, err := parsePage(c, repository.CompanySorting)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+	isActive, err := parseBoolFilter(c, "isActive")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	companies, err := middleware.Repositories(c).Companies.List(c.UserContext(), repository.CompanyFilter{IsActive: isActive}, page)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -114,10 +101,9 @@ func GetAllCompanies(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"status": "success",
-		"data":   companies,
-	})
+	response := pageResponse(companies)
+	response["status"] = "success"
+	return c.JSON(response)
 }
 
 func GetCompanyByID(c *fiber.Ctx) error {
@@ -130,14 +116,8 @@ func GetCompanyByID(c *fiber.Ctx) error {
 		})
 	}
 
-	var company models.Company
-	query := `
-		SELECT id, name, description, is_active, created_at, updated_at
-		FROM companies
-		WHERE id = $1
-	`
-	err = database.DB.Get(&company, query, companyID)
-	if err == sql.ErrNoRows {
+	company, err := middleware.Repositories(c).Companies.Get(c.UserContext(), companyID)
+	if err == repository.ErrNotFound {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Empresa não encontrada",
@@ -156,6 +136,7 @@ func GetCompanyByID(c *fiber.Ctx) error {
 }
 
 func UpdateCompany(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
 	id := c.Params("id")
 	companyID, err := uuid.Parse(id)
 	if err != nil {
@@ -174,9 +155,8 @@ func UpdateCompany(c *fiber.Ctx) error {
 	}
 
 	// Verificar se a empresa existe
-	var existingCompany models.Company
-	err = database.DB.Get(&existingCompany, "SELECT * FROM companies WHERE id = $1", companyID)
-	if err == sql.ErrNoRows {
+	_, err = repos.Companies.Get(c.UserContext(), companyID)
+	if err == repository.ErrNotFound {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Empresa não encontrada",
@@ -188,62 +168,34 @@ func UpdateCompany(c *fiber.Ctx) error {
 		})
 	}
 
-	// Construir query de update dinamicamente
-	updates := []string{}
-	args := []interface{}{}
-	argCount := 1
-
 	if req.Name != nil {
 		// Verificar se já existe outra empresa com o mesmo nome
-		var nameCheckCompany models.Company
-		err := database.DB.Get(&nameCheckCompany, "SELECT id FROM companies WHERE name = $1 AND id != $2", *req.Name, companyID)
-		if err == nil {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Já existe uma empresa com esse nome",
-			})
-		} else if err != sql.ErrNoRows {
+		nameExists, err := repos.Companies.NameExists(c.UserContext(), *req.Name, &companyID)
+		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"status":  "error",
 				"message": "Erro interno do servidor",
 			})
 		}
-
-		updates = append(updates, "name = $"+fmt.Sprintf("%d", argCount))
-		args = append(args, *req.Name)
-		argCount++
-	}
-
-	if req.Description != nil {
-		updates = append(updates, "description = $"+fmt.Sprintf("%d", argCount))
-		args = append(args, *req.Description)
-		argCount++
-	}
-
-	if req.IsActive != nil {
-		updates = append(updates, "is_active = $"+fmt.Sprintf("%d", argCount))
-		args = append(args, *req.IsActive)
-		argCount++
+		if nameExists {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Já existe uma empresa com esse nome",
+			})
+		}
 	}
 
-	if len(updates) == 0 {
+	updatedCompany, err := repos.Companies.Update(c.UserContext(), companyID, repository.CompanyUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		IsActive:    req.IsActive,
+	})
+	if err == repository.ErrNoChanges {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Nenhum campo foi fornecido para atualização",
 		})
 	}
-
-	// Adicionar updated_at
-	updates = append(updates, "updated_at = $"+fmt.Sprintf("%d", argCount))
-	args = append(args, time.Now())
-	argCount++
-
-	// Adicionar ID para WHERE clause
-	args = append(args, companyID)
-
-	query := fmt.Sprintf("UPDATE companies SET %s WHERE id = $%d", strings.Join(updates, ", "), argCount)
-	
-	_, err = database.DB.Exec(query, args...)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -251,16 +203,6 @@ func UpdateCompany(c *fiber.Ctx) error {
 		})
 	}
 
-	// Buscar empresa atualizada
-	var updatedCompany models.Company
-	err = database.DB.Get(&updatedCompany, "SELECT * FROM companies WHERE id = $1", companyID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Erro ao buscar empresa atualizada",
-		})
-	}
-
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data":   updatedCompany,
@@ -268,6 +210,7 @@ func UpdateCompany(c *fiber.Ctx) error {
 }
 
 func DeleteCompany(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
 	id := c.Params("id")
 	companyID, err := uuid.Parse(id)
 	if err != nil {
@@ -278,9 +221,8 @@ func DeleteCompany(c *fiber.Ctx) error {
 	}
 
 	// Verificar se a empresa existe
-	var company models.Company
-	err = database.DB.Get(&company, "SELECT id FROM companies WHERE id = $1", companyID)
-	if err == sql.ErrNoRows {
+	_, err = repos.Companies.Get(c.UserContext(), companyID)
+	if err == repository.ErrNotFound {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Empresa não encontrada",
@@ -293,8 +235,7 @@ func DeleteCompany(c *fiber.Ctx) error {
 	}
 
 	// Verificar se existem usuários associados à empresa
-	var userCount int
-	err = database.DB.Get(&userCount, "SELECT COUNT(*) FROM users WHERE company_id = $1", companyID)
+	userCount, err := repos.Companies.CountUsers(c.UserContext(), companyID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -310,8 +251,7 @@ func DeleteCompany(c *fiber.Ctx) error {
 	}
 
 	// Deletar empresa
-	_, err = database.DB.Exec("DELETE FROM companies WHERE id = $1", companyID)
-	if err != nil {
+	if err := repos.Companies.Delete(c.UserContext(), companyID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Erro ao excluir empresa",
@@ -323,3 +263,44 @@ func DeleteCompany(c *fiber.Ctx) error {
 		"message": "Empresa excluída com sucesso",
 	})
 }
+
+// RecomputeCompanyScores recalcula a pontuação mais recente de todos os desenvolvedores da empresa
+func RecomputeCompanyScores(c *fiber.Ctx) error {
+	id := c.Params("id")
+	companyID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID da empresa inválido",
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	if _, err := repos.Companies.Get(c.UserContext(), companyID); err == repository.ErrNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Empresa não encontrada",
+		})
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar empresa",
+		})
+	}
+
+	updated, err := repos.Companies.RecomputeScores(c.UserContext(), companyID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao recalcular pontuações",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Pontuações recalculadas com sucesso",
+		"data": fiber.Map{
+			"developers": updated,
+		},
+	})
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"database/sql"
 	"fmt"
 	"log"
 	"time"
@@ -9,54 +8,69 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
-	"tivix-performance-tracker-backend/database"
+	"tivix-performance-tracker-backend/audit"
 	"tivix-performance-tracker-backend/middleware"
 	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
 )
 
-// GetAllDevelopers retorna todos os desenvolvedores
+// GetAllDevelopers retorna os desenvolvedores paginados por cursor, com filtros por time,
+// cargo, faixa de pontuação e arquivamento (archived=false, true ou all)
 func GetAllDevelopers(c *fiber.Ctx) error {
 	user := c.Locals("user").(*middleware.JWTClaims)
-	
-	// Verificar se deve incluir arquivados
-	includeArchived := c.Query("includeArchived", "false")
-
-	var query string
-	var args []interface{}
-
-	if user.Role == "admin" {
-		// Admins podem ver todos os desenvolvedores
-		query = `
-			SELECT id, name, role, latest_performance_score, team_id, company_id, archived_at, created_at, updated_at 
-			FROM developers 
-		`
-		if includeArchived != "true" {
-			query += " WHERE archived_at IS NULL"
-		}
-	} else {
-		// Managers e usuários só podem ver desenvolvedores da sua empresa
-		if user.CompanyID == nil {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   true,
-				"message": "Usuário deve estar associado a uma empresa",
-			})
-		}
-		
-		query = `
-			SELECT id, name, role, latest_performance_score, team_id, company_id, archived_at, created_at, updated_at 
-			FROM developers 
-			WHERE company_id = $1
-		`
-		args = append(args, *user.CompanyID)
-		
-		if includeArchived != "true" {
-			query += " AND archived_at IS NULL"
-		}
+
+	// Managers e usuários só podem ver desenvolvedores da sua empresa
+	if !user.HasPermission(models.PermCompaniesAll) && user.CompanyID == nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Usuário deve estar associado a uma empresa",
+		})
 	}
 
-	query += " ORDER BY created_at DESC"
+	page, err := parsePage(c, repository.DeveloperSorting)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
 
-	rows, err := database.DB.Query(query, args...)
+	filter := repository.DeveloperFilter{Role: c.Query("role")}
+	if filter.TeamID, err = parseUUIDFilter(c, "teamId"); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	filter.MinScore, filter.MaxScore, err = parseScoreRange(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	// includeArchived=true continua aceito como archived=all
+	archived := c.Query("archived", "false")
+	if c.Query("includeArchived") == "true" {
+		archived = "all"
+	}
+	switch archived {
+	case "false":
+	case "true":
+		filter.ArchivedOnly = true
+	case "all":
+		filter.IncludeArchived = true
+	default:
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Valor inválido para archived (use false, true ou all)",
+		})
+	}
+
+	// Empresa e times do usuário são aplicados pelo repositório
+	developers, err := middleware.Repositories(c).Developers.List(c.UserContext(), filter, page)
 	if err != nil {
 		log.Printf("Error querying developers: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -64,45 +78,18 @@ func GetAllDevelopers(c *fiber.Ctx) error {
 			"message": "Erro ao buscar desenvolvedores",
 		})
 	}
-	defer rows.Close()
-
-	var developers []models.Developer
-	for rows.Next() {
-		var developer models.Developer
-		err := rows.Scan(
-			&developer.ID,
-			&developer.Name,
-			&developer.Role,
-			&developer.LatestPerformanceScore,
-			&developer.TeamID,
-			&developer.CompanyID,
-			&developer.ArchivedAt,
-			&developer.CreatedAt,
-			&developer.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning developer: %v", err)
-			continue
-		}
-		developers = append(developers, developer)
-	}
 
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    developers,
-	})
+	response := pageResponse(developers)
+	response["success"] = true
+	return c.JSON(response)
 }
 
-// GetArchivedDevelopers retorna apenas desenvolvedores arquivados
+// GetArchivedDevelopers retorna apenas desenvolvedores arquivados, do arquivamento mais
+// recente para o mais antigo
 func GetArchivedDevelopers(c *fiber.Ctx) error {
-	query := `
-		SELECT id, name, role, latest_performance_score, team_id, archived_at, created_at, updated_at 
-		FROM developers 
-		WHERE archived_at IS NOT NULL
-		ORDER BY archived_at DESC
-	`
-
-	rows, err := database.DB.Query(query)
+	developers, err := middleware.Repositories(c).Developers.List(c.UserContext(), repository.DeveloperFilter{
+		ArchivedOnly: true,
+	}, repository.PageRequest{Sort: "archivedAt", Desc: true})
 	if err != nil {
 		log.Printf("Error querying archived developers: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -110,31 +97,10 @@ func GetArchivedDevelopers(c *fiber.Ctx) error {
 			"message": "Erro ao buscar desenvolvedores arquivados",
 		})
 	}
-	defer rows.Close()
-
-	var developers []models.Developer
-	for rows.Next() {
-		var developer models.Developer
-		err := rows.Scan(
-			&developer.ID,
-			&developer.Name,
-			&developer.Role,
-			&developer.LatestPerformanceScore,
-			&developer.TeamID,
-			&developer.ArchivedAt,
-			&developer.CreatedAt,
-			&developer.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning developer: %v", err)
-			continue
-		}
-		developers = append(developers, developer)
-	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data":    developers,
+		"data":    developers.Items,
 	})
 }
 
@@ -149,25 +115,9 @@ func GetDeveloperByID(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		SELECT id, name, role, latest_performance_score, team_id, archived_at, created_at, updated_at 
-		FROM developers 
-		WHERE id = $1
-	`
-
-	var developer models.Developer
-	err = database.DB.QueryRow(query, developerUUID).Scan(
-		&developer.ID,
-		&developer.Name,
-		&developer.Role,
-		&developer.LatestPerformanceScore,
-		&developer.TeamID,
-		&developer.ArchivedAt,
-		&developer.CreatedAt,
-		&developer.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
+	// Desenvolvedores de outra empresa (ou fora dos times do usuário) são tratados como inexistentes
+	developer, err := middleware.Repositories(c).Developers.Get(c.UserContext(), developerUUID)
+	if err == repository.ErrNotFound {
 		return c.Status(404).JSON(fiber.Map{
 			"error":   true,
 			"message": "Desenvolvedor não encontrado",
@@ -189,8 +139,9 @@ func GetDeveloperByID(c *fiber.Ctx) error {
 
 // CreateDeveloper cria um novo desenvolvedor
 func CreateDeveloper(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
 	user := c.Locals("user").(*middleware.JWTClaims)
-	
+
 	var req models.CreateDeveloperRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -215,7 +166,7 @@ func CreateDeveloper(c *fiber.Ctx) error {
 
 	// Determinar a empresa do desenvolvedor
 	var companyID *uuid.UUID
-	if user.Role == "admin" && req.CompanyID != nil {
+	if user.HasPermission(models.PermCompaniesAll) && req.CompanyID != nil {
 		// Admin pode especificar a empresa
 		companyID = req.CompanyID
 	} else if user.CompanyID != nil {
@@ -230,9 +181,8 @@ func CreateDeveloper(c *fiber.Ctx) error {
 
 	// Verificar se o team_id existe e pertence à mesma empresa (se fornecido)
 	if req.TeamID != nil {
-		var teamCompanyID *uuid.UUID
-		err := database.DB.QueryRow("SELECT company_id FROM teams WHERE id = $1", *req.TeamID).Scan(&teamCompanyID)
-		if err == sql.ErrNoRows {
+		team, err := repos.Teams.Get(c.UserContext(), *req.TeamID)
+		if err == repository.ErrNotFound {
 			return c.Status(400).JSON(fiber.Map{
 				"error":   true,
 				"message": "Time não encontrado",
@@ -244,8 +194,7 @@ func CreateDeveloper(c *fiber.Ctx) error {
 			})
 		}
 
-		// Verificar se o time pertence à mesma empresa
-		if user.Role != "admin" && (teamCompanyID == nil || companyID == nil || *teamCompanyID != *companyID) {
+		if team.CompanyID == nil || *team.CompanyID != *companyID {
 			return c.Status(400).JSON(fiber.Map{
 				"error":   true,
 				"message": "Time não pertence à sua empresa",
@@ -253,25 +202,27 @@ func CreateDeveloper(c *fiber.Ctx) error {
 		}
 	}
 
-	query := `
-		INSERT INTO developers (name, role, team_id, company_id)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, name, role, latest_performance_score, team_id, company_id, archived_at, created_at, updated_at
-	`
-
-	var developer models.Developer
-	err := database.DB.QueryRow(query, req.Name, req.Role, req.TeamID, companyID).Scan(
-		&developer.ID,
-		&developer.Name,
-		&developer.Role,
-		&developer.LatestPerformanceScore,
-		&developer.TeamID,
-		&developer.CompanyID,
-		&developer.ArchivedAt,
-		&developer.CreatedAt,
-		&developer.UpdatedAt,
-	)
+	// Sem teams:all, o desenvolvedor precisa entrar em um dos times atribuídos ao usuário
+	if !user.HasPermission(models.PermTeamsAll) && req.TeamID == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Informe um dos seus times para o desenvolvedor",
+		})
+	}
 
+	developer := models.Developer{
+		Name:      req.Name,
+		Role:      req.Role,
+		TeamID:    req.TeamID,
+		CompanyID: companyID,
+	}
+	err := repos.Developers.Create(c.UserContext(), &developer)
+	if err == repository.ErrNotFound {
+		return c.Status(403).JSON(fiber.Map{
+			"error":   true,
+			"message": "Você só pode adicionar desenvolvedores aos seus times",
+		})
+	}
 	if err != nil {
 		log.Printf("Error creating developer: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -288,6 +239,7 @@ func CreateDeveloper(c *fiber.Ctx) error {
 
 // UpdateDeveloper atualiza um desenvolvedor existente
 func UpdateDeveloper(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
 	id := c.Params("id")
 	developerUUID, err := uuid.Parse(id)
 	if err != nil {
@@ -305,21 +257,9 @@ func UpdateDeveloper(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verificar se o desenvolvedor existe
-	var exists bool
-	err = database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM developers WHERE id = $1)", developerUUID).Scan(&exists)
-	if err != nil || !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error":   true,
-			"message": "Desenvolvedor não encontrado",
-		})
-	}
-
-	// Verificar se o team_id existe (se fornecido)
+	// Verificar se o team_id existe e está no alcance do usuário (se fornecido)
 	if req.TeamID != nil {
-		var teamExists bool
-		err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM teams WHERE id = $1)", *req.TeamID).Scan(&teamExists)
-		if err != nil || !teamExists {
+		if _, err := repos.Teams.GetAccessible(c.UserContext(), *req.TeamID); err != nil {
 			return c.Status(400).JSON(fiber.Map{
 				"error":   true,
 				"message": "Time não encontrado",
@@ -327,67 +267,117 @@ func UpdateDeveloper(c *fiber.Ctx) error {
 		}
 	}
 
-	// Construir query dinâmica
-	setParts := []string{}
-	args := []interface{}{}
-	argIndex := 1
-
-	if req.Name != nil {
-		setParts = append(setParts, fmt.Sprintf("name = $%d", argIndex))
-		args = append(args, *req.Name)
-		argIndex++
+	developer, err := repos.Developers.Update(c.UserContext(), developerUUID, repository.DeveloperUpdate{
+		Name:   req.Name,
+		Role:   req.Role,
+		TeamID: req.TeamID,
+	})
+	if err == repository.ErrNoChanges {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Nenhum campo para atualizar",
+		})
 	}
-	if req.Role != nil {
-		setParts = append(setParts, fmt.Sprintf("role = $%d", argIndex))
-		args = append(args, *req.Role)
-		argIndex++
+	// Desenvolvedores fora do alcance do usuário (empresa e times) são tratados como inexistentes
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Desenvolvedor não encontrado",
+		})
 	}
-	if req.LatestPerformanceScore != nil {
-		setParts = append(setParts, fmt.Sprintf("latest_performance_score = $%d", argIndex))
-		args = append(args, *req.LatestPerformanceScore)
-		argIndex++
+	if err != nil {
+		log.Printf("Error updating developer: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao atualizar desenvolvedor",
+		})
 	}
-	if req.TeamID != nil {
-		setParts = append(setParts, fmt.Sprintf("team_id = $%d", argIndex))
-		args = append(args, *req.TeamID)
-		argIndex++
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    developer,
+	})
+}
+
+// LinkDeveloperUser vincula o desenvolvedor a uma conta de usuário da mesma empresa,
+// permitindo que ele acesse os próprios relatórios. userId nulo remove o vínculo.
+func LinkDeveloperUser(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
+	developerUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
 	}
 
-	if len(setParts) == 0 {
+	var req models.LinkDeveloperUserRequest
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error":   true,
-			"message": "Nenhum campo para atualizar",
+			"message": "Dados inválidos",
 		})
 	}
 
-	query := "UPDATE developers SET "
-	for i, part := range setParts {
-		if i > 0 {
-			query += ", "
-		}
-		query += part
+	existing, err := repos.Developers.Get(c.UserContext(), developerUUID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Desenvolvedor não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying developer: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar desenvolvedor",
+		})
 	}
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, role, latest_performance_score, team_id, archived_at, created_at, updated_at", argIndex)
 
-	args = append(args, developerUUID)
+	if req.UserID != nil {
+		linkedUser, err := repos.Users.Get(c.UserContext(), *req.UserID)
+		if err == repository.ErrNotFound {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Usuário não encontrado",
+			})
+		}
+		if err != nil {
+			log.Printf("Error querying user: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao verificar usuário",
+			})
+		}
+		if linkedUser.CompanyID == nil || existing.CompanyID == nil || *linkedUser.CompanyID != *existing.CompanyID {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Usuário não pertence à empresa do desenvolvedor",
+			})
+		}
 
-	var developer models.Developer
-	err = database.DB.QueryRow(query, args...).Scan(
-		&developer.ID,
-		&developer.Name,
-		&developer.Role,
-		&developer.LatestPerformanceScore,
-		&developer.TeamID,
-		&developer.ArchivedAt,
-		&developer.CreatedAt,
-		&developer.UpdatedAt,
-	)
+		linked, err := repos.Developers.IsUserLinked(c.UserContext(), *req.UserID, developerUUID)
+		if err != nil {
+			log.Printf("Error checking developer link: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao verificar vínculo do usuário",
+			})
+		}
+		if linked {
+			return c.Status(409).JSON(fiber.Map{
+				"error":   true,
+				"message": "Usuário já está vinculado a outro desenvolvedor",
+			})
+		}
+	}
 
+	developer, err := repos.Developers.SetUser(c.UserContext(), developerUUID, req.UserID)
 	if err != nil {
-		log.Printf("Error updating developer: %v", err)
+		log.Printf("Error linking developer user: %v", err)
 		return c.Status(500).JSON(fiber.Map{
 			"error":   true,
-			"message": "Erro ao atualizar desenvolvedor",
+			"message": "Erro ao vincular usuário ao desenvolvedor",
 		})
 	}
 
@@ -399,6 +389,7 @@ func UpdateDeveloper(c *fiber.Ctx) error {
 
 // ArchiveDeveloper arquiva ou restaura um desenvolvedor
 func ArchiveDeveloper(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
 	id := c.Params("id")
 	developerUUID, err := uuid.Parse(id)
 	if err != nil {
@@ -408,6 +399,14 @@ func ArchiveDeveloper(c *fiber.Ctx) error {
 		})
 	}
 
+	before, err := repos.Developers.Get(c.UserContext(), developerUUID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Desenvolvedor não encontrado",
+		})
+	}
+
 	var req models.ArchiveDeveloperRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -416,64 +415,22 @@ func ArchiveDeveloper(c *fiber.Ctx) error {
 		})
 	}
 
-	var query string
+	// Arquivar preenche archived_at; restaurar o limpa
 	var archivedAt *time.Time
-
 	if req.Archive {
-		// Arquivar desenvolvedor
 		now := time.Now()
 		archivedAt = &now
-		query = `
-			UPDATE developers 
-			SET archived_at = $1 
-			WHERE id = $2 
-			RETURNING id, name, role, latest_performance_score, team_id, archived_at, created_at, updated_at
-		`
-	} else {
-		// Restaurar desenvolvedor
-		query = `
-			UPDATE developers 
-			SET archived_at = NULL 
-			WHERE id = $1 
-			RETURNING id, name, role, latest_performance_score, team_id, archived_at, created_at, updated_at
-		`
 	}
 
-	var developer models.Developer
-	var scanErr error
-
-	if req.Archive {
-		scanErr = database.DB.QueryRow(query, archivedAt, developerUUID).Scan(
-			&developer.ID,
-			&developer.Name,
-			&developer.Role,
-			&developer.LatestPerformanceScore,
-			&developer.TeamID,
-			&developer.ArchivedAt,
-			&developer.CreatedAt,
-			&developer.UpdatedAt,
-		)
-	} else {
-		scanErr = database.DB.QueryRow(query, developerUUID).Scan(
-			&developer.ID,
-			&developer.Name,
-			&developer.Role,
-			&developer.LatestPerformanceScore,
-			&developer.TeamID,
-			&developer.ArchivedAt,
-			&developer.CreatedAt,
-			&developer.UpdatedAt,
-		)
-	}
-
-	if scanErr == sql.ErrNoRows {
+	developer, err := repos.Developers.SetArchived(c.UserContext(), developerUUID, archivedAt)
+	if err == repository.ErrNotFound {
 		return c.Status(404).JSON(fiber.Map{
 			"error":   true,
 			"message": "Desenvolvedor não encontrado",
 		})
 	}
-	if scanErr != nil {
-		log.Printf("Error archiving/restoring developer: %v", scanErr)
+	if err != nil {
+		log.Printf("Error archiving/restoring developer: %v", err)
 		return c.Status(500).JSON(fiber.Map{
 			"error":   true,
 			"message": "Erro ao arquivar/restaurar desenvolvedor",
@@ -481,10 +438,21 @@ func ArchiveDeveloper(c *fiber.Ctx) error {
 	}
 
 	action := "restaurado"
+	auditAction := models.AuditActionRestore
 	if req.Archive {
 		action = "arquivado"
+		auditAction = models.AuditActionArchive
 	}
 
+	audit.Annotate(c, audit.Entry{
+		Action:     auditAction,
+		EntityType: "developers",
+		EntityID:   developer.ID.String(),
+		CompanyID:  developer.CompanyID,
+		Before:     before,
+		After:      developer,
+	})
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": fmt.Sprintf("Desenvolvedor %s com sucesso", action),
@@ -494,6 +462,7 @@ func ArchiveDeveloper(c *fiber.Ctx) error {
 
 // GetDevelopersByTeam retorna desenvolvedores de um time específico
 func GetDevelopersByTeam(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
 	teamID := c.Params("teamId")
 	teamUUID, err := uuid.Parse(teamID)
 	if err != nil {
@@ -503,22 +472,17 @@ func GetDevelopersByTeam(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verificar se deve incluir arquivados
-	includeArchived := c.Query("includeArchived", "false")
-
-	query := `
-		SELECT id, name, role, latest_performance_score, team_id, archived_at, created_at, updated_at 
-		FROM developers 
-		WHERE team_id = $1
-	`
-
-	if includeArchived != "true" {
-		query += " AND archived_at IS NULL"
+	if _, err := repos.Teams.GetAccessible(c.UserContext(), teamUUID); err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Time não encontrado",
+		})
 	}
 
-	query += " ORDER BY created_at DESC"
-
-	rows, err := database.DB.Query(query, teamUUID)
+	developers, err := repos.Developers.List(c.UserContext(), repository.DeveloperFilter{
+		TeamID:          &teamUUID,
+		IncludeArchived: c.Query("includeArchived", "false") == "true",
+	}, repository.PageRequest{})
 	if err != nil {
 		log.Printf("Error querying developers by team: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -526,31 +490,10 @@ func GetDevelopersByTeam(c *fiber.Ctx) error {
 			"message": "Erro ao buscar desenvolvedores do time",
 		})
 	}
-	defer rows.Close()
-
-	var developers []models.Developer
-	for rows.Next() {
-		var developer models.Developer
-		err := rows.Scan(
-			&developer.ID,
-			&developer.Name,
-			&developer.Role,
-			&developer.LatestPerformanceScore,
-			&developer.TeamID,
-			&developer.ArchivedAt,
-			&developer.CreatedAt,
-			&developer.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning developer: %v", err)
-			continue
-		}
-		developers = append(developers, developer)
-	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data":    developers,
+		"data":    developers.Items,
 	})
 }
 
@@ -566,27 +509,12 @@ func DeleteDeveloper(c *fiber.Ctx) error {
 
 	// Obter usuário atual das claims do JWT
 	user := c.Locals("user").(*middleware.JWTClaims)
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
 
-	var existingDeveloper models.Developer
-	checkQuery := `
-		SELECT id, name, role, latest_performance_score, team_id, company_id, archived_at, created_at, updated_at 
-		FROM developers 
-		WHERE id = $1
-	`
-
-	err = database.DB.QueryRow(checkQuery, developerUUID).Scan(
-		&existingDeveloper.ID,
-		&existingDeveloper.Name,
-		&existingDeveloper.Role,
-		&existingDeveloper.LatestPerformanceScore,
-		&existingDeveloper.TeamID,
-		&existingDeveloper.CompanyID,
-		&existingDeveloper.ArchivedAt,
-		&existingDeveloper.CreatedAt,
-		&existingDeveloper.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
+	// Desenvolvedores de outra empresa ou fora dos times do usuário são tratados como inexistentes
+	existingDeveloper, err := repos.Developers.Get(ctx, developerUUID)
+	if err == repository.ErrNotFound {
 		return c.Status(404).JSON(fiber.Map{
 			"error":   true,
 			"message": "Desenvolvedor não encontrado",
@@ -600,31 +528,29 @@ func DeleteDeveloper(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verificar permissões de exclusão para managers
-	if user.Role != "admin" {
-		// Managers só podem excluir desenvolvedores da sua própria empresa
-		if user.CompanyID == nil || existingDeveloper.CompanyID == nil || *user.CompanyID != *existingDeveloper.CompanyID {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   true,
-				"message": "Sem permissão para excluir este desenvolvedor",
-			})
-		}
-	}
-
-	// Inicia uma transação para garantir consistência
-	tx, err := database.DB.Begin()
+	// Tudo abaixo roda na transação da requisição: uma resposta de erro desfaz a exclusão inteira
+	// Preserva o conteúdo dos relatórios no histórico de revisões antes de excluí-los
+	reports, err := repos.Reports.LockByDeveloper(ctx, developerUUID)
 	if err != nil {
-		log.Printf("Error starting transaction: %v", err)
+		log.Printf("Error loading performance reports: %v", err)
 		return c.Status(500).JSON(fiber.Map{
 			"error":   true,
-			"message": "Erro interno do servidor",
+			"message": "Erro ao excluir relatórios de performance",
 		})
 	}
-	defer tx.Rollback()
+
+	for i := range reports {
+		if err := repos.Reports.RecordRevision(ctx, reports[i].ID, existingDeveloper.CompanyID, "delete", user.UserID, &reports[i], nil); err != nil {
+			log.Printf("Error recording report revision: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao registrar histórico dos relatórios",
+			})
+		}
+	}
 
 	// Primeiro, exclui todos os relatórios de performance do desenvolvedor
-	_, err = tx.Exec("DELETE FROM performance_reports WHERE developer_id = $1", developerUUID)
-	if err != nil {
+	if err := repos.Reports.DeleteByDeveloper(ctx, developerUUID); err != nil {
 		log.Printf("Error deleting performance reports: %v", err)
 		return c.Status(500).JSON(fiber.Map{
 			"error":   true,
@@ -633,33 +559,32 @@ func DeleteDeveloper(c *fiber.Ctx) error {
 	}
 
 	// Agora exclui o desenvolvedor
-	result, err := tx.Exec("DELETE FROM developers WHERE id = $1", developerUUID)
-	if err != nil {
-		log.Printf("Error deleting developer: %v", err)
-		return c.Status(500).JSON(fiber.Map{
-			"error":   true,
-			"message": "Erro ao excluir desenvolvedor",
-		})
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	err = repos.Developers.Delete(ctx, developerUUID)
+	if err == repository.ErrNotFound {
 		return c.Status(404).JSON(fiber.Map{
 			"error":   true,
 			"message": "Desenvolvedor não encontrado",
 		})
 	}
-
-	// Confirma a transação
-	err = tx.Commit()
 	if err != nil {
-		log.Printf("Error committing transaction: %v", err)
+		log.Printf("Error deleting developer: %v", err)
 		return c.Status(500).JSON(fiber.Map{
 			"error":   true,
-			"message": "Erro ao confirmar exclusão",
+			"message": "Erro ao excluir desenvolvedor",
 		})
 	}
 
+	// A auditoria guarda também os relatórios excluídos junto com o desenvolvedor
+	audit.Annotate(c, audit.Entry{
+		EntityType: "developers",
+		EntityID:   existingDeveloper.ID.String(),
+		CompanyID:  existingDeveloper.CompanyID,
+		Before: fiber.Map{
+			"developer":      existingDeveloper,
+			"deletedReports": reports,
+		},
+	})
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Desenvolvedor excluído com sucesso",
@@ -0,0 +1,593 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// validateEvaluationCategories garante chaves únicas de categorias e perguntas dentro da versão
+func validateEvaluationCategories(categories []models.EvaluationCategoryRequest) error {
+	categoryKeys := make(map[string]bool)
+	questionKeys := make(map[string]bool)
+	for _, category := range categories {
+		if categoryKeys[category.Key] {
+			return errors.New("Chave de categoria duplicada: " + category.Key)
+		}
+		categoryKeys[category.Key] = true
+
+		for _, question := range category.Questions {
+			if questionKeys[question.Key] {
+				return errors.New("Chave de pergunta duplicada: " + question.Key)
+			}
+			questionKeys[question.Key] = true
+		}
+	}
+	return nil
+}
+
+// resolveScoreScale aplica a escala informada, herdando os limites ausentes da versão anterior
+func resolveScoreScale(scoreMin, scoreMax *float64, currentMin, currentMax float64) (float64, float64, error) {
+	min, max := currentMin, currentMax
+	if scoreMin != nil {
+		min = *scoreMin
+	}
+	if scoreMax != nil {
+		max = *scoreMax
+	}
+
+	if min < 0 || max > models.MaxScoreScale || max <= min {
+		return 0, 0, fmt.Errorf("Escala de notas inválida: use 0 <= mínimo < máximo <= %g", models.MaxScoreScale)
+	}
+	return min, max, nil
+}
+
+// canAccessCompanyTemplates verifica se o usuário pode gerenciar os templates da empresa
+func canAccessCompanyTemplates(user *middleware.JWTClaims, companyID uuid.UUID) bool {
+	if user.HasPermission(models.PermCompaniesAll) {
+		return true
+	}
+	return user.CompanyID != nil && *user.CompanyID == companyID
+}
+
+// GetActiveEvaluationTemplate retorna o template de avaliação em uso para novos relatórios da empresa
+func GetActiveEvaluationTemplate(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	companyID := user.CompanyID
+	if user.HasPermission(models.PermCompaniesAll) && c.Query("companyId") != "" {
+		parsed, err := uuid.Parse(c.Query("companyId"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "ID da empresa inválido",
+			})
+		}
+		companyID = &parsed
+	}
+
+	template, err := middleware.Repositories(c).Templates.Active(c.UserContext(), companyID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Nenhum template de avaliação ativo",
+		})
+	}
+	if err != nil {
+		log.Printf("Error loading active evaluation template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar template de avaliação",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    template,
+	})
+}
+
+// GetEvaluationTemplateVersion retorna uma versão específica de template (usada por relatórios históricos)
+func GetEvaluationTemplateVersion(c *fiber.Ctx) error {
+	versionUUID, err := uuid.Parse(c.Params("versionId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID da versão inválido",
+		})
+	}
+
+	// Templates globais são visíveis a todos; templates de empresa apenas à própria empresa
+	template, err := middleware.Repositories(c).Templates.Version(c.UserContext(), versionUUID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Versão de template não encontrada",
+		})
+	}
+	if err != nil {
+		log.Printf("Error loading evaluation template version: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar versão do template",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    template,
+	})
+}
+
+// CreateEvaluationTemplateVersion publica uma nova versão de um template global.
+// Versões anteriores permanecem intactas para que relatórios antigos continuem reproduzíveis.
+func CreateEvaluationTemplateVersion(c *fiber.Ctx) error {
+	templateUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID do template inválido",
+		})
+	}
+
+	var req models.CreateEvaluationTemplateVersionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if err := validateEvaluationCategories(req.Categories); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	templates := middleware.Repositories(c).Templates
+	current, err := templates.Latest(c.UserContext(), templateUUID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Template não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error loading evaluation template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar template",
+		})
+	}
+
+	scoreMin, scoreMax, err := resolveScoreScale(req.ScoreMin, req.ScoreMax, current.ScoreMin, current.ScoreMax)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	template, err := templates.Update(c.UserContext(), templateUUID, repository.TemplateUpdate{
+		Version: &repository.TemplateVersion{ScoreMin: scoreMin, ScoreMax: scoreMax, Categories: req.Categories},
+	})
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Template não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error creating evaluation template version: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao criar versão do template",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "evaluation-templates",
+		EntityID:   template.ID.String(),
+		CompanyID:  template.CompanyID,
+		Before:     current,
+		After:      template,
+	})
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"message": "Versão " + strconv.Itoa(template.Version) + " do template publicada com sucesso",
+		"data":    template,
+	})
+}
+
+// parseCompanyTemplateParams valida os parâmetros de rota e o acesso do usuário à empresa.
+// Quando ok é falso a resposta de erro já foi enviada.
+func parseCompanyTemplateParams(c *fiber.Ctx, withTemplate bool) (companyID, templateID uuid.UUID, ok bool) {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	companyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID da empresa inválido",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if !canAccessCompanyTemplates(user, companyID) {
+		c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Sem permissão para gerenciar templates desta empresa",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if !withTemplate {
+		return companyID, uuid.Nil, true
+	}
+
+	templateID, err = uuid.Parse(c.Params("templateId"))
+	if err != nil {
+		c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID do template inválido",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return companyID, templateID, true
+}
+
+// loadCompanyTemplate carrega a versão mais recente de um template garantindo que pertença à empresa
+func loadCompanyTemplate(c *fiber.Ctx, companyID, templateID uuid.UUID) (*models.EvaluationTemplate, error) {
+	template, err := middleware.Repositories(c).Templates.Latest(c.UserContext(), templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.CompanyID == nil || *template.CompanyID != companyID {
+		return nil, repository.ErrNotFound
+	}
+	return template, nil
+}
+
+// ListCompanyTemplates lista os templates de avaliação de uma empresa (versão mais recente de cada)
+func ListCompanyTemplates(c *fiber.Ctx) error {
+	companyID, _, ok := parseCompanyTemplateParams(c, false)
+	if !ok {
+		return nil
+	}
+
+	templates, err := middleware.Repositories(c).Templates.ListByCompany(c.UserContext(), companyID)
+	if err != nil {
+		log.Printf("Error querying company templates: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar templates da empresa",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    templates,
+	})
+}
+
+// GetCompanyTemplate retorna a versão mais recente de um template da empresa
+func GetCompanyTemplate(c *fiber.Ctx) error {
+	companyID, templateID, ok := parseCompanyTemplateParams(c, true)
+	if !ok {
+		return nil
+	}
+
+	template, err := loadCompanyTemplate(c, companyID, templateID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Template não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error loading company template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar template",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    template,
+	})
+}
+
+// CreateCompanyTemplate cria um template de avaliação para a empresa
+func CreateCompanyTemplate(c *fiber.Ctx) error {
+	companyID, _, ok := parseCompanyTemplateParams(c, false)
+	if !ok {
+		return nil
+	}
+
+	var req models.CreateEvaluationTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if err := validateEvaluationCategories(req.Categories); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	scoreMin, scoreMax, err := resolveScoreScale(req.ScoreMin, req.ScoreMax, models.DefaultScoreMin, models.DefaultScoreMax)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	if _, err := repos.Companies.Get(c.UserContext(), companyID); err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Empresa não encontrada",
+		})
+	}
+
+	template, err := repos.Templates.Create(c.UserContext(), &models.EvaluationTemplate{
+		Name:        req.Name,
+		Description: req.Description,
+		CompanyID:   &companyID,
+	}, repository.TemplateVersion{ScoreMin: scoreMin, ScoreMax: scoreMax, Categories: req.Categories}, req.Activate)
+	if err != nil {
+		log.Printf("Error creating company template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao criar template",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "evaluation-templates",
+		EntityID:   template.ID.String(),
+		CompanyID:  &companyID,
+		After:      template,
+	})
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"data":    template,
+	})
+}
+
+// UpdateCompanyTemplate atualiza um template da empresa. Mudanças de escala ou categorias
+// publicam uma nova versão; relatórios já criados continuam apontando para a versão anterior.
+func UpdateCompanyTemplate(c *fiber.Ctx) error {
+	companyID, templateID, ok := parseCompanyTemplateParams(c, true)
+	if !ok {
+		return nil
+	}
+
+	var req models.UpdateEvaluationTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if req.Name == nil && req.Description == nil && req.ScoreMin == nil && req.ScoreMax == nil && req.Categories == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Nenhum campo para atualizar",
+		})
+	}
+
+	current, err := loadCompanyTemplate(c, companyID, templateID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Template não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error loading company template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar template",
+		})
+	}
+
+	categories := req.Categories
+	if categories == nil {
+		categories = current.CategoryRequests()
+	}
+	if err := validateEvaluationCategories(categories); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	scoreMin, scoreMax, err := resolveScoreScale(req.ScoreMin, req.ScoreMax, current.ScoreMin, current.ScoreMax)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	update := repository.TemplateUpdate{Name: req.Name, Description: req.Description}
+	if req.Categories != nil || req.ScoreMin != nil || req.ScoreMax != nil {
+		update.Version = &repository.TemplateVersion{ScoreMin: scoreMin, ScoreMax: scoreMax, Categories: categories}
+	}
+
+	template, err := middleware.Repositories(c).Templates.Update(c.UserContext(), templateID, update)
+	if err != nil {
+		log.Printf("Error updating company template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao atualizar template",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "evaluation-templates",
+		EntityID:   templateID.String(),
+		CompanyID:  &companyID,
+		Before:     current,
+		After:      template,
+	})
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    template,
+	})
+}
+
+// ActivateCompanyTemplate define o template usado nos novos relatórios da empresa
+func ActivateCompanyTemplate(c *fiber.Ctx) error {
+	companyID, templateID, ok := parseCompanyTemplateParams(c, true)
+	if !ok {
+		return nil
+	}
+
+	template, err := loadCompanyTemplate(c, companyID, templateID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Template não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error loading company template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar template",
+		})
+	}
+
+	if err := middleware.Repositories(c).Templates.Activate(c.UserContext(), companyID, templateID); err != nil {
+		log.Printf("Error activating company template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao ativar template",
+		})
+	}
+
+	activated := *template
+	activated.IsActive = true
+	audit.Annotate(c, audit.Entry{
+		Action:     models.AuditActionUpdate,
+		EntityType: "evaluation-templates",
+		EntityID:   templateID.String(),
+		CompanyID:  &companyID,
+		Before:     template,
+		After:      activated,
+	})
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Template ativado com sucesso",
+	})
+}
+
+// DeleteCompanyTemplate exclui um template da empresa que ainda não foi usado em relatórios,
+// ciclos, autoavaliações ou rodadas de feedback
+func DeleteCompanyTemplate(c *fiber.Ctx) error {
+	companyID, templateID, ok := parseCompanyTemplateParams(c, true)
+	if !ok {
+		return nil
+	}
+
+	template, err := loadCompanyTemplate(c, companyID, templateID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Template não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error loading company template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar template",
+		})
+	}
+
+	// Relatórios, ciclos, autoavaliações e rodadas de feedback referenciam versões do template;
+	// excluí-lo quebraria a reprodutibilidade
+	templates := middleware.Repositories(c).Templates
+	inUse, err := templates.InUse(c.UserContext(), templateID)
+	if err != nil {
+		log.Printf("Error checking template usage: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao verificar uso do template",
+		})
+	}
+	if inUse {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Template já utilizado em relatórios, ciclos, autoavaliações ou rodadas de feedback; ative outro template em vez de excluí-lo",
+		})
+	}
+
+	if err := templates.Delete(c.UserContext(), templateID); err != nil {
+		log.Printf("Error deleting company template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao excluir template",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "evaluation-templates",
+		EntityID:   templateID.String(),
+		CompanyID:  &companyID,
+		Before:     template,
+	})
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Template excluído com sucesso",
+	})
+}
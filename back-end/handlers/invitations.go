@@ -0,0 +1,460 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/mail"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// requirePendingInvitation carrega o convite do parâmetro :id e exige que ainda esteja pendente;
+// quando ok é false a resposta de erro já foi enviada
+func requirePendingInvitation(c *fiber.Ctx) (*models.UserInvitation, bool) {
+	invitationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID do convite inválido",
+		})
+		return nil, false
+	}
+
+	invitation, err := middleware.Repositories(c).Invitations.Get(c.UserContext(), invitationID)
+	if err == repository.ErrNotFound {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Convite não encontrado",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying invitation: %v", err)
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar convite",
+		})
+		return nil, false
+	}
+
+	if invitation.Status != models.InvitationPending {
+		c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "O convite não está mais pendente",
+		})
+		return nil, false
+	}
+	return invitation, true
+}
+
+// sendInvitationEmail envia o link do convite; falhas são devolvidas para que o chamador
+// possa avisar que o convite precisa ser reenviado
+func sendInvitationEmail(c *fiber.Ctx, invitation *models.UserInvitation, token string) error {
+	companyName, inviterName, err := middleware.Repositories(c).Invitations.EmailNames(c.UserContext(), invitation)
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(mail.InvitationMessage(
+		invitation.Email,
+		invitation.Name,
+		companyName,
+		inviterName,
+		mail.Link("/accept-invitation", token),
+		models.InvitationTTL,
+	))
+}
+
+// CreateInvitation cria o usuário pendente e envia o convite por email
+func CreateInvitation(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	var req models.CreateInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if !user.CanGrantRole(req.Role) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Você não pode conceder um papel com permissões que não possui",
+		})
+	}
+
+	// Mesmas regras de empresa do CreateUser; o papel já foi limitado por CanGrantRole
+	var companyID uuid.UUID
+	if user.HasPermission(models.PermCompaniesAll) {
+		if req.CompanyID == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Admin deve especificar uma empresa para o usuário",
+			})
+		}
+		companyID = *req.CompanyID
+	} else {
+		if user.CompanyID == nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Manager deve estar associado a uma empresa",
+			})
+		}
+		companyID = *user.CompanyID
+	}
+
+	repos := middleware.Repositories(c)
+
+	companyActive, err := repos.Companies.IsActive(c.UserContext(), companyID)
+	if err != nil || !companyActive {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Empresa não encontrada ou inativa",
+		})
+	}
+
+	emailInUse, err := repos.Accounts.EmailExists(c.UserContext(), req.Email)
+	if err != nil {
+		log.Printf("Error querying user: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro interno do servidor",
+		})
+	}
+	if emailInUse {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Email já está em uso",
+		})
+	}
+
+	// O usuário pendente recebe uma senha aleatória que ninguém conhece até aceitar o convite
+	placeholderPassword, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Error generating placeholder password: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao criar convite",
+		})
+	}
+	pendingUser := models.User{Email: req.Email}
+	if err := pendingUser.HashPassword(placeholderPassword); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao processar senha",
+		})
+	}
+
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Error generating invitation token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao criar convite",
+		})
+	}
+
+	invitation := models.UserInvitation{
+		CompanyID: companyID,
+		Email:     req.Email,
+		Name:      req.Name,
+		Role:      req.Role,
+		InvitedBy: &user.UserID,
+	}
+	if err := repos.Invitations.Create(c.UserContext(), &invitation, &pendingUser, tokenHash); err != nil {
+		log.Printf("Error creating invitation: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao criar convite",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "invitations",
+		EntityID:   invitation.ID.String(),
+		CompanyID:  &invitation.CompanyID,
+		After:      invitation,
+	})
+
+	message := "Convite enviado com sucesso"
+	emailSent := true
+	if err := sendInvitationEmail(c, &invitation, token); err != nil {
+		log.Printf("Error sending invitation email: %v", err)
+		message = "Convite criado, mas o email não pôde ser enviado. Tente reenviar"
+		emailSent = false
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":    "success",
+		"message":   message,
+		"emailSent": emailSent,
+		"data":      invitation,
+	})
+}
+
+// ListInvitations lista os convites da empresa, opcionalmente filtrados por ?status=
+func ListInvitations(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	filter := repository.InvitationFilter{}
+	if user.HasPermission(models.PermCompaniesAll) {
+		if companyID := c.Query("companyId"); companyID != "" {
+			parsed, err := uuid.Parse(companyID)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"status":  "error",
+					"message": "ID da empresa inválido",
+				})
+			}
+			filter.CompanyID = &parsed
+		}
+	} else {
+		if user.CompanyID == nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Usuário deve estar associado a uma empresa",
+			})
+		}
+		filter.CompanyID = user.CompanyID
+	}
+
+	if status := c.Query("status"); status != "" {
+		if status != models.InvitationPending && status != models.InvitationAccepted && status != models.InvitationRevoked {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Status inválido. Use pending, accepted ou revoked",
+			})
+		}
+		filter.Status = status
+	}
+
+	invitations, err := middleware.Repositories(c).Invitations.List(c.UserContext(), filter)
+	if err != nil {
+		log.Printf("Error querying invitations: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar convites",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   invitations,
+	})
+}
+
+// ResendInvitation gera um novo link (o anterior deixa de valer) e renova a validade do convite
+func ResendInvitation(c *fiber.Ctx) error {
+	invitation, ok := requirePendingInvitation(c)
+	if !ok {
+		return nil
+	}
+
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Error generating invitation token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao reenviar convite",
+		})
+	}
+
+	before := *invitation
+	err = middleware.Repositories(c).Invitations.Renew(c.UserContext(), invitation, tokenHash)
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "O convite não está mais pendente",
+		})
+	}
+	if err != nil {
+		log.Printf("Error updating invitation: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao reenviar convite",
+		})
+	}
+
+	// O link anterior deixou de valer mesmo que o email falhe
+	audit.Annotate(c, audit.Entry{
+		Action:     models.AuditActionUpdate,
+		EntityType: "invitations",
+		EntityID:   invitation.ID.String(),
+		CompanyID:  &invitation.CompanyID,
+		Before:     before,
+		After:      invitation,
+	})
+
+	if err := sendInvitationEmail(c, invitation, token); err != nil {
+		log.Printf("Error sending invitation email: %v", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Não foi possível enviar o email do convite",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Convite reenviado com sucesso",
+		"data":    invitation,
+	})
+}
+
+// RevokeInvitation cancela um convite pendente e remove o usuário que aguardava a aceitação
+func RevokeInvitation(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	invitation, ok := requirePendingInvitation(c)
+	if !ok {
+		return nil
+	}
+
+	before := *invitation
+	err := middleware.Repositories(c).Invitations.Revoke(c.UserContext(), invitation, user.UserID)
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "O convite não está mais pendente",
+		})
+	}
+	if err != nil {
+		log.Printf("Error revoking invitation: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao revogar convite",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "invitations",
+		EntityID:   invitation.ID.String(),
+		CompanyID:  &invitation.CompanyID,
+		Before:     before,
+		After:      invitation,
+	})
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Convite revogado com sucesso",
+	})
+}
+
+// GetInvitationByToken mostra ao convidado os dados do convite antes de aceitá-lo (rota pública)
+func GetInvitationByToken(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Token do convite não informado",
+		})
+	}
+
+	preview, err := middleware.Repositories(c).Invitations.Preview(c.UserContext(), utils.HashToken(token))
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Convite inválido ou expirado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying invitation: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar convite",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   preview,
+	})
+}
+
+// AcceptInvitation define a senha escolhida pelo convidado, ativa a conta e já abre a sessão (rota pública)
+func AcceptInvitation(c *fiber.Ctx) error {
+	var req models.AcceptInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	// A senha é processada antes de consumir o convite; o usuário pendente só é conhecido depois
+	hashed := models.User{}
+	if err := hashed.HashPassword(req.Password); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao processar senha",
+		})
+	}
+
+	user, err := middleware.Repositories(c).Invitations.Accept(c.UserContext(), utils.HashToken(req.Token), hashed.Password)
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Convite inválido ou expirado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error accepting invitation: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao aceitar convite",
+		})
+	}
+
+	// Rota pública: sem a anotação, o aceite não entraria na auditoria
+	audit.Annotate(c, audit.Entry{
+		Action:     models.AuditActionUpdate,
+		EntityType: "users",
+		EntityID:   user.ID.String(),
+		CompanyID:  user.CompanyID,
+		Before:     fiber.Map{"isActive": false, "invitation": "pending"},
+		After:      fiber.Map{"isActive": user.IsActive, "invitation": "accepted"},
+	})
+
+	session, err := startSession(c, *user)
+	if err != nil {
+		log.Printf("Error starting session: %v", err)
+		return c.JSON(fiber.Map{
+			"status":  "success",
+			"message": "Convite aceito. Faça login com a nova senha",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Convite aceito com sucesso",
+		"data":    session,
+	})
+}
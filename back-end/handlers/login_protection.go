@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// throttleKey normaliza o email usado como chave dos contadores de falha
+func throttleKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// securityEvent monta o evento de segurança da autenticação, gravado pelos repositórios junto
+// com a alteração que ele registra
+func securityEvent(c *fiber.Ctx, eventType string, userID, companyID *uuid.UUID, email string, createdBy *uuid.UUID, details models.JSONB) *models.SecurityEvent {
+	ipAddress := c.IP()
+	return &models.SecurityEvent{
+		EventType: eventType,
+		UserID:    userID,
+		CompanyID: companyID,
+		Email:     &email,
+		IPAddress: &ipAddress,
+		Details:   details,
+		CreatedBy: createdBy,
+	}
+}
+
+// checkLoginThrottle indica se o email está bloqueado ou se ainda precisa esperar o atraso
+// progressivo desde a última falha; retryAfter é o tempo restante em ambos os casos
+func checkLoginThrottle(c *fiber.Ctx, email string) (retryAfter time.Duration, locked bool, err error) {
+	throttle, err := middleware.Repositories(c).LoginThrottles.Get(c.UserContext(), throttleKey(email))
+	if err == repository.ErrNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	if throttle.LockedFor > 0 {
+		return throttle.LockedFor, true, nil
+	}
+
+	delay := models.LoginDelay(throttle.FailedAttempts)
+	if delay > 0 && throttle.SinceLastFailure < delay {
+		return delay - throttle.SinceLastFailure, false, nil
+	}
+	return 0, false, nil
+}
+
+// recordLoginFailure incrementa o contador de falhas do email e bloqueia a conta ao atingir
+// models.LoginLockoutAfter; o contador recomeça após o bloqueio
+func recordLoginFailure(c *fiber.Ctx, email string, user *models.User) error {
+	var userID, companyID *uuid.UUID
+	if user != nil {
+		userID, companyID = &user.ID, user.CompanyID
+	}
+	lockEvent := securityEvent(c, models.SecurityEventAccountLocked, userID, companyID, throttleKey(email), nil, models.JSONB{
+		"lockoutMinutes": models.LoginLockoutDuration.Minutes(),
+		"userAgent":      c.Get(fiber.HeaderUserAgent),
+		"knownEmail":     user != nil,
+	})
+
+	failedAttempts, locked, err := middleware.Repositories(c).LoginThrottles.RecordFailure(c.UserContext(), throttleKey(email), lockEvent)
+	if err != nil {
+		return err
+	}
+	if locked {
+		log.Printf("Account locked after %d failed login attempts: %s", failedAttempts, throttleKey(email))
+	}
+	return nil
+}
+
+// clearLoginFailures zera o contador após um login bem-sucedido
+func clearLoginFailures(c *fiber.Ctx, email string) error {
+	return middleware.Repositories(c).LoginThrottles.Clear(c.UserContext(), throttleKey(email), nil)
+}
+
+// loginThrottledResponse responde 423 para contas bloqueadas e 429 durante o atraso progressivo
+func loginThrottledResponse(c *fiber.Ctx, retryAfter time.Duration, locked bool) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+
+	if locked {
+		return c.Status(fiber.StatusLocked).JSON(fiber.Map{
+			"error":      true,
+			"message":    "Conta bloqueada temporariamente por excesso de tentativas. Tente novamente mais tarde",
+			"retryAfter": seconds,
+		})
+	}
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error":      true,
+		"message":    "Muitas tentativas de login. Aguarde antes de tentar novamente",
+		"retryAfter": seconds,
+	})
+}
+
+// UnlockUser remove o bloqueio e o contador de falhas de login de um usuário (admin)
+func UnlockUser(c *fiber.Ctx) error {
+	currentUser := c.Locals("user").(*middleware.JWTClaims)
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID do usuário inválido",
+		})
+	}
+
+	user, ok := scopedUser(c, userID)
+	if !ok {
+		return nil
+	}
+
+	event := securityEvent(c, models.SecurityEventAccountUnlocked, &user.ID, user.CompanyID, throttleKey(user.Email), &currentUser.UserID, nil)
+	if err := middleware.Repositories(c).LoginThrottles.Clear(c.UserContext(), throttleKey(user.Email), event); err != nil {
+		log.Printf("Error clearing login throttle: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao desbloquear usuário",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Usuário desbloqueado com sucesso",
+	})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// Quantidade padrão e máxima de meses retornados em /me/trend
+const (
+	defaultTrendMonths = 12
+	maxTrendMonths     = 60
+)
+
+// requireLinkedDeveloper carrega o desenvolvedor do usuário logado; quando ok é false
+// a resposta de erro já foi enviada
+func requireLinkedDeveloper(c *fiber.Ctx) (*models.Developer, bool) {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	developer, err := middleware.Repositories(c).Developers.GetByUser(c.UserContext(), user.UserID)
+	if err == repository.ErrNotFound {
+		c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Nenhum desenvolvedor vinculado a este usuário",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying linked developer: %v", err)
+		c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar desenvolvedor",
+		})
+		return nil, false
+	}
+	return developer, true
+}
+
+// GetMyReports retorna os relatórios enviados do desenvolvedor logado (rascunhos não aparecem)
+func GetMyReports(c *fiber.Ctx) error {
+	developer, ok := requireLinkedDeveloper(c)
+	if !ok {
+		return nil
+	}
+
+	repos := middleware.Repositories(c)
+	reports, err := repos.Reports.ListPublished(c.UserContext(), developer.ID)
+	if err != nil {
+		log.Printf("Error querying my performance reports: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar relatórios",
+		})
+	}
+
+	for i := range reports {
+		reports[i].PeerFeedback, err = repos.FeedbackRounds.ForReport(c.UserContext(), &reports[i])
+		if err != nil {
+			log.Printf("Error loading peer feedback: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao buscar feedback dos pares",
+			})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    reports,
+	})
+}
+
+// GetMyTrend retorna a evolução mensal da pontuação do desenvolvedor logado,
+// em ordem cronológica (?months=N limita aos N meses mais recentes)
+func GetMyTrend(c *fiber.Ctx) error {
+	months := defaultTrendMonths
+	if value := c.Query("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxTrendMonths {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Parâmetro months deve estar entre 1 e " + strconv.Itoa(maxTrendMonths),
+			})
+		}
+		months = parsed
+	}
+
+	developer, ok := requireLinkedDeveloper(c)
+	if !ok {
+		return nil
+	}
+
+	points, err := middleware.Repositories(c).Reports.Trend(c.UserContext(), developer.ID, months)
+	if err != nil {
+		log.Printf("Error querying my performance trend: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar evolução de performance",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"developerId":            developer.ID,
+			"latestPerformanceScore": developer.LatestPerformanceScore,
+			"points":                 points,
+		},
+	})
+}
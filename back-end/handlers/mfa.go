@@ -0,0 +1,674 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// userHasMFA indica se o usuário concluiu o cadastro do 2FA
+func userHasMFA(c *fiber.Ctx, userID uuid.UUID) (bool, error) {
+	return middleware.Repositories(c).MFA.Enabled(c.UserContext(), userID)
+}
+
+// companyRequiresMFA indica se a política da empresa exige 2FA para admins e gerentes
+func companyRequiresMFA(c *fiber.Ctx, companyID *uuid.UUID) (bool, error) {
+	if companyID == nil {
+		return false, nil
+	}
+
+	policy, err := middleware.Repositories(c).SecurityPolicies.Get(c.UserContext(), *companyID)
+	if err == repository.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return policy.RequireMFA, nil
+}
+
+// newRecoveryCodes gera um novo conjunto de códigos de recuperação. Os códigos em texto só
+// são devolvidos ao usuário nesta chamada; o banco guarda apenas os hashes.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	codes, err = utils.GenerateRecoveryCodes(models.MFARecoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, code := range codes {
+		hashes = append(hashes, utils.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// verifySecondFactor confere o código TOTP ou, na falta dele, um código de recuperação
+// (que é consumido). usedRecoveryCode indica qual dos dois foi aceito.
+func verifySecondFactor(c *fiber.Ctx, userID uuid.UUID, code, recoveryCode string) (ok, usedRecoveryCode bool, err error) {
+	mfa := middleware.Repositories(c).MFA
+
+	if code != "" {
+		ok, err := mfa.VerifyCode(c.UserContext(), userID, code)
+		return ok, false, err
+	}
+
+	if recoveryCode != "" {
+		ok, err := mfa.UseRecoveryCode(c.UserContext(), userID, utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode)))
+		return ok, ok, err
+	}
+
+	return false, false, nil
+}
+
+// createMFAChallenge emite o token de curta duração trocado pelo JWT em VerifyMFAChallenge
+func createMFAChallenge(c *fiber.Ctx, userID uuid.UUID) (string, error) {
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := middleware.Repositories(c).MFA.CreateChallenge(c.UserContext(), userID, hash); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// respondMFAChallenge abre o desafio de 2FA e responde com o token que o usuário troca pelo
+// JWT em /auth/mfa/verify, junto com o código do aplicativo autenticador
+func respondMFAChallenge(c *fiber.Ctx, userID uuid.UUID) error {
+	challengeToken, err := createMFAChallenge(c, userID)
+	if err != nil {
+		log.Printf("Error creating MFA challenge: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Informe o código de verificação do aplicativo autenticador",
+		"data": models.MFAChallengeResponse{
+			MFARequired:    true,
+			ChallengeToken: challengeToken,
+			ExpiresIn:      int(models.MFAChallengeTTL.Seconds()),
+		},
+	})
+}
+
+// scopedCompany confere se a empresa existe e está no escopo de quem administra.
+// Quando ok é false a resposta de erro já foi enviada.
+func scopedCompany(c *fiber.Ctx, companyID uuid.UUID) (*models.Company, bool) {
+	company, err := middleware.Repositories(c).Companies.Get(c.UserContext(), companyID)
+	if err == repository.ErrNotFound {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Empresa não encontrada",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying company: %v", err)
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar empresa",
+		})
+		return nil, false
+	}
+	return company, true
+}
+
+// GetMFAStatus informa se o usuário logado tem 2FA ativo e se a empresa o exige
+func GetMFAStatus(c *fiber.Ctx) error {
+	userClaims := c.Locals("user").(*middleware.JWTClaims)
+
+	repos := middleware.Repositories(c)
+
+	status := models.MFAStatus{}
+	mfa, err := repos.MFA.Get(c.UserContext(), userClaims.UserID)
+	if err != nil && err != repository.ErrNotFound {
+		log.Printf("Error querying MFA: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar configuração de 2FA",
+		})
+	}
+	if mfa != nil && mfa.EnabledAt != nil {
+		status.Enabled = true
+		status.EnabledAt = mfa.EnabledAt
+
+		status.RecoveryCodesRemaining, err = repos.MFA.RecoveryCodesRemaining(c.UserContext(), userClaims.UserID)
+		if err != nil {
+			log.Printf("Error counting recovery codes: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Erro ao buscar configuração de 2FA",
+			})
+		}
+	}
+
+	required, err := companyRequiresMFA(c, userClaims.CompanyID)
+	if err != nil {
+		log.Printf("Error querying security policy: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar configuração de 2FA",
+		})
+	}
+	status.Required = required && models.RoleRequiresMFA(userClaims.Role)
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   status,
+	})
+}
+
+// EnrollMFA gera um novo segredo TOTP pendente de confirmação e a URI para o QR code
+func EnrollMFA(c *fiber.Ctx) error {
+	userClaims := c.Locals("user").(*middleware.JWTClaims)
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		log.Printf("Error generating TOTP secret: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao iniciar cadastro do 2FA",
+		})
+	}
+
+	err = middleware.Repositories(c).MFA.Enroll(c.UserContext(), userClaims.UserID, secret)
+	if err == repository.ErrMFAEnabled {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "A autenticação em duas etapas já está ativa",
+		})
+	}
+	if err != nil {
+		log.Printf("Error saving TOTP secret: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao iniciar cadastro do 2FA",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Escaneie o QR code no aplicativo autenticador e confirme com um código",
+		"data": models.MFAEnrollment{
+			Secret:          secret,
+			ProvisioningURI: utils.TOTPProvisioningURI(models.MFAIssuer, userClaims.Email, secret),
+		},
+	})
+}
+
+// EnableMFA confirma o cadastro com o primeiro código do aplicativo e devolve os códigos de recuperação
+func EnableMFA(c *fiber.Ctx) error {
+	userClaims := c.Locals("user").(*middleware.JWTClaims)
+
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		log.Printf("Error creating recovery codes: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao ativar 2FA",
+		})
+	}
+
+	event := securityEvent(c, models.SecurityEventMFAEnabled, &userClaims.UserID, userClaims.CompanyID, userClaims.Email, &userClaims.UserID, nil)
+	err = middleware.Repositories(c).MFA.Enable(c.UserContext(), userClaims.UserID, req.Code, hashes, event)
+	switch err {
+	case nil:
+	case repository.ErrNotFound:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Inicie o cadastro do 2FA antes de confirmá-lo",
+		})
+	case repository.ErrMFAEnabled:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "A autenticação em duas etapas já está ativa",
+		})
+	case repository.ErrInvalidCode:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Código de verificação inválido",
+		})
+	default:
+		log.Printf("Error enabling MFA: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao ativar 2FA",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Autenticação em duas etapas ativada. Guarde os códigos de recuperação em local seguro",
+		"data": fiber.Map{
+			"recoveryCodes": codes,
+		},
+	})
+}
+
+// DisableMFA desativa o 2FA do usuário logado, exigindo a senha e um segundo fator
+func DisableMFA(c *fiber.Ctx) error {
+	userClaims := c.Locals("user").(*middleware.JWTClaims)
+
+	var req models.DisableMFARequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	required, err := companyRequiresMFA(c, userClaims.CompanyID)
+	if err != nil {
+		log.Printf("Error querying security policy: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao desativar 2FA",
+		})
+	}
+	if required && models.RoleRequiresMFA(userClaims.Role) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Sua empresa exige autenticação em duas etapas para o seu papel",
+		})
+	}
+
+	repos := middleware.Repositories(c)
+
+	user, err := repos.Accounts.Get(c.UserContext(), userClaims.UserID)
+	if err != nil {
+		log.Printf("Error querying user: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao desativar 2FA",
+		})
+	}
+	if err := user.CheckPassword(req.Password); err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Senha incorreta",
+		})
+	}
+
+	enabled, err := userHasMFA(c, userClaims.UserID)
+	if err != nil {
+		log.Printf("Error querying MFA: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao desativar 2FA",
+		})
+	}
+	if !enabled {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "A autenticação em duas etapas não está ativa",
+		})
+	}
+
+	ok, _, err := verifySecondFactor(c, userClaims.UserID, req.Code, req.RecoveryCode)
+	if err != nil {
+		log.Printf("Error verifying second factor: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao desativar 2FA",
+		})
+	}
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Código de verificação inválido",
+		})
+	}
+
+	event := securityEvent(c, models.SecurityEventMFADisabled, &userClaims.UserID, userClaims.CompanyID, userClaims.Email, &userClaims.UserID, nil)
+	if err := repos.MFA.Remove(c.UserContext(), userClaims.UserID, event); err != nil {
+		log.Printf("Error disabling MFA: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao desativar 2FA",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Autenticação em duas etapas desativada",
+	})
+}
+
+// RegenerateRecoveryCodes invalida os códigos de recuperação atuais e gera novos
+func RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userClaims := c.Locals("user").(*middleware.JWTClaims)
+
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	repos := middleware.Repositories(c)
+
+	enabled, err := userHasMFA(c, userClaims.UserID)
+	if err != nil {
+		log.Printf("Error querying MFA: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao gerar códigos de recuperação",
+		})
+	}
+	if !enabled {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "A autenticação em duas etapas não está ativa",
+		})
+	}
+
+	ok, _, err := verifySecondFactor(c, userClaims.UserID, req.Code, "")
+	if err != nil {
+		log.Printf("Error verifying second factor: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao gerar códigos de recuperação",
+		})
+	}
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Código de verificação inválido",
+		})
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err == nil {
+		err = repos.MFA.ReplaceRecoveryCodes(c.UserContext(), userClaims.UserID, hashes)
+	}
+	if err != nil {
+		log.Printf("Error creating recovery codes: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao gerar códigos de recuperação",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Novos códigos de recuperação gerados; os anteriores deixaram de valer",
+		"data": fiber.Map{
+			"recoveryCodes": codes,
+		},
+	})
+}
+
+// VerifyMFAChallenge conclui o login em duas etapas: troca o token de desafio e um código
+// TOTP (ou de recuperação) pelo access token e refresh token
+func VerifyMFAChallenge(c *fiber.Ctx) error {
+	var req models.VerifyMFARequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Informe o código do aplicativo autenticador ou um código de recuperação",
+		})
+	}
+
+	repos := middleware.Repositories(c)
+
+	challenge, err := repos.MFA.Challenge(c.UserContext(), utils.HashToken(req.ChallengeToken))
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Desafio de verificação inválido ou expirado. Faça login novamente",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying MFA challenge: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+
+	found, err := repos.Accounts.Get(c.UserContext(), challenge.UserID)
+	if err != nil {
+		log.Printf("Error querying user: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+	user := *found
+	if !user.IsActive {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Usuário inativo",
+		})
+	}
+
+	// Códigos errados contam para o bloqueio da conta, como senhas erradas
+	retryAfter, locked, err := checkLoginThrottle(c, user.Email)
+	if err != nil {
+		log.Printf("Error checking login throttle: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+	if locked || retryAfter > 0 {
+		return loginThrottledResponse(c, retryAfter, locked)
+	}
+
+	ok, usedRecoveryCode, err := verifySecondFactor(c, user.ID, req.Code, req.RecoveryCode)
+	if err != nil {
+		log.Printf("Error verifying second factor: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+	if !ok {
+		if err := repos.MFA.FailChallenge(c.UserContext(), challenge.ID); err != nil {
+			log.Printf("Error updating MFA challenge: %v", err)
+		}
+		if err := recordLoginFailure(c, user.Email, &user); err != nil {
+			log.Printf("Error recording login failure: %v", err)
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Código de verificação inválido",
+		})
+	}
+
+	var event *models.SecurityEvent
+	if usedRecoveryCode {
+		event = securityEvent(c, models.SecurityEventMFARecoveryCodeUsed, &user.ID, user.CompanyID, user.Email, &user.ID, nil)
+	}
+	err = repos.MFA.CompleteChallenge(c.UserContext(), challenge.ID, event)
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Desafio de verificação inválido ou expirado. Faça login novamente",
+		})
+	}
+	if err != nil {
+		log.Printf("Error updating MFA challenge: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+
+	if err := clearLoginFailures(c, user.Email); err != nil {
+		log.Printf("Error clearing login failures: %v", err)
+	}
+
+	session, err := startSession(c, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao gerar token",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Login realizado com sucesso",
+		"data":    session,
+	})
+}
+
+// ResetUserMFA remove o 2FA de um usuário que perdeu o aplicativo e os códigos de recuperação (admin).
+// Se a empresa exigir 2FA, o usuário terá de cadastrá-lo novamente no próximo acesso.
+func ResetUserMFA(c *fiber.Ctx) error {
+	currentUser := c.Locals("user").(*middleware.JWTClaims)
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID do usuário inválido",
+		})
+	}
+
+	user, ok := scopedUser(c, userID)
+	if !ok {
+		return nil
+	}
+
+	event := securityEvent(c, models.SecurityEventMFAReset, &user.ID, user.CompanyID, user.Email, &currentUser.UserID, nil)
+	if err := middleware.Repositories(c).MFA.Remove(c.UserContext(), user.ID, event); err != nil {
+		log.Printf("Error resetting MFA: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao redefinir 2FA",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Autenticação em duas etapas do usuário redefinida",
+	})
+}
+
+// GetCompanySecurityPolicy retorna a política de segurança da empresa (admin)
+func GetCompanySecurityPolicy(c *fiber.Ctx) error {
+	companyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID da empresa inválido",
+		})
+	}
+
+	if _, ok := scopedCompany(c, companyID); !ok {
+		return nil
+	}
+
+	policy, err := middleware.Repositories(c).SecurityPolicies.Get(c.UserContext(), companyID)
+	if err != nil {
+		log.Printf("Error querying security policy: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar política de segurança",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   policy,
+	})
+}
+
+// UpdateCompanySecurityPolicy liga ou desliga o 2FA obrigatório para admins e gerentes da empresa (admin)
+func UpdateCompanySecurityPolicy(c *fiber.Ctx) error {
+	currentUser := c.Locals("user").(*middleware.JWTClaims)
+	companyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID da empresa inválido",
+		})
+	}
+
+	var req models.UpdateCompanySecurityPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if _, ok := scopedCompany(c, companyID); !ok {
+		return nil
+	}
+
+	policy := models.CompanySecurityPolicy{CompanyID: companyID, RequireMFA: *req.RequireMFA, UpdatedBy: &currentUser.UserID}
+	if err := middleware.Repositories(c).SecurityPolicies.Save(c.UserContext(), &policy); err != nil {
+		log.Printf("Error saving security policy: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao salvar política de segurança",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Política de segurança atualizada com sucesso",
+		"data":    policy,
+	})
+}
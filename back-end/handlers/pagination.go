@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// parsePage lê os parâmetros limit, cursor, sort e order de uma listagem. limit vai de 1 a
+// models.MaxPageSize; sort aceita apenas os campos da ordenação informada; order é asc ou desc
+// (sem sort, vale a ordenação padrão).
+func parsePage[T any](c *fiber.Ctx, sorting repository.Sorting[T]) (repository.PageRequest, error) {
+	page := repository.PageRequest{Limit: models.DefaultPageSize}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > models.MaxPageSize {
+			return page, fmt.Errorf("Valor inválido para limit (use de 1 a %d)", models.MaxPageSize)
+		}
+		page.Limit = limit
+	}
+
+	page.Sort, page.Desc = sorting.Default, sorting.DefaultDesc
+	if sort := c.Query("sort"); sort != "" {
+		if !sorting.Allows(sort) {
+			return page, errors.New("Campo de ordenação inválido: " + sort)
+		}
+		page.Sort, page.Desc = sort, false
+	}
+	switch c.Query("order") {
+	case "":
+	case "asc":
+		page.Desc = false
+	case "desc":
+		page.Desc = true
+	default:
+		return page, errors.New("Ordem inválida (use asc ou desc)")
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := repository.ParseCursor(value)
+		if err != nil {
+			return page, errors.New("Cursor inválido")
+		}
+		page.After = cursor
+		if err := sorting.CheckCursor(page); err != nil {
+			return page, errors.New("Cursor inválido para esta ordenação")
+		}
+	}
+	return page, nil
+}
+
+// pageResponse devolve os campos comuns das listagens paginadas: os itens em data, o total
+// de itens que atendem aos filtros e o cursor da próxima página (null na última)
+func pageResponse[T any](page *repository.Page[T]) fiber.Map {
+	items := page.Items
+	if items == nil {
+		items = []T{}
+	}
+
+	var nextCursor *string
+	if page.NextCursor != "" {
+		nextCursor = &page.NextCursor
+	}
+	return fiber.Map{
+		"data":       items,
+		"total":      page.Total,
+		"nextCursor": nextCursor,
+	}
+}
+
+// parseScoreRange lê os filtros minScore e maxScore
+func parseScoreRange(c *fiber.Ctx) (*float64, *float64, error) {
+	var bounds [2]*float64
+	for i, name := range []string{"minScore", "maxScore"} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		score, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, nil, errors.New("Valor inválido para " + name)
+		}
+		bounds[i] = &score
+	}
+	if bounds[0] != nil && bounds[1] != nil && *bounds[0] > *bounds[1] {
+		return nil, nil, errors.New("minScore não pode ser maior que maxScore")
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// parseUUIDFilter lê um filtro de id opcional
+func parseUUIDFilter(c *fiber.Ctx, name string) (*uuid.UUID, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, errors.New("Valor inválido para " + name)
+	}
+	return &id, nil
+}
+
+// parseBoolFilter lê um filtro true/false opcional
+func parseBoolFilter(c *fiber.Ctx, name string) (*bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, errors.New("Valor inválido para " + name + " (use true ou false)")
+	}
+	return &parsed, nil
+}
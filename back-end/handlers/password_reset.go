@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/mail"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// passwordResetsPerHour limita quantos links podem ser enviados para a mesma conta por hora
+const passwordResetsPerHour = 3
+
+// ForgotPassword envia um link de redefinição de senha. A resposta é a mesma para emails
+// cadastrados ou não, para não revelar quais contas existem.
+func ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	response := fiber.Map{
+		"status":  "success",
+		"message": "Se o email estiver cadastrado, você receberá um link para redefinir a senha",
+	}
+
+	user, err := middleware.Repositories(c).Accounts.GetByEmail(c.UserContext(), req.Email)
+	if err == repository.ErrNotFound {
+		return c.JSON(response)
+	}
+	if err != nil {
+		log.Printf("Error querying user: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro interno do servidor",
+		})
+	}
+	if !user.IsActive {
+		return c.JSON(response)
+	}
+
+	token, err := createPasswordResetToken(c, user.ID)
+	if err != nil {
+		log.Printf("Error creating password reset token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro interno do servidor",
+		})
+	}
+	if token == "" {
+		log.Printf("Password reset limit reached for user %s", user.ID)
+		return c.JSON(response)
+	}
+
+	// Envio em segundo plano para que o tempo de resposta não indique se a conta existe
+	mail.SendAsync(mail.PasswordResetMessage(user.Email, user.Name, mail.Link("/reset-password", token), models.PasswordResetTTL))
+
+	return c.JSON(response)
+}
+
+// createPasswordResetToken invalida os links anteriores do usuário e gera um novo.
+// Retorna token vazio se o limite de envios por hora foi atingido.
+func createPasswordResetToken(c *fiber.Ctx, userID uuid.UUID) (string, error) {
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = middleware.Repositories(c).PasswordResets.Create(c.UserContext(), userID, hash, c.IP(), passwordResetsPerHour)
+	if err == repository.ErrRateLimited {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ResetPassword troca a senha usando o token recebido por email. O token vale uma única vez;
+// todas as sessões do usuário são encerradas e o bloqueio por tentativas falhas é removido.
+func ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if err := utils.ValidatePassword(req.NewPassword); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	resets := middleware.Repositories(c).PasswordResets
+	tokenHash := utils.HashToken(req.Token)
+
+	user, err := resets.User(c.UserContext(), tokenHash)
+	if err != nil && err != repository.ErrNotFound {
+		log.Printf("Error querying password reset token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro interno do servidor",
+		})
+	}
+	if user == nil || !user.IsActive {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Link de redefinição inválido ou expirado",
+		})
+	}
+
+	if err := user.HashPassword(req.NewPassword); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao processar nova senha",
+		})
+	}
+
+	event := securityEvent(c, models.SecurityEventPasswordReset, &user.ID, user.CompanyID, user.Email, &user.ID, nil)
+	err = resets.Reset(c.UserContext(), tokenHash, user.Password, event)
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Link de redefinição inválido ou expirado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error updating password: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao atualizar senha",
+		})
+	}
+
+	if err := clearLoginFailures(c, user.Email); err != nil {
+		log.Printf("Error clearing login failures: %v", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Senha redefinida com sucesso. Faça login com a nova senha",
+	})
+}
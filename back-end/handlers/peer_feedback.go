@@ -0,0 +1,534 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// requireScopedFeedbackRound carrega a rodada do parâmetro :id; quando ok é false
+// a resposta de erro já foi enviada
+func requireScopedFeedbackRound(c *fiber.Ctx) (*models.FeedbackRound, bool) {
+	roundUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+		return nil, false
+	}
+
+	round, err := middleware.Repositories(c).FeedbackRounds.Get(c.UserContext(), roundUUID)
+	if err == repository.ErrNotFound {
+		c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Rodada de feedback não encontrada",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying feedback round: %v", err)
+		c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar rodada de feedback",
+		})
+		return nil, false
+	}
+	return round, true
+}
+
+// CreateFeedbackRound abre uma rodada de feedback 360 para um desenvolvedor em um mês
+func CreateFeedbackRound(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	var req models.CreateFeedbackRoundRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if !isValidMonth(req.Month) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Mês inválido, use o formato YYYY-MM",
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+	developer, err := repos.Developers.Get(ctx, req.DeveloperID)
+	if err == repository.ErrNotFound {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Desenvolvedor não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying developer: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar desenvolvedor",
+		})
+	}
+	if developer.CompanyID == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Desenvolvedor deve estar associado a uma empresa",
+		})
+	}
+
+	exists, err := repos.FeedbackRounds.ExistsForMonth(ctx, developer.ID, req.Month)
+	if err != nil {
+		log.Printf("Error checking feedback round: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao verificar rodada existente",
+		})
+	}
+	if exists {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Já existe uma rodada de feedback para este desenvolvedor neste mês",
+		})
+	}
+
+	// Os pares respondem as mesmas perguntas do relatório do mês (ou do template ativo)
+	var templateVersionID *uuid.UUID
+	report, err := repos.Reports.GetForMonth(ctx, developer.ID, req.Month)
+	if err == nil {
+		templateVersionID = report.TemplateVersionID
+	} else if err != repository.ErrNotFound {
+		log.Printf("Error querying performance report: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao verificar relatório do mês",
+		})
+	}
+	if templateVersionID == nil {
+		template, err := repos.Templates.Active(ctx, developer.CompanyID)
+		if err != nil {
+			log.Printf("Error loading active evaluation template: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao carregar template de avaliação",
+			})
+		}
+		templateVersionID = &template.VersionID
+	}
+
+	anonymize := true
+	if req.Anonymize != nil {
+		anonymize = *req.Anonymize
+	}
+	minRespondents := models.DefaultMinRespondents
+	if req.MinRespondents != nil {
+		minRespondents = *req.MinRespondents
+	}
+
+	round := models.FeedbackRound{
+		CompanyID:         *developer.CompanyID,
+		DeveloperID:       developer.ID,
+		Month:             req.Month,
+		TemplateVersionID: *templateVersionID,
+		Anonymize:         anonymize,
+		MinRespondents:    minRespondents,
+		CreatedBy:         &user.UserID,
+	}
+	if err := repos.FeedbackRounds.Create(ctx, &round); err != nil {
+		log.Printf("Error creating feedback round: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao criar rodada de feedback",
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"data":    round,
+	})
+}
+
+// ListFeedbackRounds lista as rodadas da empresa (?developerId= e ?month= filtram)
+func ListFeedbackRounds(c *fiber.Ctx) error {
+	var filter repository.FeedbackRoundFilter
+	if value := c.Query("developerId"); value != "" {
+		developerUUID, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "ID do desenvolvedor inválido",
+			})
+		}
+		filter.DeveloperID = &developerUUID
+	}
+	filter.Month = c.Query("month")
+
+	rounds, err := middleware.Repositories(c).FeedbackRounds.List(c.UserContext(), filter)
+	if err != nil {
+		log.Printf("Error querying feedback rounds: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar rodadas de feedback",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    rounds,
+	})
+}
+
+// GetFeedbackRound retorna a rodada, os pares indicados e o agregado das respostas
+func GetFeedbackRound(c *fiber.Ctx) error {
+	round, ok := requireScopedFeedbackRound(c)
+	if !ok {
+		return nil
+	}
+
+	repos := middleware.Repositories(c)
+	nominations, err := repos.FeedbackRounds.Nominations(c.UserContext(), round.ID)
+	if err != nil {
+		log.Printf("Error querying feedback nominations: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar indicações",
+		})
+	}
+
+	summary, err := repos.FeedbackRounds.Summary(c.UserContext(), round)
+	if err != nil {
+		log.Printf("Error summarizing peer feedback: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao agregar feedback",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"round":       round,
+			"nominations": nominations,
+			"summary":     summary,
+		},
+	})
+}
+
+// NominatePeers indica pares da mesma empresa para responder a rodada. Desenvolvedores
+// só podem ser indicados se tiverem conta de usuário vinculada.
+func NominatePeers(c *fiber.Ctx) error {
+	round, ok := requireScopedFeedbackRound(c)
+	if !ok {
+		return nil
+	}
+
+	if round.Status != models.FeedbackRoundOpen {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Rodada de feedback já foi encerrada",
+		})
+	}
+
+	var req models.NominatePeersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+	if len(req.UserIDs) == 0 && len(req.DeveloperIDs) == 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Informe ao menos um usuário ou desenvolvedor",
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+	reviewerIDs := append([]uuid.UUID{}, req.UserIDs...)
+	for _, developerID := range req.DeveloperIDs {
+		userID, err := repos.FeedbackRounds.DeveloperAccount(ctx, round.CompanyID, developerID)
+		if err == repository.ErrNotFound {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Desenvolvedor " + developerID.String() + " não encontrado na empresa",
+			})
+		}
+		if err != nil {
+			log.Printf("Error querying developer: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao buscar desenvolvedor",
+			})
+		}
+		if userID == nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Desenvolvedor " + developerID.String() + " não possui conta de usuário vinculada",
+			})
+		}
+		reviewerIDs = append(reviewerIDs, *userID)
+	}
+
+	// O próprio avaliado não pode ser indicado
+	evaluatedUserID, err := repos.FeedbackRounds.DeveloperAccount(ctx, round.CompanyID, round.DeveloperID)
+	if err != nil {
+		log.Printf("Error querying developer: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar desenvolvedor",
+		})
+	}
+
+	for _, reviewerID := range reviewerIDs {
+		if evaluatedUserID != nil && reviewerID == *evaluatedUserID {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "O desenvolvedor avaliado não pode ser indicado como par",
+			})
+		}
+
+		reviewer, err := repos.Users.Get(ctx, reviewerID)
+		if err == nil && (!reviewer.IsActive || reviewer.CompanyID == nil || *reviewer.CompanyID != round.CompanyID) {
+			err = repository.ErrNotFound
+		}
+		if err == repository.ErrNotFound {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Usuário " + reviewerID.String() + " não encontrado na empresa",
+			})
+		}
+		if err != nil {
+			log.Printf("Error querying user: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao verificar usuário",
+			})
+		}
+	}
+
+	if err := repos.FeedbackRounds.Nominate(ctx, round.ID, reviewerIDs); err != nil {
+		log.Printf("Error creating feedback nominations: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao indicar pares",
+		})
+	}
+
+	nominations, err := repos.FeedbackRounds.Nominations(ctx, round.ID)
+	if err != nil {
+		log.Printf("Error querying feedback nominations: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar indicações",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    nominations,
+	})
+}
+
+// RemovePeerNomination retira a indicação de um par que ainda não respondeu
+func RemovePeerNomination(c *fiber.Ctx) error {
+	round, ok := requireScopedFeedbackRound(c)
+	if !ok {
+		return nil
+	}
+
+	reviewerUUID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID do usuário inválido",
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	nomination, err := repos.FeedbackRounds.Nomination(c.UserContext(), round.ID, reviewerUUID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Indicação não encontrada",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying feedback nomination: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar indicação",
+		})
+	}
+	if nomination.SubmittedAt != nil {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Não é possível remover um par que já respondeu",
+		})
+	}
+
+	if err := repos.FeedbackRounds.RemoveNomination(c.UserContext(), round.ID, reviewerUUID); err != nil {
+		log.Printf("Error deleting feedback nomination: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao remover indicação",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Indicação removida com sucesso",
+	})
+}
+
+// CloseFeedbackRound encerra a rodada; novas respostas deixam de ser aceitas
+func CloseFeedbackRound(c *fiber.Ctx) error {
+	round, ok := requireScopedFeedbackRound(c)
+	if !ok {
+		return nil
+	}
+
+	if round.Status != models.FeedbackRoundOpen {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Rodada de feedback já foi encerrada",
+		})
+	}
+
+	if err := middleware.Repositories(c).FeedbackRounds.Close(c.UserContext(), round); err != nil {
+		log.Printf("Error closing feedback round: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao encerrar rodada de feedback",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    round,
+	})
+}
+
+// GetMyFeedbackRequests lista as rodadas em que o usuário logado foi indicado como par
+func GetMyFeedbackRequests(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	requests, err := middleware.Repositories(c).FeedbackRounds.Requests(c.UserContext(), user.UserID)
+	if err != nil {
+		log.Printf("Error querying feedback requests: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar pedidos de feedback",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    requests,
+	})
+}
+
+// SubmitMyPeerFeedback grava (ou substitui, enquanto a rodada estiver aberta) a resposta
+// do usuário logado para uma rodada em que foi indicado
+func SubmitMyPeerFeedback(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	roundUUID, err := uuid.Parse(c.Params("roundId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+	}
+
+	var req models.SubmitPeerFeedbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	// Apenas pares indicados enxergam a rodada
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+	round, err := repos.FeedbackRounds.GetForReviewer(ctx, roundUUID, user.UserID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Pedido de feedback não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying feedback round: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar pedido de feedback",
+		})
+	}
+
+	if round.Status != models.FeedbackRoundOpen {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Rodada de feedback já foi encerrada",
+		})
+	}
+
+	template, err := repos.Templates.Version(ctx, round.TemplateVersionID)
+	if err != nil {
+		log.Printf("Error loading evaluation template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao carregar template de avaliação",
+		})
+	}
+
+	categoryScores, weightedAverageScore, err := template.Score(req.QuestionScores)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	err = repos.FeedbackRounds.SubmitResponse(ctx, round.ID, user.UserID, models.PeerResponse{
+		QuestionScores:       req.QuestionScores,
+		CategoryScores:       categoryScores,
+		WeightedAverageScore: weightedAverageScore,
+		Comments:             req.Comments,
+	})
+	if err != nil {
+		log.Printf("Error saving peer feedback: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao salvar feedback",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Feedback enviado com sucesso",
+	})
+}
@@ -1,62 +1,79 @@
 package handlers
 
 import (
-	"database/sql"
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
-	"tivix-performance-tracker-backend/database"
+	"tivix-performance-tracker-backend/audit"
 	"tivix-performance-tracker-backend/middleware"
 	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
 )
 
-// GetAllPerformanceReports retorna todos os relatórios de performance
+// GetAllPerformanceReports retorna os relatórios de performance paginados por cursor, com
+// filtros por time, desenvolvedor, status, intervalo de meses e faixa de nota
 func GetAllPerformanceReports(c *fiber.Ctx) error {
 	user := c.Locals("user").(*middleware.JWTClaims)
-	
-	var query string
-	var args []interface{}
-
-	if user.Role == "admin" {
-		// Admins podem ver todos os relatórios
-		query = `
-			SELECT pr.id, pr.developer_id, pr.month, pr.question_scores, pr.category_scores, 
-			       pr.weighted_average_score, pr.highlights, pr.points_to_develop, 
-			       pr.created_at, pr.updated_at
-			FROM performance_reports pr
-			ORDER BY pr.month DESC, pr.created_at DESC
-		`
-	} else {
-		// Managers e usuários só podem ver relatórios da sua empresa
-		if user.CompanyID == nil {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   true,
-				"message": "Usuário deve estar associado a uma empresa",
-			})
-		}
-		
-		query = `
-			SELECT pr.id, pr.developer_id, pr.month, pr.question_scores, pr.category_scores, 
-			       pr.weighted_average_score, pr.highlights, pr.points_to_develop, 
-			       pr.created_at, pr.updated_at
-			FROM performance_reports pr
-			INNER JOIN developers d ON pr.developer_id = d.id
-			WHERE d.company_id = $1
-			ORDER BY pr.month DESC, pr.created_at DESC
-		`
-		args = append(args, *user.CompanyID)
-	}
-
-	var rows *sql.Rows
-	var err error
-	
-	if len(args) > 0 {
-		rows, err = database.DB.Query(query, args...)
-	} else {
-		rows, err = database.DB.Query(query)
+
+	// Managers e usuários só podem ver relatórios da sua empresa
+	if !user.HasPermission(models.PermCompaniesAll) && user.CompanyID == nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Usuário deve estar associado a uma empresa",
+		})
 	}
+
+	page, err := parsePage(c, repository.ReportSorting)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	filter := repository.ReportFilter{
+		Status:    c.Query("status"),
+		MonthFrom: c.Query("fromMonth"),
+		MonthTo:   c.Query("toMonth"),
+	}
+	if filter.TeamID, err = parseUUIDFilter(c, "teamId"); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	if filter.DeveloperID, err = parseUUIDFilter(c, "developerId"); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	if filter.Status != "" && filter.Status != models.ReportStatusDraft && !models.IsReportPublished(filter.Status) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Status inválido. Use draft, submitted, acknowledged ou locked",
+		})
+	}
+	if (filter.MonthFrom != "" && !isValidMonth(filter.MonthFrom)) || (filter.MonthTo != "" && !isValidMonth(filter.MonthTo)) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Mês inválido (use YYYY-MM)",
+		})
+	}
+
+	filter.MinScore, filter.MaxScore, err = parseScoreRange(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	// Rascunhos, empresa e times do usuário são aplicados pelo repositório
+	reports, err := middleware.Repositories(c).Reports.List(c.UserContext(), filter, page)
 	if err != nil {
 		log.Printf("Error querying performance reports: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -64,34 +81,10 @@ func GetAllPerformanceReports(c *fiber.Ctx) error {
 			"message": "Erro ao buscar relatórios de performance",
 		})
 	}
-	defer rows.Close()
-
-	var reports []models.PerformanceReport
-	for rows.Next() {
-		var report models.PerformanceReport
-		err := rows.Scan(
-			&report.ID,
-			&report.DeveloperID,
-			&report.Month,
-			&report.QuestionScores,
-			&report.CategoryScores,
-			&report.WeightedAverageScore,
-			&report.Highlights,
-			&report.PointsToDevelop,
-			&report.CreatedAt,
-			&report.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning performance report: %v", err)
-			continue
-		}
-		reports = append(reports, report)
-	}
 
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    reports,
-	})
+	response := pageResponse(reports)
+	response["success"] = true
+	return c.JSON(response)
 }
 
 // GetPerformanceReportsByDeveloper retorna relatórios de performance de um desenvolvedor
@@ -105,16 +98,7 @@ func GetPerformanceReportsByDeveloper(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		SELECT id, developer_id, month, question_scores, category_scores, 
-		       weighted_average_score, highlights, points_to_develop, 
-		       created_at, updated_at
-		FROM performance_reports 
-		WHERE developer_id = $1
-		ORDER BY month DESC, created_at DESC
-	`
-
-	rows, err := database.DB.Query(query, developerUUID)
+	reports, err := middleware.Repositories(c).Reports.ListByDeveloper(c.UserContext(), developerUUID)
 	if err != nil {
 		log.Printf("Error querying performance reports by developer: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -122,29 +106,6 @@ func GetPerformanceReportsByDeveloper(c *fiber.Ctx) error {
 			"message": "Erro ao buscar relatórios do desenvolvedor",
 		})
 	}
-	defer rows.Close()
-
-	var reports []models.PerformanceReport
-	for rows.Next() {
-		var report models.PerformanceReport
-		err := rows.Scan(
-			&report.ID,
-			&report.DeveloperID,
-			&report.Month,
-			&report.QuestionScores,
-			&report.CategoryScores,
-			&report.WeightedAverageScore,
-			&report.Highlights,
-			&report.PointsToDevelop,
-			&report.CreatedAt,
-			&report.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning performance report: %v", err)
-			continue
-		}
-		reports = append(reports, report)
-	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -154,36 +115,7 @@ func GetPerformanceReportsByDeveloper(c *fiber.Ctx) error {
 
 // GetPerformanceReportsByMonth retorna relatórios de performance de um mês específico
 func GetPerformanceReportsByMonth(c *fiber.Ctx) error {
-	month := c.Params("month")
-	user := c.Locals("user").(*middleware.JWTClaims)
-
-	var query string
-	var args []interface{}
-
-	if user.Role == "admin" {
-		query = `
-			SELECT pr.id, pr.developer_id, pr.month, pr.question_scores, pr.category_scores, 
-			       pr.weighted_average_score, pr.highlights, pr.points_to_develop, 
-			       pr.created_at, pr.updated_at
-			FROM performance_reports pr
-			WHERE pr.month = $1
-			ORDER BY pr.weighted_average_score DESC, pr.created_at DESC
-		`
-		args = []interface{}{month}
-	} else {
-		query = `
-			SELECT pr.id, pr.developer_id, pr.month, pr.question_scores, pr.category_scores, 
-			       pr.weighted_average_score, pr.highlights, pr.points_to_develop, 
-			       pr.created_at, pr.updated_at
-			FROM performance_reports pr
-			JOIN users d ON pr.developer_id = d.id
-			WHERE pr.month = $1 AND d.company_id = $2
-			ORDER BY pr.weighted_average_score DESC, pr.created_at DESC
-		`
-		args = []interface{}{month, user.CompanyID}
-	}
-
-	rows, err := database.DB.Query(query, args...)
+	reports, err := middleware.Repositories(c).Reports.ListByMonth(c.UserContext(), c.Params("month"))
 	if err != nil {
 		log.Printf("Error querying performance reports by month: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -191,29 +123,6 @@ func GetPerformanceReportsByMonth(c *fiber.Ctx) error {
 			"message": "Erro ao buscar relatórios do mês",
 		})
 	}
-	defer rows.Close()
-
-	var reports []models.PerformanceReport
-	for rows.Next() {
-		var report models.PerformanceReport
-		err := rows.Scan(
-			&report.ID,
-			&report.DeveloperID,
-			&report.Month,
-			&report.QuestionScores,
-			&report.CategoryScores,
-			&report.WeightedAverageScore,
-			&report.Highlights,
-			&report.PointsToDevelop,
-			&report.CreatedAt,
-			&report.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning performance report: %v", err)
-			continue
-		}
-		reports = append(reports, report)
-	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -223,6 +132,7 @@ func GetPerformanceReportsByMonth(c *fiber.Ctx) error {
 
 // GetPerformanceReportByID retorna um relatório específico por ID
 func GetPerformanceReportByID(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
 	id := c.Params("id")
 	reportUUID, err := uuid.Parse(id)
 	if err != nil {
@@ -232,29 +142,8 @@ func GetPerformanceReportByID(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		SELECT id, developer_id, month, question_scores, category_scores, 
-		       weighted_average_score, highlights, points_to_develop, 
-		       created_at, updated_at
-		FROM performance_reports 
-		WHERE id = $1
-	`
-
-	var report models.PerformanceReport
-	err = database.DB.QueryRow(query, reportUUID).Scan(
-		&report.ID,
-		&report.DeveloperID,
-		&report.Month,
-		&report.QuestionScores,
-		&report.CategoryScores,
-		&report.WeightedAverageScore,
-		&report.Highlights,
-		&report.PointsToDevelop,
-		&report.CreatedAt,
-		&report.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
+	report, err := repos.Reports.Get(c.UserContext(), reportUUID)
+	if err == repository.ErrNotFound {
 		return c.Status(404).JSON(fiber.Map{
 			"error":   true,
 			"message": "Relatório não encontrado",
@@ -268,6 +157,15 @@ func GetPerformanceReportByID(c *fiber.Ctx) error {
 		})
 	}
 
+	report.PeerFeedback, err = repos.FeedbackRounds.ForReport(c.UserContext(), report)
+	if err != nil {
+		log.Printf("Error loading peer feedback: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar feedback dos pares",
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    report,
@@ -276,6 +174,10 @@ func GetPerformanceReportByID(c *fiber.Ctx) error {
 
 // CreatePerformanceReport cria um novo relatório de performance
 func CreatePerformanceReport(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+
 	var req models.CreatePerformanceReportRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -298,18 +200,28 @@ func CreatePerformanceReport(c *fiber.Ctx) error {
 			"message": "Mês é obrigatório",
 		})
 	}
+	// Ciclos, templates e a pontuação mais recente comparam o mês como texto YYYY-MM
+	if !isValidMonth(req.Month) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Mês inválido (use YYYY-MM)",
+		})
+	}
 
-	if req.WeightedAverageScore < 0 || req.WeightedAverageScore > 10 {
+	// Sem status explícito o relatório é enviado direto, como antes do fluxo de rascunhos
+	if req.Status == "" {
+		req.Status = models.ReportStatusSubmitted
+	}
+	if req.Status != models.ReportStatusDraft && req.Status != models.ReportStatusSubmitted {
 		return c.Status(400).JSON(fiber.Map{
 			"error":   true,
-			"message": "Pontuação deve estar entre 0 e 10",
+			"message": "Status inicial deve ser draft ou submitted",
 		})
 	}
 
-	// Verificar se o desenvolvedor existe
-	var developerExists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM developers WHERE id = $1)", req.DeveloperID).Scan(&developerExists)
-	if err != nil || !developerExists {
+	// Verificar se o desenvolvedor existe e está no alcance do usuário (empresa e times)
+	developer, err := repos.Developers.Get(ctx, req.DeveloperID)
+	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error":   true,
 			"message": "Desenvolvedor não encontrado",
@@ -317,11 +229,7 @@ func CreatePerformanceReport(c *fiber.Ctx) error {
 	}
 
 	// Verificar se já existe um relatório para este desenvolvedor neste mês
-	var existingReportExists bool
-	err = database.DB.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM performance_reports WHERE developer_id = $1 AND month = $2)",
-		req.DeveloperID, req.Month,
-	).Scan(&existingReportExists)
+	existingReportExists, err := repos.Reports.ExistsForMonth(ctx, req.DeveloperID, req.Month, nil)
 	if err != nil {
 		log.Printf("Error checking existing report: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -336,39 +244,64 @@ func CreatePerformanceReport(c *fiber.Ctx) error {
 		})
 	}
 
-	// Inserir novo relatório
-	query := `
-		INSERT INTO performance_reports (developer_id, month, question_scores, category_scores, 
-		                               weighted_average_score, highlights, points_to_develop)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, developer_id, month, question_scores, category_scores, 
-		          weighted_average_score, highlights, points_to_develop, created_at, updated_at
-	`
-
-	var report models.PerformanceReport
-	err = database.DB.QueryRow(
-		query,
-		req.DeveloperID,
-		req.Month,
-		req.QuestionScores,
-		req.CategoryScores,
-		req.WeightedAverageScore,
-		req.Highlights,
-		req.PointsToDevelop,
-	).Scan(
-		&report.ID,
-		&report.DeveloperID,
-		&report.Month,
-		&report.QuestionScores,
-		&report.CategoryScores,
-		&report.WeightedAverageScore,
-		&report.Highlights,
-		&report.PointsToDevelop,
-		&report.CreatedAt,
-		&report.UpdatedAt,
-	)
+	// Meses com ciclo de avaliação usam o template do ciclo e não aceitam relatórios após o encerramento
+	cycle, ok := unclosedCycleForMonth(c, developer.CompanyID, req.Month)
+	if !ok {
+		return nil
+	}
+
+	// Recalcular as notas no servidor a partir do template do ciclo ou do template ativo
+	// da empresa do desenvolvedor
+	var template *models.EvaluationTemplate
+	if cycle != nil {
+		template, err = repos.Templates.Version(ctx, cycle.TemplateVersionID)
+	} else {
+		template, err = repos.Templates.Active(ctx, developer.CompanyID)
+	}
+	if err != nil {
+		log.Printf("Error loading active evaluation template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao carregar template de avaliação",
+		})
+	}
 
+	// Rascunhos aceitam notas parciais; a pontuação só é calculada no envio
+	categoryScores := models.JSONB{}
+	var weightedAverageScore float64
+	if req.Status == models.ReportStatusDraft {
+		err = template.ValidatePartial(req.QuestionScores)
+	} else {
+		categoryScores, weightedAverageScore, err = template.Score(req.QuestionScores)
+	}
 	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	var submittedAt *time.Time
+	var submittedBy *uuid.UUID
+	if req.Status == models.ReportStatusSubmitted {
+		now := time.Now()
+		submittedAt, submittedBy = &now, &user.UserID
+	}
+
+	report := models.PerformanceReport{
+		DeveloperID:          req.DeveloperID,
+		Month:                req.Month,
+		QuestionScores:       req.QuestionScores,
+		CategoryScores:       categoryScores,
+		WeightedAverageScore: weightedAverageScore,
+		Highlights:           req.Highlights,
+		PointsToDevelop:      req.PointsToDevelop,
+		TemplateVersionID:    &template.VersionID,
+		Status:               req.Status,
+		SubmittedAt:          submittedAt,
+		SubmittedBy:          submittedBy,
+	}
+	if err := repos.Reports.Create(ctx, &report); err != nil {
 		log.Printf("Error creating performance report: %v", err)
 		return c.Status(500).JSON(fiber.Map{
 			"error":   true,
@@ -376,67 +309,291 @@ func CreatePerformanceReport(c *fiber.Ctx) error {
 		})
 	}
 
-	// Atualizar a pontuação mais recente do desenvolvedor
-	_, err = database.DB.Exec(
-		"UPDATE developers SET latest_performance_score = $1 WHERE id = $2",
-		req.WeightedAverageScore,
-		req.DeveloperID,
-	)
-	if err != nil {
-		log.Printf("Error updating developer latest score: %v", err)
-		// Não retorna erro porque o relatório foi criado com sucesso
+	if err := repos.Reports.RecordRevision(ctx, report.ID, developer.CompanyID, "create", user.UserID, nil, &report); err != nil {
+		log.Printf("Error recording report revision: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao registrar histórico do relatório",
+		})
 	}
 
+	// A pontuação mais recente do desenvolvedor é mantida pelo trigger refresh_developer_latest_score
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "performance-reports",
+		EntityID:   report.ID.String(),
+		CompanyID:  developer.CompanyID,
+		After:      report,
+	})
+
 	return c.Status(201).JSON(fiber.Map{
 		"success": true,
 		"data":    report,
 	})
 }
 
-// GetAvailableMonths retorna os meses disponíveis com relatórios
-func GetAvailableMonths(c *fiber.Ctx) error {
-	user := c.Locals("user").(*middleware.JWTClaims)
-	
-	var query string
-	var args []interface{}
-
-	if user.Role == "admin" {
-		query = `
-			SELECT DISTINCT month 
-			FROM performance_reports 
-			ORDER BY month DESC
-		`
-		args = []interface{}{}
-	} else {
-		query = `
-			SELECT DISTINCT pr.month 
-			FROM performance_reports pr
-			JOIN users d ON pr.developer_id = d.id
-			WHERE d.company_id = $1
-			ORDER BY pr.month DESC
-		`
-		args = []interface{}{user.CompanyID}
+// lockScopedReport carrega com bloqueio de linha um relatório cujo desenvolvedor está no alcance
+// do usuário (empresa e times); quando ok é false a resposta de erro já foi enviada
+func lockScopedReport(c *fiber.Ctx, reportID uuid.UUID) (*models.PerformanceReport, *uuid.UUID, bool) {
+	repos := middleware.Repositories(c)
+
+	existing, companyID, err := repos.Reports.LockForChange(c.UserContext(), reportID)
+	if err == nil {
+		_, err = repos.Developers.Get(c.UserContext(), existing.DeveloperID)
+	}
+	if err == repository.ErrNotFound {
+		c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Relatório não encontrado",
+		})
+		return nil, nil, false
+	}
+	if err != nil {
+		log.Printf("Error loading performance report: %v", err)
+		c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar relatório",
+		})
+		return nil, nil, false
 	}
+	return existing, companyID, true
+}
 
-	rows, err := database.DB.Query(query, args...)
+// unclosedCycleForMonth devolve o ciclo de avaliação do mês (nil se não houver) e recusa com 409
+// os meses cujo ciclo já foi encerrado; quando ok é false a resposta de erro já foi enviada
+func unclosedCycleForMonth(c *fiber.Ctx, companyID *uuid.UUID, month string) (*models.ReviewCycle, bool) {
+	cycle, err := middleware.Repositories(c).ReviewCycles.ForMonth(c.UserContext(), companyID, month)
 	if err != nil {
-		log.Printf("Error querying available months: %v", err)
-		return c.Status(500).JSON(fiber.Map{
+		log.Printf("Error querying review cycle: %v", err)
+		c.Status(500).JSON(fiber.Map{
 			"error":   true,
-			"message": "Erro ao buscar meses disponíveis",
+			"message": "Erro ao verificar ciclo de avaliação",
 		})
+		return nil, false
 	}
-	defer rows.Close()
+	if cycle != nil && cycle.Status == models.ReviewCycleClosed {
+		c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "O ciclo de avaliação deste mês já foi encerrado",
+		})
+		return nil, false
+	}
+	return cycle, true
+}
 
-	var months []string
-	for rows.Next() {
-		var month string
-		err := rows.Scan(&month)
+// UpdatePerformanceReport edita um relatório existente, registrando a versão anterior no histórico.
+// As notas são recalculadas com a mesma versão de template usada na criação do relatório.
+func UpdatePerformanceReport(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+	reportUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+	}
+
+	var req models.UpdatePerformanceReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if req.Month == nil && req.QuestionScores == nil && req.Highlights == nil && req.PointsToDevelop == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Nenhum campo para atualizar",
+		})
+	}
+	if req.Month != nil && !isValidMonth(*req.Month) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Mês inválido (use YYYY-MM)",
+		})
+	}
+
+	existing, companyID, ok := lockScopedReport(c, reportUUID)
+	if !ok {
+		return nil
+	}
+
+	if !models.IsReportEditable(existing.Status) {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Relatório com status " + existing.Status + " não pode ser alterado",
+		})
+	}
+
+	updated := *existing
+	if req.Month != nil && *req.Month != existing.Month {
+		duplicate, err := repos.Reports.ExistsForMonth(ctx, existing.DeveloperID, *req.Month, &existing.ID)
+		if err != nil {
+			log.Printf("Error checking existing report: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao verificar relatório existente",
+			})
+		}
+		if duplicate {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Já existe um relatório para este desenvolvedor neste mês",
+			})
+		}
+		if _, ok := unclosedCycleForMonth(c, companyID, *req.Month); !ok {
+			return nil
+		}
+		updated.Month = *req.Month
+	}
+	if req.Highlights != nil {
+		updated.Highlights = *req.Highlights
+	}
+	if req.PointsToDevelop != nil {
+		updated.PointsToDevelop = *req.PointsToDevelop
+	}
+
+	if req.QuestionScores != nil {
+		var template *models.EvaluationTemplate
+		if existing.TemplateVersionID != nil {
+			template, err = repos.Templates.Version(ctx, *existing.TemplateVersionID)
+		} else {
+			template, err = repos.Templates.Active(ctx, companyID)
+		}
+		if err != nil {
+			log.Printf("Error loading evaluation template: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao carregar template de avaliação",
+			})
+		}
+
+		// Rascunhos continuam aceitando notas parciais
+		if existing.Status == models.ReportStatusDraft {
+			err = template.ValidatePartial(req.QuestionScores)
+		} else {
+			updated.CategoryScores, updated.WeightedAverageScore, err = template.Score(req.QuestionScores)
+		}
 		if err != nil {
-			log.Printf("Error scanning month: %v", err)
-			continue
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
 		}
-		months = append(months, month)
+
+		updated.QuestionScores = req.QuestionScores
+		updated.TemplateVersionID = &template.VersionID
+	}
+
+	// Uma calibração feita sobre a nota anterior deixa de valer quando a nota muda
+	if updated.WeightedAverageScore != existing.WeightedAverageScore || updated.Month != existing.Month {
+		updated.CalibratedScore = nil
+		updated.CalibrationAdjustmentID = nil
+	}
+
+	if err := repos.Reports.Update(ctx, &updated); err != nil {
+		log.Printf("Error updating performance report: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao atualizar relatório",
+		})
+	}
+
+	if err := repos.Reports.RecordRevision(ctx, updated.ID, companyID, "update", user.UserID, existing, &updated); err != nil {
+		log.Printf("Error recording report revision: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao registrar histórico do relatório",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "performance-reports",
+		EntityID:   updated.ID.String(),
+		CompanyID:  companyID,
+		Before:     existing,
+		After:      updated,
+	})
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// DeletePerformanceReport exclui um relatório; o conteúdo excluído permanece no histórico de revisões
+func DeletePerformanceReport(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	repos := middleware.Repositories(c)
+	reportUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+	}
+
+	existing, companyID, ok := lockScopedReport(c, reportUUID)
+	if !ok {
+		return nil
+	}
+
+	if !models.IsReportEditable(existing.Status) {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Relatório com status " + existing.Status + " não pode ser excluído",
+		})
+	}
+
+	if err := repos.Reports.Delete(c.UserContext(), existing.ID); err != nil {
+		log.Printf("Error deleting performance report: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao excluir relatório",
+		})
+	}
+
+	if err := repos.Reports.RecordRevision(c.UserContext(), existing.ID, companyID, "delete", user.UserID, existing, nil); err != nil {
+		log.Printf("Error recording report revision: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao registrar histórico do relatório",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "performance-reports",
+		EntityID:   existing.ID.String(),
+		CompanyID:  companyID,
+		Before:     existing,
+	})
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Relatório excluído com sucesso",
+	})
+}
+
+// GetAvailableMonths retorna os meses disponíveis com relatórios enviados
+func GetAvailableMonths(c *fiber.Ctx) error {
+	months, err := middleware.Repositories(c).Reports.Months(c.UserContext())
+	if err != nil {
+		log.Printf("Error querying available months: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar meses disponíveis",
+		})
 	}
 
 	return c.JSON(fiber.Map{
@@ -445,69 +602,20 @@ func GetAvailableMonths(c *fiber.Ctx) error {
 	})
 }
 
-// GetPerformanceStats retorna estatísticas gerais de performance
+// GetPerformanceStats retorna estatísticas gerais de performance (apenas relatórios enviados).
+// Relatórios calibrados entram com a nota calibrada.
 func GetPerformanceStats(c *fiber.Ctx) error {
 	user := c.Locals("user").(*middleware.JWTClaims)
-	
-	var query string
-	var args []interface{}
-
-	if user.Role == "admin" {
-		// Admins podem ver estatísticas de todas as empresas
-		query = `
-			SELECT 
-				COUNT(*) as total_reports,
-				ROUND(AVG(weighted_average_score)::numeric, 2) as average_score,
-				MAX(weighted_average_score) as highest_score,
-				MIN(weighted_average_score) as lowest_score
-			FROM performance_reports
-		`
-	} else {
-		// Managers e usuários só podem ver estatísticas da sua empresa
-		if user.CompanyID == nil {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   true,
-				"message": "Usuário deve estar associado a uma empresa",
-			})
-		}
-		
-		query = `
-			SELECT 
-				COUNT(*) as total_reports,
-				ROUND(AVG(pr.weighted_average_score)::numeric, 2) as average_score,
-				MAX(pr.weighted_average_score) as highest_score,
-				MIN(pr.weighted_average_score) as lowest_score
-			FROM performance_reports pr
-			INNER JOIN developers d ON pr.developer_id = d.id
-			WHERE d.company_id = $1
-		`
-		args = append(args, *user.CompanyID)
-	}
-
-	var stats struct {
-		TotalReports  int     `json:"totalReports"`
-		AverageScore  float64 `json:"averageScore"`
-		HighestScore  float64 `json:"highestScore"`
-		LowestScore   float64 `json:"lowestScore"`
-	}
-
-	var err error
-	if len(args) > 0 {
-		err = database.DB.QueryRow(query, args...).Scan(
-			&stats.TotalReports,
-			&stats.AverageScore,
-			&stats.HighestScore,
-			&stats.LowestScore,
-		)
-	} else {
-		err = database.DB.QueryRow(query).Scan(
-			&stats.TotalReports,
-			&stats.AverageScore,
-			&stats.HighestScore,
-			&stats.LowestScore,
-		)
+
+	// Managers e usuários só podem ver estatísticas da sua empresa
+	if !user.HasPermission(models.PermCompaniesAll) && user.CompanyID == nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Usuário deve estar associado a uma empresa",
+		})
 	}
 
+	stats, err := middleware.Repositories(c).Reports.Stats(c.UserContext())
 	if err != nil {
 		log.Printf("Error querying performance stats: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// GetPerformanceReportRevisions lista o histórico de alterações de um relatório
+func GetPerformanceReportRevisions(c *fiber.Ctx) error {
+	reportUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+	}
+
+	revisions, err := middleware.Repositories(c).Reports.Revisions(c.UserContext(), reportUUID)
+	if err != nil {
+		log.Printf("Error querying report revisions: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar histórico do relatório",
+		})
+	}
+
+	if len(revisions) == 0 {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Relatório não encontrado",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    revisions,
+	})
+}
+
+// GetPerformanceReportRevisionDiff compara o conteúdo do relatório entre duas revisões
+// (?from=1&to=3). Sem parâmetros, compara a penúltima com a última revisão.
+func GetPerformanceReportRevisionDiff(c *fiber.Ctx) error {
+	reportUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+	}
+
+	revisions, err := middleware.Repositories(c).Reports.Revisions(c.UserContext(), reportUUID)
+	if err != nil {
+		log.Printf("Error querying report revisions: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar histórico do relatório",
+		})
+	}
+	if len(revisions) == 0 {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Relatório não encontrado",
+		})
+	}
+
+	latest := revisions[len(revisions)-1].Revision
+	from, to := latest-1, latest
+	if value := c.Query("from"); value != "" {
+		if from, err = strconv.Atoi(value); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Revisão inicial inválida",
+			})
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = strconv.Atoi(value); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Revisão final inválida",
+			})
+		}
+	}
+
+	byNumber := make(map[int]*models.PerformanceReportRevision, len(revisions))
+	for i := range revisions {
+		byNumber[revisions[i].Revision] = &revisions[i]
+	}
+
+	// A revisão 0 representa o relatório antes de existir
+	fromData := models.JSONB{}
+	if from != 0 {
+		fromRevision, ok := byNumber[from]
+		if !ok {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   true,
+				"message": "Revisão " + strconv.Itoa(from) + " não encontrada",
+			})
+		}
+		fromData = fromRevision.NewData
+	}
+
+	toRevision, ok := byNumber[to]
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Revisão " + strconv.Itoa(to) + " não encontrada",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"reportId": reportUUID,
+			"from":     from,
+			"to":       to,
+			"changes":  utils.DiffJSON(fromData, toRevision.NewData),
+		},
+	})
+}
+
+// canSeeDraftReports indica se o usuário pode ver relatórios em rascunho
+func canSeeDraftReports(user *middleware.JWTClaims) bool {
+	return user.HasPermission(models.PermReportsReadDrafts)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// SubmitPerformanceReport envia um rascunho (draft → submitted). Todas as perguntas
+// precisam ter nota; a pontuação é calculada neste momento.
+func SubmitPerformanceReport(c *fiber.Ctx) error {
+	return transitionPerformanceReport(c, models.ReportTransitions["submit"])
+}
+
+// AcknowledgePerformanceReport registra que o desenvolvedor tomou ciência da avaliação
+// (submitted → acknowledged)
+func AcknowledgePerformanceReport(c *fiber.Ctx) error {
+	return transitionPerformanceReport(c, models.ReportTransitions["acknowledge"])
+}
+
+// LockPerformanceReport bloqueia definitivamente o relatório (acknowledged → locked)
+func LockPerformanceReport(c *fiber.Ctx) error {
+	return transitionPerformanceReport(c, models.ReportTransitions["lock"])
+}
+
+func transitionPerformanceReport(c *fiber.Ctx, transition models.ReportTransition) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	reportUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+	}
+
+	// Sem a permissão geral, a permissão "own" só vale para os relatórios do próprio usuário
+	ownOnly := !user.HasPermission(transition.Permission)
+	if ownOnly && (transition.OwnPermission == "" || !user.HasPermission(transition.OwnPermission)) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Acesso negado para esta ação",
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+
+	existing, companyID, err := repos.Reports.LockForChange(ctx, reportUUID)
+	if err == nil && existing.Status == models.ReportStatusDraft && !canSeeDraftReports(user) {
+		err = repository.ErrNotFound
+	}
+	if err == nil && !ownOnly {
+		_, err = repos.Developers.Get(ctx, existing.DeveloperID)
+	}
+	if err == nil && ownOnly {
+		var developer *models.Developer
+		developer, err = repos.Developers.GetByUser(ctx, user.UserID)
+		if err == nil && developer.ID != existing.DeveloperID {
+			err = repository.ErrNotFound
+		}
+	}
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Relatório não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error loading performance report: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar relatório",
+		})
+	}
+
+	if existing.Status != transition.From {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Transição inválida: relatório está com status " + existing.Status + ", esperado " + transition.From,
+		})
+	}
+
+	updated := *existing
+	if transition.To == models.ReportStatusSubmitted {
+		// Um rascunho não pode entrar em um ciclo que já foi encerrado
+		if _, ok := unclosedCycleForMonth(c, companyID, existing.Month); !ok {
+			return nil
+		}
+
+		var template *models.EvaluationTemplate
+		if existing.TemplateVersionID != nil {
+			template, err = repos.Templates.Version(ctx, *existing.TemplateVersionID)
+		} else {
+			template, err = repos.Templates.Active(ctx, companyID)
+		}
+		if err != nil {
+			log.Printf("Error loading evaluation template: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao carregar template de avaliação",
+			})
+		}
+
+		updated.CategoryScores, updated.WeightedAverageScore, err = template.Score(existing.QuestionScores)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		updated.TemplateVersionID = &template.VersionID
+	}
+
+	err = repos.Reports.UpdateStatus(ctx, &updated, transition.To, user.UserID)
+	if err != nil {
+		log.Printf("Error updating performance report status: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao atualizar status do relatório",
+		})
+	}
+
+	if err := repos.Reports.RecordRevision(ctx, updated.ID, companyID, transition.Action, user.UserID, existing, &updated); err != nil {
+		log.Printf("Error recording report revision: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao registrar histórico do relatório",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		Action:     transition.Action,
+		EntityType: "performance-reports",
+		EntityID:   updated.ID.String(),
+		CompanyID:  companyID,
+		Before:     existing,
+		After:      updated,
+	})
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    updated,
+	})
+}
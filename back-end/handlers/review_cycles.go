@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// requireScopedReviewCycle carrega o ciclo do parâmetro :id; quando ok é false
+// a resposta de erro já foi enviada
+func requireScopedReviewCycle(c *fiber.Ctx) (*models.ReviewCycle, bool) {
+	cycleUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+		return nil, false
+	}
+
+	cycle, err := middleware.Repositories(c).ReviewCycles.Get(c.UserContext(), cycleUUID)
+	if err == repository.ErrNotFound {
+		c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Ciclo de avaliação não encontrado",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying review cycle: %v", err)
+		c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar ciclo de avaliação",
+		})
+		return nil, false
+	}
+	return cycle, true
+}
+
+// CreateReviewCycle cria um ciclo planejado para um mês da empresa. O template é fixado
+// na criação para que todos os relatórios do ciclo usem as mesmas perguntas.
+func CreateReviewCycle(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	var req models.CreateReviewCycleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if !isValidMonth(req.Month) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Mês inválido, use o formato YYYY-MM",
+		})
+	}
+
+	opensAt, err := time.Parse("2006-01-02", req.OpensAt)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Data de abertura inválida, use o formato YYYY-MM-DD",
+		})
+	}
+	closesAt, err := time.Parse("2006-01-02", req.ClosesAt)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Prazo inválido, use o formato YYYY-MM-DD",
+		})
+	}
+	if closesAt.Before(opensAt) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "O prazo deve ser igual ou posterior à data de abertura",
+		})
+	}
+
+	// Determinar a empresa do ciclo
+	var companyID *uuid.UUID
+	if user.HasPermission(models.PermCompaniesAll) && req.CompanyID != nil {
+		companyID = req.CompanyID
+	} else if user.CompanyID != nil {
+		companyID = user.CompanyID
+	} else {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Informe a empresa do ciclo",
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+
+	var template *models.EvaluationTemplate
+	if req.TemplateVersionID != nil {
+		template, err = repos.Templates.Version(ctx, *req.TemplateVersionID)
+		if err == nil && template.CompanyID != nil && *template.CompanyID != *companyID {
+			err = repository.ErrNotFound
+		}
+		if err == repository.ErrNotFound {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Versão de template não encontrada",
+			})
+		}
+	} else {
+		template, err = repos.Templates.Active(ctx, companyID)
+	}
+	if err != nil {
+		log.Printf("Error loading evaluation template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao carregar template de avaliação",
+		})
+	}
+
+	existing, err := repos.ReviewCycles.ForMonth(ctx, companyID, req.Month)
+	if err != nil {
+		log.Printf("Error checking review cycle: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao verificar ciclo existente",
+		})
+	}
+	if existing != nil {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Já existe um ciclo de avaliação para este mês",
+		})
+	}
+
+	for _, teamID := range req.TeamIDs {
+		team, err := repos.Teams.Get(ctx, teamID)
+		if err == nil && (team.CompanyID == nil || *team.CompanyID != *companyID) {
+			err = repository.ErrNotFound
+		}
+		if err == repository.ErrNotFound {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Time " + teamID.String() + " não encontrado na empresa",
+			})
+		}
+		if err != nil {
+			log.Printf("Error querying team: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao verificar time",
+			})
+		}
+	}
+
+	cycle := models.ReviewCycle{
+		CompanyID:         *companyID,
+		Name:              req.Name,
+		Month:             req.Month,
+		OpensAt:           opensAt,
+		ClosesAt:          closesAt,
+		TemplateVersionID: template.VersionID,
+		CreatedBy:         &user.UserID,
+		TeamIDs:           req.TeamIDs,
+	}
+	if err := repos.ReviewCycles.Create(ctx, &cycle); err != nil {
+		log.Printf("Error creating review cycle: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao criar ciclo de avaliação",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		EntityType: "review-cycles",
+		EntityID:   cycle.ID.String(),
+		CompanyID:  &cycle.CompanyID,
+		After:      cycle,
+	})
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"data":    cycle,
+	})
+}
+
+// ListReviewCycles lista os ciclos da empresa (?status= filtra)
+func ListReviewCycles(c *fiber.Ctx) error {
+	cycles, err := middleware.Repositories(c).ReviewCycles.List(c.UserContext(), c.Query("status"))
+	if err != nil {
+		log.Printf("Error querying review cycles: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar ciclos de avaliação",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    cycles,
+	})
+}
+
+// GetReviewCycle retorna um ciclo com seus times e avaliadores
+func GetReviewCycle(c *fiber.Ctx) error {
+	cycle, ok := requireScopedReviewCycle(c)
+	if !ok {
+		return nil
+	}
+
+	assignments, err := middleware.Repositories(c).ReviewCycles.Assignments(c.UserContext(), cycle.ID)
+	if err != nil {
+		log.Printf("Error querying review cycle assignments: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar avaliadores do ciclo",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"cycle":       cycle,
+			"assignments": assignments,
+		},
+	})
+}
+
+// transitionReviewCycle move o ciclo de from para to
+func transitionReviewCycle(c *fiber.Ctx, from, to, conflictMessage string) error {
+	cycle, ok := requireScopedReviewCycle(c)
+	if !ok {
+		return nil
+	}
+
+	if cycle.Status != from {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": conflictMessage,
+		})
+	}
+
+	before := *cycle
+	if err := middleware.Repositories(c).ReviewCycles.UpdateStatus(c.UserContext(), cycle, to); err != nil {
+		log.Printf("Error updating review cycle status: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao atualizar ciclo de avaliação",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		Action:     models.AuditActionUpdate,
+		EntityType: "review-cycles",
+		EntityID:   cycle.ID.String(),
+		CompanyID:  &cycle.CompanyID,
+		Before:     before,
+		After:      cycle,
+	})
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    cycle,
+	})
+}
+
+// OpenReviewCycle abre um ciclo planejado
+func OpenReviewCycle(c *fiber.Ctx) error {
+	return transitionReviewCycle(c, models.ReviewCyclePlanned, models.ReviewCycleOpen,
+		"Apenas ciclos planejados podem ser abertos")
+}
+
+// CloseReviewCycle encerra um ciclo aberto; depois disso não é possível criar relatórios do mês
+func CloseReviewCycle(c *fiber.Ctx) error {
+	return transitionReviewCycle(c, models.ReviewCycleOpen, models.ReviewCycleClosed,
+		"Apenas ciclos abertos podem ser encerrados")
+}
+
+// AssignReviewCycleEvaluators define (ou substitui) o avaliador de cada desenvolvedor informado.
+// Avaliadores devem ser gerentes ou admins ativos da empresa do ciclo.
+func AssignReviewCycleEvaluators(c *fiber.Ctx) error {
+	cycle, ok := requireScopedReviewCycle(c)
+	if !ok {
+		return nil
+	}
+
+	if cycle.Status == models.ReviewCycleClosed {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "Ciclo de avaliação já foi encerrado",
+		})
+	}
+
+	var req models.AssignReviewersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+	for _, assignment := range req.Assignments {
+		developer, err := repos.Developers.Get(ctx, assignment.DeveloperID)
+		if err == nil && (developer.CompanyID == nil || *developer.CompanyID != cycle.CompanyID) {
+			err = repository.ErrNotFound
+		}
+		if err == repository.ErrNotFound {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Desenvolvedor " + assignment.DeveloperID.String() + " não encontrado na empresa",
+			})
+		}
+		if err != nil {
+			log.Printf("Error querying developer: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao buscar desenvolvedor",
+			})
+		}
+
+		eligible, err := repos.ReviewCycles.IsEligibleEvaluator(ctx, cycle.CompanyID, assignment.EvaluatorID)
+		if err != nil {
+			log.Printf("Error querying user: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao verificar avaliador",
+			})
+		}
+		if !eligible {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   true,
+				"message": "Avaliador " + assignment.EvaluatorID.String() + " deve ser gerente ou admin da empresa",
+			})
+		}
+	}
+
+	if err := repos.ReviewCycles.Assign(ctx, cycle.ID, req.Assignments); err != nil {
+		log.Printf("Error saving review cycle assignments: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao definir avaliadores",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Avaliadores definidos com sucesso",
+	})
+}
+
+// GetReviewCycleCompletion lista os desenvolvedores ativos dos times do ciclo e quais ainda
+// não têm relatório enviado no mês, agrupados por time e por avaliador. Sem avaliador
+// definido no ciclo, vale quem enviou o relatório.
+func GetReviewCycleCompletion(c *fiber.Ctx) error {
+	cycle, ok := requireScopedReviewCycle(c)
+	if !ok {
+		return nil
+	}
+
+	statuses, err := middleware.Repositories(c).ReviewCycles.Completion(c.UserContext(), cycle)
+	if err != nil {
+		log.Printf("Error querying review cycle completion: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao calcular conclusão do ciclo",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    models.BuildCycleCompletion(*cycle, statuses, time.Now()),
+	})
+}
@@ -0,0 +1,501 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// requireCompanyRole carrega o papel do parâmetro :id; quando ok é false a resposta de erro já foi enviada
+func requireCompanyRole(c *fiber.Ctx) (*models.CompanyRole, bool) {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID do papel inválido",
+		})
+		return nil, false
+	}
+
+	role, err := middleware.Repositories(c).Roles.Get(c.UserContext(), roleID)
+	if err == repository.ErrNotFound {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Papel não encontrado",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying company role: %v", err)
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar papel",
+		})
+		return nil, false
+	}
+	return role, true
+}
+
+// normalizePermissions valida as permissões de um papel personalizado: precisam existir no
+// registro, ser atribuíveis e já pertencer a quem está editando, para que ninguém conceda
+// mais do que possui
+func normalizePermissions(user *middleware.JWTClaims, permissions []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, key := range permissions {
+		key = strings.TrimSpace(key)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		permission, ok := models.LookupPermission(key)
+		if !ok {
+			return nil, fmt.Errorf("Permissão desconhecida: %s", key)
+		}
+		if !permission.Assignable {
+			return nil, fmt.Errorf("A permissão %s é exclusiva do administrador do sistema", key)
+		}
+		if !user.HasPermission(key) {
+			return nil, fmt.Errorf("Você não pode conceder uma permissão que não possui: %s", key)
+		}
+		normalized = append(normalized, key)
+	}
+	return normalized, nil
+}
+
+// userPermissions devolve as permissões efetivas do usuário: as do papel personalizado
+// atribuído a ele ou, sem papel personalizado, as do papel padrão
+func userPermissions(c *fiber.Ctx, user *models.User) ([]string, error) {
+	role, err := middleware.Repositories(c).Roles.ForUser(c.UserContext(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if role != nil {
+		return role.Permissions, nil
+	}
+	return models.RolePermissions[user.Role], nil
+}
+
+// ListPermissions devolve o registro de permissões, os papéis padrão e as permissões do usuário logado
+func ListPermissions(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"permissions":     models.Permissions,
+			"roles":           models.RolePermissions,
+			"userPermissions": user.Permissions,
+		},
+	})
+}
+
+// ListCompanyRoles lista os papéis personalizados da empresa (admins podem filtrar por ?companyId)
+func ListCompanyRoles(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	var companyID *uuid.UUID
+	if user.HasPermission(models.PermCompaniesAll) {
+		if filter := c.Query("companyId"); filter != "" {
+			parsed, err := uuid.Parse(filter)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"status":  "error",
+					"message": "ID da empresa inválido",
+				})
+			}
+			companyID = &parsed
+		}
+	} else {
+		if user.CompanyID == nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Usuário deve estar associado a uma empresa",
+			})
+		}
+		companyID = user.CompanyID
+	}
+
+	roles, err := middleware.Repositories(c).Roles.List(c.UserContext(), companyID)
+	if err != nil {
+		log.Printf("Error querying company roles: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar papéis",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   roles,
+	})
+}
+
+// CreateCompanyRole cria um papel personalizado a partir de permissões do registro
+func CreateCompanyRole(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	var req models.CreateCompanyRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	var companyID uuid.UUID
+	if user.HasPermission(models.PermCompaniesAll) {
+		if req.CompanyID == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Admin deve especificar uma empresa para o papel",
+			})
+		}
+		companyID = *req.CompanyID
+	} else {
+		if user.CompanyID == nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Usuário deve estar associado a uma empresa",
+			})
+		}
+		companyID = *user.CompanyID
+	}
+
+	permissions, err := normalizePermissions(user, req.Permissions)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	repos := middleware.Repositories(c)
+
+	companyActive, err := repos.Companies.IsActive(c.UserContext(), companyID)
+	if err != nil || !companyActive {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Empresa não encontrada ou inativa",
+		})
+	}
+
+	created := models.CompanyRole{
+		CompanyID:   companyID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Permissions: pq.StringArray(permissions),
+		CreatedBy:   &user.UserID,
+	}
+	err = repos.Roles.Create(c.UserContext(), &created)
+	if err == repository.ErrDuplicate {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Já existe um papel com este nome na empresa",
+		})
+	}
+	if err != nil {
+		log.Printf("Error creating company role: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao criar papel",
+		})
+	}
+
+	role, err := repos.Roles.Get(c.UserContext(), created.ID)
+	if err != nil {
+		log.Printf("Error querying company role: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar papel",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Papel criado com sucesso",
+		"data":    role,
+	})
+}
+
+// UpdateCompanyRole altera nome, descrição ou permissões; a mudança vale na próxima requisição
+// de cada usuário com o papel
+func UpdateCompanyRole(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	role, ok := requireCompanyRole(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.UpdateCompanyRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	// Editar um papel exige possuir tudo o que ele concede, inclusive as permissões mantidas
+	if _, err := normalizePermissions(user, role.Permissions); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	if req.Name != nil {
+		role.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		permissions, err := normalizePermissions(user, req.Permissions)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": err.Error(),
+			})
+		}
+		role.Permissions = permissions
+	}
+
+	roles := middleware.Repositories(c).Roles
+
+	role.UpdatedBy = &user.UserID
+	err := roles.Update(c.UserContext(), role)
+	if err == repository.ErrDuplicate {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Já existe um papel com este nome na empresa",
+		})
+	}
+	if err != nil {
+		log.Printf("Error updating company role: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao atualizar papel",
+		})
+	}
+
+	updated, err := roles.Get(c.UserContext(), role.ID)
+	if err != nil {
+		log.Printf("Error querying company role: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar papel",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Papel atualizado com sucesso",
+		"data":    updated,
+	})
+}
+
+// DeleteCompanyRole remove o papel; os usuários com ele voltam às permissões do papel padrão
+func DeleteCompanyRole(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	role, ok := requireCompanyRole(c)
+	if !ok {
+		return nil
+	}
+
+	if _, err := normalizePermissions(user, role.Permissions); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	if err := middleware.Repositories(c).Roles.Delete(c.UserContext(), role.ID); err != nil {
+		log.Printf("Error deleting company role: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao remover papel",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Papel removido com sucesso",
+	})
+}
+
+// AssignUserRole atribui um papel personalizado ao usuário ou, com roleId nulo, devolve o
+// usuário às permissões do seu papel padrão
+func AssignUserRole(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	targetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID do usuário inválido",
+		})
+	}
+
+	var req models.AssignUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	target, ok := scopedUser(c, targetID)
+	if !ok {
+		return nil
+	}
+
+	if target.ID == user.UserID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Você não pode alterar o próprio papel",
+		})
+	}
+
+	// Quem atribui ou remove precisa possuir tudo o que o usuário passa a ter ou deixa de ter
+	roles := middleware.Repositories(c).Roles
+
+	current, err := roles.ForUser(c.UserContext(), target.ID)
+	if err != nil {
+		log.Printf("Error querying user role: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar papel do usuário",
+		})
+	}
+	var previousRoleID *uuid.UUID
+	if current != nil {
+		if _, err := normalizePermissions(user, current.Permissions); err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":  "error",
+				"message": err.Error(),
+			})
+		}
+		previousRoleID = &current.ID
+	}
+
+	if req.RoleID == nil {
+		// Sem papel personalizado o usuário volta a ter todas as permissões do papel padrão
+		if _, err := normalizePermissions(user, models.RolePermissions[target.Role]); err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":  "error",
+				"message": err.Error(),
+			})
+		}
+
+		if err := roles.Assign(c.UserContext(), target.ID, nil, user.UserID); err != nil {
+			log.Printf("Error removing user role: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Erro ao remover papel do usuário",
+			})
+		}
+
+		audit.Annotate(c, audit.Entry{
+			Action:     models.AuditActionAssignRole,
+			EntityType: "users",
+			EntityID:   target.ID.String(),
+			CompanyID:  target.CompanyID,
+			Before:     fiber.Map{"role": target.Role, "customRoleId": previousRoleID},
+			After:      fiber.Map{"role": target.Role, "customRoleId": nil},
+		})
+
+		return c.JSON(fiber.Map{
+			"status":  "success",
+			"message": "Usuário voltou ao papel padrão",
+		})
+	}
+
+	allowedBase := false
+	for _, base := range models.CustomRoleBaseRoles {
+		if target.Role == base {
+			allowedBase = true
+		}
+	}
+	if !allowedBase {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Papéis personalizados só podem ser atribuídos a gerentes e usuários",
+		})
+	}
+
+	role, err := roles.Get(c.UserContext(), *req.RoleID)
+	if err == nil && (target.CompanyID == nil || role.CompanyID != *target.CompanyID) {
+		err = repository.ErrNotFound
+	}
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Papel não encontrado na empresa do usuário",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying company role: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar papel",
+		})
+	}
+
+	if _, err := normalizePermissions(user, role.Permissions); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	if err := roles.Assign(c.UserContext(), target.ID, &role.ID, user.UserID); err != nil {
+		log.Printf("Error assigning user role: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao atribuir papel",
+		})
+	}
+
+	audit.Annotate(c, audit.Entry{
+		Action:     models.AuditActionAssignRole,
+		EntityType: "users",
+		EntityID:   target.ID.String(),
+		CompanyID:  target.CompanyID,
+		Before:     fiber.Map{"role": target.Role, "customRoleId": previousRoleID},
+		After:      fiber.Map{"role": target.Role, "customRoleId": role.ID},
+	})
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Papel atribuído com sucesso",
+		"data":    role,
+	})
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// isValidMonth verifica o formato YYYY-MM usado em relatórios e autoavaliações
+func isValidMonth(month string) bool {
+	_, err := time.Parse("2006-01", month)
+	return err == nil
+}
+
+func listSelfAssessments(c *fiber.Ctx, developerID uuid.UUID) error {
+	assessments, err := middleware.Repositories(c).SelfAssessments.ListByDeveloper(c.UserContext(), developerID)
+	if err != nil {
+		log.Printf("Error querying self assessments: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar autoavaliações",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    assessments,
+	})
+}
+
+func getSelfAssessment(c *fiber.Ctx, developerID uuid.UUID, month string) error {
+	assessment, err := middleware.Repositories(c).SelfAssessments.Get(c.UserContext(), developerID, month)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Autoavaliação não encontrada",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying self assessment: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar autoavaliação",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    assessment,
+	})
+}
+
+// compareSelfAssessment monta a comparação gerente x autoavaliação de um mês.
+// Com publishedOnly, relatórios do gerente ainda em rascunho são tratados como inexistentes.
+func compareSelfAssessment(c *fiber.Ctx, developerID uuid.UUID, month string, publishedOnly bool) error {
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+	report, err := repos.Reports.GetForMonth(ctx, developerID, month)
+	if err == nil && publishedOnly && !models.IsReportPublished(report.Status) {
+		err = repository.ErrNotFound
+	}
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Relatório do gerente não encontrado para este mês",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying performance report: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar relatório",
+		})
+	}
+
+	assessment, err := repos.SelfAssessments.Get(ctx, developerID, month)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Autoavaliação não encontrada para este mês",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying self assessment: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar autoavaliação",
+		})
+	}
+
+	// As perguntas e rótulos seguem o template usado pelo gerente
+	templateVersionID := assessment.TemplateVersionID
+	if report.TemplateVersionID != nil {
+		templateVersionID = *report.TemplateVersionID
+	}
+	template, err := repos.Templates.Version(ctx, templateVersionID)
+	if err != nil {
+		log.Printf("Error loading evaluation template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao carregar template de avaliação",
+		})
+	}
+
+	// Rascunhos do gerente ainda não têm média calculada
+	var overallDelta *float64
+	if models.IsReportPublished(report.Status) {
+		delta := math.Round((report.WeightedAverageScore-assessment.WeightedAverageScore)*100) / 100
+		overallDelta = &delta
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"developerId":       developerID,
+			"month":             month,
+			"reportId":          report.ID,
+			"reportStatus":      report.Status,
+			"selfAssessmentId":  assessment.ID,
+			"templateVersionId": templateVersionID,
+			"managerScore":      report.WeightedAverageScore,
+			"selfScore":         assessment.WeightedAverageScore,
+			"delta":             overallDelta,
+			"questions":         template.CompareScores(report.QuestionScores, assessment.QuestionScores),
+		},
+	})
+}
+
+// SubmitMySelfAssessment cria ou atualiza a autoavaliação do desenvolvedor logado para o mês.
+// Depois que o gerente envia o relatório do mês, a autoavaliação não pode mais ser alterada.
+func SubmitMySelfAssessment(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	month := c.Params("month")
+	if !isValidMonth(month) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Mês inválido, use o formato YYYY-MM",
+		})
+	}
+
+	var req models.SubmitSelfAssessmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	developer, ok := requireLinkedDeveloper(c)
+	if !ok {
+		return nil
+	}
+
+	repos := middleware.Repositories(c)
+	ctx := c.UserContext()
+	report, err := repos.Reports.GetForMonth(ctx, developer.ID, month)
+	if err != nil && err != repository.ErrNotFound {
+		log.Printf("Error checking performance report: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao verificar relatório do mês",
+		})
+	}
+	if err == nil && report.Status != models.ReportStatusDraft {
+		return c.Status(409).JSON(fiber.Map{
+			"error":   true,
+			"message": "A avaliação do gerente para este mês já foi enviada",
+		})
+	}
+
+	// Se o gerente já começou um rascunho, a autoavaliação usa o mesmo template
+	var template *models.EvaluationTemplate
+	if report != nil && report.TemplateVersionID != nil {
+		template, err = repos.Templates.Version(ctx, *report.TemplateVersionID)
+	} else {
+		template, err = repos.Templates.Active(ctx, developer.CompanyID)
+	}
+	if err != nil {
+		log.Printf("Error loading evaluation template: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao carregar template de avaliação",
+		})
+	}
+
+	categoryScores, weightedAverageScore, err := template.Score(req.QuestionScores)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	assessment := models.SelfAssessment{
+		DeveloperID:          developer.ID,
+		Month:                month,
+		TemplateVersionID:    template.VersionID,
+		QuestionScores:       req.QuestionScores,
+		CategoryScores:       categoryScores,
+		WeightedAverageScore: weightedAverageScore,
+		Comments:             req.Comments,
+		SubmittedBy:          &user.UserID,
+	}
+	err = repos.SelfAssessments.Save(ctx, &assessment)
+	if err != nil {
+		log.Printf("Error saving self assessment: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao salvar autoavaliação",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    assessment,
+	})
+}
+
+// GetMySelfAssessments lista as autoavaliações do desenvolvedor logado
+func GetMySelfAssessments(c *fiber.Ctx) error {
+	developer, ok := requireLinkedDeveloper(c)
+	if !ok {
+		return nil
+	}
+	return listSelfAssessments(c, developer.ID)
+}
+
+// GetMySelfAssessment retorna a autoavaliação do desenvolvedor logado em um mês
+func GetMySelfAssessment(c *fiber.Ctx) error {
+	developer, ok := requireLinkedDeveloper(c)
+	if !ok {
+		return nil
+	}
+	return getSelfAssessment(c, developer.ID, c.Params("month"))
+}
+
+// GetMySelfAssessmentComparison compara a autoavaliação com o relatório enviado pelo gerente
+func GetMySelfAssessmentComparison(c *fiber.Ctx) error {
+	developer, ok := requireLinkedDeveloper(c)
+	if !ok {
+		return nil
+	}
+	return compareSelfAssessment(c, developer.ID, c.Params("month"), true)
+}
+
+// requireScopedDeveloper carrega o desenvolvedor do parâmetro :id respeitando a empresa do
+// usuário; quando ok é false a resposta de erro já foi enviada
+func requireScopedDeveloper(c *fiber.Ctx) (*models.Developer, bool) {
+	developerUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+		return nil, false
+	}
+
+	developer, err := middleware.Repositories(c).Developers.Get(c.UserContext(), developerUUID)
+	if err == repository.ErrNotFound {
+		c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Desenvolvedor não encontrado",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying developer: %v", err)
+		c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar desenvolvedor",
+		})
+		return nil, false
+	}
+	return developer, true
+}
+
+// GetDeveloperSelfAssessments lista as autoavaliações de um desenvolvedor
+func GetDeveloperSelfAssessments(c *fiber.Ctx) error {
+	developer, ok := requireScopedDeveloper(c)
+	if !ok {
+		return nil
+	}
+	return listSelfAssessments(c, developer.ID)
+}
+
+// GetDeveloperSelfAssessment retorna a autoavaliação de um desenvolvedor em um mês
+func GetDeveloperSelfAssessment(c *fiber.Ctx) error {
+	developer, ok := requireScopedDeveloper(c)
+	if !ok {
+		return nil
+	}
+	return getSelfAssessment(c, developer.ID, c.Params("month"))
+}
+
+// GetDeveloperSelfAssessmentComparison retorna as diferenças por pergunta entre a nota do
+// gerente e a autoavaliação, das maiores para as menores. Rascunhos do gerente só entram para
+// quem pode ver rascunhos, como na listagem de relatórios.
+func GetDeveloperSelfAssessmentComparison(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+	developer, ok := requireScopedDeveloper(c)
+	if !ok {
+		return nil
+	}
+	return compareSelfAssessment(c, developer.ID, c.Params("month"), !canSeeDraftReports(user))
+}
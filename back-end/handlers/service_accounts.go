@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// requireServiceAccount carrega a conta de serviço do parâmetro :id;
+// quando ok é false a resposta de erro já foi enviada
+func requireServiceAccount(c *fiber.Ctx) (*models.ServiceAccount, bool) {
+	accountID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID da conta de serviço inválido",
+		})
+		return nil, false
+	}
+
+	account, err := middleware.Repositories(c).ServiceAccounts.Get(c.UserContext(), accountID)
+	if err == repository.ErrNotFound {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Conta de serviço não encontrada",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying service account: %v", err)
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar conta de serviço",
+		})
+		return nil, false
+	}
+	return account, true
+}
+
+func serviceAccountOwner(account *models.ServiceAccount) apiTokenOwner {
+	return apiTokenOwner{
+		UserID:    account.ID,
+		CompanyID: &account.CompanyID,
+		Email:     account.ID.String() + "@" + models.ServiceAccountEmailDomain,
+	}
+}
+
+// ListServiceAccounts lista as contas de serviço da empresa (admins podem filtrar por ?companyId)
+func ListServiceAccounts(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	var companyID *uuid.UUID
+	if user.HasPermission(models.PermCompaniesAll) {
+		if filter := c.Query("companyId"); filter != "" {
+			parsed, err := uuid.Parse(filter)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"status":  "error",
+					"message": "ID da empresa inválido",
+				})
+			}
+			companyID = &parsed
+		}
+	} else {
+		if user.CompanyID == nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Usuário deve estar associado a uma empresa",
+			})
+		}
+		companyID = user.CompanyID
+	}
+
+	accounts, err := middleware.Repositories(c).ServiceAccounts.List(c.UserContext(), companyID)
+	if err != nil {
+		log.Printf("Error querying service accounts: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar contas de serviço",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   accounts,
+	})
+}
+
+// CreateServiceAccount cria um usuário não humano da empresa, que não faz login e só se
+// autentica pelos tokens de API emitidos para ele
+func CreateServiceAccount(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	var req models.CreateServiceAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if !user.CanGrantRole(req.Role) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Você não pode conceder um papel com permissões que não possui",
+		})
+	}
+
+	var companyID uuid.UUID
+	if user.HasPermission(models.PermCompaniesAll) {
+		if req.CompanyID == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Admin deve especificar uma empresa para a conta de serviço",
+			})
+		}
+		companyID = *req.CompanyID
+	} else {
+		if user.CompanyID == nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Manager deve estar associado a uma empresa",
+			})
+		}
+		companyID = *user.CompanyID
+	}
+
+	repos := middleware.Repositories(c)
+
+	companyActive, err := repos.Companies.IsActive(c.UserContext(), companyID)
+	if err != nil || !companyActive {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Empresa não encontrada ou inativa",
+		})
+	}
+
+	// Senha aleatória descartada: a conta nunca passa pelo login
+	placeholderPassword, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Error generating placeholder password: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao criar conta de serviço",
+		})
+	}
+	accountID := uuid.New()
+	accountUser := models.User{ID: accountID, Email: accountID.String() + "@" + models.ServiceAccountEmailDomain}
+	if err := accountUser.HashPassword(placeholderPassword); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao processar senha",
+		})
+	}
+
+	created := models.ServiceAccount{
+		CompanyID:   companyID,
+		Name:        req.Name,
+		Description: req.Description,
+		Role:        req.Role,
+		CreatedBy:   &user.UserID,
+	}
+	if err := repos.ServiceAccounts.Create(c.UserContext(), &created, &accountUser); err != nil {
+		log.Printf("Error creating service account: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao criar conta de serviço",
+		})
+	}
+
+	account, err := repos.ServiceAccounts.Get(c.UserContext(), accountID)
+	if err != nil {
+		log.Printf("Error querying service account: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar conta de serviço",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Conta de serviço criada com sucesso",
+		"data":    account,
+	})
+}
+
+// UpdateServiceAccount altera nome, descrição, papel ou status; desativar bloqueia todos os tokens
+func UpdateServiceAccount(c *fiber.Ctx) error {
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	account, ok := requireServiceAccount(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.UpdateServiceAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if !user.CanGrantRole(account.Role) || (req.Role != nil && !user.CanGrantRole(*req.Role)) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Você não pode conceder um papel com permissões que não possui",
+		})
+	}
+
+	if req.Name != nil {
+		account.Name = *req.Name
+	}
+	if req.Description != nil {
+		account.Description = *req.Description
+	}
+	if req.Role != nil {
+		account.Role = *req.Role
+	}
+	if req.IsActive != nil {
+		account.IsActive = *req.IsActive
+	}
+
+	accounts := middleware.Repositories(c).ServiceAccounts
+	if err := accounts.Update(c.UserContext(), account); err != nil {
+		log.Printf("Error updating service account: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao atualizar conta de serviço",
+		})
+	}
+
+	updated, err := accounts.Get(c.UserContext(), account.ID)
+	if err != nil {
+		log.Printf("Error querying service account: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar conta de serviço",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Conta de serviço atualizada com sucesso",
+		"data":    updated,
+	})
+}
+
+// DeleteServiceAccount remove a conta de serviço e todos os seus tokens; registros criados
+// por ela ficam sem autor
+func DeleteServiceAccount(c *fiber.Ctx) error {
+	account, ok := requireServiceAccount(c)
+	if !ok {
+		return nil
+	}
+
+	if err := middleware.Repositories(c).ServiceAccounts.Delete(c.UserContext(), account.ID); err != nil {
+		log.Printf("Error deleting service account: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao remover conta de serviço",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Conta de serviço removida com sucesso",
+	})
+}
+
+// ListServiceAccountTokens lista os tokens da conta de serviço
+func ListServiceAccountTokens(c *fiber.Ctx) error {
+	account, ok := requireServiceAccount(c)
+	if !ok {
+		return nil
+	}
+
+	tokens, err := middleware.Repositories(c).APITokens.List(c.UserContext(), account.ID)
+	if err != nil {
+		log.Printf("Error querying API tokens: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar tokens",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   tokens,
+	})
+}
+
+// CreateServiceAccountToken emite um token para a conta de serviço
+func CreateServiceAccountToken(c *fiber.Ctx) error {
+	account, ok := requireServiceAccount(c)
+	if !ok {
+		return nil
+	}
+
+	if !account.IsActive {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Conta de serviço inativa",
+		})
+	}
+
+	req, ok := parseCreateAPITokenRequest(c)
+	if !ok {
+		return nil
+	}
+
+	created, err := issueAPIToken(c, serviceAccountOwner(account), req)
+	if err != nil {
+		log.Printf("Error creating API token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao criar token",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Token criado. Copie-o agora: ele não será exibido novamente",
+		"data":    created,
+	})
+}
+
+// RevokeServiceAccountToken revoga um token da conta de serviço
+func RevokeServiceAccountToken(c *fiber.Ctx) error {
+	account, ok := requireServiceAccount(c)
+	if !ok {
+		return nil
+	}
+
+	token, ok := revokeAPIToken(c, serviceAccountOwner(account), "tokenId")
+	if !ok {
+		return nil
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Token revogado com sucesso",
+		"data":    token,
+	})
+}
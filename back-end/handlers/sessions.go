@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// startSession abre uma sessão para o usuário e devolve o access token e o refresh token
+func startSession(c *fiber.Ctx, user models.User) (*models.LoginResponse, error) {
+	session, err := middleware.Repositories(c).Sessions.Start(c.UserContext(), &user, c.Get(fiber.HeaderUserAgent), c.IP(), middleware.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return loginResponse(session)
+}
+
+// loginResponse gera o access token da sessão aberta ou renovada
+func loginResponse(session *repository.StartedSession) (*models.LoginResponse, error) {
+	token, err := middleware.GenerateJWT(session.User, session.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		Token:        token,
+		RefreshToken: session.RefreshToken,
+		ExpiresIn:    int(middleware.AccessTokenTTL.Seconds()),
+		User:         session.User,
+	}, nil
+}
+
+// RefreshToken troca um refresh token válido por um novo par de tokens (rotação).
+// Se um refresh token já usado for apresentado novamente, a sessão inteira é revogada.
+func RefreshToken(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	session, err := middleware.Repositories(c).Sessions.Refresh(c.UserContext(), utils.HashToken(req.RefreshToken),
+		c.Get(fiber.HeaderUserAgent), c.IP(), middleware.RefreshTokenTTL)
+	switch err {
+	case nil:
+	case repository.ErrNotFound:
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Refresh token inválido",
+		})
+	case repository.ErrTokenReused:
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Refresh token já utilizado. Faça login novamente",
+		})
+	case repository.ErrSessionExpired:
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Sessão encerrada ou expirada",
+		})
+	case repository.ErrInactiveUser:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Usuário inativo",
+		})
+	default:
+		log.Printf("Error refreshing session: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro interno do servidor",
+		})
+	}
+
+	response, err := loginResponse(session)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao gerar token",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   response,
+	})
+}
+
+// Logout encerra a sessão do token atual
+func Logout(c *fiber.Ctx) error {
+	userClaims := c.Locals("user").(*middleware.JWTClaims)
+
+	if err := middleware.Repositories(c).Sessions.Revoke(c.UserContext(), userClaims.SessionID, models.SessionRevokedLogout); err != nil {
+		log.Printf("Error revoking session: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao encerrar sessão",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Logout realizado com sucesso",
+	})
+}
+
+// loadActiveSessions lista as sessões ativas do usuário, das mais recentes para as mais antigas
+func loadActiveSessions(c *fiber.Ctx, userID, currentSessionID uuid.UUID) ([]models.AuthSession, error) {
+	sessions, err := middleware.Repositories(c).Sessions.ListActive(c.UserContext(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		userAgent := ""
+		if sessions[i].UserAgent != nil {
+			userAgent = *sessions[i].UserAgent
+		}
+		sessions[i].Device = utils.DescribeDevice(userAgent)
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// ListMySessions lista onde o usuário logado tem sessões ativas
+func ListMySessions(c *fiber.Ctx) error {
+	userClaims := c.Locals("user").(*middleware.JWTClaims)
+
+	sessions, err := loadActiveSessions(c, userClaims.UserID, userClaims.SessionID)
+	if err != nil {
+		log.Printf("Error querying sessions: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar sessões",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   sessions,
+	})
+}
+
+// RevokeMySession encerra uma sessão do próprio usuário (encerrar a sessão atual equivale ao logout)
+func RevokeMySession(c *fiber.Ctx) error {
+	userClaims := c.Locals("user").(*middleware.JWTClaims)
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID da sessão inválido",
+		})
+	}
+
+	err = middleware.Repositories(c).Sessions.RevokeOwn(c.UserContext(), sessionID, userClaims.UserID)
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Sessão não encontrada",
+		})
+	}
+	if err != nil {
+		log.Printf("Error revoking session: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao encerrar sessão",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Sessão encerrada com sucesso",
+	})
+}
+
+// ListUserSessions lista as sessões ativas de um usuário (admin)
+func ListUserSessions(c *fiber.Ctx) error {
+	userClaims := c.Locals("user").(*middleware.JWTClaims)
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID do usuário inválido",
+		})
+	}
+
+	if _, ok := scopedUser(c, userID); !ok {
+		return nil
+	}
+
+	sessions, err := loadActiveSessions(c, userID, userClaims.SessionID)
+	if err != nil {
+		log.Printf("Error querying sessions: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar sessões",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   sessions,
+	})
+}
+
+// TerminateUserSessions encerra todas as sessões de um usuário (admin)
+func TerminateUserSessions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID do usuário inválido",
+		})
+	}
+
+	if _, ok := scopedUser(c, userID); !ok {
+		return nil
+	}
+
+	revoked, err := middleware.Repositories(c).Sessions.RevokeAll(c.UserContext(), userID, models.SessionRevokedByAdmin)
+	if err != nil {
+		log.Printf("Error revoking sessions: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao encerrar sessões do usuário",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Sessões encerradas com sucesso",
+		"data": fiber.Map{
+			"revokedSessions": revoked,
+		},
+	})
+}
+
+// scopedUser busca o usuário administrado, que precisa estar no escopo de quem administra.
+// Quando ok é false a resposta de erro já foi enviada.
+func scopedUser(c *fiber.Ctx, userID uuid.UUID) (*models.User, bool) {
+	user, err := middleware.Repositories(c).Users.Get(c.UserContext(), userID)
+	if err == repository.ErrNotFound {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Usuário não encontrado",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error querying user: %v", err)
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar usuário",
+		})
+		return nil, false
+	}
+	return user, true
+}
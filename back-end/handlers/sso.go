@@ -0,0 +1,521 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"tivix-performance-tracker-backend/config"
+	"tivix-performance-tracker-backend/mail"
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/oidc"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// Erros do provisionamento SSO; o código vai na query string do redirecionamento ao front-end
+var (
+	errSSOEmailMissing     = errors.New("email_missing")
+	errSSOEmailNotVerified = errors.New("email_not_verified")
+	errSSODomainNotAllowed = errors.New("domain_not_allowed")
+	errSSOCompanyMismatch  = errors.New("company_mismatch")
+	errSSOUserInactive     = errors.New("user_inactive")
+)
+
+func ssoClient(cfg *models.CompanySSOConfig) oidc.Client {
+	return oidc.Client{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  strings.TrimRight(config.LoadConfig().APIURL, "/") + "/api/v1/auth/sso/callback",
+	}
+}
+
+// ssoRedirect devolve o navegador para a página de callback do front-end
+func ssoRedirect(c *fiber.Ctx, params url.Values) error {
+	return c.Redirect(strings.TrimRight(mail.AppURL(), "/")+"/sso/callback?"+params.Encode(), fiber.StatusFound)
+}
+
+func ssoRedirectError(c *fiber.Ctx, code string) error {
+	return ssoRedirect(c, url.Values{"error": {code}})
+}
+
+// DiscoverSSO indica se o domínio do email pertence a uma empresa com SSO ativo (rota pública)
+func DiscoverSSO(c *fiber.Ctx) error {
+	email := strings.ToLower(strings.TrimSpace(c.Query("email")))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Email inválido",
+		})
+	}
+
+	companyID, err := middleware.Repositories(c).SSO.CompanyForDomain(c.UserContext(), email[at+1:])
+	if err == repository.ErrNotFound {
+		return c.JSON(fiber.Map{
+			"status": "success",
+			"data":   fiber.Map{"ssoEnabled": false},
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying SSO config: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"ssoEnabled": true,
+			"companyId":  companyID,
+			"loginUrl":   "/api/v1/auth/sso/" + companyID.String() + "/start",
+		},
+	})
+}
+
+// StartSSOLogin inicia o login SSO da empresa redirecionando para o provedor OIDC
+func StartSSOLogin(c *fiber.Ctx) error {
+	companyID, err := uuid.Parse(c.Params("companyId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID da empresa inválido",
+		})
+	}
+
+	sso := middleware.Repositories(c).SSO
+
+	cfg, err := sso.Config(c.UserContext(), companyID)
+	if err == repository.ErrNotFound || (err == nil && !cfg.IsEnabled) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "SSO não configurado para esta empresa",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying SSO config: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+
+	state, stateHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Error generating SSO state: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+	nonce, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Error generating SSO nonce: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+	codeVerifier, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Error generating PKCE verifier: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+
+	authURL, err := ssoClient(cfg).AuthCodeURL(c.UserContext(), state, nonce, codeVerifier)
+	if err != nil {
+		log.Printf("Error discovering OIDC provider %s: %v", cfg.Issuer, err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error":   true,
+			"message": "Provedor de identidade indisponível",
+		})
+	}
+
+	if err := sso.StartLogin(c.UserContext(), companyID, stateHash, nonce, codeVerifier); err != nil {
+		log.Printf("Error saving SSO state: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// SSOCallback recebe o retorno do provedor, valida o ID token, provisiona o usuário e
+// devolve o navegador ao front-end com um código de uso único para obter os tokens
+func SSOCallback(c *fiber.Ctx) error {
+	if providerError := c.Query("error"); providerError != "" {
+		log.Printf("OIDC provider returned error: %s %s", providerError, c.Query("error_description"))
+		return ssoRedirectError(c, "provider_denied")
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		return ssoRedirectError(c, "invalid_request")
+	}
+
+	sso := middleware.Repositories(c).SSO
+
+	// O state é consumido antes da troca do código para não poder ser reutilizado
+	loginState, err := sso.ConsumeState(c.UserContext(), utils.HashToken(state))
+	if err == repository.ErrNotFound {
+		return ssoRedirectError(c, "invalid_state")
+	}
+	if err != nil {
+		log.Printf("Error consuming SSO state: %v", err)
+		return ssoRedirectError(c, "server_error")
+	}
+
+	cfg, err := sso.Config(c.UserContext(), loginState.CompanyID)
+	if err != nil || !cfg.IsEnabled {
+		return ssoRedirectError(c, "sso_disabled")
+	}
+
+	claims, err := ssoClient(cfg).Exchange(c.UserContext(), code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		log.Printf("Error exchanging OIDC code: %v", err)
+		return ssoRedirectError(c, "provider_error")
+	}
+
+	user, err := provisionSSOUser(c, cfg, claims)
+	if err != nil {
+		log.Printf("SSO login rejected for %s: %v", claims.Email, err)
+		switch err {
+		case errSSOEmailMissing, errSSOEmailNotVerified, errSSODomainNotAllowed, errSSOCompanyMismatch, errSSOUserInactive:
+			return ssoRedirectError(c, err.Error())
+		}
+		return ssoRedirectError(c, "server_error")
+	}
+
+	loginCode, loginCodeHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Error generating SSO login code: %v", err)
+		return ssoRedirectError(c, "server_error")
+	}
+	if err := sso.IssueLoginCode(c.UserContext(), loginState.ID, user.ID, loginCodeHash); err != nil {
+		log.Printf("Error saving SSO login code: %v", err)
+		return ssoRedirectError(c, "server_error")
+	}
+
+	return ssoRedirect(c, url.Values{"code": {loginCode}})
+}
+
+// provisionSSOUser encontra o usuário pela identidade do provedor ou pelo email e, se ainda
+// não existir, cria a conta na empresa com o papel padrão da configuração (just-in-time)
+func provisionSSOUser(c *fiber.Ctx, cfg *models.CompanySSOConfig, claims *oidc.IDTokenClaims) (*models.User, error) {
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return nil, errSSOEmailMissing
+	}
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return nil, errSSOEmailNotVerified
+	}
+	if !cfg.AllowsEmail(email[at+1:]) {
+		return nil, errSSODomainNotAllowed
+	}
+
+	newUser, err := newSSOUser(cfg, email, claims.Name)
+	if err != nil {
+		return nil, err
+	}
+	event := securityEvent(c, models.SecurityEventSSOProvisioned, &newUser.ID, newUser.CompanyID, email, nil, models.JSONB{
+		"issuer": cfg.Issuer,
+		"role":   newUser.Role,
+	})
+
+	identity := repository.SSOIdentity{Issuer: cfg.Issuer, Subject: claims.Subject}
+	user, err := middleware.Repositories(c).SSO.Provision(c.UserContext(), identity, newUser, event)
+	switch err {
+	case repository.ErrOtherCompany:
+		return nil, errSSOCompanyMismatch
+	case repository.ErrInactiveUser:
+		return nil, errSSOUserInactive
+	}
+	return user, err
+}
+
+// newSSOUser monta a conta criada no primeiro login SSO, com o papel padrão da configuração
+func newSSOUser(cfg *models.CompanySSOConfig, email, name string) (*models.User, error) {
+	if strings.TrimSpace(name) == "" {
+		name = email
+	}
+
+	// Contas SSO não usam senha local; guarda uma senha aleatória que ninguém conhece
+	placeholderPassword, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	companyID := cfg.CompanyID
+	user := models.User{
+		ID:        uuid.New(),
+		Email:     email,
+		Name:      name,
+		Role:      cfg.DefaultRole,
+		CompanyID: &companyID,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := user.HashPassword(placeholderPassword); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ExchangeSSOCode troca o código de uso único do callback pelo access token e refresh token
+// ou, para usuários com 2FA ativo, pelo desafio de /auth/mfa/verify
+func ExchangeSSOCode(c *fiber.Ctx) error {
+	var req models.SSOExchangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	repos := middleware.Repositories(c)
+
+	userID, err := repos.SSO.ExchangeLoginCode(c.UserContext(), utils.HashToken(req.Code))
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Código de login inválido ou expirado",
+		})
+	}
+	if err != nil {
+		log.Printf("Error consuming SSO login code: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+
+	user, err := repos.Accounts.Get(c.UserContext(), userID)
+	if err != nil {
+		log.Printf("Error querying user: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+	if !user.IsActive {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Usuário inativo",
+		})
+	}
+
+	// O provedor autentica apenas o primeiro fator: com 2FA ativo o código de login só libera
+	// o mesmo desafio do login por senha
+	mfaEnabled, err := userHasMFA(c, user.ID)
+	if err != nil {
+		log.Printf("Error querying MFA: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro interno do servidor",
+		})
+	}
+	if mfaEnabled {
+		return respondMFAChallenge(c, user.ID)
+	}
+
+	session, err := startSession(c, *user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao gerar token",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Login realizado com sucesso",
+		"data":    session,
+	})
+}
+
+// GetCompanySSOConfig retorna a configuração OIDC da empresa, sem o client secret (admin)
+func GetCompanySSOConfig(c *fiber.Ctx) error {
+	companyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID da empresa inválido",
+		})
+	}
+
+	if _, ok := scopedCompany(c, companyID); !ok {
+		return nil
+	}
+
+	cfg, err := middleware.Repositories(c).SSO.Config(c.UserContext(), companyID)
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "SSO não configurado para esta empresa",
+		})
+	}
+	if err != nil {
+		log.Printf("Error querying SSO config: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao buscar configuração de SSO",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   cfg,
+	})
+}
+
+// UpdateCompanySSOConfig cria ou substitui a configuração OIDC da empresa (admin). O issuer
+// é validado pela descoberta antes de salvar.
+func UpdateCompanySSOConfig(c *fiber.Ctx) error {
+	currentUser := c.Locals("user").(*middleware.JWTClaims)
+	companyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID da empresa inválido",
+		})
+	}
+
+	var req models.UpdateCompanySSOConfigRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados inválidos",
+		})
+	}
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Dados de entrada inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if _, ok := scopedCompany(c, companyID); !ok {
+		return nil
+	}
+
+	sso := middleware.Repositories(c).SSO
+
+	clientSecret := req.ClientSecret
+	if clientSecret == "" {
+		current, err := sso.Config(c.UserContext(), companyID)
+		if err == repository.ErrNotFound {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Client secret é obrigatório na primeira configuração",
+			})
+		}
+		if err != nil {
+			log.Printf("Error querying SSO config: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Erro ao buscar configuração de SSO",
+			})
+		}
+		clientSecret = current.ClientSecret
+	}
+
+	issuer := strings.TrimRight(req.Issuer, "/")
+	if _, err := oidc.Discover(c.UserContext(), issuer); err != nil {
+		log.Printf("Error discovering OIDC provider %s: %v", issuer, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Não foi possível validar o provedor OIDC",
+			"details": err.Error(),
+		})
+	}
+
+	domains := pq.StringArray{}
+	for _, domain := range req.AllowedEmailDomains {
+		domains = append(domains, strings.ToLower(strings.TrimSpace(domain)))
+	}
+	isEnabled := true
+	if req.IsEnabled != nil {
+		isEnabled = *req.IsEnabled
+	}
+
+	cfg := models.CompanySSOConfig{
+		CompanyID:           companyID,
+		Issuer:              issuer,
+		ClientID:            req.ClientID,
+		ClientSecret:        clientSecret,
+		AllowedEmailDomains: domains,
+		DefaultRole:         req.DefaultRole,
+		IsEnabled:           isEnabled,
+		UpdatedBy:           &currentUser.UserID,
+	}
+	if err := sso.SaveConfig(c.UserContext(), &cfg); err != nil {
+		log.Printf("Error saving SSO config: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao salvar configuração de SSO",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Configuração de SSO salva com sucesso",
+		"data":    cfg,
+	})
+}
+
+// DeleteCompanySSOConfig remove o SSO da empresa; usuários voltam a depender de senha (admin)
+func DeleteCompanySSOConfig(c *fiber.Ctx) error {
+	companyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID da empresa inválido",
+		})
+	}
+
+	err = middleware.Repositories(c).SSO.DeleteConfig(c.UserContext(), companyID)
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "SSO não configurado para esta empresa",
+		})
+	}
+	if err != nil {
+		log.Printf("Error deleting SSO config: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Erro ao remover configuração de SSO",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Configuração de SSO removida com sucesso",
+	})
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// requireAccessibleTeam valida o parâmetro :id do time; quando ok é false a resposta de erro já foi enviada
+func requireAccessibleTeam(c *fiber.Ctx) (uuid.UUID, bool) {
+	teamID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID inválido",
+		})
+		return uuid.Nil, false
+	}
+
+	_, err = middleware.Repositories(c).Teams.GetAccessible(c.UserContext(), teamID)
+	if err == repository.ErrNotFound {
+		c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Time não encontrado",
+		})
+		return uuid.Nil, false
+	}
+	if err != nil {
+		log.Printf("Error checking team access: %v", err)
+		c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar time",
+		})
+		return uuid.Nil, false
+	}
+	return teamID, true
+}
+
+// GetTeamManagers lista os usuários atribuídos ao time
+func GetTeamManagers(c *fiber.Ctx) error {
+	teamID, ok := requireAccessibleTeam(c)
+	if !ok {
+		return nil
+	}
+
+	managers, err := middleware.Repositories(c).Teams.ListManagers(c.UserContext(), teamID)
+	if err != nil {
+		log.Printf("Error querying team managers: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar gerentes do time",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    managers,
+	})
+}
+
+// AssignTeamManager atribui um gerente ou usuário da mesma empresa ao time
+func AssignTeamManager(c *fiber.Ctx) error {
+	repos := middleware.Repositories(c)
+	user := c.Locals("user").(*middleware.JWTClaims)
+
+	teamID, ok := requireAccessibleTeam(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.AssignTeamManagerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados inválidos",
+		})
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Dados de entrada inválidos",
+		})
+	}
+
+	eligible, err := repos.Teams.IsEligibleManager(c.UserContext(), teamID, req.UserID)
+	if err != nil {
+		log.Printf("Error querying user: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao buscar usuário",
+		})
+	}
+	if !eligible {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "Usuário não encontrado na empresa do time ou com papel que não recebe times",
+		})
+	}
+
+	if err := repos.Teams.AddManager(c.UserContext(), teamID, req.UserID, user.UserID); err != nil {
+		log.Printf("Error assigning team manager: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao atribuir gerente ao time",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Usuário atribuído ao time com sucesso",
+	})
+}
+
+// RemoveTeamManager remove a atribuição do usuário ao time
+func RemoveTeamManager(c *fiber.Ctx) error {
+	teamID, ok := requireAccessibleTeam(c)
+	if !ok {
+		return nil
+	}
+
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": "ID do usuário inválido",
+		})
+	}
+
+	err = middleware.Repositories(c).Teams.RemoveManager(c.UserContext(), teamID, userID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Usuário não está atribuído a este time",
+		})
+	}
+	if err != nil {
+		log.Printf("Error removing team manager: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"error":   true,
+			"message": "Erro ao remover gerente do time",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Usuário removido do time com sucesso",
+	})
+}
@@ -1,51 +1,38 @@
 package handlers
 
 import (
-	"database/sql"
-	"fmt"
 	"log"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
-	"tivix-performance-tracker-backend/database"
+	"tivix-performance-tracker-backend/audit"
 	"tivix-performance-tracker-backend/middleware"
 	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
 )
 
-// GetAllTeams retorna todos os times
+// GetAllTeams retorna os times da empresa paginados por cursor
 func GetAllTeams(c *fiber.Ctx) error {
 	user := c.Locals("user").(*middleware.JWTClaims)
-	
-	var query string
-	var args []interface{}
 
-	if user.Role == "admin" {
-		// Admins podem ver todos os times
-		query = `
-			SELECT id, name, description, color, company_id, created_at, updated_at 
-			FROM teams 
-			ORDER BY created_at DESC
-		`
-	} else {
-		// Managers e usuários só podem ver times da sua empresa
-		if user.CompanyID == nil {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   true,
-				"message": "Usuário deve estar associado a uma empresa",
-			})
-		}
-		
-		query = `
-			SELECT id, name, description, color, company_id, created_at, updated_at 
-			FROM teams 
-			WHERE company_id = $1
-			ORDER BY created_at DESC
-		`
-		args = append(args, *user.CompanyID)
+	// Managers e usuários só podem ver times da sua empresa
+	if !user.HasPermission(models.PermCompaniesAll) && user.CompanyID == nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Usuário deve estar associado a uma empresa",
+		})
 	}
 
-	rows, err := database.DB.Query(query, args...)
+	page, err := parsePage(c, repository.TeamSorting)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	teams, err := middleware.Repositories(c).Teams.List(c.UserContext(), page)
 	if err != nil {
 		log.Printf("Error querying teams: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -53,36 +40,14 @@ func GetAllTeams(c *fiber.Ctx) error {
 			"message": "Erro ao buscar times",
 		})
 	}
-	defer rows.Close()
 
-	var teams []models.Team
-	for rows.Next() {
-		var team models.Team
-		err := rows.Scan(
-			&team.ID,
-			&team.Name,
-			&team.Description,
-			&team.Color,
-			&team.CompanyID,
-			&team.CreatedAt,
-			&team.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning team: %v", err)
-			continue
-		}
-		teams = append(teams, team)
-	}
-
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    teams,
-	})
+	response := pageResponse(teams)
+	response["success"] = true
+	return c.JSON(response)
 }
 
 // GetTeamByID retorna um time específico por ID
 func GetTeamByID(c *fiber.Ctx) error {
-	user := c.Locals("user").(*middleware.JWTClaims)
 	id := c.Params("id")
 	teamUUID, err := uuid.Parse(id)
 	if err != nil {
@@ -92,24 +57,9 @@ func GetTeamByID(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		SELECT id, name, description, color, company_id, created_at, updated_at 
-		FROM teams 
-		WHERE id = $1
-	`
-
-	var team models.Team
-	err = database.DB.QueryRow(query, teamUUID).Scan(
-		&team.ID,
-		&team.Name,
-		&team.Description,
-		&team.Color,
-		&team.CompanyID,
-		&team.CreatedAt,
-		&team.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
+	// Times de outra empresa são tratados como inexistentes
+	team, err := middleware.Repositories(c).Teams.Get(c.UserContext(), teamUUID)
+	if err == repository.ErrNotFound {
 		return c.Status(404).JSON(fiber.Map{
 			"error":   true,
 			"message": "Time não encontrado",
@@ -123,16 +73,6 @@ func GetTeamByID(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verificar se o usuário tem permissão para ver este time
-	if user.Role != "admin" {
-		if user.CompanyID == nil || team.CompanyID == nil || *user.CompanyID != *team.CompanyID {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   true,
-				"message": "Sem permissão para acessar este time",
-			})
-		}
-	}
-
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    team,
@@ -142,7 +82,7 @@ func GetTeamByID(c *fiber.Ctx) error {
 // CreateTeam cria um novo time
 func CreateTeam(c *fiber.Ctx) error {
 	user := c.Locals("user").(*middleware.JWTClaims)
-	
+
 	var req models.CreateTeamRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -164,7 +104,7 @@ func CreateTeam(c *fiber.Ctx) error {
 
 	// Determinar a empresa do time
 	var companyID *uuid.UUID
-	if user.Role == "admin" && req.CompanyID != nil {
+	if user.HasPermission(models.PermCompaniesAll) && req.CompanyID != nil {
 		// Admin pode especificar a empresa
 		companyID = req.CompanyID
 	} else if user.CompanyID != nil {
@@ -177,24 +117,14 @@ func CreateTeam(c *fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		INSERT INTO teams (name, description, color, company_id)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, name, description, color, company_id, created_at, updated_at
-	`
-
-	var team models.Team
-	err := database.DB.QueryRow(query, req.Name, req.Description, req.Color, companyID).Scan(
-		&team.ID,
-		&team.Name,
-		&team.Description,
-		&team.Color,
-		&team.CompanyID,
-		&team.CreatedAt,
-		&team.UpdatedAt,
-	)
-
-	if err != nil {
+	// Sem teams:all, o repositório atribui o time a quem o criou
+	team := models.Team{
+		Name:        req.Name,
+		Description: req.Description,
+		Color:       req.Color,
+		CompanyID:   companyID,
+	}
+	if err := middleware.Repositories(c).Teams.Create(c.UserContext(), &team); err != nil {
 		log.Printf("Error creating team: %v", err)
 		return c.Status(500).JSON(fiber.Map{
 			"error":   true,
@@ -227,65 +157,24 @@ func UpdateTeam(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verificar se o time existe
-	var exists bool
-	err = database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM teams WHERE id = $1)", teamUUID).Scan(&exists)
-	if err != nil || !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error":   true,
-			"message": "Time não encontrado",
-		})
-	}
-
-	// Construir query dinâmica
-	setParts := []string{}
-	args := []interface{}{}
-	argIndex := 1
-
-	if req.Name != nil {
-		setParts = append(setParts, fmt.Sprintf("name = $%d", argIndex))
-		args = append(args, *req.Name)
-		argIndex++
-	}
-	if req.Description != nil {
-		setParts = append(setParts, fmt.Sprintf("description = $%d", argIndex))
-		args = append(args, *req.Description)
-		argIndex++
-	}
-	if req.Color != nil {
-		setParts = append(setParts, fmt.Sprintf("color = $%d", argIndex))
-		args = append(args, *req.Color)
-		argIndex++
-	}
-
-	if len(setParts) == 0 {
+	team, err := middleware.Repositories(c).Teams.Update(c.UserContext(), teamUUID, repository.TeamUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		Color:       req.Color,
+	})
+	if err == repository.ErrNoChanges {
 		return c.Status(400).JSON(fiber.Map{
 			"error":   true,
 			"message": "Nenhum campo para atualizar",
 		})
 	}
-
-	query := "UPDATE teams SET "
-	for i, part := range setParts {
-		if i > 0 {
-			query += ", "
-		}
-		query += part
+	// Times fora do alcance do usuário (empresa e times atribuídos) são tratados como inexistentes
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   true,
+			"message": "Time não encontrado",
+		})
 	}
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, description, color, created_at, updated_at", argIndex)
-
-	args = append(args, teamUUID)
-
-	var team models.Team
-	err = database.DB.QueryRow(query, args...).Scan(
-		&team.ID,
-		&team.Name,
-		&team.Description,
-		&team.Color,
-		&team.CreatedAt,
-		&team.UpdatedAt,
-	)
-
 	if err != nil {
 		log.Printf("Error updating team: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -311,18 +200,14 @@ func DeleteTeam(c *fiber.Ctx) error {
 		})
 	}
 
-	// Primeiro, remove a associação dos desenvolvedores com o time
-	_, err = database.DB.Exec("UPDATE developers SET team_id = NULL WHERE team_id = $1", teamUUID)
-	if err != nil {
-		log.Printf("Error removing team associations: %v", err)
-		return c.Status(500).JSON(fiber.Map{
+	// Os desenvolvedores do time ficam sem time antes da exclusão
+	team, unassignedDevelopers, err := middleware.Repositories(c).Teams.Delete(c.UserContext(), teamUUID)
+	if err == repository.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{
 			"error":   true,
-			"message": "Erro ao remover associações do time",
+			"message": "Time não encontrado",
 		})
 	}
-
-	// Agora exclui o time
-	result, err := database.DB.Exec("DELETE FROM teams WHERE id = $1", teamUUID)
 	if err != nil {
 		log.Printf("Error deleting team: %v", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -331,13 +216,15 @@ func DeleteTeam(c *fiber.Ctx) error {
 		})
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return c.Status(404).JSON(fiber.Map{
-			"error":   true,
-			"message": "Time não encontrado",
-		})
-	}
+	audit.Annotate(c, audit.Entry{
+		EntityType: "teams",
+		EntityID:   team.ID.String(),
+		CompanyID:  team.CompanyID,
+		Before: fiber.Map{
+			"team":                 team,
+			"unassignedDevelopers": unassignedDevelopers,
+		},
+	})
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"tivix-performance-tracker-backend/config"
+)
+
+// Message é um email em texto simples
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Transport entrega mensagens; há implementações para SMTP, arquivo e log
+type Transport interface {
+	Send(msg Message) error
+}
+
+var (
+	mu        sync.RWMutex
+	transport Transport = LogTransport{}
+	appURL              = "http://localhost:5173"
+)
+
+// Init configura o transporte padrão a partir das variáveis de ambiente
+func Init(cfg *config.Config) error {
+	t, err := NewTransport(cfg)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	transport = t
+	appURL = cfg.AppURL
+	return nil
+}
+
+// NewTransport cria o transporte indicado em MAIL_TRANSPORT
+func NewTransport(cfg *config.Config) (Transport, error) {
+	switch cfg.MailTransport {
+	case "smtp":
+		return SMTPTransport{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}, nil
+	case "file":
+		return FileTransport{Dir: cfg.MailFileDir, From: cfg.MailFrom}, nil
+	case "log", "":
+		return LogTransport{}, nil
+	default:
+		return nil, fmt.Errorf("transporte de email desconhecido: %s", cfg.MailTransport)
+	}
+}
+
+// SetTransport troca o transporte padrão (usado em testes)
+func SetTransport(t Transport) {
+	mu.Lock()
+	defer mu.Unlock()
+	transport = t
+}
+
+// Send entrega a mensagem pelo transporte padrão
+func Send(msg Message) error {
+	mu.RLock()
+	t := transport
+	mu.RUnlock()
+	return t.Send(msg)
+}
+
+// SendAsync entrega a mensagem em segundo plano, registrando falhas no log. Usado quando a
+// resposta não pode depender do envio (por exemplo, para não revelar se um email existe).
+func SendAsync(msg Message) {
+	go func() {
+		if err := Send(msg); err != nil {
+			log.Printf("Error sending email to %s: %v", msg.To, err)
+		}
+	}()
+}
+
+// AppURL é o endereço do front-end usado nos links enviados por email
+func AppURL() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return appURL
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Link monta um link do front-end com o token informado na query string
+func Link(path, token string) string {
+	return strings.TrimRight(AppURL(), "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// PasswordResetMessage é o email com o link de redefinição de senha
+func PasswordResetMessage(to, name, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Redefinição de senha - Tivix Performance Tracker",
+		Body: fmt.Sprintf(`Olá, %s.
+
+Recebemos um pedido para redefinir a senha da sua conta no Tivix Performance Tracker.
+Para escolher uma nova senha, acesse o link abaixo:
+
+%s
+
+O link expira em %d minutos e só pode ser usado uma vez.
+Se você não fez este pedido, ignore este email; sua senha continua a mesma.
+`, name, link, int(ttl.Minutes())),
+	}
+}
+
+// InvitationMessage é o email de convite para criar a conta
+func InvitationMessage(to, name, companyName, inviterName, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Convite para o Tivix Performance Tracker",
+		Body: fmt.Sprintf(`Olá, %s.
+
+%s convidou você para acessar o Tivix Performance Tracker da empresa %s.
+Para aceitar o convite e definir sua senha, acesse o link abaixo:
+
+%s
+
+O convite expira em %d dias e só pode ser usado uma vez.
+Se você não esperava este convite, ignore este email.
+`, name, inviterName, companyName, link, int(ttl.Hours()/24)),
+	}
+}
@@ -0,0 +1,86 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SMTPTransport envia pelo servidor SMTP configurado, com STARTTLS quando disponível
+type SMTPTransport struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (t SMTPTransport) Send(msg Message) error {
+	from, err := netmail.ParseAddress(t.From)
+	if err != nil {
+		return fmt.Errorf("remetente inválido: %w", err)
+	}
+
+	var auth smtp.Auth
+	if t.Username != "" {
+		auth = smtp.PlainAuth("", t.Username, t.Password, t.Host)
+	}
+
+	if _, err := netmail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("destinatário inválido: %w", err)
+	}
+
+	addr := net.JoinHostPort(t.Host, t.Port)
+	return smtp.SendMail(addr, auth, from.Address, []string{msg.To}, buildMessage(t.From, msg))
+}
+
+// FileTransport grava cada mensagem como um arquivo .eml no diretório informado (desenvolvimento)
+type FileTransport struct {
+	Dir  string
+	From string
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func (t FileTransport) Send(msg Message) error {
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	return os.WriteFile(filepath.Join(t.Dir, name), buildMessage(t.From, msg), 0o600)
+}
+
+// LogTransport apenas escreve a mensagem no log da aplicação (padrão em desenvolvimento)
+type LogTransport struct{}
+
+func (LogTransport) Send(msg Message) error {
+	log.Printf("📧 Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// headerValue remove quebras de linha para impedir injeção de cabeçalhos
+var headerValue = strings.NewReplacer("\r", "", "\n", "").Replace
+
+func buildMessage(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&buf, "To: %s\r\n", headerValue(msg.To))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(msg.Subject)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
@@ -8,9 +8,12 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/joho/godotenv"
 
+	"tivix-performance-tracker-backend/config"
 	"tivix-performance-tracker-backend/database"
+	"tivix-performance-tracker-backend/mail"
 	"tivix-performance-tracker-backend/routes"
 )
 
@@ -26,6 +29,11 @@ func main() {
 	// Executar migrações
 	database.Migrate()
 
+	// Configurar envio de emails
+	if err := mail.Init(config.LoadConfig()); err != nil {
+		log.Fatal("Failed to configure mail transport:", err)
+	}
+
 	// Criar instância do Fiber
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(ctx *fiber.Ctx, err error) error {
@@ -67,6 +75,7 @@ func main() {
 	}
 
 	// Middleware
+	app.Use(requestid.New())
 	app.Use(logger.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     strings.Join(finalOrigins, ","),
@@ -76,7 +85,7 @@ func main() {
 	}))
 
 	// Rotas
-	routes.SetupRoutes(app)
+	routes.SetupRoutes(app, database.NewRepositories())
 
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/models"
+)
+
+// auditSensitiveFields são removidos do corpo da requisição antes de ir para a auditoria
+var auditSensitiveFields = []string{"password", "token", "secret", "code"}
+
+// AuditMiddleware grava um evento de auditoria para cada POST, PUT e DELETE. Requisições
+// autenticadas são sempre registradas; as públicas (login, aceite de convite, ...) apenas
+// quando o handler anota a alteração. Falhas ao gravar são logadas e não afetam a resposta.
+func AuditMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		method := c.Method()
+		if method != fiber.MethodPost && method != fiber.MethodPut && method != fiber.MethodDelete {
+			return c.Next()
+		}
+
+		handlerErr := c.Next()
+
+		user, _ := c.Locals("user").(*JWTClaims)
+		entry := audit.FromContext(c)
+		if user == nil && entry == nil {
+			return handlerErr
+		}
+
+		statusCode := c.Response().StatusCode()
+		if handlerErr != nil {
+			statusCode = fiber.StatusInternalServerError
+			if fiberErr, ok := handlerErr.(*fiber.Error); ok {
+				statusCode = fiberErr.Code
+			}
+		}
+
+		event := models.AuditEvent{
+			Action:     auditActionForMethod(method),
+			Method:     method,
+			Path:       c.Path(),
+			StatusCode: statusCode,
+			IPAddress:  c.IP(),
+			RequestID:  auditRequestID(c),
+		}
+		event.EntityType, event.EntityID = auditEntityFromRoute(c)
+
+		if user != nil {
+			event.ActorID = &user.UserID
+			event.ActorEmail = user.Email
+			event.CompanyID = user.CompanyID
+		}
+
+		if entry != nil {
+			if entry.Action != "" {
+				event.Action = entry.Action
+			}
+			if entry.EntityType != "" {
+				event.EntityType = entry.EntityType
+			}
+			if entry.EntityID != "" {
+				event.EntityID = entry.EntityID
+			}
+			// Ações do admin sobre dados de uma empresa entram na cadeia dessa empresa
+			if entry.CompanyID != nil {
+				event.CompanyID = entry.CompanyID
+			}
+			event.Before = audit.Snapshot(entry.Before)
+			event.After = audit.Snapshot(entry.After)
+		} else if statusCode < fiber.StatusBadRequest {
+			// Sem anotação, o corpo enviado (sem campos sensíveis) é o melhor retrato da alteração
+			event.After = auditRequestBody(c)
+		}
+
+		if err := Repositories(c).Audit.Record(c.UserContext(), &event); err != nil {
+			log.Printf("Error recording audit event for %s %s: %v", method, event.Path, err)
+		}
+		return handlerErr
+	}
+}
+
+func auditActionForMethod(method string) string {
+	switch method {
+	case fiber.MethodPost:
+		return models.AuditActionCreate
+	case fiber.MethodDelete:
+		return models.AuditActionDelete
+	default:
+		return models.AuditActionUpdate
+	}
+}
+
+func auditRequestID(c *fiber.Ctx) string {
+	if requestID, ok := c.Locals("requestid").(string); ok && requestID != "" {
+		return requestID
+	}
+	return c.Get(fiber.HeaderXRequestID)
+}
+
+// auditEntityFromRoute deduz o tipo e o id da entidade pela rota: o primeiro segmento após
+// /api/v1 (ou após /auth) e o parâmetro :id, quando existe
+func auditEntityFromRoute(c *fiber.Ctx) (string, string) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(c.Route().Path, "/api/v1"), "/"), "/")
+	if len(segments) > 1 && segments[0] == "auth" {
+		segments = segments[1:]
+	}
+
+	entityType := ""
+	if len(segments) > 0 && !strings.HasPrefix(segments[0], ":") {
+		entityType = segments[0]
+	}
+	return entityType, c.Params("id")
+}
+
+// auditRequestBody devolve o corpo JSON da requisição sem campos sensíveis
+func auditRequestBody(c *fiber.Ctx) models.JSONB {
+	var body models.JSONB
+	if len(c.Body()) == 0 || json.Unmarshal(c.Body(), &body) != nil {
+		return nil
+	}
+	return redactAuditFields(body)
+}
+
+func redactAuditFields(doc models.JSONB) models.JSONB {
+fields:
+	for key, value := range doc {
+		lower := strings.ToLower(key)
+		for _, sensitive := range auditSensitiveFields {
+			if strings.Contains(lower, sensitive) {
+				delete(doc, key)
+				continue fields
+			}
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			doc[key] = map[string]interface{}(redactAuditFields(nested))
+		}
+	}
+	return doc
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"errors"
+	"log"
 	"os"
 	"strings"
 	"time"
@@ -11,8 +12,15 @@ import (
 	"github.com/google/uuid"
 
 	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
 )
 
+// AccessTokenTTL é a validade do access token; a sessão é renovada via refresh token
+const AccessTokenTTL = 15 * time.Minute
+
+// RefreshTokenTTL é a validade do refresh token e da sessão sem uso
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
 type JWTClaims struct {
 	UserID              uuid.UUID  `json:"userId"`
 	Email               string     `json:"email"`
@@ -20,10 +28,22 @@ type JWTClaims struct {
 	CompanyID           *uuid.UUID `json:"companyId"`
 	IsActive            bool       `json:"isActive"`
 	NeedsPasswordChange bool       `json:"needsPasswordChange"`
+	SessionID           uuid.UUID  `json:"sessionId"`
+
+	// Calculado a cada requisição a partir da política da empresa; não é gravado no token
+	MFAEnrollmentRequired bool `json:"-"`
+
+	// Permissões efetivas: as do papel personalizado atribuído ou as do papel padrão
+	Permissions []string `json:"-"`
+
+	// Preenchidos quando a requisição é autenticada por um token de API em vez de um JWT
+	APITokenID     *uuid.UUID `json:"-"`
+	APITokenScopes []string   `json:"-"`
+
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(user models.User) (string, error) {
+func GenerateJWT(user models.User, sessionID uuid.UUID) (string, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "your-secret-key-change-this-in-production"
@@ -36,8 +56,9 @@ func GenerateJWT(user models.User) (string, error) {
 		CompanyID:           user.CompanyID,
 		IsActive:            user.IsActive,
 		NeedsPasswordChange: user.NeedsPasswordChange,
+		SessionID:           sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Token expira em 24 horas
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "tivix-performance-tracker",
 		},
@@ -86,13 +107,46 @@ func AuthMiddleware() fiber.Handler {
 			})
 		}
 
-		claims, err := ValidateJWT(tokenString)
+		sessions := Repositories(c).Sessions
+		claims := &JWTClaims{}
+		var owner *repository.SessionUser
+		var err error
+		if strings.HasPrefix(tokenString, models.APITokenBrand) {
+			// Tokens de API são opacos: a validade, a revogação e o dono vêm do banco
+			owner, err = sessions.APITokenUser(c.UserContext(), tokenString, c.IP())
+			if err == repository.ErrNotFound {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error":   true,
+					"message": "Token de API inválido, revogado ou expirado",
+				})
+			}
+		} else {
+			claims, err = ValidateJWT(tokenString)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error":   true,
+					"message": "Token inválido ou expirado",
+				})
+			}
+
+			// O token só vale enquanto a sessão estiver ativa; papel, empresa e status vêm do banco
+			// para que desativações e mudanças de papel tenham efeito imediato
+			owner, err = sessions.ActiveSession(c.UserContext(), claims.SessionID, claims.UserID)
+			if err == repository.ErrNotFound {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error":   true,
+					"message": "Sessão encerrada ou expirada",
+				})
+			}
+		}
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			log.Printf("Error loading session: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error":   true,
-				"message": "Token inválido ou expirado",
+				"message": "Erro interno do servidor",
 			})
 		}
+		applySessionUser(claims, owner)
 
 		if !claims.IsActive {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -101,32 +155,101 @@ func AuthMiddleware() fiber.Handler {
 			})
 		}
 
+		if claims.APITokenID != nil && !APITokenAllows(claims.APITokenScopes, c.Method(), c.Path()) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   true,
+				"message": "O token de API não tem escopo para esta rota",
+			})
+		}
+
 		c.Locals("user", claims)
+		// Os repositórios leem o escopo do contexto da requisição
+		c.SetUserContext(repository.WithScope(c.UserContext(), ScopeFor(claims)))
 
 		return c.Next()
 	}
 }
 
-func ManagerOrAdminMiddleware() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		user := c.Locals("user").(*JWTClaims)
-		if user.Role != "admin" && user.Role != "manager" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Acesso negado. Apenas administradores e gerentes têm permissão",
-			})
+// applySessionUser atualiza as claims com os dados atuais do dono da sessão ou do token
+func applySessionUser(claims *JWTClaims, user *repository.SessionUser) {
+	claims.UserID = user.UserID
+	claims.Email = user.Email
+	claims.Role = user.Role
+	claims.CompanyID = user.CompanyID
+	claims.IsActive = user.IsActive
+	claims.NeedsPasswordChange = user.NeedsPasswordChange
+	claims.MFAEnrollmentRequired = user.MFAEnrollmentRequired
+	claims.Permissions = user.Permissions
+	claims.APITokenID = user.APITokenID
+	claims.APITokenScopes = user.APITokenScopes
+}
+
+// APITokenAllows indica se os escopos do token cobrem a rota: GET e HEAD exigem
+// "<recurso>:read", os demais métodos "<recurso>:write"
+func APITokenAllows(scopes []string, method, path string) bool {
+	segment := strings.SplitN(strings.TrimPrefix(path, "/api/v1/"), "/", 2)[0]
+	resource, ok := models.APITokenResources[segment]
+	if !ok {
+		return false
+	}
+
+	required := resource + ":write"
+	if method == fiber.MethodGet || method == fiber.MethodHead {
+		required = resource + ":read"
+	}
+	for _, scope := range scopes {
+		if scope == required {
+			return true
 		}
-		return c.Next()
 	}
+	return false
 }
 
-func AdminOnlyMiddleware() fiber.Handler {
+// HasPermission indica se o usuário tem a permissão
+func (c *JWTClaims) HasPermission(permission string) bool {
+	for _, granted := range c.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAnyPermission indica se o usuário tem ao menos uma das permissões
+func (c *JWTClaims) HasAnyPermission(permissions ...string) bool {
+	for _, permission := range permissions {
+		if c.HasPermission(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAllPermissions indica se o usuário tem todas as permissões informadas
+func (c *JWTClaims) HasAllPermissions(permissions ...string) bool {
+	for _, permission := range permissions {
+		if !c.HasPermission(permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanGrantRole indica se o usuário possui todas as permissões do papel padrão, condição
+// para criar, convidar ou promover alguém a esse papel
+func (c *JWTClaims) CanGrantRole(role string) bool {
+	return c.HasAllPermissions(models.RolePermissions[role]...)
+}
+
+// RequirePermission libera a rota para usuários com ao menos uma das permissões informadas;
+// o alcance (empresa, time, próprios dados) continua sendo aplicado pelos handlers
+func RequirePermission(permissions ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		user := c.Locals("user").(*JWTClaims)
-		if user.Role != "admin" {
+		if !user.HasAnyPermission(permissions...) {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"status":  "error",
-				"message": "Acesso negado. Apenas administradores têm permissão",
+				"message": "Acesso negado. Permissão necessária: " + strings.Join(permissions, " ou "),
 			})
 		}
 		return c.Next()
@@ -140,7 +263,6 @@ func CheckPasswordChangeMiddleware() fiber.Handler {
 			"/api/v1/auth/logout",
 			"/api/v1/auth/profile",
 			"/api/v1/auth/change-password",
-			"/api/v1/auth/refresh",
 		}
 
 		path := c.Path()
@@ -163,13 +285,29 @@ func CheckPasswordChangeMiddleware() fiber.Handler {
 	}
 }
 
+// CheckMFAEnrollmentMiddleware bloqueia admins e gerentes que ainda não cadastraram o 2FA
+// exigido pela política da empresa; o cadastro é feito pelas rotas /auth/mfa
+func CheckMFAEnrollmentMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := c.Locals("user").(*JWTClaims)
+		if user.MFAEnrollmentRequired {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"status":                "error",
+				"message":               "Sua empresa exige autenticação em duas etapas. Configure o 2FA antes de continuar",
+				"requiresMfaEnrollment": true,
+			})
+		}
+		return c.Next()
+	}
+}
+
 // CompanyAccessMiddleware garante que usuários não-admin só acessem dados de sua própria empresa
 func CompanyAccessMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		user := c.Locals("user").(*JWTClaims)
 		
 		// Admins têm acesso a tudo
-		if user.Role == "admin" {
+		if user.HasPermission(models.PermCompaniesAll) {
 			return c.Next()
 		}
 		
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+const (
+	repositoriesKey = "repositories"
+	tenantScopeKey  = "tenantScope"
+)
+
+// InjectRepositories disponibiliza os repositórios para os handlers da requisição
+func InjectRepositories(repos *repository.Repositories) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(repositoriesKey, repos)
+		return c.Next()
+	}
+}
+
+// Repositories devolve os repositórios injetados por InjectRepositories
+func Repositories(c *fiber.Ctx) *repository.Repositories {
+	repos, _ := c.Locals(repositoriesKey).(*repository.Repositories)
+	return repos
+}
+
+// ScopeFor traduz as permissões do usuário no escopo aplicado pelos repositórios
+func ScopeFor(user *JWTClaims) repository.Scope {
+	allTeams := user.HasPermission(models.PermTeamsAll)
+	return repository.Scope{
+		UserID:         user.UserID,
+		CompanyID:      user.CompanyID,
+		AllCompanies:   user.HasPermission(models.PermCompaniesAll),
+		AllTeams:       allTeams,
+		AllTeamReports: allTeams && user.HasPermission(models.PermReportsRead),
+		Drafts:         user.HasPermission(models.PermReportsReadDrafts),
+	}
+}
+
+// TenantScope abre a transação da requisição com o escopo do usuário: as chamadas aos
+// repositórios feitas com c.UserContext() rodam nela, com o papel da API e as políticas de RLS
+// escondendo as linhas de outras empresas mesmo que uma consulta esqueça o filtro. Admins com
+// companies:all ignoram as políticas. A transação é confirmada quando o handler responde com
+// sucesso e desfeita nos demais casos. Grupos aninhados reaproveitam a transação já aberta.
+func TenantScope() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals(tenantScopeKey) != nil {
+			return c.Next()
+		}
+		c.Locals(tenantScopeKey, true)
+
+		readOnly := c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead
+
+		ctx, tx, err := Repositories(c).Transactions.Begin(c.UserContext(), readOnly)
+		if err != nil {
+			log.Printf("Error starting tenant transaction: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro interno do servidor",
+			})
+		}
+		defer tx.Rollback()
+
+		c.SetUserContext(ctx)
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() >= fiber.StatusBadRequest {
+			return nil
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Printf("Error committing tenant transaction: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   true,
+				"message": "Erro ao confirmar a operação",
+			})
+		}
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+-- ============================================
+-- Migração 007: Templates de Avaliação Versionados
+-- ============================================
+-- Descrição: Move categorias, perguntas e pesos da avaliação para o banco,
+--            com versionamento, e vincula cada relatório à versão usada no cálculo
+-- Data: 2025-09-01
+-- Versão: v1.2.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Templates de avaliação (identidade do template)
+CREATE TABLE IF NOT EXISTS evaluation_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Versões imutáveis de cada template
+CREATE TABLE IF NOT EXISTS evaluation_template_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_id UUID NOT NULL REFERENCES evaluation_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (template_id, version)
+);
+
+-- Categorias de cada versão
+CREATE TABLE IF NOT EXISTS evaluation_categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id) ON DELETE CASCADE,
+    key VARCHAR(100) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    weight DECIMAL(8,4) NOT NULL CHECK (weight > 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (template_version_id, key)
+);
+
+-- Perguntas de cada categoria (chaves únicas dentro da versão)
+CREATE TABLE IF NOT EXISTS evaluation_questions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES evaluation_categories(id) ON DELETE CASCADE,
+    key VARCHAR(100) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    weight DECIMAL(8,4) NOT NULL CHECK (weight > 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (template_version_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_template_versions_template_id ON evaluation_template_versions(template_id);
+CREATE INDEX IF NOT EXISTS idx_evaluation_categories_version_id ON evaluation_categories(template_version_id);
+CREATE INDEX IF NOT EXISTS idx_evaluation_questions_category_id ON evaluation_questions(category_id);
+
+DROP TRIGGER IF EXISTS update_evaluation_templates_updated_at ON evaluation_templates;
+CREATE TRIGGER update_evaluation_templates_updated_at
+    BEFORE UPDATE ON evaluation_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Versão do template usada para calcular cada relatório
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='performance_reports' AND column_name='template_version_id') THEN
+        ALTER TABLE performance_reports ADD COLUMN template_version_id UUID REFERENCES evaluation_template_versions(id);
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_performance_reports_template_version_id ON performance_reports(template_version_id);
+
+-- Template padrão (v1) equivalente às categorias usadas até então pelo front-end
+DO $$
+DECLARE
+    tpl_id UUID;
+    ver_id UUID;
+    cat_id UUID;
+BEGIN
+    IF EXISTS (SELECT 1 FROM evaluation_templates WHERE name = 'Avaliação Padrão') THEN
+        RETURN;
+    END IF;
+
+    INSERT INTO evaluation_templates (name, description)
+    VALUES ('Avaliação Padrão', 'Template padrão de avaliação de desenvolvedores')
+    RETURNING id INTO tpl_id;
+
+    INSERT INTO evaluation_template_versions (template_id, version)
+    VALUES (tpl_id, 1)
+    RETURNING id INTO ver_id;
+
+    INSERT INTO evaluation_categories (template_version_id, key, label, weight, position)
+    VALUES (ver_id, 'commitment', 'Comprometimento e Disciplina', 0.3, 1)
+    RETURNING id INTO cat_id;
+    INSERT INTO evaluation_questions (template_version_id, category_id, key, label, weight, position) VALUES
+        (ver_id, cat_id, 'punctualityDeliveries', 'Pontualidade nas Entregas', 3, 1),
+        (ver_id, cat_id, 'punctualityRituals', 'Pontualidade em Rituais (Reuniões, Dailies)', 2, 2),
+        (ver_id, cat_id, 'hybridModelAdherence', 'Adesão ao Modelo Híbrido', 1, 3);
+
+    INSERT INTO evaluation_categories (template_version_id, key, label, weight, position)
+    VALUES (ver_id, 'technicalQuality', 'Qualidade e Execução Técnica', 0.4, 2)
+    RETURNING id INTO cat_id;
+    INSERT INTO evaluation_questions (template_version_id, category_id, key, label, weight, position) VALUES
+        (ver_id, cat_id, 'deliveryQuality', 'Qualidade das Entregas (código, poucos bugs)', 4, 1),
+        (ver_id, cat_id, 'taskAutonomy', 'Autonomia na Resolução de Tarefas', 3, 2);
+
+    INSERT INTO evaluation_categories (template_version_id, key, label, weight, position)
+    VALUES (ver_id, 'collaboration', 'Colaboração e Proatividade', 0.3, 3)
+    RETURNING id INTO cat_id;
+    INSERT INTO evaluation_questions (template_version_id, category_id, key, label, weight, position) VALUES
+        (ver_id, cat_id, 'proactivityImprovements', 'Proatividade e Sugestão de Melhorias', 3, 1),
+        (ver_id, cat_id, 'communicationQuality', 'Qualidade da Comunicação', 2, 2),
+        (ver_id, cat_id, 'teamCollaboration', 'Colaboração e Suporte à Equipe', 2, 3);
+
+    -- Relatórios existentes foram calculados com as mesmas regras da v1
+    UPDATE performance_reports SET template_version_id = ver_id WHERE template_version_id IS NULL;
+END $$;
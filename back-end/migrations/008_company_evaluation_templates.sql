@@ -0,0 +1,39 @@
+-- ============================================
+-- Migração 008: Templates de Avaliação por Empresa
+-- ============================================
+-- Descrição: Permite que cada empresa defina seus próprios templates de avaliação,
+--            com escala de notas própria e um único template ativo por empresa
+-- Data: 2025-09-03
+-- Versão: v1.2.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Empresa dona do template (NULL = template global padrão)
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='evaluation_templates' AND column_name='company_id') THEN
+        ALTER TABLE evaluation_templates ADD COLUMN company_id UUID REFERENCES companies(id) ON DELETE CASCADE;
+    END IF;
+END $$;
+
+-- Escala de notas de cada versão
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='evaluation_template_versions' AND column_name='score_min') THEN
+        ALTER TABLE evaluation_template_versions ADD COLUMN score_min DECIMAL(6,2) NOT NULL DEFAULT 0;
+        ALTER TABLE evaluation_template_versions ADD COLUMN score_max DECIMAL(6,2) NOT NULL DEFAULT 10;
+        ALTER TABLE evaluation_template_versions ADD CONSTRAINT chk_evaluation_template_versions_scale CHECK (score_max > score_min);
+    END IF;
+END $$;
+
+-- Escalas maiores que 0-10 exigem mais dígitos nas pontuações
+ALTER TABLE performance_reports ALTER COLUMN weighted_average_score TYPE DECIMAL(6,2);
+ALTER TABLE developers ALTER COLUMN latest_performance_score TYPE DECIMAL(6,2);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_templates_company_id ON evaluation_templates(company_id);
+
+-- No máximo um template ativo por empresa (e um global)
+DROP INDEX IF EXISTS ux_evaluation_templates_active_company;
+CREATE UNIQUE INDEX ux_evaluation_templates_active_company
+    ON evaluation_templates (COALESCE(company_id, '00000000-0000-0000-0000-000000000000'::uuid))
+    WHERE is_active;
@@ -0,0 +1,47 @@
+-- ============================================
+-- Migração 009: Histórico de Revisões de Relatórios
+-- ============================================
+-- Descrição: Registra cada versão de um relatório de performance (criação, edição
+--            e exclusão) com autor, data e o conteúdo anterior/novo em JSON
+-- Data: 2025-09-08
+-- Versão: v1.3.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- report_id não tem chave estrangeira para que o histórico sobreviva à exclusão do relatório
+CREATE TABLE IF NOT EXISTS performance_report_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    report_id UUID NOT NULL,
+    revision INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    old_data JSONB,
+    new_data JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (report_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_performance_report_revisions_report_id ON performance_report_revisions(report_id);
+CREATE INDEX IF NOT EXISTS idx_performance_report_revisions_company_id ON performance_report_revisions(company_id);
+
+-- Revisão inicial para relatórios já existentes
+INSERT INTO performance_report_revisions (report_id, revision, action, company_id, new_data, created_at)
+SELECT pr.id, 1, 'create', d.company_id,
+       jsonb_build_object(
+           'id', pr.id,
+           'developerId', pr.developer_id,
+           'month', pr.month,
+           'questionScores', pr.question_scores,
+           'categoryScores', pr.category_scores,
+           'weightedAverageScore', pr.weighted_average_score,
+           'highlights', pr.highlights,
+           'pointsToDevelop', pr.points_to_develop,
+           'templateVersionId', pr.template_version_id,
+           'createdAt', pr.created_at,
+           'updatedAt', pr.updated_at
+       ),
+       pr.created_at
+FROM performance_reports pr
+INNER JOIN developers d ON d.id = pr.developer_id
+WHERE NOT EXISTS (SELECT 1 FROM performance_report_revisions r WHERE r.report_id = pr.id);
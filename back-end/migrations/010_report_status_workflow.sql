@@ -0,0 +1,36 @@
+-- ============================================
+-- Migração 010: Fluxo de Status dos Relatórios
+-- ============================================
+-- Descrição: Adiciona o ciclo de vida dos relatórios de performance
+--            (draft → submitted → acknowledged → locked) com data e autor de cada etapa
+-- Data: 2025-09-10
+-- Versão: v1.3.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Relatórios existentes já foram enviados; novos relatórios começam como rascunho
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='performance_reports' AND column_name='status') THEN
+        ALTER TABLE performance_reports ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'submitted';
+        ALTER TABLE performance_reports ALTER COLUMN status SET DEFAULT 'draft';
+        ALTER TABLE performance_reports ADD CONSTRAINT chk_performance_reports_status
+            CHECK (status IN ('draft', 'submitted', 'acknowledged', 'locked'));
+    END IF;
+END $$;
+
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS submitted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMP;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS acknowledged_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS locked_at TIMESTAMP;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS locked_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+UPDATE performance_reports SET submitted_at = created_at WHERE status = 'submitted' AND submitted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_performance_reports_status ON performance_reports(status);
+
+-- Transições de status também entram no histórico de revisões
+ALTER TABLE performance_report_revisions DROP CONSTRAINT IF EXISTS performance_report_revisions_action_check;
+ALTER TABLE performance_report_revisions ADD CONSTRAINT performance_report_revisions_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'submit', 'acknowledge', 'lock'));
@@ -0,0 +1,52 @@
+-- ============================================
+-- Migração 011: Pontuação Mais Recente Derivada dos Relatórios
+-- ============================================
+-- Descrição: Mantém developers.latest_performance_score a partir do relatório enviado
+--            com o mês mais recente, via trigger em inserções, edições e exclusões
+-- Data: 2025-09-12
+-- Versão: v1.3.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Recalcula a pontuação de um desenvolvedor a partir do relatório enviado mais recente
+-- (por mês, não por data de criação). Sem relatórios enviados, a pontuação volta a 0.
+CREATE OR REPLACE FUNCTION refresh_developer_latest_score(dev_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    latest_score DECIMAL(6,2);
+BEGIN
+    SELECT pr.weighted_average_score INTO latest_score
+    FROM performance_reports pr
+    WHERE pr.developer_id = dev_id
+      AND pr.status IN ('submitted', 'acknowledged', 'locked')
+    ORDER BY pr.month DESC, pr.created_at DESC
+    LIMIT 1;
+
+    UPDATE developers
+    SET latest_performance_score = COALESCE(latest_score, 0)
+    WHERE id = dev_id
+      AND latest_performance_score IS DISTINCT FROM COALESCE(latest_score, 0);
+END;
+$$ language 'plpgsql';
+
+CREATE OR REPLACE FUNCTION performance_reports_refresh_latest_score()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM refresh_developer_latest_score(OLD.developer_id);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.developer_id <> OLD.developer_id) THEN
+        PERFORM refresh_developer_latest_score(NEW.developer_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS refresh_developer_latest_score ON performance_reports;
+CREATE TRIGGER refresh_developer_latest_score
+    AFTER INSERT OR DELETE OR UPDATE OF developer_id, month, weighted_average_score, status ON performance_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION performance_reports_refresh_latest_score();
+
+-- Corrige pontuações sobrescritas por relatórios retroativos
+SELECT refresh_developer_latest_score(id) FROM developers;
@@ -0,0 +1,16 @@
+-- ============================================
+-- Migração 012: Acesso dos Desenvolvedores
+-- ============================================
+-- Descrição: Vincula desenvolvedores a contas de usuário e cria o papel "developer",
+--            permitindo que cada desenvolvedor consulte apenas os próprios relatórios
+-- Data: 2025-09-15
+-- Versão: v1.4.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Cada conta de usuário pode estar vinculada a no máximo um desenvolvedor
+ALTER TABLE developers ADD COLUMN IF NOT EXISTS user_id UUID UNIQUE REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'manager', 'user', 'developer'));
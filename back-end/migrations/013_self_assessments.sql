@@ -0,0 +1,32 @@
+-- ============================================
+-- Migração 013: Autoavaliações
+-- ============================================
+-- Descrição: Permite que o desenvolvedor se avalie nas mesmas perguntas do template
+--            antes da avaliação do gerente, para comparação nas conversas 1:1
+-- Data: 2025-09-17
+-- Versão: v1.4.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS self_assessments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    developer_id UUID NOT NULL REFERENCES developers(id) ON DELETE CASCADE,
+    month VARCHAR(7) NOT NULL, -- Formato YYYY-MM
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id),
+    question_scores JSONB NOT NULL,
+    category_scores JSONB NOT NULL,
+    weighted_average_score DECIMAL(6,2) NOT NULL,
+    comments TEXT NOT NULL DEFAULT '',
+    submitted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (developer_id, month)
+);
+
+CREATE INDEX IF NOT EXISTS idx_self_assessments_developer_id ON self_assessments(developer_id);
+
+DROP TRIGGER IF EXISTS update_self_assessments_updated_at ON self_assessments;
+CREATE TRIGGER update_self_assessments_updated_at
+    BEFORE UPDATE ON self_assessments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,48 @@
+-- ============================================
+-- Migração 014: Feedback 360 entre Pares
+-- ============================================
+-- Descrição: Rodadas de feedback por desenvolvedor/mês, com pares indicados pelo
+--            gerente, notas nas perguntas do template e comentários livres
+-- Data: 2025-09-19
+-- Versão: v1.4.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS feedback_rounds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    developer_id UUID NOT NULL REFERENCES developers(id) ON DELETE CASCADE,
+    month VARCHAR(7) NOT NULL, -- Formato YYYY-MM
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    anonymize BOOLEAN NOT NULL DEFAULT true,
+    min_respondents INTEGER NOT NULL DEFAULT 3 CHECK (min_respondents >= 1),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (developer_id, month)
+);
+
+-- Cada indicação guarda a resposta do par (colunas nulas até o envio)
+CREATE TABLE IF NOT EXISTS feedback_nominations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    round_id UUID NOT NULL REFERENCES feedback_rounds(id) ON DELETE CASCADE,
+    reviewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_scores JSONB,
+    category_scores JSONB,
+    weighted_average_score DECIMAL(6,2),
+    comments TEXT,
+    submitted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (round_id, reviewer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_feedback_rounds_company_id ON feedback_rounds(company_id);
+CREATE INDEX IF NOT EXISTS idx_feedback_nominations_reviewer_id ON feedback_nominations(reviewer_id);
+
+DROP TRIGGER IF EXISTS update_feedback_rounds_updated_at ON feedback_rounds;
+CREATE TRIGGER update_feedback_rounds_updated_at
+    BEFORE UPDATE ON feedback_rounds
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
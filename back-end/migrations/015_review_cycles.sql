@@ -0,0 +1,50 @@
+-- ============================================
+-- Migração 015: Ciclos de Avaliação
+-- ============================================
+-- Descrição: Ciclos de avaliação por empresa (período, datas de abertura e prazo,
+--            template, times incluídos e avaliadores) para acompanhar a conclusão
+-- Data: 2025-09-22
+-- Versão: v1.5.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS review_cycles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    month VARCHAR(7) NOT NULL, -- Formato YYYY-MM, mesmo período dos relatórios
+    opens_at DATE NOT NULL,
+    closes_at DATE NOT NULL,
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'planned' CHECK (status IN ('planned', 'open', 'closed')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    opened_at TIMESTAMP,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, month),
+    CHECK (closes_at >= opens_at)
+);
+
+-- Times incluídos no ciclo (sem registros = todos os times da empresa)
+CREATE TABLE IF NOT EXISTS review_cycle_teams (
+    cycle_id UUID NOT NULL REFERENCES review_cycles(id) ON DELETE CASCADE,
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    PRIMARY KEY (cycle_id, team_id)
+);
+
+-- Avaliador responsável por cada desenvolvedor no ciclo
+CREATE TABLE IF NOT EXISTS review_cycle_assignments (
+    cycle_id UUID NOT NULL REFERENCES review_cycles(id) ON DELETE CASCADE,
+    developer_id UUID NOT NULL REFERENCES developers(id) ON DELETE CASCADE,
+    evaluator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (cycle_id, developer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_cycles_company_id ON review_cycles(company_id);
+
+DROP TRIGGER IF EXISTS update_review_cycles_updated_at ON review_cycles;
+CREATE TRIGGER update_review_cycles_updated_at
+    BEFORE UPDATE ON review_cycles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
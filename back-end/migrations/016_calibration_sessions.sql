@@ -0,0 +1,89 @@
+-- ============================================
+-- Migração 016: Sessões de Calibração
+-- ============================================
+-- Descrição: Sessões de calibração por ciclo de avaliação, com ajustes de nota
+--            justificados que ficam registrados ao lado da nota original do relatório
+-- Data: 2025-09-24
+-- Versão: v1.5.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS calibration_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cycle_id UUID NOT NULL REFERENCES review_cycles(id) ON DELETE CASCADE,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'committed')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    committed_at TIMESTAMP,
+    committed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Apenas uma sessão aberta por ciclo
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calibration_sessions_open_cycle
+    ON calibration_sessions(cycle_id) WHERE status = 'open';
+
+-- Ajustes propostos na sessão; original_score guarda a nota do gerente no momento da proposta
+CREATE TABLE IF NOT EXISTS calibration_adjustments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES calibration_sessions(id) ON DELETE CASCADE,
+    report_id UUID NOT NULL REFERENCES performance_reports(id) ON DELETE CASCADE,
+    original_score DECIMAL(6,2) NOT NULL,
+    proposed_score DECIMAL(6,2) NOT NULL,
+    justification TEXT NOT NULL,
+    proposed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (session_id, report_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_calibration_adjustments_report_id ON calibration_adjustments(report_id);
+
+-- Nota calibrada ao lado da nota original (weighted_average_score não é alterada)
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS calibrated_score DECIMAL(6,2);
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS calibration_adjustment_id UUID
+    REFERENCES calibration_adjustments(id) ON DELETE SET NULL;
+
+ALTER TABLE performance_report_revisions DROP CONSTRAINT IF EXISTS performance_report_revisions_action_check;
+ALTER TABLE performance_report_revisions ADD CONSTRAINT performance_report_revisions_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'submit', 'acknowledge', 'lock', 'calibrate'));
+
+-- A pontuação mais recente do desenvolvedor passa a considerar a nota calibrada
+CREATE OR REPLACE FUNCTION refresh_developer_latest_score(dev_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    latest_score DECIMAL(6,2);
+BEGIN
+    SELECT COALESCE(pr.calibrated_score, pr.weighted_average_score) INTO latest_score
+    FROM performance_reports pr
+    WHERE pr.developer_id = dev_id
+      AND pr.status IN ('submitted', 'acknowledged', 'locked')
+    ORDER BY pr.month DESC, pr.created_at DESC
+    LIMIT 1;
+
+    UPDATE developers
+    SET latest_performance_score = COALESCE(latest_score, 0)
+    WHERE id = dev_id
+      AND latest_performance_score IS DISTINCT FROM COALESCE(latest_score, 0);
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS refresh_developer_latest_score ON performance_reports;
+CREATE TRIGGER refresh_developer_latest_score
+    AFTER INSERT OR DELETE OR UPDATE OF developer_id, month, weighted_average_score, calibrated_score, status ON performance_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION performance_reports_refresh_latest_score();
+
+DROP TRIGGER IF EXISTS update_calibration_sessions_updated_at ON calibration_sessions;
+CREATE TRIGGER update_calibration_sessions_updated_at
+    BEFORE UPDATE ON calibration_sessions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_calibration_adjustments_updated_at ON calibration_adjustments;
+CREATE TRIGGER update_calibration_adjustments_updated_at
+    BEFORE UPDATE ON calibration_adjustments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,36 @@
+-- ============================================
+-- Migração 017: Sessões e Refresh Tokens
+-- ============================================
+-- Descrição: Sessões de login persistidas com refresh tokens rotativos (armazenados
+--            como hash) para permitir logout e revogação de tokens no servidor
+-- Data: 2025-09-26
+-- Versão: v1.6.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Cada login abre uma sessão; o access token carrega o id da sessão
+CREATE TABLE IF NOT EXISTS auth_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    revoked_reason VARCHAR(50)
+);
+
+-- Refresh tokens da sessão; cada uso gera um novo token e marca o anterior como usado.
+-- Reapresentar um token já usado revoga a sessão inteira.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES auth_sessions(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_user_id ON auth_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
@@ -0,0 +1,34 @@
+-- ============================================
+-- Migração 018: Proteção Contra Força Bruta no Login
+-- ============================================
+-- Descrição: Contadores de tentativas de login com falha por email, bloqueio
+--            temporário de contas e registro de eventos de segurança
+-- Data: 2025-09-29
+-- Versão: v1.6.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Tentativas com falha por email (também para emails inexistentes, para não revelar quais existem)
+CREATE TABLE IF NOT EXISTS login_throttles (
+    email VARCHAR(255) PRIMARY KEY,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Eventos de segurança da autenticação (falhas, bloqueios, desbloqueios)
+CREATE TABLE IF NOT EXISTS security_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type VARCHAR(50) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    company_id UUID REFERENCES companies(id) ON DELETE SET NULL,
+    email VARCHAR(255),
+    ip_address VARCHAR(45),
+    details JSONB NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events(user_id);
+CREATE INDEX IF NOT EXISTS idx_security_events_created_at ON security_events(created_at);
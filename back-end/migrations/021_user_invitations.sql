@@ -0,0 +1,37 @@
+-- ============================================
+-- Migração 021: Convites de Usuários
+-- ============================================
+-- Descrição: Convites enviados por email com token de uso único; o convidado define
+--            a própria senha ao aceitar, no lugar da senha temporária do admin
+-- Data: 2025-10-06
+-- Versão: v1.7.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- O usuário convidado é criado inativo e só é ativado quando o convite é aceito.
+-- Ao revogar, o usuário pendente é removido e user_id fica nulo.
+CREATE TABLE IF NOT EXISTS user_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'revoked')),
+    expires_at TIMESTAMP NOT NULL,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    send_count INTEGER NOT NULL DEFAULT 1,
+    last_sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Um único convite pendente por usuário
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_invitations_pending_user
+    ON user_invitations(user_id) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_user_invitations_company_status ON user_invitations(company_id, status);
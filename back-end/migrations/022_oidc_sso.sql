@@ -0,0 +1,54 @@
+-- ============================================
+-- Migração 022: Login SSO via OpenID Connect
+-- ============================================
+-- Descrição: Configuração OIDC por empresa, estado temporário do fluxo authorization
+--            code + PKCE e vínculo entre usuários e identidades do provedor
+-- Data: 2025-10-08
+-- Versão: v1.7.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Provedor OIDC da empresa; usuários novos entram com default_role
+CREATE TABLE IF NOT EXISTS company_sso_configs (
+    company_id UUID PRIMARY KEY REFERENCES companies(id) ON DELETE CASCADE,
+    issuer VARCHAR(500) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    client_secret TEXT NOT NULL,
+    allowed_email_domains TEXT[] NOT NULL DEFAULT '{}',
+    default_role VARCHAR(50) NOT NULL DEFAULT 'user' CHECK (default_role IN ('manager', 'user', 'developer')),
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Estado de cada login SSO em andamento (state, nonce e code verifier do PKCE). Após o
+-- callback, guarda o código de uso único que o front-end troca pelos tokens.
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    state_hash VARCHAR(64) NOT NULL UNIQUE,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    login_code_hash VARCHAR(64) UNIQUE,
+    login_code_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Identidade do usuário no provedor (issuer + subject)
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer VARCHAR(500) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    last_login_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+CREATE INDEX IF NOT EXISTS idx_company_sso_configs_domains ON company_sso_configs USING GIN (allowed_email_domains);
@@ -0,0 +1,42 @@
+-- ============================================
+-- Migração 023: Tokens de API e Contas de Serviço
+-- ============================================
+-- Descrição: Tokens de longa duração com escopos para integrações (importação de
+--            relatórios, BI) e contas de serviço não humanas por empresa
+-- Data: 2025-10-10
+-- Versão: v1.7.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- A conta de serviço é um usuário sem login (senha aleatória, email .invalid) marcado
+-- por esta tabela, para que os campos de autoria continuem apontando para users
+CREATE TABLE IF NOT EXISTS service_accounts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    description TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_accounts_company ON service_accounts(company_id);
+
+-- Tokens pessoais e de contas de serviço; apenas o hash SHA-256 é guardado e o prefixo
+-- público identifica o token em listagens e logs
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    token_prefix VARCHAR(32) NOT NULL UNIQUE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(64),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP,
+    revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens(user_id);
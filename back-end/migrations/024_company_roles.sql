@@ -0,0 +1,36 @@
+-- ============================================
+-- Migração 024: Papéis Personalizados
+-- ============================================
+-- Descrição: Papéis por empresa como conjuntos de permissões do registro
+--            (models.Permissions) e atribuição de um papel personalizado por usuário
+-- Data: 2025-10-13
+-- Versão: v1.8.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Os papéis padrão (admin, manager, user, developer) ficam no código; aqui só os
+-- papéis criados pelas empresas
+CREATE TABLE IF NOT EXISTS company_roles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, name)
+);
+
+-- O papel personalizado substitui as permissões do papel padrão (users.role), que continua
+-- definindo o alcance por empresa e a política de 2FA. Excluir o papel devolve os usuários
+-- ao papel padrão.
+CREATE TABLE IF NOT EXISTS user_role_assignments (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES company_roles(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_role_assignments_role ON user_role_assignments(role_id);
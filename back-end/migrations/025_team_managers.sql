@@ -0,0 +1,33 @@
+-- ============================================
+-- Migração 025: Gerentes por Time
+-- ============================================
+-- Descrição: Atribuição de usuários a times (muitos-para-muitos) e papel company_admin,
+--            com acesso a todos os times da empresa
+-- Data: 2025-10-15
+-- Versão: v1.8.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'company_admin', 'manager', 'user', 'developer'));
+
+-- Sem a permissão teams:all, gerentes e usuários só acessam desenvolvedores e relatórios
+-- dos times atribuídos aqui
+CREATE TABLE IF NOT EXISTS user_teams (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, team_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_teams_team ON user_teams(team_id);
+
+-- Gerentes existentes mantêm o acesso atual: ficam atribuídos a todos os times da empresa
+INSERT INTO user_teams (user_id, team_id)
+SELECT u.id, t.id
+FROM users u
+INNER JOIN teams t ON t.company_id = u.company_id
+WHERE u.role = 'manager'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,60 @@
+-- ============================================
+-- Migração 026: Trilha de Auditoria
+-- ============================================
+-- Descrição: Eventos de auditoria das alterações feitas pela API (POST/PUT/DELETE),
+--            somente inserção e encadeados por hash para detectar adulteração
+-- Data: 2025-10-17
+-- Versão: v1.8.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Cada empresa tem a própria cadeia (chain_key = id da empresa; 'global' para ações sem
+-- empresa). O hash de cada evento cobre o hash do anterior, então alterar ou remover um
+-- evento quebra a cadeia a partir dele. Sem chaves estrangeiras: o histórico sobrevive à
+-- exclusão de usuários e empresas e nenhuma linha precisa ser alterada.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    chain_key VARCHAR(64) NOT NULL,
+    seq BIGINT NOT NULL,
+    company_id UUID,
+    actor_id UUID,
+    actor_email VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(50) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status_code INTEGER NOT NULL,
+    entity_type VARCHAR(100) NOT NULL DEFAULT '',
+    entity_id VARCHAR(100) NOT NULL DEFAULT '',
+    before_data JSONB,
+    after_data JSONB,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (chain_key, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_company_created ON audit_events(company_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_entity ON audit_events(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_id);
+
+-- Somente inserção: alterações e exclusões são rejeitadas pelo banco
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS audit_events_no_update ON audit_events;
+CREATE TRIGGER audit_events_no_update
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW
+    EXECUTE FUNCTION audit_events_append_only();
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION audit_events_append_only();
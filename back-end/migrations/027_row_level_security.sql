@@ -0,0 +1,164 @@
+-- ============================================
+-- Migração 027: Row-Level Security por Empresa
+-- ============================================
+-- Descrição: Papel tivix_app assumido pela API nas transações com escopo de empresa e
+--            políticas de RLS nas tabelas das empresas, com bypass para admins
+-- Data: 2025-10-20
+-- Versão: v1.8.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Papel sem login: a API continua conectando com o usuário dono das tabelas e assume
+-- tivix_app com SET LOCAL ROLE nas transações das rotas de times, desenvolvedores e
+-- relatórios. O dono das tabelas não é afetado pelas políticas (sem FORCE), então as
+-- migrações e os fluxos de autenticação continuam enxergando tudo.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'tivix_app') THEN
+        CREATE ROLE tivix_app NOLOGIN NOSUPERUSER NOBYPASSRLS;
+    END IF;
+    IF NOT pg_has_role(current_user, 'tivix_app', 'MEMBER') THEN
+        EXECUTE format('GRANT tivix_app TO %I', current_user);
+    END IF;
+END
+$$;
+
+GRANT USAGE ON SCHEMA public TO tivix_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO tivix_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO tivix_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO tivix_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO tivix_app;
+
+-- Credenciais e controle de migrações nunca são lidos nas transações com escopo de empresa
+REVOKE ALL ON schema_migrations, auth_sessions, refresh_tokens, login_throttles, user_mfa,
+    mfa_recovery_codes, mfa_challenges, password_reset_tokens, oidc_login_states,
+    user_identities, api_tokens FROM tivix_app;
+
+-- Escopo da transação, definido com set_config(..., true) (equivale a SET LOCAL).
+-- Sem empresa definida nenhuma linha é visível.
+CREATE OR REPLACE FUNCTION app_current_company_id()
+RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.company_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION app_rls_bypass()
+RETURNS BOOLEAN AS $$
+    SELECT COALESCE(current_setting('app.bypass_rls', true), '') = 'on'
+$$ LANGUAGE sql STABLE;
+
+-- Tabelas com company_id: a linha é visível (e gravável) apenas na empresa do escopo
+DO $$
+DECLARE
+    tenant_table TEXT;
+BEGIN
+    FOREACH tenant_table IN ARRAY ARRAY[
+        'users', 'teams', 'developers', 'performance_report_revisions', 'feedback_rounds',
+        'review_cycles', 'calibration_sessions', 'security_events', 'company_security_policies',
+        'user_invitations', 'company_sso_configs', 'service_accounts', 'company_roles', 'audit_events'
+    ]
+    LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', tenant_table);
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', tenant_table);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I USING (app_rls_bypass() OR company_id = app_current_company_id())',
+            tenant_table
+        );
+    END LOOP;
+END
+$$;
+
+ALTER TABLE companies ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON companies;
+CREATE POLICY tenant_isolation ON companies
+    USING (app_rls_bypass() OR id = app_current_company_id());
+
+-- Templates globais (sem empresa) são visíveis para todos, mas só o bypass os altera
+ALTER TABLE evaluation_templates ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON evaluation_templates;
+CREATE POLICY tenant_isolation ON evaluation_templates
+    USING (app_rls_bypass() OR company_id IS NULL OR company_id = app_current_company_id())
+    WITH CHECK (app_rls_bypass() OR company_id = app_current_company_id());
+
+-- Tabelas sem company_id herdam a empresa da linha pai
+ALTER TABLE evaluation_template_versions ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON evaluation_template_versions;
+CREATE POLICY tenant_isolation ON evaluation_template_versions
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM evaluation_templates t
+        WHERE t.id = template_id AND (t.company_id IS NULL OR t.company_id = app_current_company_id())
+    ))
+    WITH CHECK (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM evaluation_templates t
+        WHERE t.id = template_id AND t.company_id = app_current_company_id()
+    ));
+
+-- Categorias e perguntas seguem a versão, que já é filtrada pela própria política
+ALTER TABLE evaluation_categories ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON evaluation_categories;
+CREATE POLICY tenant_isolation ON evaluation_categories
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM evaluation_template_versions v WHERE v.id = template_version_id
+    ));
+
+ALTER TABLE evaluation_questions ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON evaluation_questions;
+CREATE POLICY tenant_isolation ON evaluation_questions
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM evaluation_template_versions v WHERE v.id = template_version_id
+    ));
+
+ALTER TABLE performance_reports ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON performance_reports;
+CREATE POLICY tenant_isolation ON performance_reports
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM developers d WHERE d.id = developer_id AND d.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE self_assessments ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON self_assessments;
+CREATE POLICY tenant_isolation ON self_assessments
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM developers d WHERE d.id = developer_id AND d.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE feedback_nominations ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON feedback_nominations;
+CREATE POLICY tenant_isolation ON feedback_nominations
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM feedback_rounds r WHERE r.id = round_id AND r.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE review_cycle_teams ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON review_cycle_teams;
+CREATE POLICY tenant_isolation ON review_cycle_teams
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM review_cycles rc WHERE rc.id = cycle_id AND rc.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE review_cycle_assignments ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON review_cycle_assignments;
+CREATE POLICY tenant_isolation ON review_cycle_assignments
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM review_cycles rc WHERE rc.id = cycle_id AND rc.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE calibration_adjustments ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON calibration_adjustments;
+CREATE POLICY tenant_isolation ON calibration_adjustments
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM calibration_sessions cs WHERE cs.id = session_id AND cs.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE user_teams ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON user_teams;
+CREATE POLICY tenant_isolation ON user_teams
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM teams t WHERE t.id = team_id AND t.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE user_role_assignments ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON user_role_assignments;
+CREATE POLICY tenant_isolation ON user_role_assignments
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM company_roles cr WHERE cr.id = role_id AND cr.company_id = app_current_company_id()
+    ));
@@ -0,0 +1,18 @@
+-- ============================================
+-- Migração 028: Escopo de Empresa em Todas as Rotas
+-- ============================================
+-- Descrição: Permissões por coluna para o papel tivix_app nas tabelas de credenciais
+--            consultadas pelas rotas de contas de serviço e de usuários
+-- Data: 2025-10-22
+-- Versão: v1.8.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- Todas as rotas com escopo de empresa passam a rodar com tivix_app. Duas consultas dessas
+-- rotas tocam tabelas de credenciais, que continuam fechadas para o papel: a contagem de
+-- tokens ativos das contas de serviço e o encerramento das sessões ao desativar um usuário.
+-- As permissões abaixo cobrem apenas as colunas usadas; hashes e demais dados seguem
+-- acessíveis somente ao dono das tabelas (login, sessões, 2FA, SSO e tokens).
+GRANT SELECT (user_id, revoked_at, expires_at) ON api_tokens TO tivix_app;
+GRANT SELECT (user_id, revoked_at) ON auth_sessions TO tivix_app;
+GRANT UPDATE (revoked_at, revoked_reason) ON auth_sessions TO tivix_app;
@@ -0,0 +1,16 @@
+-- ============================================
+-- Migração 029: Permissão para Criar Tokens de API
+-- ============================================
+-- Descrição: Concede api_tokens:create aos papéis personalizados existentes
+-- Data: 2025-10-24
+-- Versão: v1.8.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- A criação de tokens pessoais passa a exigir api_tokens:create em vez de depender do papel.
+-- Papéis personalizados só existem sobre manager e user, que já criavam tokens; a permissão
+-- é acrescentada a eles para manter o comportamento. As empresas podem retirá-la depois.
+UPDATE company_roles
+SET permissions = array_append(permissions, 'api_tokens:create'),
+    updated_at = CURRENT_TIMESTAMP
+WHERE NOT ('api_tokens:create' = ANY(permissions));
@@ -0,0 +1,39 @@
+-- ============================================
+-- Migração 030: Pontuação Mais Recente com Nota Calibrada
+-- ============================================
+-- Descrição: Pontuação mais recente do desenvolvedor pela nota calibrada, quando houver
+-- Data: 2025-10-27
+-- Versão: v1.8.0
+-- Autor: Sistema Tivix Performance Tracker
+-- ============================================
+
+-- A pontuação mais recente segue a mesma nota das estatísticas: a calibrada quando existe,
+-- senão a média ponderada do gerente. A definição substitui a da migração 011 e fica junto
+-- da lista de colunas do trigger, que passa a reagir também à calibração.
+CREATE OR REPLACE FUNCTION refresh_developer_latest_score(dev_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    latest_score DECIMAL(6,2);
+BEGIN
+    SELECT COALESCE(pr.calibrated_score, pr.weighted_average_score) INTO latest_score
+    FROM performance_reports pr
+    WHERE pr.developer_id = dev_id
+      AND pr.status IN ('submitted', 'acknowledged', 'locked')
+    ORDER BY pr.month DESC, pr.created_at DESC
+    LIMIT 1;
+
+    UPDATE developers
+    SET latest_performance_score = COALESCE(latest_score, 0)
+    WHERE id = dev_id
+      AND latest_performance_score IS DISTINCT FROM COALESCE(latest_score, 0);
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS refresh_developer_latest_score ON performance_reports;
+CREATE TRIGGER refresh_developer_latest_score
+    AFTER INSERT OR DELETE OR UPDATE OF developer_id, month, weighted_average_score, calibrated_score, status ON performance_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION performance_reports_refresh_latest_score();
+
+-- Recalcula as pontuações de relatórios calibrados antes desta migração
+SELECT refresh_developer_latest_score(id) FROM developers;
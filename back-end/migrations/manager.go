@@ -156,5 +156,125 @@ func (m *MigrationManager) GetAllMigrations() []Migration {
 			Description: "Migração de dados para multitenancy",
 			SQL:         migration006SQL,
 		},
+		{
+			ID:          "007_evaluation_templates",
+			Description: "Templates de avaliação versionados",
+			SQL:         migration007SQL,
+		},
+		{
+			ID:          "008_company_evaluation_templates",
+			Description: "Templates de avaliação por empresa",
+			SQL:         migration008SQL,
+		},
+		{
+			ID:          "009_performance_report_revisions",
+			Description: "Histórico de revisões de relatórios",
+			SQL:         migration009SQL,
+		},
+		{
+			ID:          "010_report_status_workflow",
+			Description: "Fluxo de status dos relatórios",
+			SQL:         migration010SQL,
+		},
+		{
+			ID:          "011_latest_performance_score_trigger",
+			Description: "Pontuação mais recente derivada dos relatórios",
+			SQL:         migration011SQL,
+		},
+		{
+			ID:          "012_developer_user_link",
+			Description: "Vínculo entre desenvolvedores e usuários",
+			SQL:         migration012SQL,
+		},
+		{
+			ID:          "013_self_assessments",
+			Description: "Autoavaliações dos desenvolvedores",
+			SQL:         migration013SQL,
+		},
+		{
+			ID:          "014_peer_feedback",
+			Description: "Feedback 360 entre pares",
+			SQL:         migration014SQL,
+		},
+		{
+			ID:          "015_review_cycles",
+			Description: "Ciclos de avaliação",
+			SQL:         migration015SQL,
+		},
+		{
+			ID:          "016_calibration_sessions",
+			Description: "Sessões de calibração",
+			SQL:         migration016SQL,
+		},
+		{
+			ID:          "017_auth_sessions",
+			Description: "Sessões e refresh tokens",
+			SQL:         migration017SQL,
+		},
+		{
+			ID:          "018_login_protection",
+			Description: "Proteção contra força bruta no login",
+			SQL:         migration018SQL,
+		},
+		{
+			ID:          "019_mfa",
+			Description: "Autenticação em duas etapas (TOTP)",
+			SQL:         migration019SQL,
+		},
+		{
+			ID:          "020_password_resets",
+			Description: "Redefinição de senha por email",
+			SQL:         migration020SQL,
+		},
+		{
+			ID:          "021_user_invitations",
+			Description: "Convites de usuários",
+			SQL:         migration021SQL,
+		},
+		{
+			ID:          "022_oidc_sso",
+			Description: "Login SSO via OpenID Connect",
+			SQL:         migration022SQL,
+		},
+		{
+			ID:          "023_api_tokens",
+			Description: "Tokens de API e contas de serviço",
+			SQL:         migration023SQL,
+		},
+		{
+			ID:          "024_company_roles",
+			Description: "Papéis personalizados por empresa",
+			SQL:         migration024SQL,
+		},
+		{
+			ID:          "025_team_managers",
+			Description: "Gerentes por time e papel company_admin",
+			SQL:         migration025SQL,
+		},
+		{
+			ID:          "026_audit_events",
+			Description: "Trilha de auditoria encadeada por hash",
+			SQL:         migration026SQL,
+		},
+		{
+			ID:          "027_row_level_security",
+			Description: "Row-level security por empresa e papel tivix_app da API",
+			SQL:         migration027SQL,
+		},
+		{
+			ID:          "028_tenant_scope_grants",
+			Description: "Permissões por coluna do papel tivix_app nas tabelas de credenciais",
+			SQL:         migration028SQL,
+		},
+		{
+			ID:          "029_api_tokens_create_permission",
+			Description: "Permissão api_tokens:create nos papéis personalizados existentes",
+			SQL:         migration029SQL,
+		},
+		{
+			ID:          "030_latest_score_calibrated",
+			Description: "Pontuação mais recente com a nota calibrada",
+			SQL:         migration030SQL,
+		},
 	}
 }
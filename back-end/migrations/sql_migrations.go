@@ -225,3 +225,1029 @@ BEGIN
     RAISE NOTICE 'Migração de dados para multitenancy concluída com sucesso';
 END $$;
 `
+
+// migration007SQL - Templates de avaliação versionados
+const migration007SQL = `
+-- Templates de avaliação (identidade do template)
+CREATE TABLE IF NOT EXISTS evaluation_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Versões imutáveis de cada template
+CREATE TABLE IF NOT EXISTS evaluation_template_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_id UUID NOT NULL REFERENCES evaluation_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (template_id, version)
+);
+
+-- Categorias de cada versão
+CREATE TABLE IF NOT EXISTS evaluation_categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id) ON DELETE CASCADE,
+    key VARCHAR(100) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    weight DECIMAL(8,4) NOT NULL CHECK (weight > 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (template_version_id, key)
+);
+
+-- Perguntas de cada categoria (chaves únicas dentro da versão)
+CREATE TABLE IF NOT EXISTS evaluation_questions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES evaluation_categories(id) ON DELETE CASCADE,
+    key VARCHAR(100) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    weight DECIMAL(8,4) NOT NULL CHECK (weight > 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (template_version_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_template_versions_template_id ON evaluation_template_versions(template_id);
+CREATE INDEX IF NOT EXISTS idx_evaluation_categories_version_id ON evaluation_categories(template_version_id);
+CREATE INDEX IF NOT EXISTS idx_evaluation_questions_category_id ON evaluation_questions(category_id);
+
+DROP TRIGGER IF EXISTS update_evaluation_templates_updated_at ON evaluation_templates;
+CREATE TRIGGER update_evaluation_templates_updated_at
+    BEFORE UPDATE ON evaluation_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Versão do template usada para calcular cada relatório
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='performance_reports' AND column_name='template_version_id') THEN
+        ALTER TABLE performance_reports ADD COLUMN template_version_id UUID REFERENCES evaluation_template_versions(id);
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_performance_reports_template_version_id ON performance_reports(template_version_id);
+
+-- Template padrão (v1) equivalente às categorias usadas até então pelo front-end
+DO $$
+DECLARE
+    tpl_id UUID;
+    ver_id UUID;
+    cat_id UUID;
+BEGIN
+    IF EXISTS (SELECT 1 FROM evaluation_templates WHERE name = 'Avaliação Padrão') THEN
+        RETURN;
+    END IF;
+
+    INSERT INTO evaluation_templates (name, description)
+    VALUES ('Avaliação Padrão', 'Template padrão de avaliação de desenvolvedores')
+    RETURNING id INTO tpl_id;
+
+    INSERT INTO evaluation_template_versions (template_id, version)
+    VALUES (tpl_id, 1)
+    RETURNING id INTO ver_id;
+
+    INSERT INTO evaluation_categories (template_version_id, key, label, weight, position)
+    VALUES (ver_id, 'commitment', 'Comprometimento e Disciplina', 0.3, 1)
+    RETURNING id INTO cat_id;
+    INSERT INTO evaluation_questions (template_version_id, category_id, key, label, weight, position) VALUES
+        (ver_id, cat_id, 'punctualityDeliveries', 'Pontualidade nas Entregas', 3, 1),
+        (ver_id, cat_id, 'punctualityRituals', 'Pontualidade em Rituais (Reuniões, Dailies)', 2, 2),
+        (ver_id, cat_id, 'hybridModelAdherence', 'Adesão ao Modelo Híbrido', 1, 3);
+
+    INSERT INTO evaluation_categories (template_version_id, key, label, weight, position)
+    VALUES (ver_id, 'technicalQuality', 'Qualidade e Execução Técnica', 0.4, 2)
+    RETURNING id INTO cat_id;
+    INSERT INTO evaluation_questions (template_version_id, category_id, key, label, weight, position) VALUES
+        (ver_id, cat_id, 'deliveryQuality', 'Qualidade das Entregas (código, poucos bugs)', 4, 1),
+        (ver_id, cat_id, 'taskAutonomy', 'Autonomia na Resolução de Tarefas', 3, 2);
+
+    INSERT INTO evaluation_categories (template_version_id, key, label, weight, position)
+    VALUES (ver_id, 'collaboration', 'Colaboração e Proatividade', 0.3, 3)
+    RETURNING id INTO cat_id;
+    INSERT INTO evaluation_questions (template_version_id, category_id, key, label, weight, position) VALUES
+        (ver_id, cat_id, 'proactivityImprovements', 'Proatividade e Sugestão de Melhorias', 3, 1),
+        (ver_id, cat_id, 'communicationQuality', 'Qualidade da Comunicação', 2, 2),
+        (ver_id, cat_id, 'teamCollaboration', 'Colaboração e Suporte à Equipe', 2, 3);
+
+    -- Relatórios existentes foram calculados com as mesmas regras da v1
+    UPDATE performance_reports SET template_version_id = ver_id WHERE template_version_id IS NULL;
+END $$;
+`
+
+// migration008SQL - Templates de avaliação por empresa
+const migration008SQL = `
+-- Empresa dona do template (NULL = template global padrão)
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='evaluation_templates' AND column_name='company_id') THEN
+        ALTER TABLE evaluation_templates ADD COLUMN company_id UUID REFERENCES companies(id) ON DELETE CASCADE;
+    END IF;
+END $$;
+
+-- Escala de notas de cada versão
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='evaluation_template_versions' AND column_name='score_min') THEN
+        ALTER TABLE evaluation_template_versions ADD COLUMN score_min DECIMAL(6,2) NOT NULL DEFAULT 0;
+        ALTER TABLE evaluation_template_versions ADD COLUMN score_max DECIMAL(6,2) NOT NULL DEFAULT 10;
+        ALTER TABLE evaluation_template_versions ADD CONSTRAINT chk_evaluation_template_versions_scale CHECK (score_max > score_min);
+    END IF;
+END $$;
+
+-- Escalas maiores que 0-10 exigem mais dígitos nas pontuações
+ALTER TABLE performance_reports ALTER COLUMN weighted_average_score TYPE DECIMAL(6,2);
+ALTER TABLE developers ALTER COLUMN latest_performance_score TYPE DECIMAL(6,2);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_templates_company_id ON evaluation_templates(company_id);
+
+-- No máximo um template ativo por empresa (e um global)
+DROP INDEX IF EXISTS ux_evaluation_templates_active_company;
+CREATE UNIQUE INDEX ux_evaluation_templates_active_company
+    ON evaluation_templates (COALESCE(company_id, '00000000-0000-0000-0000-000000000000'::uuid))
+    WHERE is_active;
+`
+
+// migration009SQL - Histórico de revisões de relatórios
+const migration009SQL = `
+-- report_id não tem chave estrangeira para que o histórico sobreviva à exclusão do relatório
+CREATE TABLE IF NOT EXISTS performance_report_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    report_id UUID NOT NULL,
+    revision INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    old_data JSONB,
+    new_data JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (report_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_performance_report_revisions_report_id ON performance_report_revisions(report_id);
+CREATE INDEX IF NOT EXISTS idx_performance_report_revisions_company_id ON performance_report_revisions(company_id);
+
+-- Revisão inicial para relatórios já existentes
+INSERT INTO performance_report_revisions (report_id, revision, action, company_id, new_data, created_at)
+SELECT pr.id, 1, 'create', d.company_id,
+       jsonb_build_object(
+           'id', pr.id,
+           'developerId', pr.developer_id,
+           'month', pr.month,
+           'questionScores', pr.question_scores,
+           'categoryScores', pr.category_scores,
+           'weightedAverageScore', pr.weighted_average_score,
+           'highlights', pr.highlights,
+           'pointsToDevelop', pr.points_to_develop,
+           'templateVersionId', pr.template_version_id,
+           'createdAt', pr.created_at,
+           'updatedAt', pr.updated_at
+       ),
+       pr.created_at
+FROM performance_reports pr
+INNER JOIN developers d ON d.id = pr.developer_id
+WHERE NOT EXISTS (SELECT 1 FROM performance_report_revisions r WHERE r.report_id = pr.id);
+`
+
+// migration010SQL - Fluxo de status dos relatórios
+const migration010SQL = `
+-- Relatórios existentes já foram enviados; novos relatórios começam como rascunho
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='performance_reports' AND column_name='status') THEN
+        ALTER TABLE performance_reports ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'submitted';
+        ALTER TABLE performance_reports ALTER COLUMN status SET DEFAULT 'draft';
+        ALTER TABLE performance_reports ADD CONSTRAINT chk_performance_reports_status
+            CHECK (status IN ('draft', 'submitted', 'acknowledged', 'locked'));
+    END IF;
+END $$;
+
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS submitted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMP;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS acknowledged_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS locked_at TIMESTAMP;
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS locked_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+UPDATE performance_reports SET submitted_at = created_at WHERE status = 'submitted' AND submitted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_performance_reports_status ON performance_reports(status);
+
+-- Transições de status também entram no histórico de revisões
+ALTER TABLE performance_report_revisions DROP CONSTRAINT IF EXISTS performance_report_revisions_action_check;
+ALTER TABLE performance_report_revisions ADD CONSTRAINT performance_report_revisions_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'submit', 'acknowledge', 'lock'));
+`
+
+// migration011SQL - Pontuação mais recente derivada dos relatórios
+const migration011SQL = `
+-- Recalcula a pontuação de um desenvolvedor a partir do relatório enviado mais recente
+-- (por mês, não por data de criação). Sem relatórios enviados, a pontuação volta a 0.
+CREATE OR REPLACE FUNCTION refresh_developer_latest_score(dev_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    latest_score DECIMAL(6,2);
+BEGIN
+    SELECT pr.weighted_average_score INTO latest_score
+    FROM performance_reports pr
+    WHERE pr.developer_id = dev_id
+      AND pr.status IN ('submitted', 'acknowledged', 'locked')
+    ORDER BY pr.month DESC, pr.created_at DESC
+    LIMIT 1;
+
+    UPDATE developers
+    SET latest_performance_score = COALESCE(latest_score, 0)
+    WHERE id = dev_id
+      AND latest_performance_score IS DISTINCT FROM COALESCE(latest_score, 0);
+END;
+$$ language 'plpgsql';
+
+CREATE OR REPLACE FUNCTION performance_reports_refresh_latest_score()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM refresh_developer_latest_score(OLD.developer_id);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.developer_id <> OLD.developer_id) THEN
+        PERFORM refresh_developer_latest_score(NEW.developer_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS refresh_developer_latest_score ON performance_reports;
+CREATE TRIGGER refresh_developer_latest_score
+    AFTER INSERT OR DELETE OR UPDATE OF developer_id, month, weighted_average_score, status ON performance_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION performance_reports_refresh_latest_score();
+
+-- Corrige pontuações sobrescritas por relatórios retroativos
+SELECT refresh_developer_latest_score(id) FROM developers;
+`
+
+// migration012SQL - Vínculo entre desenvolvedores e usuários
+const migration012SQL = `
+-- Cada conta de usuário pode estar vinculada a no máximo um desenvolvedor
+ALTER TABLE developers ADD COLUMN IF NOT EXISTS user_id UUID UNIQUE REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'manager', 'user', 'developer'));
+`
+
+// migration013SQL - Autoavaliações dos desenvolvedores
+const migration013SQL = `
+CREATE TABLE IF NOT EXISTS self_assessments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    developer_id UUID NOT NULL REFERENCES developers(id) ON DELETE CASCADE,
+    month VARCHAR(7) NOT NULL, -- Formato YYYY-MM
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id),
+    question_scores JSONB NOT NULL,
+    category_scores JSONB NOT NULL,
+    weighted_average_score DECIMAL(6,2) NOT NULL,
+    comments TEXT NOT NULL DEFAULT '',
+    submitted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (developer_id, month)
+);
+
+CREATE INDEX IF NOT EXISTS idx_self_assessments_developer_id ON self_assessments(developer_id);
+
+DROP TRIGGER IF EXISTS update_self_assessments_updated_at ON self_assessments;
+CREATE TRIGGER update_self_assessments_updated_at
+    BEFORE UPDATE ON self_assessments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+`
+
+// migration014SQL - Feedback 360 entre pares
+const migration014SQL = `
+CREATE TABLE IF NOT EXISTS feedback_rounds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    developer_id UUID NOT NULL REFERENCES developers(id) ON DELETE CASCADE,
+    month VARCHAR(7) NOT NULL, -- Formato YYYY-MM
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    anonymize BOOLEAN NOT NULL DEFAULT true,
+    min_respondents INTEGER NOT NULL DEFAULT 3 CHECK (min_respondents >= 1),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (developer_id, month)
+);
+
+-- Cada indicação guarda a resposta do par (colunas nulas até o envio)
+CREATE TABLE IF NOT EXISTS feedback_nominations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    round_id UUID NOT NULL REFERENCES feedback_rounds(id) ON DELETE CASCADE,
+    reviewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_scores JSONB,
+    category_scores JSONB,
+    weighted_average_score DECIMAL(6,2),
+    comments TEXT,
+    submitted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (round_id, reviewer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_feedback_rounds_company_id ON feedback_rounds(company_id);
+CREATE INDEX IF NOT EXISTS idx_feedback_nominations_reviewer_id ON feedback_nominations(reviewer_id);
+
+DROP TRIGGER IF EXISTS update_feedback_rounds_updated_at ON feedback_rounds;
+CREATE TRIGGER update_feedback_rounds_updated_at
+    BEFORE UPDATE ON feedback_rounds
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+`
+
+// migration015SQL - Ciclos de avaliação
+const migration015SQL = `
+CREATE TABLE IF NOT EXISTS review_cycles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    month VARCHAR(7) NOT NULL, -- Formato YYYY-MM, mesmo período dos relatórios
+    opens_at DATE NOT NULL,
+    closes_at DATE NOT NULL,
+    template_version_id UUID NOT NULL REFERENCES evaluation_template_versions(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'planned' CHECK (status IN ('planned', 'open', 'closed')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    opened_at TIMESTAMP,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, month),
+    CHECK (closes_at >= opens_at)
+);
+
+-- Times incluídos no ciclo (sem registros = todos os times da empresa)
+CREATE TABLE IF NOT EXISTS review_cycle_teams (
+    cycle_id UUID NOT NULL REFERENCES review_cycles(id) ON DELETE CASCADE,
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    PRIMARY KEY (cycle_id, team_id)
+);
+
+-- Avaliador responsável por cada desenvolvedor no ciclo
+CREATE TABLE IF NOT EXISTS review_cycle_assignments (
+    cycle_id UUID NOT NULL REFERENCES review_cycles(id) ON DELETE CASCADE,
+    developer_id UUID NOT NULL REFERENCES developers(id) ON DELETE CASCADE,
+    evaluator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (cycle_id, developer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_cycles_company_id ON review_cycles(company_id);
+
+DROP TRIGGER IF EXISTS update_review_cycles_updated_at ON review_cycles;
+CREATE TRIGGER update_review_cycles_updated_at
+    BEFORE UPDATE ON review_cycles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+`
+
+// migration016SQL - Sessões de calibração
+const migration016SQL = `
+CREATE TABLE IF NOT EXISTS calibration_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cycle_id UUID NOT NULL REFERENCES review_cycles(id) ON DELETE CASCADE,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'committed')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    committed_at TIMESTAMP,
+    committed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Apenas uma sessão aberta por ciclo
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calibration_sessions_open_cycle
+    ON calibration_sessions(cycle_id) WHERE status = 'open';
+
+-- Ajustes propostos na sessão; original_score guarda a nota do gerente no momento da proposta
+CREATE TABLE IF NOT EXISTS calibration_adjustments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES calibration_sessions(id) ON DELETE CASCADE,
+    report_id UUID NOT NULL REFERENCES performance_reports(id) ON DELETE CASCADE,
+    original_score DECIMAL(6,2) NOT NULL,
+    proposed_score DECIMAL(6,2) NOT NULL,
+    justification TEXT NOT NULL,
+    proposed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (session_id, report_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_calibration_adjustments_report_id ON calibration_adjustments(report_id);
+
+-- Nota calibrada ao lado da nota original (weighted_average_score não é alterada)
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS calibrated_score DECIMAL(6,2);
+ALTER TABLE performance_reports ADD COLUMN IF NOT EXISTS calibration_adjustment_id UUID
+    REFERENCES calibration_adjustments(id) ON DELETE SET NULL;
+
+ALTER TABLE performance_report_revisions DROP CONSTRAINT IF EXISTS performance_report_revisions_action_check;
+ALTER TABLE performance_report_revisions ADD CONSTRAINT performance_report_revisions_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'submit', 'acknowledge', 'lock', 'calibrate'));
+
+-- A pontuação mais recente do desenvolvedor passa a considerar a nota calibrada
+CREATE OR REPLACE FUNCTION refresh_developer_latest_score(dev_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    latest_score DECIMAL(6,2);
+BEGIN
+    SELECT COALESCE(pr.calibrated_score, pr.weighted_average_score) INTO latest_score
+    FROM performance_reports pr
+    WHERE pr.developer_id = dev_id
+      AND pr.status IN ('submitted', 'acknowledged', 'locked')
+    ORDER BY pr.month DESC, pr.created_at DESC
+    LIMIT 1;
+
+    UPDATE developers
+    SET latest_performance_score = COALESCE(latest_score, 0)
+    WHERE id = dev_id
+      AND latest_performance_score IS DISTINCT FROM COALESCE(latest_score, 0);
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS refresh_developer_latest_score ON performance_reports;
+CREATE TRIGGER refresh_developer_latest_score
+    AFTER INSERT OR DELETE OR UPDATE OF developer_id, month, weighted_average_score, calibrated_score, status ON performance_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION performance_reports_refresh_latest_score();
+
+DROP TRIGGER IF EXISTS update_calibration_sessions_updated_at ON calibration_sessions;
+CREATE TRIGGER update_calibration_sessions_updated_at
+    BEFORE UPDATE ON calibration_sessions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_calibration_adjustments_updated_at ON calibration_adjustments;
+CREATE TRIGGER update_calibration_adjustments_updated_at
+    BEFORE UPDATE ON calibration_adjustments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+`
+
+// migration017SQL - Sessões e refresh tokens
+const migration017SQL = `
+-- Cada login abre uma sessão; o access token carrega o id da sessão
+CREATE TABLE IF NOT EXISTS auth_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    revoked_reason VARCHAR(50)
+);
+
+-- Refresh tokens da sessão; cada uso gera um novo token e marca o anterior como usado.
+-- Reapresentar um token já usado revoga a sessão inteira.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES auth_sessions(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_user_id ON auth_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+`
+
+// migration018SQL - Proteção contra força bruta no login
+const migration018SQL = `
+-- Tentativas com falha por email (também para emails inexistentes, para não revelar quais existem)
+CREATE TABLE IF NOT EXISTS login_throttles (
+    email VARCHAR(255) PRIMARY KEY,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Eventos de segurança da autenticação (falhas, bloqueios, desbloqueios)
+CREATE TABLE IF NOT EXISTS security_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type VARCHAR(50) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    company_id UUID REFERENCES companies(id) ON DELETE SET NULL,
+    email VARCHAR(255),
+    ip_address VARCHAR(45),
+    details JSONB NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events(user_id);
+CREATE INDEX IF NOT EXISTS idx_security_events_created_at ON security_events(created_at);
+`
+
+// migration019SQL - Autenticação em duas etapas (TOTP)
+const migration019SQL = `
+-- Política de segurança por empresa (em tabela própria para não alterar companies)
+CREATE TABLE IF NOT EXISTS company_security_policies (
+    company_id UUID PRIMARY KEY REFERENCES companies(id) ON DELETE CASCADE,
+    require_mfa BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Segredo TOTP do usuário; enabled_at fica nulo até o primeiro código ser confirmado.
+-- last_used_step impede reutilizar o mesmo código dentro da janela de validade.
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Códigos de recuperação de uso único (armazenados como hash)
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Desafio emitido pelo login quando a senha confere e o usuário tem 2FA ativo
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_user_id ON mfa_challenges(user_id);
+`
+
+// migration020SQL - Redefinição de senha por email
+const migration020SQL = `
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    requested_ip VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+`
+
+// migration021SQL - Convites de usuários
+const migration021SQL = `
+-- O usuário convidado é criado inativo e só é ativado quando o convite é aceito.
+-- Ao revogar, o usuário pendente é removido e user_id fica nulo.
+CREATE TABLE IF NOT EXISTS user_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'revoked')),
+    expires_at TIMESTAMP NOT NULL,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    send_count INTEGER NOT NULL DEFAULT 1,
+    last_sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Um único convite pendente por usuário
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_invitations_pending_user
+    ON user_invitations(user_id) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_user_invitations_company_status ON user_invitations(company_id, status);
+`
+
+// migration022SQL - Login SSO via OpenID Connect
+const migration022SQL = `
+-- Provedor OIDC da empresa; usuários novos entram com default_role
+CREATE TABLE IF NOT EXISTS company_sso_configs (
+    company_id UUID PRIMARY KEY REFERENCES companies(id) ON DELETE CASCADE,
+    issuer VARCHAR(500) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    client_secret TEXT NOT NULL,
+    allowed_email_domains TEXT[] NOT NULL DEFAULT '{}',
+    default_role VARCHAR(50) NOT NULL DEFAULT 'user' CHECK (default_role IN ('manager', 'user', 'developer')),
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Estado de cada login SSO em andamento (state, nonce e code verifier do PKCE). Após o
+-- callback, guarda o código de uso único que o front-end troca pelos tokens.
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    state_hash VARCHAR(64) NOT NULL UNIQUE,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    login_code_hash VARCHAR(64) UNIQUE,
+    login_code_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Identidade do usuário no provedor (issuer + subject)
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer VARCHAR(500) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    last_login_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+CREATE INDEX IF NOT EXISTS idx_company_sso_configs_domains ON company_sso_configs USING GIN (allowed_email_domains);
+`
+
+// migration023SQL - Tokens de API e contas de serviço
+const migration023SQL = `
+-- A conta de serviço é um usuário sem login (senha aleatória, email .invalid) marcado
+-- por esta tabela, para que os campos de autoria continuem apontando para users
+CREATE TABLE IF NOT EXISTS service_accounts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    description TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_accounts_company ON service_accounts(company_id);
+
+-- Tokens pessoais e de contas de serviço; apenas o hash SHA-256 é guardado e o prefixo
+-- público identifica o token em listagens e logs
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    token_prefix VARCHAR(32) NOT NULL UNIQUE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(64),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP,
+    revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens(user_id);
+`
+
+// migration024SQL - Papéis personalizados por empresa
+const migration024SQL = `
+-- Os papéis padrão (admin, manager, user, developer) ficam no código; aqui só os
+-- papéis criados pelas empresas
+CREATE TABLE IF NOT EXISTS company_roles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, name)
+);
+
+-- O papel personalizado substitui as permissões do papel padrão (users.role), que continua
+-- definindo o alcance por empresa e a política de 2FA. Excluir o papel devolve os usuários
+-- ao papel padrão.
+CREATE TABLE IF NOT EXISTS user_role_assignments (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES company_roles(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_role_assignments_role ON user_role_assignments(role_id);
+`
+
+// migration025SQL - Gerentes por time e papel company_admin
+const migration025SQL = `
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'company_admin', 'manager', 'user', 'developer'));
+
+-- Sem a permissão teams:all, gerentes e usuários só acessam desenvolvedores e relatórios
+-- dos times atribuídos aqui
+CREATE TABLE IF NOT EXISTS user_teams (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, team_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_teams_team ON user_teams(team_id);
+
+-- Gerentes existentes mantêm o acesso atual: ficam atribuídos a todos os times da empresa
+INSERT INTO user_teams (user_id, team_id)
+SELECT u.id, t.id
+FROM users u
+INNER JOIN teams t ON t.company_id = u.company_id
+WHERE u.role = 'manager'
+ON CONFLICT DO NOTHING;
+`
+
+// migration026SQL - Trilha de auditoria encadeada por hash
+const migration026SQL = `
+-- Cada empresa tem a própria cadeia (chain_key = id da empresa; 'global' para ações sem
+-- empresa). O hash de cada evento cobre o hash do anterior, então alterar ou remover um
+-- evento quebra a cadeia a partir dele. Sem chaves estrangeiras: o histórico sobrevive à
+-- exclusão de usuários e empresas e nenhuma linha precisa ser alterada.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    chain_key VARCHAR(64) NOT NULL,
+    seq BIGINT NOT NULL,
+    company_id UUID,
+    actor_id UUID,
+    actor_email VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(50) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status_code INTEGER NOT NULL,
+    entity_type VARCHAR(100) NOT NULL DEFAULT '',
+    entity_id VARCHAR(100) NOT NULL DEFAULT '',
+    before_data JSONB,
+    after_data JSONB,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (chain_key, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_company_created ON audit_events(company_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_entity ON audit_events(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_id);
+
+-- Somente inserção: alterações e exclusões são rejeitadas pelo banco
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS audit_events_no_update ON audit_events;
+CREATE TRIGGER audit_events_no_update
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW
+    EXECUTE FUNCTION audit_events_append_only();
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION audit_events_append_only();
+`
+
+// migration027SQL - Row-level security por empresa e papel tivix_app da API
+const migration027SQL = `
+-- Papel sem login: a API continua conectando com o usuário dono das tabelas e assume
+-- tivix_app com SET LOCAL ROLE nas transações das rotas de times, desenvolvedores e
+-- relatórios. O dono das tabelas não é afetado pelas políticas (sem FORCE), então as
+-- migrações e os fluxos de autenticação continuam enxergando tudo.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'tivix_app') THEN
+        CREATE ROLE tivix_app NOLOGIN NOSUPERUSER NOBYPASSRLS;
+    END IF;
+    IF NOT pg_has_role(current_user, 'tivix_app', 'MEMBER') THEN
+        EXECUTE format('GRANT tivix_app TO %I', current_user);
+    END IF;
+END
+$$;
+
+GRANT USAGE ON SCHEMA public TO tivix_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO tivix_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO tivix_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO tivix_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO tivix_app;
+
+-- Credenciais e controle de migrações nunca são lidos nas transações com escopo de empresa
+REVOKE ALL ON schema_migrations, auth_sessions, refresh_tokens, login_throttles, user_mfa,
+    mfa_recovery_codes, mfa_challenges, password_reset_tokens, oidc_login_states,
+    user_identities, api_tokens FROM tivix_app;
+
+-- Escopo da transação, definido com set_config(..., true) (equivale a SET LOCAL).
+-- Sem empresa definida nenhuma linha é visível.
+CREATE OR REPLACE FUNCTION app_current_company_id()
+RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.company_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION app_rls_bypass()
+RETURNS BOOLEAN AS $$
+    SELECT COALESCE(current_setting('app.bypass_rls', true), '') = 'on'
+$$ LANGUAGE sql STABLE;
+
+-- Tabelas com company_id: a linha é visível (e gravável) apenas na empresa do escopo
+DO $$
+DECLARE
+    tenant_table TEXT;
+BEGIN
+    FOREACH tenant_table IN ARRAY ARRAY[
+        'users', 'teams', 'developers', 'performance_report_revisions', 'feedback_rounds',
+        'review_cycles', 'calibration_sessions', 'security_events', 'company_security_policies',
+        'user_invitations', 'company_sso_configs', 'service_accounts', 'company_roles', 'audit_events'
+    ]
+    LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', tenant_table);
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', tenant_table);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I USING (app_rls_bypass() OR company_id = app_current_company_id())',
+            tenant_table
+        );
+    END LOOP;
+END
+$$;
+
+ALTER TABLE companies ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON companies;
+CREATE POLICY tenant_isolation ON companies
+    USING (app_rls_bypass() OR id = app_current_company_id());
+
+-- Templates globais (sem empresa) são visíveis para todos, mas só o bypass os altera
+ALTER TABLE evaluation_templates ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON evaluation_templates;
+CREATE POLICY tenant_isolation ON evaluation_templates
+    USING (app_rls_bypass() OR company_id IS NULL OR company_id = app_current_company_id())
+    WITH CHECK (app_rls_bypass() OR company_id = app_current_company_id());
+
+-- Tabelas sem company_id herdam a empresa da linha pai
+ALTER TABLE evaluation_template_versions ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON evaluation_template_versions;
+CREATE POLICY tenant_isolation ON evaluation_template_versions
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM evaluation_templates t
+        WHERE t.id = template_id AND (t.company_id IS NULL OR t.company_id = app_current_company_id())
+    ))
+    WITH CHECK (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM evaluation_templates t
+        WHERE t.id = template_id AND t.company_id = app_current_company_id()
+    ));
+
+-- Categorias e perguntas seguem a versão, que já é filtrada pela própria política
+ALTER TABLE evaluation_categories ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON evaluation_categories;
+CREATE POLICY tenant_isolation ON evaluation_categories
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM evaluation_template_versions v WHERE v.id = template_version_id
+    ));
+
+ALTER TABLE evaluation_questions ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON evaluation_questions;
+CREATE POLICY tenant_isolation ON evaluation_questions
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM evaluation_template_versions v WHERE v.id = template_version_id
+    ));
+
+ALTER TABLE performance_reports ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON performance_reports;
+CREATE POLICY tenant_isolation ON performance_reports
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM developers d WHERE d.id = developer_id AND d.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE self_assessments ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON self_assessments;
+CREATE POLICY tenant_isolation ON self_assessments
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM developers d WHERE d.id = developer_id AND d.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE feedback_nominations ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON feedback_nominations;
+CREATE POLICY tenant_isolation ON feedback_nominations
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM feedback_rounds r WHERE r.id = round_id AND r.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE review_cycle_teams ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON review_cycle_teams;
+CREATE POLICY tenant_isolation ON review_cycle_teams
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM review_cycles rc WHERE rc.id = cycle_id AND rc.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE review_cycle_assignments ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON review_cycle_assignments;
+CREATE POLICY tenant_isolation ON review_cycle_assignments
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM review_cycles rc WHERE rc.id = cycle_id AND rc.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE calibration_adjustments ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON calibration_adjustments;
+CREATE POLICY tenant_isolation ON calibration_adjustments
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM calibration_sessions cs WHERE cs.id = session_id AND cs.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE user_teams ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON user_teams;
+CREATE POLICY tenant_isolation ON user_teams
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM teams t WHERE t.id = team_id AND t.company_id = app_current_company_id()
+    ));
+
+ALTER TABLE user_role_assignments ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON user_role_assignments;
+CREATE POLICY tenant_isolation ON user_role_assignments
+    USING (app_rls_bypass() OR EXISTS (
+        SELECT 1 FROM company_roles cr WHERE cr.id = role_id AND cr.company_id = app_current_company_id()
+    ));
+`
+
+// migration028SQL - Permissões por coluna do papel tivix_app nas tabelas de credenciais
+const migration028SQL = `
+-- Todas as rotas com escopo de empresa passam a rodar com tivix_app. Duas consultas dessas
+-- rotas tocam tabelas de credenciais, que continuam fechadas para o papel: a contagem de
+-- tokens ativos das contas de serviço e o encerramento das sessões ao desativar um usuário.
+-- As permissões abaixo cobrem apenas as colunas usadas; hashes e demais dados seguem
+-- acessíveis somente ao dono das tabelas (login, sessões, 2FA, SSO e tokens).
+GRANT SELECT (user_id, revoked_at, expires_at) ON api_tokens TO tivix_app;
+GRANT SELECT (user_id, revoked_at) ON auth_sessions TO tivix_app;
+GRANT UPDATE (revoked_at, revoked_reason) ON auth_sessions TO tivix_app;
+`
+
+// migration029SQL - Permissão api_tokens:create nos papéis personalizados existentes
+const migration029SQL = `
+-- A criação de tokens pessoais passa a exigir api_tokens:create em vez de depender do papel.
+-- Papéis personalizados só existem sobre manager e user, que já criavam tokens; a permissão
+-- é acrescentada a eles para manter o comportamento. As empresas podem retirá-la depois.
+UPDATE company_roles
+SET permissions = array_append(permissions, 'api_tokens:create'),
+    updated_at = CURRENT_TIMESTAMP
+WHERE NOT ('api_tokens:create' = ANY(permissions));
+`
+
+// migration030SQL - Pontuação mais recente com a nota calibrada
+const migration030SQL = `
+-- A pontuação mais recente segue a mesma nota das estatísticas: a calibrada quando existe,
+-- senão a média ponderada do gerente. A definição substitui a da migração 011 e fica junto
+-- da lista de colunas do trigger, que passa a reagir também à calibração.
+CREATE OR REPLACE FUNCTION refresh_developer_latest_score(dev_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    latest_score DECIMAL(6,2);
+BEGIN
+    SELECT COALESCE(pr.calibrated_score, pr.weighted_average_score) INTO latest_score
+    FROM performance_reports pr
+    WHERE pr.developer_id = dev_id
+      AND pr.status IN ('submitted', 'acknowledged', 'locked')
+    ORDER BY pr.month DESC, pr.created_at DESC
+    LIMIT 1;
+
+    UPDATE developers
+    SET latest_performance_score = COALESCE(latest_score, 0)
+    WHERE id = dev_id
+      AND latest_performance_score IS DISTINCT FROM COALESCE(latest_score, 0);
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS refresh_developer_latest_score ON performance_reports;
+CREATE TRIGGER refresh_developer_latest_score
+    AFTER INSERT OR DELETE OR UPDATE OF developer_id, month, weighted_average_score, calibrated_score, status ON performance_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION performance_reports_refresh_latest_score();
+
+-- Recalcula as pontuações de relatórios calibrados antes desta migração
+SELECT refresh_developer_latest_score(id) FROM developers;
+`
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// APITokenBrand inicia todo token de API, permitindo distingui-lo de um JWT no header
+// Authorization e identificá-lo em varreduras de segredos vazados
+const APITokenBrand = "tvx_"
+
+// Validade padrão e máxima de um token de API
+const (
+	APITokenDefaultTTLDays = 90
+	APITokenMaxTTLDays     = 365
+)
+
+// APITokenResources mapeia o primeiro segmento da rota após /api/v1 para o recurso do escopo.
+// O escopo é "<recurso>:read" para GET e "<recurso>:write" para os demais métodos; o papel do
+// dono continua valendo. Rotas fora deste mapa (autenticação, empresas, /me) não aceitam tokens.
+var APITokenResources = map[string]string{
+	"performance-reports":  "reports",
+	"developers":           "developers",
+	"teams":                "teams",
+	"evaluation-templates": "templates",
+	"review-cycles":        "reviews",
+	"calibration-sessions": "reviews",
+	"feedback-rounds":      "reviews",
+}
+
+// ServiceAccountEmailDomain compõe o email interno das contas de serviço; o TLD .invalid
+// garante que nenhum email seja entregue
+const ServiceAccountEmailDomain = "service-accounts.invalid"
+
+// APIToken é um token de longa duração; o segredo só é exibido na criação
+type APIToken struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	UserID     uuid.UUID      `json:"userId" db:"user_id"`
+	Name       string         `json:"name" db:"name"`
+	Prefix     string         `json:"prefix" db:"token_prefix"`
+	Scopes     pq.StringArray `json:"scopes" db:"scopes"`
+	ExpiresAt  time.Time      `json:"expiresAt" db:"expires_at"`
+	LastUsedAt *time.Time     `json:"lastUsedAt" db:"last_used_at"`
+	LastUsedIP *string        `json:"lastUsedIp" db:"last_used_ip"`
+	CreatedBy  *uuid.UUID     `json:"createdBy" db:"created_by"`
+	RevokedAt  *time.Time     `json:"revokedAt" db:"revoked_at"`
+	RevokedBy  *uuid.UUID     `json:"revokedBy" db:"revoked_by"`
+	CreatedAt  time.Time      `json:"createdAt" db:"created_at"`
+}
+
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" validate:"required,min=2,max=255"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=reports:read reports:write developers:read developers:write teams:read teams:write templates:read reviews:read reviews:write"`
+	ExpiresInDays *int     `json:"expiresInDays" validate:"omitempty,min=1,max=365"`
+}
+
+// CreatedAPIToken devolve o token completo uma única vez, junto com seus metadados
+type CreatedAPIToken struct {
+	Token string `json:"token"`
+	APIToken
+}
+
+// ServiceAccount é um usuário não humano da empresa que só se autentica por tokens de API
+type ServiceAccount struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	CompanyID    uuid.UUID  `json:"companyId" db:"company_id"`
+	Name         string     `json:"name" db:"name"`
+	Description  string     `json:"description" db:"description"`
+	Role         string     `json:"role" db:"role"`
+	IsActive     bool       `json:"isActive" db:"is_active"`
+	ActiveTokens int        `json:"activeTokens" db:"active_tokens"`
+	CreatedBy    *uuid.UUID `json:"createdBy" db:"created_by"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type CreateServiceAccountRequest struct {
+	Name        string     `json:"name" validate:"required,min=2,max=255"`
+	Description string     `json:"description"`
+	Role        string     `json:"role" validate:"required,oneof=manager user"`
+	CompanyID   *uuid.UUID `json:"companyId"`
+}
+
+type UpdateServiceAccountRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=2,max=255"`
+	Description *string `json:"description"`
+	Role        *string `json:"role" validate:"omitempty,oneof=manager user"`
+	IsActive    *bool   `json:"isActive"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ações registradas na trilha de auditoria. Os handlers podem informar ações mais
+// específicas (archive, restore, assign-role); sem isso a ação vem do método HTTP.
+const (
+	AuditActionCreate     = "create"
+	AuditActionUpdate     = "update"
+	AuditActionDelete     = "delete"
+	AuditActionArchive    = "archive"
+	AuditActionRestore    = "restore"
+	AuditActionAssignRole = "assign-role"
+)
+
+// AuditGlobalChain é a cadeia dos eventos sem empresa (ações do administrador do sistema)
+const AuditGlobalChain = "global"
+
+// AuditGenesisHash é o hash anterior do primeiro evento de cada cadeia
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// AuditDefaultPageSize e AuditMaxPageSize limitam a listagem de eventos
+const (
+	AuditDefaultPageSize = 50
+	AuditMaxPageSize     = 200
+)
+
+// AuditEvent é um evento da trilha de auditoria. Hash cobre todos os campos (exceto ID)
+// e PrevHash, encadeando os eventos da mesma empresa.
+type AuditEvent struct {
+	ID         int64      `json:"id" db:"id"`
+	ChainKey   string     `json:"chainKey" db:"chain_key"`
+	Seq        int64      `json:"seq" db:"seq"`
+	CompanyID  *uuid.UUID `json:"companyId" db:"company_id"`
+	ActorID    *uuid.UUID `json:"actorId" db:"actor_id"`
+	ActorEmail string     `json:"actorEmail" db:"actor_email"`
+	Action     string     `json:"action" db:"action"`
+	Method     string     `json:"method" db:"method"`
+	Path       string     `json:"path" db:"path"`
+	StatusCode int        `json:"statusCode" db:"status_code"`
+	EntityType string     `json:"entityType" db:"entity_type"`
+	EntityID   string     `json:"entityId" db:"entity_id"`
+	Before     JSONB      `json:"before" db:"before_data"`
+	After      JSONB      `json:"after" db:"after_data"`
+	IPAddress  string     `json:"ipAddress" db:"ip_address"`
+	RequestID  string     `json:"requestId" db:"request_id"`
+	PrevHash   string     `json:"prevHash" db:"prev_hash"`
+	Hash       string     `json:"hash" db:"hash"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// AuditChainVerification é o resultado da verificação de uma cadeia de auditoria
+type AuditChainVerification struct {
+	ChainKey    string `json:"chainKey"`
+	Events      int64  `json:"events"`
+	Valid       bool   `json:"valid"`
+	BrokenAtSeq *int64 `json:"brokenAtSeq,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
@@ -0,0 +1,192 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status de uma sessão de calibração
+const (
+	CalibrationSessionOpen      = "open"
+	CalibrationSessionCommitted = "committed"
+)
+
+// CalibrationSession reúne gerentes e admins para ajustar as notas de um ciclo
+type CalibrationSession struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	CycleID     uuid.UUID  `json:"cycleId" db:"cycle_id"`
+	CompanyID   uuid.UUID  `json:"companyId" db:"company_id"`
+	Name        string     `json:"name" db:"name"`
+	Status      string     `json:"status" db:"status"`
+	CreatedBy   *uuid.UUID `json:"createdBy" db:"created_by"`
+	CommittedAt *time.Time `json:"committedAt" db:"committed_at"`
+	CommittedBy *uuid.UUID `json:"committedBy" db:"committed_by"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// CalibrationAdjustment é a proposta de nota calibrada para um relatório
+type CalibrationAdjustment struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	SessionID     uuid.UUID  `json:"sessionId" db:"session_id"`
+	ReportID      uuid.UUID  `json:"reportId" db:"report_id"`
+	DeveloperID   uuid.UUID  `json:"developerId" db:"developer_id"`
+	DeveloperName string     `json:"developerName" db:"developer_name"`
+	OriginalScore float64    `json:"originalScore" db:"original_score"`
+	ProposedScore float64    `json:"proposedScore" db:"proposed_score"`
+	Justification string     `json:"justification" db:"justification"`
+	ProposedBy    *uuid.UUID `json:"proposedBy" db:"proposed_by"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type CreateCalibrationSessionRequest struct {
+	Name string `json:"name" validate:"required,min=2"`
+}
+
+type ProposeCalibrationRequest struct {
+	ProposedScore *float64 `json:"proposedScore" validate:"required"`
+	Justification string   `json:"justification" validate:"required,min=10"`
+}
+
+// CalibrationScore é a nota de um relatório enviado do ciclo, com a proposta da sessão (se houver)
+type CalibrationScore struct {
+	ReportID        uuid.UUID  `json:"reportId" db:"report_id"`
+	DeveloperID     uuid.UUID  `json:"developerId" db:"developer_id"`
+	DeveloperName   string     `json:"developerName" db:"developer_name"`
+	TeamID          *uuid.UUID `json:"teamId" db:"team_id"`
+	TeamName        *string    `json:"teamName" db:"team_name"`
+	EvaluatorID     *uuid.UUID `json:"evaluatorId" db:"evaluator_id"`
+	EvaluatorName   *string    `json:"evaluatorName" db:"evaluator_name"`
+	Score           float64    `json:"score" db:"score"`
+	CalibratedScore *float64   `json:"calibratedScore" db:"calibrated_score"`
+	ProposedScore   *float64   `json:"proposedScore" db:"proposed_score"`
+}
+
+// AdjustedScore é a nota que valeria após a sessão: proposta, calibração anterior ou original
+func (s CalibrationScore) AdjustedScore() float64 {
+	if s.ProposedScore != nil {
+		return *s.ProposedScore
+	}
+	if s.CalibratedScore != nil {
+		return *s.CalibratedScore
+	}
+	return s.Score
+}
+
+// ScoreDistribution resume as notas de um avaliador, time ou da empresa. Deviation é a
+// diferença entre a média do grupo e a média geral, antes e depois dos ajustes.
+type ScoreDistribution struct {
+	ID                *uuid.UUID `json:"id,omitempty"`
+	Name              string     `json:"name"`
+	Count             int        `json:"count"`
+	Average           float64    `json:"average"`
+	Min               float64    `json:"min"`
+	Max               float64    `json:"max"`
+	StdDev            float64    `json:"stdDev"`
+	Deviation         float64    `json:"deviation"`
+	AdjustedAverage   float64    `json:"adjustedAverage"`
+	AdjustedDeviation float64    `json:"adjustedDeviation"`
+}
+
+// CalibrationDistribution é a visão usada durante a sessão de calibração
+type CalibrationDistribution struct {
+	Overall     ScoreDistribution   `json:"overall"`
+	ByEvaluator []ScoreDistribution `json:"byEvaluator"`
+	ByTeam      []ScoreDistribution `json:"byTeam"`
+	Scores      []CalibrationScore  `json:"scores"`
+}
+
+// BuildCalibrationDistribution calcula a distribuição das notas por avaliador e por time.
+// Grupos são mantidos na ordem em que aparecem em scores.
+func BuildCalibrationDistribution(scores []CalibrationScore) CalibrationDistribution {
+	distribution := CalibrationDistribution{
+		Overall:     summarizeScores(nil, "Empresa", scores),
+		ByEvaluator: []ScoreDistribution{},
+		ByTeam:      []ScoreDistribution{},
+		Scores:      scores,
+	}
+
+	byEvaluator := groupScores(scores, func(s CalibrationScore) (*uuid.UUID, *string) { return s.EvaluatorID, s.EvaluatorName })
+	for _, group := range byEvaluator {
+		name := "Sem avaliador"
+		if group.name != nil {
+			name = *group.name
+		}
+		distribution.ByEvaluator = append(distribution.ByEvaluator, summarizeScores(group.id, name, group.scores))
+	}
+
+	byTeam := groupScores(scores, func(s CalibrationScore) (*uuid.UUID, *string) { return s.TeamID, s.TeamName })
+	for _, group := range byTeam {
+		name := "Sem time"
+		if group.name != nil {
+			name = *group.name
+		}
+		distribution.ByTeam = append(distribution.ByTeam, summarizeScores(group.id, name, group.scores))
+	}
+
+	for _, groups := range [][]ScoreDistribution{distribution.ByEvaluator, distribution.ByTeam} {
+		for i := range groups {
+			groups[i].Deviation = roundScore(groups[i].Average - distribution.Overall.Average)
+			groups[i].AdjustedDeviation = roundScore(groups[i].AdjustedAverage - distribution.Overall.AdjustedAverage)
+		}
+	}
+
+	return distribution
+}
+
+type scoreGroup struct {
+	id     *uuid.UUID
+	name   *string
+	scores []CalibrationScore
+}
+
+func groupScores(scores []CalibrationScore, key func(CalibrationScore) (*uuid.UUID, *string)) []scoreGroup {
+	groups := []scoreGroup{}
+	index := map[string]int{}
+	for _, score := range scores {
+		id, name := key(score)
+		groupKey := ""
+		if id != nil {
+			groupKey = id.String()
+		}
+
+		position, ok := index[groupKey]
+		if !ok {
+			position = len(groups)
+			index[groupKey] = position
+			groups = append(groups, scoreGroup{id: id, name: name})
+		}
+		groups[position].scores = append(groups[position].scores, score)
+	}
+	return groups
+}
+
+func summarizeScores(id *uuid.UUID, name string, scores []CalibrationScore) ScoreDistribution {
+	summary := ScoreDistribution{ID: id, Name: name, Count: len(scores)}
+	if len(scores) == 0 {
+		return summary
+	}
+
+	var total, adjustedTotal float64
+	summary.Min, summary.Max = scores[0].Score, scores[0].Score
+	for _, score := range scores {
+		total += score.Score
+		adjustedTotal += score.AdjustedScore()
+		summary.Min = math.Min(summary.Min, score.Score)
+		summary.Max = math.Max(summary.Max, score.Score)
+	}
+	average := total / float64(len(scores))
+
+	var variance float64
+	for _, score := range scores {
+		variance += (score.Score - average) * (score.Score - average)
+	}
+
+	summary.Average = roundScore(average)
+	summary.AdjustedAverage = roundScore(adjustedTotal / float64(len(scores)))
+	summary.StdDev = roundScore(math.Sqrt(variance / float64(len(scores))))
+	return summary
+}
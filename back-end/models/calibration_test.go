@@ -0,0 +1,104 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCalibrationScoreAdjustedScore(t *testing.T) {
+	proposed, calibrated := 9.0, 7.5
+
+	tests := []struct {
+		name  string
+		score CalibrationScore
+		want  float64
+	}{
+		{"sem ajuste", CalibrationScore{Score: 6}, 6},
+		{"calibração anterior", CalibrationScore{Score: 6, CalibratedScore: &calibrated}, 7.5},
+		{"proposta da sessão", CalibrationScore{Score: 6, CalibratedScore: &calibrated, ProposedScore: &proposed}, 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.score.AdjustedScore(); got != tt.want {
+				t.Errorf("AdjustedScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCalibrationDistribution(t *testing.T) {
+	teamA, teamB := uuid.New(), uuid.New()
+	evaluatorX, evaluatorY := uuid.New(), uuid.New()
+	teamAName, teamBName, evaluatorXName, evaluatorYName := "Plataforma", "Mobile", "Gabi", "Hugo"
+	proposed, calibrated := 7.0, 7.0
+
+	scores := []CalibrationScore{
+		{DeveloperName: "Ana", TeamID: &teamA, TeamName: &teamAName, EvaluatorID: &evaluatorX, EvaluatorName: &evaluatorXName, Score: 8, ProposedScore: &proposed},
+		{DeveloperName: "Bruno", TeamID: &teamA, TeamName: &teamAName, EvaluatorID: &evaluatorY, EvaluatorName: &evaluatorYName, Score: 6, CalibratedScore: &calibrated},
+		{DeveloperName: "Caio", TeamID: &teamB, TeamName: &teamBName, EvaluatorID: &evaluatorX, EvaluatorName: &evaluatorXName, Score: 4},
+		{DeveloperName: "Davi", Score: 10},
+	}
+
+	tests := []struct {
+		name   string
+		scores []CalibrationScore
+		want   CalibrationDistribution
+	}{
+		{
+			name:   "sem notas",
+			scores: nil,
+			want:   CalibrationDistribution{Overall: ScoreDistribution{Name: "Empresa"}},
+		},
+		{
+			name:   "por avaliador e por time",
+			scores: scores,
+			want: CalibrationDistribution{
+				Overall: ScoreDistribution{Name: "Empresa", Count: 4, Average: 7, Min: 4, Max: 10, StdDev: 2.24, AdjustedAverage: 7},
+				ByEvaluator: []ScoreDistribution{
+					{ID: &evaluatorX, Name: "Gabi", Count: 2, Average: 6, Min: 4, Max: 8, StdDev: 2, Deviation: -1, AdjustedAverage: 5.5, AdjustedDeviation: -1.5},
+					{ID: &evaluatorY, Name: "Hugo", Count: 1, Average: 6, Min: 6, Max: 6, Deviation: -1, AdjustedAverage: 7},
+					{Name: "Sem avaliador", Count: 1, Average: 10, Min: 10, Max: 10, Deviation: 3, AdjustedAverage: 10, AdjustedDeviation: 3},
+				},
+				ByTeam: []ScoreDistribution{
+					{ID: &teamA, Name: "Plataforma", Count: 2, Average: 7, Min: 6, Max: 8, StdDev: 1, AdjustedAverage: 7},
+					{ID: &teamB, Name: "Mobile", Count: 1, Average: 4, Min: 4, Max: 4, Deviation: -3, AdjustedAverage: 4, AdjustedDeviation: -3},
+					{Name: "Sem time", Count: 1, Average: 10, Min: 10, Max: 10, Deviation: 3, AdjustedAverage: 10, AdjustedDeviation: 3},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildCalibrationDistribution(tt.scores)
+
+			if got.Overall != tt.want.Overall {
+				t.Errorf("overall = %+v, want %+v", got.Overall, tt.want.Overall)
+			}
+			if len(got.Scores) != len(tt.scores) {
+				t.Errorf("scores = %d, want %d", len(got.Scores), len(tt.scores))
+			}
+			for _, groups := range []struct {
+				name      string
+				got, want []ScoreDistribution
+			}{
+				{"byEvaluator", got.ByEvaluator, tt.want.ByEvaluator},
+				{"byTeam", got.ByTeam, tt.want.ByTeam},
+			} {
+				if groups.got == nil {
+					t.Errorf("%s = nil, want an empty list", groups.name)
+				}
+				if len(groups.got) != len(groups.want) {
+					t.Fatalf("%s = %+v, want %d groups", groups.name, groups.got, len(groups.want))
+				}
+				for i := range groups.want {
+					if groups.got[i] != groups.want[i] {
+						t.Errorf("%s[%d] = %+v, want %+v", groups.name, i, groups.got[i], groups.want[i])
+					}
+				}
+			}
+		})
+	}
+}
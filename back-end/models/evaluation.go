@@ -0,0 +1,217 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Escala de notas padrão, usada quando a versão do template não define outra
+const (
+	DefaultScoreMin = 0.0
+	DefaultScoreMax = 10.0
+	MaxScoreScale   = 1000.0
+)
+
+type EvaluationQuestion struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	Key      string    `json:"key" db:"key"`
+	Label    string    `json:"label" db:"label"`
+	Weight   float64   `json:"weight" db:"weight"`
+	Position int       `json:"position" db:"position"`
+}
+
+type EvaluationCategory struct {
+	ID        uuid.UUID            `json:"id" db:"id"`
+	Key       string               `json:"key" db:"key"`
+	Label     string               `json:"label" db:"label"`
+	Weight    float64              `json:"weight" db:"weight"`
+	Position  int                  `json:"position" db:"position"`
+	Questions []EvaluationQuestion `json:"questions"`
+}
+
+// EvaluationTemplate representa uma versão específica de um template de avaliação
+type EvaluationTemplate struct {
+	ID          uuid.UUID            `json:"id" db:"id"`
+	Name        string               `json:"name" db:"name"`
+	Description string               `json:"description" db:"description"`
+	CompanyID   *uuid.UUID           `json:"companyId" db:"company_id"`
+	IsActive    bool                 `json:"isActive" db:"is_active"`
+	VersionID   uuid.UUID            `json:"versionId" db:"version_id"`
+	Version     int                  `json:"version" db:"version"`
+	ScoreMin    float64              `json:"scoreMin" db:"score_min"`
+	ScoreMax    float64              `json:"scoreMax" db:"score_max"`
+	Categories  []EvaluationCategory `json:"categories"`
+	CreatedAt   time.Time            `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time            `json:"updatedAt" db:"updated_at"`
+}
+
+// ScoreValidationError indica notas inconsistentes com o template
+type ScoreValidationError struct {
+	Message string
+	Keys    []string
+}
+
+func (e *ScoreValidationError) Error() string {
+	if len(e.Keys) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Message, strings.Join(e.Keys, ", "))
+}
+
+// Score recalcula as notas por categoria e a média ponderada a partir das notas
+// por pergunta. Perguntas desconhecidas, ausentes ou fora da escala são rejeitadas.
+func (t *EvaluationTemplate) Score(questionScores JSONB) (JSONB, float64, error) {
+	values, err := t.checkScores(questionScores, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	categoryScores := make(JSONB, len(t.Categories))
+	var weightedTotal, categoryWeightSum float64
+	for _, category := range t.Categories {
+		var total, weightSum float64
+		for _, question := range category.Questions {
+			total += values[question.Key] * question.Weight
+			weightSum += question.Weight
+		}
+
+		var average float64
+		if weightSum > 0 {
+			average = total / weightSum
+		}
+		categoryScores[category.Key] = roundScore(average)
+		weightedTotal += average * category.Weight
+		categoryWeightSum += category.Weight
+	}
+
+	if categoryWeightSum == 0 {
+		return nil, 0, &ScoreValidationError{Message: "Template sem categorias com peso"}
+	}
+
+	return categoryScores, roundScore(weightedTotal / categoryWeightSum), nil
+}
+
+// ValidatePartial valida as notas de um rascunho: perguntas podem ficar sem nota,
+// mas as informadas precisam existir no template e respeitar a escala
+func (t *EvaluationTemplate) ValidatePartial(questionScores JSONB) error {
+	_, err := t.checkScores(questionScores, false)
+	return err
+}
+
+func (t *EvaluationTemplate) checkScores(questionScores JSONB, requireAll bool) (map[string]float64, error) {
+	known := make(map[string]bool)
+	for _, category := range t.Categories {
+		for _, question := range category.Questions {
+			known[question.Key] = true
+		}
+	}
+
+	var unknown []string
+	for key := range questionScores {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, &ScoreValidationError{Message: "Perguntas desconhecidas para o template", Keys: unknown}
+	}
+
+	var missing, invalid []string
+	values := make(map[string]float64, len(questionScores))
+	for _, category := range t.Categories {
+		for _, question := range category.Questions {
+			raw, ok := questionScores[question.Key]
+			if !ok || raw == nil {
+				missing = append(missing, question.Key)
+				continue
+			}
+			value, ok := raw.(float64)
+			if !ok || math.IsNaN(value) || value < t.ScoreMin || value > t.ScoreMax {
+				invalid = append(invalid, question.Key)
+				continue
+			}
+			values[question.Key] = value
+		}
+	}
+	if requireAll && len(missing) > 0 {
+		return nil, &ScoreValidationError{Message: "Perguntas sem nota", Keys: missing}
+	}
+	if len(invalid) > 0 {
+		return nil, &ScoreValidationError{
+			Message: fmt.Sprintf("Notas devem ser numéricas entre %g e %g", t.ScoreMin, t.ScoreMax),
+			Keys:    invalid,
+		}
+	}
+	return values, nil
+}
+
+func roundScore(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+type EvaluationQuestionRequest struct {
+	Key    string  `json:"key" validate:"required,min=1,max=100"`
+	Label  string  `json:"label" validate:"required,min=2"`
+	Weight float64 `json:"weight" validate:"gt=0"`
+}
+
+type EvaluationCategoryRequest struct {
+	Key       string                      `json:"key" validate:"required,min=1,max=100"`
+	Label     string                      `json:"label" validate:"required,min=2"`
+	Weight    float64                     `json:"weight" validate:"gt=0"`
+	Questions []EvaluationQuestionRequest `json:"questions" validate:"required,min=1,dive"`
+}
+
+// CategoryRequests converte as categorias do template para o formato de requisição,
+// permitindo derivar uma nova versão a partir da atual
+func (t *EvaluationTemplate) CategoryRequests() []EvaluationCategoryRequest {
+	categories := make([]EvaluationCategoryRequest, 0, len(t.Categories))
+	for _, category := range t.Categories {
+		questions := make([]EvaluationQuestionRequest, 0, len(category.Questions))
+		for _, question := range category.Questions {
+			questions = append(questions, EvaluationQuestionRequest{
+				Key:    question.Key,
+				Label:  question.Label,
+				Weight: question.Weight,
+			})
+		}
+		categories = append(categories, EvaluationCategoryRequest{
+			Key:       category.Key,
+			Label:     category.Label,
+			Weight:    category.Weight,
+			Questions: questions,
+		})
+	}
+	return categories
+}
+
+type CreateEvaluationTemplateVersionRequest struct {
+	ScoreMin   *float64                    `json:"scoreMin,omitempty"`
+	ScoreMax   *float64                    `json:"scoreMax,omitempty"`
+	Categories []EvaluationCategoryRequest `json:"categories" validate:"required,min=1,dive"`
+}
+
+type CreateEvaluationTemplateRequest struct {
+	Name        string                      `json:"name" validate:"required,min=2"`
+	Description string                      `json:"description"`
+	ScoreMin    *float64                    `json:"scoreMin,omitempty"`
+	ScoreMax    *float64                    `json:"scoreMax,omitempty"`
+	Categories  []EvaluationCategoryRequest `json:"categories" validate:"required,min=1,dive"`
+	Activate    bool                        `json:"activate"`
+}
+
+// UpdateEvaluationTemplateRequest altera nome/descrição no próprio template; mudanças de
+// escala ou categorias geram uma nova versão
+type UpdateEvaluationTemplateRequest struct {
+	Name        *string                     `json:"name,omitempty" validate:"omitempty,min=2"`
+	Description *string                     `json:"description,omitempty"`
+	ScoreMin    *float64                    `json:"scoreMin,omitempty"`
+	ScoreMax    *float64                    `json:"scoreMax,omitempty"`
+	Categories  []EvaluationCategoryRequest `json:"categories,omitempty" validate:"omitempty,min=1,dive"`
+}
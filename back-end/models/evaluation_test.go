@@ -0,0 +1,169 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// scoringTemplate tem duas categorias com pesos diferentes: técnico (peso 2, perguntas com
+// pesos 2 e 1) e colaboração (peso 1, uma pergunta)
+func scoringTemplate(scoreMin, scoreMax float64) *EvaluationTemplate {
+	return &EvaluationTemplate{
+		ScoreMin: scoreMin,
+		ScoreMax: scoreMax,
+		Categories: []EvaluationCategory{
+			{Key: "technical", Weight: 2, Questions: []EvaluationQuestion{
+				{Key: "code_quality", Weight: 2},
+				{Key: "delivery", Weight: 1},
+			}},
+			{Key: "collaboration", Weight: 1, Questions: []EvaluationQuestion{
+				{Key: "communication", Weight: 1},
+			}},
+		},
+	}
+}
+
+func TestEvaluationTemplateScore(t *testing.T) {
+	tests := []struct {
+		name           string
+		scoreMin       float64
+		scoreMax       float64
+		questionScores JSONB
+		wantCategories JSONB
+		wantAverage    float64
+		wantMessage    string
+		wantKeys       []string
+	}{
+		{
+			name:     "escala padrão",
+			scoreMin: DefaultScoreMin, scoreMax: DefaultScoreMax,
+			questionScores: JSONB{"code_quality": 9.0, "delivery": 6.0, "communication": 5.0},
+			wantCategories: JSONB{"technical": 8.0, "collaboration": 5.0},
+			wantAverage:    7,
+		},
+		{
+			name:     "escala de 1 a 5",
+			scoreMin: 1, scoreMax: 5,
+			questionScores: JSONB{"code_quality": 5.0, "delivery": 2.0, "communication": 3.0},
+			wantCategories: JSONB{"technical": 4.0, "collaboration": 3.0},
+			wantAverage:    3.67,
+		},
+		{
+			name:     "escala de 0 a 100 nos limites",
+			scoreMin: 0, scoreMax: 100,
+			questionScores: JSONB{"code_quality": 100.0, "delivery": 40.0, "communication": 0.0},
+			wantCategories: JSONB{"technical": 80.0, "collaboration": 0.0},
+			wantAverage:    53.33,
+		},
+		{
+			name:     "escala negativa",
+			scoreMin: -2, scoreMax: 2,
+			questionScores: JSONB{"code_quality": -2.0, "delivery": 1.0, "communication": 2.0},
+			wantCategories: JSONB{"technical": -1.0, "collaboration": 2.0},
+			wantAverage:    0,
+		},
+		{
+			name:     "abaixo do mínimo da escala",
+			scoreMin: 1, scoreMax: 5,
+			questionScores: JSONB{"code_quality": 0.0, "delivery": 2.0, "communication": 3.0},
+			wantMessage:    "Notas devem ser numéricas entre 1 e 5",
+			wantKeys:       []string{"code_quality"},
+		},
+		{
+			name:     "nota da escala padrão em template de 1 a 5",
+			scoreMin: 1, scoreMax: 5,
+			questionScores: JSONB{"code_quality": 9.0, "delivery": 7.0, "communication": 5.0},
+			wantMessage:    "Notas devem ser numéricas entre 1 e 5",
+			wantKeys:       []string{"code_quality", "delivery"},
+		},
+		{
+			name:     "acima do máximo da escala",
+			scoreMin: 0, scoreMax: 100,
+			questionScores: JSONB{"code_quality": 100.5, "delivery": 40.0, "communication": 0.0},
+			wantMessage:    "Notas devem ser numéricas entre 0 e 100",
+			wantKeys:       []string{"code_quality"},
+		},
+		{
+			name:     "nota não numérica",
+			scoreMin: DefaultScoreMin, scoreMax: DefaultScoreMax,
+			questionScores: JSONB{"code_quality": "9", "delivery": 6.0, "communication": 5.0},
+			wantMessage:    "Notas devem ser numéricas entre 0 e 10",
+			wantKeys:       []string{"code_quality"},
+		},
+		{
+			name:     "pergunta sem nota",
+			scoreMin: DefaultScoreMin, scoreMax: DefaultScoreMax,
+			questionScores: JSONB{"code_quality": 9.0, "delivery": 6.0},
+			wantMessage:    "Perguntas sem nota",
+			wantKeys:       []string{"communication"},
+		},
+		{
+			name:     "pergunta desconhecida",
+			scoreMin: DefaultScoreMin, scoreMax: DefaultScoreMax,
+			questionScores: JSONB{"code_quality": 9.0, "delivery": 6.0, "communication": 5.0, "teamwork": 7.0, "attitude": 8.0},
+			wantMessage:    "Perguntas desconhecidas para o template",
+			wantKeys:       []string{"attitude", "teamwork"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			categories, average, err := scoringTemplate(tt.scoreMin, tt.scoreMax).Score(tt.questionScores)
+
+			if tt.wantMessage != "" {
+				var validation *ScoreValidationError
+				if !errors.As(err, &validation) {
+					t.Fatalf("err = %v, want a ScoreValidationError", err)
+				}
+				if validation.Message != tt.wantMessage || !reflect.DeepEqual(validation.Keys, tt.wantKeys) {
+					t.Errorf("err = %q %v, want %q %v", validation.Message, validation.Keys, tt.wantMessage, tt.wantKeys)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Score: %v", err)
+			}
+			if !reflect.DeepEqual(categories, tt.wantCategories) {
+				t.Errorf("categories = %v, want %v", categories, tt.wantCategories)
+			}
+			if average != tt.wantAverage {
+				t.Errorf("average = %v, want %v", average, tt.wantAverage)
+			}
+		})
+	}
+}
+
+func TestEvaluationTemplateValidatePartial(t *testing.T) {
+	template := scoringTemplate(1, 5)
+
+	tests := []struct {
+		name           string
+		questionScores JSONB
+		wantKeys       []string
+	}{
+		{"sem notas", JSONB{}, nil},
+		{"notas parciais na escala", JSONB{"code_quality": 4.0}, nil},
+		{"nota nula conta como ausente", JSONB{"delivery": nil}, nil},
+		{"nota parcial fora da escala", JSONB{"code_quality": 4.0, "communication": 8.0}, []string{"communication"}},
+		{"pergunta desconhecida", JSONB{"teamwork": 3.0}, []string{"teamwork"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := template.ValidatePartial(tt.questionScores)
+			if tt.wantKeys == nil {
+				if err != nil {
+					t.Errorf("ValidatePartial: %v, want nil", err)
+				}
+				return
+			}
+
+			var validation *ScoreValidationError
+			if !errors.As(err, &validation) || !reflect.DeepEqual(validation.Keys, tt.wantKeys) {
+				t.Errorf("err = %v, want keys %v", err, tt.wantKeys)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status de um convite
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+)
+
+// InvitationTTL é a validade do link de convite; reenviar gera um novo link com nova validade
+const InvitationTTL = 7 * 24 * time.Hour
+
+// UserInvitation é o convite para um usuário definir a própria senha e ativar a conta
+type UserInvitation struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     *uuid.UUID `json:"userId" db:"user_id"`
+	CompanyID  uuid.UUID  `json:"companyId" db:"company_id"`
+	Email      string     `json:"email" db:"email"`
+	Name       string     `json:"name" db:"name"`
+	Role       string     `json:"role" db:"role"`
+	Status     string     `json:"status" db:"status"`
+	Expired    bool       `json:"expired" db:"expired"`
+	ExpiresAt  time.Time  `json:"expiresAt" db:"expires_at"`
+	InvitedBy  *uuid.UUID `json:"invitedBy" db:"invited_by"`
+	SendCount  int        `json:"sendCount" db:"send_count"`
+	LastSentAt time.Time  `json:"lastSentAt" db:"last_sent_at"`
+	AcceptedAt *time.Time `json:"acceptedAt" db:"accepted_at"`
+	RevokedAt  *time.Time `json:"revokedAt" db:"revoked_at"`
+	RevokedBy  *uuid.UUID `json:"revokedBy" db:"revoked_by"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type CreateInvitationRequest struct {
+	Name      string     `json:"name" validate:"required,min=2"`
+	Email     string     `json:"email" validate:"required,email"`
+	Role      string     `json:"role" validate:"required,oneof=admin company_admin manager user developer"`
+	CompanyID *uuid.UUID `json:"companyId"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// InvitationPreview é o que o convidado vê antes de aceitar
+type InvitationPreview struct {
+	Email       string    `json:"email" db:"email"`
+	Name        string    `json:"name" db:"name"`
+	Role        string    `json:"role" db:"role"`
+	CompanyName string    `json:"companyName" db:"company_name"`
+	ExpiresAt   time.Time `json:"expiresAt" db:"expires_at"`
+}
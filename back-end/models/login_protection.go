@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Regras de proteção do login: após LoginDelayAfter falhas seguidas cada nova tentativa
+// espera um intervalo crescente; em LoginLockoutAfter falhas a conta é bloqueada por
+// LoginLockoutDuration
+const (
+	LoginDelayAfter      = 3
+	LoginLockoutAfter    = 10
+	LoginLockoutDuration = 15 * time.Minute
+	LoginMaxDelay        = time.Minute
+)
+
+// Tipos de eventos de segurança
+const (
+	SecurityEventAccountLocked       = "account_locked"
+	SecurityEventAccountUnlocked     = "account_unlocked"
+	SecurityEventMFAEnabled          = "mfa_enabled"
+	SecurityEventMFADisabled         = "mfa_disabled"
+	SecurityEventMFAReset            = "mfa_reset"
+	SecurityEventMFARecoveryCodeUsed = "mfa_recovery_code_used"
+	SecurityEventPasswordReset       = "password_reset"
+	SecurityEventSSOProvisioned      = "sso_user_provisioned"
+	SecurityEventAPITokenCreated     = "api_token_created"
+	SecurityEventAPITokenRevoked     = "api_token_revoked"
+)
+
+// LoginDelay retorna quanto tempo esperar após a última falha antes de aceitar nova tentativa:
+// 1s, 2s, 4s... a partir da LoginDelayAfter-ésima falha, limitado a LoginMaxDelay
+func LoginDelay(failedAttempts int) time.Duration {
+	if failedAttempts < LoginDelayAfter {
+		return 0
+	}
+
+	delay := time.Second << uint(failedAttempts-LoginDelayAfter)
+	if delay > LoginMaxDelay || delay <= 0 {
+		return LoginMaxDelay
+	}
+	return delay
+}
+
+// SecurityEvent é um evento de autenticação relevante para auditoria
+type SecurityEvent struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	EventType string     `json:"eventType" db:"event_type"`
+	UserID    *uuid.UUID `json:"userId" db:"user_id"`
+	CompanyID *uuid.UUID `json:"companyId" db:"company_id"`
+	Email     *string    `json:"email" db:"email"`
+	IPAddress *string    `json:"ipAddress" db:"ip_address"`
+	Details   JSONB      `json:"details" db:"details"`
+	CreatedBy *uuid.UUID `json:"createdBy" db:"created_by"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
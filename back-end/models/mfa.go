@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Regras da autenticação em duas etapas
+const (
+	MFAIssuer            = "Tivix Performance Tracker"
+	MFARecoveryCodeCount = 10
+	MFAChallengeTTL      = 5 * time.Minute
+	MFAChallengeMaxTries = 5
+)
+
+// MFARequiredRoles são os papéis afetados pela política de 2FA obrigatório da empresa
+var MFARequiredRoles = []string{"admin", "company_admin", "manager"}
+
+// RoleRequiresMFA indica se a política de 2FA obrigatório se aplica ao papel
+func RoleRequiresMFA(role string) bool {
+	for _, required := range MFARequiredRoles {
+		if role == required {
+			return true
+		}
+	}
+	return false
+}
+
+// CompanySecurityPolicy é a política de segurança da empresa; sem registro, nada é exigido
+type CompanySecurityPolicy struct {
+	CompanyID  uuid.UUID  `json:"companyId" db:"company_id"`
+	RequireMFA bool       `json:"requireMfa" db:"require_mfa"`
+	UpdatedBy  *uuid.UUID `json:"updatedBy" db:"updated_by"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type UpdateCompanySecurityPolicyRequest struct {
+	RequireMFA *bool `json:"requireMfa" validate:"required"`
+}
+
+// UserMFA é o cadastro de 2FA do usuário; EnabledAt nulo significa cadastro pendente
+type UserMFA struct {
+	Secret       string     `db:"secret"`
+	EnabledAt    *time.Time `db:"enabled_at"`
+	LastUsedStep int64      `db:"last_used_step"`
+}
+
+// MFAChallenge é a etapa pendente de um login em duas etapas
+type MFAChallenge struct {
+	ID       uuid.UUID `db:"id"`
+	UserID   uuid.UUID `db:"user_id"`
+	Attempts int       `db:"attempts"`
+}
+
+// MFAStatus resume a configuração de 2FA do usuário logado
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabledAt"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int        `json:"recoveryCodesRemaining"`
+}
+
+// MFAEnrollment é devolvido ao iniciar o cadastro: o segredo e a URI para o QR code
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableMFARequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code"`
+	// RecoveryCode pode substituir Code quando o aplicativo autenticador foi perdido
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// MFAChallengeResponse é devolvido pelo login quando a senha confere mas falta a segunda etapa
+type MFAChallengeResponse struct {
+	MFARequired    bool   `json:"mfaRequired"`
+	ChallengeToken string `json:"challengeToken"`
+	ExpiresIn      int    `json:"expiresIn"`
+}
+
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recoveryCode"`
+}
@@ -58,22 +58,45 @@ type Developer struct {
 	LatestPerformanceScore float64    `json:"latestPerformanceScore" db:"latest_performance_score"`
 	TeamID                 *uuid.UUID `json:"teamId" db:"team_id"`
 	CompanyID              *uuid.UUID `json:"companyId" db:"company_id"`
+	UserID                 *uuid.UUID `json:"userId" db:"user_id"` // conta usada pelo próprio desenvolvedor
 	ArchivedAt             *time.Time `json:"archivedAt" db:"archived_at"`
 	CreatedAt              time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt              time.Time  `json:"updatedAt" db:"updated_at"`
 }
 
 type PerformanceReport struct {
-	ID                   uuid.UUID `json:"id" db:"id"`
-	DeveloperID          uuid.UUID `json:"developerId" db:"developer_id"`
-	Month                string    `json:"month" db:"month"`
-	QuestionScores       JSONB     `json:"questionScores" db:"question_scores"`
-	CategoryScores       JSONB     `json:"categoryScores" db:"category_scores"`
-	WeightedAverageScore float64   `json:"weightedAverageScore" db:"weighted_average_score"`
-	Highlights           string    `json:"highlights" db:"highlights"`
-	PointsToDevelop      string    `json:"pointsToDevelop" db:"points_to_develop"`
-	CreatedAt            time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt            time.Time `json:"updatedAt" db:"updated_at"`
+	ID                   uuid.UUID  `json:"id" db:"id"`
+	DeveloperID          uuid.UUID  `json:"developerId" db:"developer_id"`
+	Month                string     `json:"month" db:"month"`
+	QuestionScores       JSONB      `json:"questionScores" db:"question_scores"`
+	CategoryScores       JSONB      `json:"categoryScores" db:"category_scores"`
+	WeightedAverageScore float64    `json:"weightedAverageScore" db:"weighted_average_score"`
+	Highlights           string     `json:"highlights" db:"highlights"`
+	PointsToDevelop      string     `json:"pointsToDevelop" db:"points_to_develop"`
+	TemplateVersionID    *uuid.UUID `json:"templateVersionId" db:"template_version_id"`
+	Status               string     `json:"status" db:"status"`
+	SubmittedAt          *time.Time `json:"submittedAt" db:"submitted_at"`
+	SubmittedBy          *uuid.UUID `json:"submittedBy" db:"submitted_by"`
+	AcknowledgedAt       *time.Time `json:"acknowledgedAt" db:"acknowledged_at"`
+	AcknowledgedBy       *uuid.UUID `json:"acknowledgedBy" db:"acknowledged_by"`
+	LockedAt             *time.Time `json:"lockedAt" db:"locked_at"`
+	LockedBy             *uuid.UUID `json:"lockedBy" db:"locked_by"`
+	// CalibratedScore é a nota ajustada em sessão de calibração; WeightedAverageScore mantém a original
+	CalibratedScore         *float64   `json:"calibratedScore" db:"calibrated_score"`
+	CalibrationAdjustmentID *uuid.UUID `json:"calibrationAdjustmentId" db:"calibration_adjustment_id"`
+	CreatedAt               time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt               time.Time  `json:"updatedAt" db:"updated_at"`
+
+	// PeerFeedback é preenchido apenas nas visualizações detalhadas do relatório
+	PeerFeedback *PeerFeedbackSummary `json:"peerFeedback,omitempty" db:"-"`
+}
+
+// PerformanceStats resume as notas dos relatórios enviados (calibrados entram com a nota calibrada)
+type PerformanceStats struct {
+	TotalReports int     `json:"totalReports" db:"total_reports"`
+	AverageScore float64 `json:"averageScore" db:"average_score"`
+	HighestScore float64 `json:"highestScore" db:"highest_score"`
+	LowestScore  float64 `json:"lowestScore" db:"lowest_score"`
 }
 
 type CreateTeamRequest struct {
@@ -90,6 +113,22 @@ type UpdateTeamRequest struct {
 	CompanyID   *uuid.UUID `json:"companyId,omitempty"`
 }
 
+// TeamManager é um usuário atribuído ao time; sem a permissão teams:all, o usuário só
+// acessa desenvolvedores e relatórios dos times atribuídos a ele
+type TeamManager struct {
+	UserID     uuid.UUID  `json:"userId" db:"user_id"`
+	TeamID     uuid.UUID  `json:"teamId" db:"team_id"`
+	Name       string     `json:"name" db:"name"`
+	Email      string     `json:"email" db:"email"`
+	Role       string     `json:"role" db:"role"`
+	AssignedBy *uuid.UUID `json:"assignedBy" db:"assigned_by"`
+	AssignedAt time.Time  `json:"assignedAt" db:"assigned_at"`
+}
+
+type AssignTeamManagerRequest struct {
+	UserID uuid.UUID `json:"userId" validate:"required"`
+}
+
 type CreateDeveloperRequest struct {
 	Name      string     `json:"name" validate:"required,min=2"`
 	Role      string     `json:"role" validate:"required,min=2"`
@@ -97,22 +136,29 @@ type CreateDeveloperRequest struct {
 	CompanyID *uuid.UUID `json:"companyId,omitempty"`
 }
 
+// UpdateDeveloperRequest não inclui latestPerformanceScore: o valor é derivado dos relatórios
 type UpdateDeveloperRequest struct {
-	Name                   *string    `json:"name,omitempty"`
-	Role                   *string    `json:"role,omitempty"`
-	LatestPerformanceScore *float64   `json:"latestPerformanceScore,omitempty"`
-	TeamID                 *uuid.UUID `json:"teamId,omitempty"`
-	CompanyID              *uuid.UUID `json:"companyId,omitempty"`
+	Name      *string    `json:"name,omitempty"`
+	Role      *string    `json:"role,omitempty"`
+	TeamID    *uuid.UUID `json:"teamId,omitempty"`
+	CompanyID *uuid.UUID `json:"companyId,omitempty"`
 }
 
+// CreatePerformanceReportRequest não aceita notas por categoria nem média ponderada:
+// ambas são recalculadas no servidor a partir de QuestionScores.
+// Status "draft" salva uma avaliação parcial; sem status o relatório é enviado direto.
 type CreatePerformanceReportRequest struct {
-	DeveloperID          uuid.UUID `json:"developerId" validate:"required"`
-	Month                string    `json:"month" validate:"required"`
-	QuestionScores       JSONB     `json:"questionScores" validate:"required"`
-	CategoryScores       JSONB     `json:"categoryScores" validate:"required"`
-	WeightedAverageScore float64   `json:"weightedAverageScore" validate:"required,min=0,max=10"`
-	Highlights           string    `json:"highlights"`
-	PointsToDevelop      string    `json:"pointsToDevelop"`
+	DeveloperID     uuid.UUID `json:"developerId" validate:"required"`
+	Month           string    `json:"month" validate:"required"`
+	QuestionScores  JSONB     `json:"questionScores" validate:"required"`
+	Highlights      string    `json:"highlights"`
+	PointsToDevelop string    `json:"pointsToDevelop"`
+	Status          string    `json:"status,omitempty" validate:"omitempty,oneof=draft submitted"`
+}
+
+// LinkDeveloperUserRequest vincula (ou desvincula, com userId nulo) a conta de usuário do desenvolvedor
+type LinkDeveloperUserRequest struct {
+	UserID *uuid.UUID `json:"userId"`
 }
 
 type ArchiveDeveloperRequest struct {
@@ -124,7 +170,7 @@ type User struct {
 	Email               string     `json:"email" db:"email"`
 	Password            string     `json:"-" db:"password"` // O "-" faz com que este campo não seja serializado no JSON
 	Name                string     `json:"name" db:"name"`
-	Role                string     `json:"role" db:"role"` // admin, manager, user
+	Role                string     `json:"role" db:"role"` // admin, company_admin, manager, user, developer
 	CompanyID           *uuid.UUID `json:"companyId" db:"company_id"`
 	NeedsPasswordChange bool       `json:"needsPasswordChange" db:"needs_password_change"`
 	IsActive            bool       `json:"isActive" db:"is_active"`
@@ -158,8 +204,10 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // validade do access token em segundos
+	User         User   `json:"user"`
 }
 
 type JWTClaims struct {
@@ -174,7 +222,7 @@ type JWTClaims struct {
 type CreateUserRequest struct {
 	Name              string     `json:"name" validate:"required,min=2"`
 	Email             string     `json:"email" validate:"required,email"`
-	Role              string     `json:"role" validate:"required,oneof=admin manager user"`
+	Role              string     `json:"role" validate:"required,oneof=admin company_admin manager user developer"`
 	CompanyID         *uuid.UUID `json:"companyId" validate:"required"`
 	TemporaryPassword string     `json:"temporaryPassword" validate:"required,min=8"`
 }
@@ -182,7 +230,7 @@ type CreateUserRequest struct {
 type UpdateUserRequest struct {
 	Name      *string    `json:"name,omitempty"`
 	Email     *string    `json:"email,omitempty"`
-	Role      *string    `json:"role,omitempty" validate:"omitempty,oneof=admin manager user"`
+	Role      *string    `json:"role,omitempty" validate:"omitempty,oneof=admin company_admin manager user developer"`
 	CompanyID *uuid.UUID `json:"companyId,omitempty"`
 	IsActive  *bool      `json:"isActive,omitempty"`
 }
@@ -206,3 +254,33 @@ type UpdateCompanyRequest struct {
 	IsActive    *bool   `json:"isActive,omitempty"`
 	NewPassword string  `json:"newPassword" validate:"required,min=8"`
 }
+
+type UpdatePerformanceReportRequest struct {
+	Month           *string `json:"month,omitempty" validate:"omitempty,min=7,max=7"`
+	QuestionScores  JSONB   `json:"questionScores,omitempty"`
+	Highlights      *string `json:"highlights,omitempty"`
+	PointsToDevelop *string `json:"pointsToDevelop,omitempty"`
+}
+
+// PerformanceReportRevision guarda uma versão de um relatório; OldData/NewData são
+// snapshots JSON do relatório antes e depois da alteração
+type PerformanceReportRevision struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ReportID      uuid.UUID  `json:"reportId" db:"report_id"`
+	Revision      int        `json:"revision" db:"revision"`
+	Action        string     `json:"action" db:"action"` // create, update, delete, submit, acknowledge, lock
+	CompanyID     *uuid.UUID `json:"companyId" db:"company_id"`
+	ChangedBy     *uuid.UUID `json:"changedBy" db:"changed_by"`
+	ChangedByName *string    `json:"changedByName" db:"changed_by_name"`
+	OldData       JSONB      `json:"oldData" db:"old_data"`
+	NewData       JSONB      `json:"newData" db:"new_data"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// PerformanceTrendPoint é um ponto da evolução mensal da pontuação de um desenvolvedor
+type PerformanceTrendPoint struct {
+	Month                string   `json:"month" db:"month"`
+	WeightedAverageScore float64  `json:"weightedAverageScore" db:"weighted_average_score"`
+	CalibratedScore      *float64 `json:"calibratedScore" db:"calibrated_score"`
+	CategoryScores       JSONB    `json:"categoryScores" db:"category_scores"`
+}
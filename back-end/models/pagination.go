@@ -0,0 +1,8 @@
+package models
+
+// DefaultPageSize e MaxPageSize limitam as listagens paginadas por cursor (desenvolvedores,
+// relatórios, times, usuários e empresas)
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PasswordResetTTL é a validade do link de redefinição de senha
+const PasswordResetTTL = time.Hour
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,min=8"`
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status de uma rodada de feedback 360
+const (
+	FeedbackRoundOpen   = "open"
+	FeedbackRoundClosed = "closed"
+)
+
+// DefaultMinRespondents é o mínimo de respostas para exibir o feedback de uma rodada anônima
+const DefaultMinRespondents = 3
+
+// FeedbackRound é uma rodada de feedback 360 sobre um desenvolvedor em um mês
+type FeedbackRound struct {
+	ID                uuid.UUID  `json:"id" db:"id"`
+	CompanyID         uuid.UUID  `json:"companyId" db:"company_id"`
+	DeveloperID       uuid.UUID  `json:"developerId" db:"developer_id"`
+	Month             string     `json:"month" db:"month"`
+	TemplateVersionID uuid.UUID  `json:"templateVersionId" db:"template_version_id"`
+	Status            string     `json:"status" db:"status"`
+	Anonymize         bool       `json:"anonymize" db:"anonymize"`
+	MinRespondents    int        `json:"minRespondents" db:"min_respondents"`
+	CreatedBy         *uuid.UUID `json:"createdBy" db:"created_by"`
+	ClosedAt          *time.Time `json:"closedAt" db:"closed_at"`
+	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// FeedbackNomination mostra quem foi indicado e se já respondeu, sem expor as respostas
+type FeedbackNomination struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	RoundID      uuid.UUID  `json:"roundId" db:"round_id"`
+	ReviewerID   uuid.UUID  `json:"reviewerId" db:"reviewer_id"`
+	ReviewerName string     `json:"reviewerName" db:"reviewer_name"`
+	SubmittedAt  *time.Time `json:"submittedAt" db:"submitted_at"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// FeedbackRequest é um pedido de feedback pendente (ou respondido) para o usuário logado
+type FeedbackRequest struct {
+	RoundID           uuid.UUID  `json:"roundId" db:"round_id"`
+	DeveloperID       uuid.UUID  `json:"developerId" db:"developer_id"`
+	DeveloperName     string     `json:"developerName" db:"developer_name"`
+	Month             string     `json:"month" db:"month"`
+	Status            string     `json:"status" db:"status"`
+	TemplateVersionID uuid.UUID  `json:"templateVersionId" db:"template_version_id"`
+	SubmittedAt       *time.Time `json:"submittedAt" db:"submitted_at"`
+}
+
+// PeerResponse é uma resposta enviada por um par, usada apenas para agregação
+type PeerResponse struct {
+	ReviewerName         string  `db:"reviewer_name"`
+	QuestionScores       JSONB   `db:"question_scores"`
+	CategoryScores       JSONB   `db:"category_scores"`
+	WeightedAverageScore float64 `db:"weighted_average_score"`
+	Comments             string  `db:"comments"`
+}
+
+type CreateFeedbackRoundRequest struct {
+	DeveloperID    uuid.UUID `json:"developerId" validate:"required"`
+	Month          string    `json:"month" validate:"required,len=7"`
+	Anonymize      *bool     `json:"anonymize,omitempty"`
+	MinRespondents *int      `json:"minRespondents,omitempty" validate:"omitempty,min=1,max=50"`
+}
+
+// NominatePeersRequest aceita usuários diretamente ou desenvolvedores com conta vinculada
+type NominatePeersRequest struct {
+	UserIDs      []uuid.UUID `json:"userIds"`
+	DeveloperIDs []uuid.UUID `json:"developerIds"`
+}
+
+type SubmitPeerFeedbackRequest struct {
+	QuestionScores JSONB  `json:"questionScores" validate:"required"`
+	Comments       string `json:"comments"`
+}
+
+type PeerFeedbackComment struct {
+	ReviewerName *string `json:"reviewerName,omitempty"`
+	Comment      string  `json:"comment"`
+}
+
+// PeerFeedbackSummary é o agregado de uma rodada. Em rodadas anônimas ainda abertas ou com
+// menos respostas que o mínimo, Hidden é true e nenhuma nota ou comentário é exposto.
+type PeerFeedbackSummary struct {
+	RoundID          uuid.UUID             `json:"roundId"`
+	Status           string                `json:"status"`
+	Anonymized       bool                  `json:"anonymized"`
+	MinRespondents   int                   `json:"minRespondents"`
+	Nominated        int                   `json:"nominated"`
+	Respondents      int                   `json:"respondents"`
+	Hidden           bool                  `json:"hidden"`
+	AverageScore     *float64              `json:"averageScore,omitempty"`
+	QuestionAverages JSONB                 `json:"questionAverages,omitempty"`
+	CategoryAverages JSONB                 `json:"categoryAverages,omitempty"`
+	Comments         []PeerFeedbackComment `json:"comments,omitempty"`
+}
+
+// SummarizePeerFeedback agrega as respostas de uma rodada: médias por pergunta, por categoria
+// e geral. Rodadas anônimas só são reveladas depois de encerradas, para que a chegada de cada
+// resposta não mude o agregado à vista de quem acompanha. Comentários anônimos são ordenados
+// pelo texto para não revelar a ordem de envio.
+func SummarizePeerFeedback(round *FeedbackRound, nominated int, responses []PeerResponse) PeerFeedbackSummary {
+	summary := PeerFeedbackSummary{
+		RoundID:        round.ID,
+		Status:         round.Status,
+		Anonymized:     round.Anonymize,
+		MinRespondents: round.MinRespondents,
+		Nominated:      nominated,
+		Respondents:    len(responses),
+	}
+
+	if len(responses) == 0 || (round.Anonymize && (round.Status != FeedbackRoundClosed || len(responses) < round.MinRespondents)) {
+		summary.Hidden = true
+		return summary
+	}
+
+	summary.QuestionAverages = averageScores(responses, func(r PeerResponse) JSONB { return r.QuestionScores })
+	summary.CategoryAverages = averageScores(responses, func(r PeerResponse) JSONB { return r.CategoryScores })
+
+	var total float64
+	for _, response := range responses {
+		total += response.WeightedAverageScore
+	}
+	average := roundScore(total / float64(len(responses)))
+	summary.AverageScore = &average
+
+	for _, response := range responses {
+		if response.Comments == "" {
+			continue
+		}
+		comment := PeerFeedbackComment{Comment: response.Comments}
+		if !round.Anonymize {
+			name := response.ReviewerName
+			comment.ReviewerName = &name
+		}
+		summary.Comments = append(summary.Comments, comment)
+	}
+	if round.Anonymize {
+		sort.Slice(summary.Comments, func(i, j int) bool {
+			return summary.Comments[i].Comment < summary.Comments[j].Comment
+		})
+	}
+
+	return summary
+}
+
+func averageScores(responses []PeerResponse, scores func(PeerResponse) JSONB) JSONB {
+	totals := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, response := range responses {
+		for key, raw := range scores(response) {
+			if value, ok := raw.(float64); ok {
+				totals[key] += value
+				counts[key]++
+			}
+		}
+	}
+
+	averages := make(JSONB, len(totals))
+	for key, total := range totals {
+		averages[key] = roundScore(total / float64(counts[key]))
+	}
+	return averages
+}
@@ -0,0 +1,69 @@
+package models
+
+import "testing"
+
+func TestSummarizePeerFeedbackHiding(t *testing.T) {
+	responses := []PeerResponse{
+		{ReviewerName: "Ana", WeightedAverageScore: 8, Comments: "b"},
+		{ReviewerName: "Bruno", WeightedAverageScore: 6, Comments: "a"},
+		{ReviewerName: "Carla", WeightedAverageScore: 7},
+	}
+
+	tests := []struct {
+		name       string
+		anonymize  bool
+		status     string
+		min        int
+		responses  []PeerResponse
+		wantHidden bool
+	}{
+		{"sem respostas", false, FeedbackRoundClosed, 1, nil, true},
+		{"identificada e aberta", false, FeedbackRoundOpen, 3, responses[:1], false},
+		{"anônima e aberta com o mínimo", true, FeedbackRoundOpen, 3, responses, true},
+		{"anônima e encerrada abaixo do mínimo", true, FeedbackRoundClosed, 3, responses[:2], true},
+		{"anônima e encerrada com o mínimo", true, FeedbackRoundClosed, 3, responses, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			round := &FeedbackRound{Anonymize: tt.anonymize, Status: tt.status, MinRespondents: tt.min}
+			summary := SummarizePeerFeedback(round, 3, tt.responses)
+
+			if summary.Hidden != tt.wantHidden {
+				t.Fatalf("hidden = %v, want %v", summary.Hidden, tt.wantHidden)
+			}
+			if summary.Respondents != len(tt.responses) {
+				t.Errorf("respondents = %d, want %d", summary.Respondents, len(tt.responses))
+			}
+			if tt.wantHidden {
+				if summary.AverageScore != nil || summary.Comments != nil {
+					t.Errorf("hidden summary exposes scores or comments: %+v", summary)
+				}
+				return
+			}
+			if summary.AverageScore == nil {
+				t.Fatal("averageScore = nil, want a value")
+			}
+		})
+	}
+}
+
+func TestSummarizePeerFeedbackAnonymousComments(t *testing.T) {
+	round := &FeedbackRound{Anonymize: true, Status: FeedbackRoundClosed, MinRespondents: 2}
+	summary := SummarizePeerFeedback(round, 2, []PeerResponse{
+		{ReviewerName: "Ana", WeightedAverageScore: 8, Comments: "segundo"},
+		{ReviewerName: "Bruno", WeightedAverageScore: 7, Comments: "primeiro"},
+	})
+
+	if *summary.AverageScore != 7.5 {
+		t.Errorf("averageScore = %v, want 7.5", *summary.AverageScore)
+	}
+	if len(summary.Comments) != 2 || summary.Comments[0].Comment != "primeiro" {
+		t.Fatalf("comments = %+v, want them sorted by text", summary.Comments)
+	}
+	for _, comment := range summary.Comments {
+		if comment.ReviewerName != nil {
+			t.Errorf("anonymous comment names reviewer %q", *comment.ReviewerName)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Permissões do sistema, no formato <recurso>:<ação>[:<alcance>]. As rotas exigem permissões
+// e não papéis; os papéis são apenas conjuntos de permissões.
+const (
+	PermReportsRead           = "reports:read"
+	PermReportsReadOwnTeam    = "reports:read:own-team"
+	PermReportsReadDrafts     = "reports:read:drafts"
+	PermReportsWrite          = "reports:write"
+	PermReportsSubmit         = "reports:submit"
+	PermReportsAcknowledge    = "reports:acknowledge"
+	PermReportsAcknowledgeOwn = "reports:acknowledge:own"
+	PermReportsLock           = "reports:lock"
+	PermReportsRevisions      = "reports:revisions"
+
+	PermDevelopersRead    = "developers:read"
+	PermDevelopersWrite   = "developers:write"
+	PermDevelopersArchive = "developers:archive"
+	PermDevelopersDelete  = "developers:delete"
+
+	PermSelfAssessmentsRead = "self-assessments:read"
+
+	PermTeamsRead   = "teams:read"
+	PermTeamsWrite  = "teams:write"
+	PermTeamsDelete = "teams:delete"
+	PermTeamsAll    = "teams:all"
+
+	PermTemplatesRead  = "templates:read"
+	PermTemplatesWrite = "templates:write"
+
+	PermReviewsManage     = "reviews:manage"
+	PermCalibrationManage = "calibration:manage"
+
+	PermUsersRead             = "users:read"
+	PermUsersWrite            = "users:write"
+	PermUsersSecurity         = "users:security"
+	PermServiceAccountsManage = "service-accounts:manage"
+	PermRolesManage           = "roles:manage"
+	PermAuditRead             = "audit:read"
+	PermAPITokensCreate       = "api_tokens:create"
+
+	PermCompaniesRead   = "companies:read"
+	PermCompaniesManage = "companies:manage"
+	PermCompaniesAll    = "companies:all"
+)
+
+// PermissionInfo descreve uma permissão do registro. Permissões não atribuíveis são
+// exclusivas do administrador do sistema e não podem entrar em papéis personalizados.
+type PermissionInfo struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Assignable  bool   `json:"assignable"`
+}
+
+// Permissions é o registro de todas as permissões conhecidas
+var Permissions = []PermissionInfo{
+	{PermReportsRead, "Ver todos os relatórios da empresa", true},
+	{PermReportsReadOwnTeam, "Ver relatórios apenas dos times do próprio usuário", true},
+	{PermReportsReadDrafts, "Ver relatórios em rascunho", true},
+	{PermReportsWrite, "Criar, editar e excluir relatórios", true},
+	{PermReportsSubmit, "Enviar relatórios ao desenvolvedor", true},
+	{PermReportsAcknowledge, "Registrar ciência em qualquer relatório", true},
+	{PermReportsAcknowledgeOwn, "Registrar ciência nos próprios relatórios", true},
+	{PermReportsLock, "Bloquear relatórios", true},
+	{PermReportsRevisions, "Ver o histórico de alterações dos relatórios", true},
+	{PermDevelopersRead, "Ver desenvolvedores", true},
+	{PermDevelopersWrite, "Criar e editar desenvolvedores e vincular usuários", true},
+	{PermDevelopersArchive, "Arquivar e reativar desenvolvedores", true},
+	{PermDevelopersDelete, "Excluir desenvolvedores", true},
+	{PermSelfAssessmentsRead, "Ver autoavaliações dos desenvolvedores", true},
+	{PermTeamsRead, "Ver times", true},
+	{PermTeamsWrite, "Criar e editar times", true},
+	{PermTeamsDelete, "Excluir times", true},
+	{PermTeamsAll, "Acessar todos os times da empresa; sem ela, apenas os times atribuídos ao usuário", true},
+	{PermTemplatesRead, "Ver templates de avaliação", true},
+	{PermTemplatesWrite, "Gerenciar templates de avaliação da empresa", true},
+	{PermReviewsManage, "Gerenciar ciclos de avaliação e rodadas de feedback 360", true},
+	{PermCalibrationManage, "Gerenciar sessões de calibração", true},
+	{PermUsersRead, "Ver usuários da empresa", true},
+	{PermUsersWrite, "Criar, convidar, editar e excluir usuários", true},
+	{PermUsersSecurity, "Encerrar sessões, desbloquear login e redefinir 2FA de usuários", false},
+	{PermServiceAccountsManage, "Gerenciar contas de serviço e seus tokens", true},
+	{PermRolesManage, "Gerenciar papéis personalizados e atribuí-los", true},
+	{PermAuditRead, "Consultar e verificar a trilha de auditoria da empresa", true},
+	{PermAPITokensCreate, "Criar tokens de API pessoais", true},
+	{PermCompaniesRead, "Ver a própria empresa", true},
+	{PermCompaniesManage, "Criar e configurar empresas, versões globais de templates", false},
+	{PermCompaniesAll, "Acessar os dados de todas as empresas", false},
+}
+
+// managerPermissions é o conjunto do gerente, restrito aos times atribuídos a ele
+var managerPermissions = []string{
+	PermReportsRead, PermReportsReadDrafts, PermReportsWrite, PermReportsSubmit, PermReportsLock, PermReportsRevisions,
+	PermDevelopersRead, PermDevelopersWrite, PermDevelopersArchive, PermDevelopersDelete, PermSelfAssessmentsRead,
+	PermTeamsRead, PermTeamsWrite,
+	PermTemplatesRead, PermTemplatesWrite,
+	PermReviewsManage, PermCalibrationManage,
+	PermUsersRead, PermUsersWrite, PermServiceAccountsManage, PermRolesManage,
+	PermAuditRead, PermAPITokensCreate, PermCompaniesRead,
+}
+
+// RolePermissions são os papéis padrão como conjuntos de permissões. O admin recebe todas;
+// o company_admin tem as do gerente sobre todos os times da empresa.
+var RolePermissions = map[string][]string{
+	"admin":         AllPermissions(),
+	"company_admin": append([]string{PermTeamsAll, PermTeamsDelete}, managerPermissions...),
+	"manager":       managerPermissions,
+	"user": {
+		PermReportsReadOwnTeam, PermDevelopersRead, PermTeamsRead, PermTemplatesRead, PermAPITokensCreate,
+	},
+	"developer": {
+		PermReportsAcknowledgeOwn, PermTemplatesRead,
+	},
+}
+
+// CustomRoleBaseRoles são os papéis padrão que podem receber um papel personalizado;
+// admins são globais e desenvolvedores usam apenas as rotas /me
+var CustomRoleBaseRoles = []string{"manager", "user"}
+
+// AllPermissions devolve as chaves de todas as permissões do registro
+func AllPermissions() []string {
+	keys := make([]string, 0, len(Permissions))
+	for _, permission := range Permissions {
+		keys = append(keys, permission.Key)
+	}
+	return keys
+}
+
+// LookupPermission busca a permissão no registro
+func LookupPermission(key string) (PermissionInfo, bool) {
+	for _, permission := range Permissions {
+		if permission.Key == key {
+			return permission, true
+		}
+	}
+	return PermissionInfo{}, false
+}
+
+// CompanyRole é um papel personalizado da empresa; substitui as permissões do papel padrão
+// dos usuários a quem é atribuído
+type CompanyRole struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	CompanyID   uuid.UUID      `json:"companyId" db:"company_id"`
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	Permissions pq.StringArray `json:"permissions" db:"permissions"`
+	UserCount   int            `json:"userCount" db:"user_count"`
+	CreatedBy   *uuid.UUID     `json:"createdBy" db:"created_by"`
+	UpdatedBy   *uuid.UUID     `json:"updatedBy" db:"updated_by"`
+	CreatedAt   time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updatedAt" db:"updated_at"`
+}
+
+type CreateCompanyRoleRequest struct {
+	Name        string     `json:"name" validate:"required,min=2,max=100"`
+	Description string     `json:"description"`
+	Permissions []string   `json:"permissions" validate:"required"`
+	CompanyID   *uuid.UUID `json:"companyId"`
+}
+
+type UpdateCompanyRoleRequest struct {
+	Name        *string  `json:"name" validate:"omitempty,min=2,max=100"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// AssignUserRoleRequest atribui um papel personalizado; RoleID nulo volta ao papel padrão
+type AssignUserRoleRequest struct {
+	RoleID *uuid.UUID `json:"roleId"`
+}
@@ -0,0 +1,54 @@
+package models
+
+// Status do ciclo de vida de um relatório de performance
+const (
+	ReportStatusDraft        = "draft"
+	ReportStatusSubmitted    = "submitted"
+	ReportStatusAcknowledged = "acknowledged"
+	ReportStatusLocked       = "locked"
+)
+
+// ReportTransition descreve uma ação do fluxo: o status de origem, o de destino,
+// a permissão para executá-la em qualquer relatório e, opcionalmente, a permissão
+// para executá-la apenas nos relatórios do próprio usuário
+type ReportTransition struct {
+	Action        string
+	From          string
+	To            string
+	Permission    string
+	OwnPermission string
+}
+
+// ReportTransitions lista as transições permitidas, indexadas pela ação
+var ReportTransitions = map[string]ReportTransition{
+	"submit": {
+		Action:     "submit",
+		From:       ReportStatusDraft,
+		To:         ReportStatusSubmitted,
+		Permission: PermReportsSubmit,
+	},
+	"acknowledge": {
+		Action:        "acknowledge",
+		From:          ReportStatusSubmitted,
+		To:            ReportStatusAcknowledged,
+		Permission:    PermReportsAcknowledge,
+		OwnPermission: PermReportsAcknowledgeOwn,
+	},
+	"lock": {
+		Action:     "lock",
+		From:       ReportStatusAcknowledged,
+		To:         ReportStatusLocked,
+		Permission: PermReportsLock,
+	},
+}
+
+// IsReportEditable indica se o conteúdo do relatório ainda pode ser alterado ou excluído.
+// Depois que o desenvolvedor toma ciência, o relatório fica congelado.
+func IsReportEditable(status string) bool {
+	return status == ReportStatusDraft || status == ReportStatusSubmitted
+}
+
+// IsReportPublished indica se o relatório já foi enviado e deve aparecer em estatísticas
+func IsReportPublished(status string) bool {
+	return status == ReportStatusSubmitted || status == ReportStatusAcknowledged || status == ReportStatusLocked
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status de um ciclo de avaliação
+const (
+	ReviewCyclePlanned = "planned"
+	ReviewCycleOpen    = "open"
+	ReviewCycleClosed  = "closed"
+)
+
+// ReviewCycle agrupa as avaliações de um período de uma empresa
+type ReviewCycle struct {
+	ID                uuid.UUID   `json:"id" db:"id"`
+	CompanyID         uuid.UUID   `json:"companyId" db:"company_id"`
+	Name              string      `json:"name" db:"name"`
+	Month             string      `json:"month" db:"month"`
+	OpensAt           time.Time   `json:"opensAt" db:"opens_at"`
+	ClosesAt          time.Time   `json:"closesAt" db:"closes_at"`
+	TemplateVersionID uuid.UUID   `json:"templateVersionId" db:"template_version_id"`
+	Status            string      `json:"status" db:"status"`
+	CreatedBy         *uuid.UUID  `json:"createdBy" db:"created_by"`
+	OpenedAt          *time.Time  `json:"openedAt" db:"opened_at"`
+	ClosedAt          *time.Time  `json:"closedAt" db:"closed_at"`
+	CreatedAt         time.Time   `json:"createdAt" db:"created_at"`
+	UpdatedAt         time.Time   `json:"updatedAt" db:"updated_at"`
+	TeamIDs           []uuid.UUID `json:"teamIds" db:"-"` // vazio = todos os times
+}
+
+// CreateReviewCycleRequest usa datas no formato YYYY-MM-DD. Sem templateVersionId,
+// o ciclo usa o template ativo da empresa no momento da criação.
+type CreateReviewCycleRequest struct {
+	CompanyID         *uuid.UUID  `json:"companyId,omitempty"`
+	Name              string      `json:"name" validate:"required,min=2"`
+	Month             string      `json:"month" validate:"required,len=7"`
+	OpensAt           string      `json:"opensAt" validate:"required"`
+	ClosesAt          string      `json:"closesAt" validate:"required"`
+	TemplateVersionID *uuid.UUID  `json:"templateVersionId,omitempty"`
+	TeamIDs           []uuid.UUID `json:"teamIds"`
+}
+
+type ReviewCycleAssignment struct {
+	DeveloperID uuid.UUID `json:"developerId" db:"developer_id" validate:"required"`
+	EvaluatorID uuid.UUID `json:"evaluatorId" db:"evaluator_id" validate:"required"`
+}
+
+type AssignReviewersRequest struct {
+	Assignments []ReviewCycleAssignment `json:"assignments" validate:"required,min=1,dive"`
+}
+
+// CycleDeveloperStatus é a situação da avaliação de um desenvolvedor dentro do ciclo
+type CycleDeveloperStatus struct {
+	DeveloperID   uuid.UUID  `json:"developerId" db:"developer_id"`
+	DeveloperName string     `json:"developerName" db:"developer_name"`
+	TeamID        *uuid.UUID `json:"teamId" db:"team_id"`
+	TeamName      *string    `json:"teamName" db:"team_name"`
+	EvaluatorID   *uuid.UUID `json:"evaluatorId" db:"evaluator_id"`
+	EvaluatorName *string    `json:"evaluatorName" db:"evaluator_name"`
+	ReportID      *uuid.UUID `json:"reportId" db:"report_id"`
+	ReportStatus  *string    `json:"reportStatus" db:"report_status"`
+	Completed     bool       `json:"completed" db:"-"`
+}
+
+// CycleCompletionGroup resume a conclusão de um time ou avaliador
+type CycleCompletionGroup struct {
+	ID        *uuid.UUID             `json:"id"`
+	Name      string                 `json:"name"`
+	Total     int                    `json:"total"`
+	Completed int                    `json:"completed"`
+	Missing   []CycleDeveloperStatus `json:"missing"`
+}
+
+// CycleCompletion é o relatório de conclusão de um ciclo
+type CycleCompletion struct {
+	Cycle       ReviewCycle            `json:"cycle"`
+	Total       int                    `json:"total"`
+	Completed   int                    `json:"completed"`
+	Overdue     bool                   `json:"overdue"`
+	ByTeam      []CycleCompletionGroup `json:"byTeam"`
+	ByEvaluator []CycleCompletionGroup `json:"byEvaluator"`
+}
+
+// BuildCycleCompletion agrupa a situação dos desenvolvedores por time e por avaliador.
+// Um desenvolvedor está concluído quando seu relatório do mês já foi enviado.
+// Grupos são mantidos na ordem em que aparecem em statuses.
+func BuildCycleCompletion(cycle ReviewCycle, statuses []CycleDeveloperStatus, now time.Time) CycleCompletion {
+	completion := CycleCompletion{
+		Cycle:       cycle,
+		Total:       len(statuses),
+		Overdue:     cycle.Status != ReviewCycleClosed && now.After(cycle.ClosesAt.AddDate(0, 0, 1)),
+		ByTeam:      []CycleCompletionGroup{},
+		ByEvaluator: []CycleCompletionGroup{},
+	}
+
+	teams := map[string]int{}
+	evaluators := map[string]int{}
+	for _, status := range statuses {
+		status.Completed = status.ReportStatus != nil && IsReportPublished(*status.ReportStatus)
+		if status.Completed {
+			completion.Completed++
+		}
+
+		teamName := "Sem time"
+		if status.TeamName != nil {
+			teamName = *status.TeamName
+		}
+		completion.ByTeam = addToCompletionGroup(completion.ByTeam, teams, status.TeamID, teamName, status)
+
+		evaluatorName := "Sem avaliador"
+		if status.EvaluatorName != nil {
+			evaluatorName = *status.EvaluatorName
+		}
+		completion.ByEvaluator = addToCompletionGroup(completion.ByEvaluator, evaluators, status.EvaluatorID, evaluatorName, status)
+	}
+
+	return completion
+}
+
+func addToCompletionGroup(groups []CycleCompletionGroup, index map[string]int, id *uuid.UUID, name string, status CycleDeveloperStatus) []CycleCompletionGroup {
+	key := ""
+	if id != nil {
+		key = id.String()
+	}
+
+	position, ok := index[key]
+	if !ok {
+		position = len(groups)
+		index[key] = position
+		groups = append(groups, CycleCompletionGroup{ID: id, Name: name, Missing: []CycleDeveloperStatus{}})
+	}
+
+	groups[position].Total++
+	if status.Completed {
+		groups[position].Completed++
+	} else {
+		groups[position].Missing = append(groups[position].Missing, status)
+	}
+	return groups
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildCycleCompletionOverdue(t *testing.T) {
+	closesAt := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		status      string
+		now         time.Time
+		wantOverdue bool
+	}{
+		{"aberto antes do prazo", ReviewCycleOpen, closesAt.Add(-time.Hour), false},
+		{"aberto no último dia", ReviewCycleOpen, closesAt.Add(23 * time.Hour), false},
+		{"aberto após o último dia", ReviewCycleOpen, closesAt.AddDate(0, 0, 1).Add(time.Second), true},
+		{"planejado após o prazo", ReviewCyclePlanned, closesAt.AddDate(0, 0, 5), true},
+		{"encerrado após o prazo", ReviewCycleClosed, closesAt.AddDate(0, 0, 5), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completion := BuildCycleCompletion(ReviewCycle{Status: tt.status, ClosesAt: closesAt}, nil, tt.now)
+			if completion.Overdue != tt.wantOverdue {
+				t.Errorf("overdue = %v, want %v", completion.Overdue, tt.wantOverdue)
+			}
+		})
+	}
+}
+
+func TestBuildCycleCompletionGroups(t *testing.T) {
+	teamA, teamB := uuid.New(), uuid.New()
+	evaluatorA := uuid.New()
+	teamAName, teamBName, evaluatorAName := "Plataforma", "Mobile", "Gabi"
+	status := func(report string) *string {
+		if report == "" {
+			return nil
+		}
+		return &report
+	}
+
+	statuses := []CycleDeveloperStatus{
+		{DeveloperName: "Ana", TeamID: &teamA, TeamName: &teamAName, EvaluatorID: &evaluatorA, EvaluatorName: &evaluatorAName, ReportStatus: status(ReportStatusSubmitted)},
+		{DeveloperName: "Bruno", TeamID: &teamB, TeamName: &teamBName, ReportStatus: status(ReportStatusDraft)},
+		{DeveloperName: "Caio", TeamID: &teamA, TeamName: &teamAName, EvaluatorID: &evaluatorA, EvaluatorName: &evaluatorAName},
+		{DeveloperName: "Davi", ReportStatus: status(ReportStatusLocked)},
+		{DeveloperName: "Eva", TeamID: &teamB, TeamName: &teamBName, EvaluatorID: &evaluatorA, EvaluatorName: &evaluatorAName, ReportStatus: status(ReportStatusAcknowledged)},
+	}
+	completion := BuildCycleCompletion(ReviewCycle{Status: ReviewCycleOpen, ClosesAt: time.Now().AddDate(0, 0, 7)}, statuses, time.Now())
+
+	if completion.Total != 5 || completion.Completed != 3 {
+		t.Errorf("total/completed = %d/%d, want 5/3", completion.Total, completion.Completed)
+	}
+
+	type group struct {
+		name      string
+		total     int
+		completed int
+		missing   []string
+	}
+	tests := []struct {
+		name   string
+		groups []CycleCompletionGroup
+		want   []group
+	}{
+		{
+			name:   "por time",
+			groups: completion.ByTeam,
+			want: []group{
+				{"Plataforma", 2, 1, []string{"Caio"}},
+				{"Mobile", 2, 1, []string{"Bruno"}},
+				{"Sem time", 1, 1, nil},
+			},
+		},
+		{
+			name:   "por avaliador",
+			groups: completion.ByEvaluator,
+			want: []group{
+				{"Gabi", 3, 2, []string{"Caio"}},
+				{"Sem avaliador", 2, 1, []string{"Bruno"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.groups) != len(tt.want) {
+				t.Fatalf("groups = %+v, want %d groups", tt.groups, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := tt.groups[i]
+				if got.Name != want.name || got.Total != want.total || got.Completed != want.completed {
+					t.Errorf("group %d = %s %d/%d, want %s %d/%d", i, got.Name, got.Completed, got.Total, want.name, want.completed, want.total)
+				}
+				if len(got.Missing) != len(want.missing) {
+					t.Errorf("group %s missing = %+v, want %v", got.Name, got.Missing, want.missing)
+					continue
+				}
+				for j, name := range want.missing {
+					if got.Missing[j].DeveloperName != name || got.Missing[j].Completed {
+						t.Errorf("group %s missing[%d] = %+v, want %s", got.Name, j, got.Missing[j], name)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SelfAssessment é a avaliação que o desenvolvedor faz de si mesmo em um mês,
+// usando as mesmas perguntas do template da empresa
+type SelfAssessment struct {
+	ID                   uuid.UUID  `json:"id" db:"id"`
+	DeveloperID          uuid.UUID  `json:"developerId" db:"developer_id"`
+	Month                string     `json:"month" db:"month"`
+	TemplateVersionID    uuid.UUID  `json:"templateVersionId" db:"template_version_id"`
+	QuestionScores       JSONB      `json:"questionScores" db:"question_scores"`
+	CategoryScores       JSONB      `json:"categoryScores" db:"category_scores"`
+	WeightedAverageScore float64    `json:"weightedAverageScore" db:"weighted_average_score"`
+	Comments             string     `json:"comments" db:"comments"`
+	SubmittedBy          *uuid.UUID `json:"submittedBy" db:"submitted_by"`
+	CreatedAt            time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type SubmitSelfAssessmentRequest struct {
+	QuestionScores JSONB  `json:"questionScores" validate:"required"`
+	Comments       string `json:"comments"`
+}
+
+// QuestionComparison compara a nota do gerente com a autoavaliação em uma pergunta.
+// Delta = gerente - autoavaliação (negativo quando o desenvolvedor se avaliou melhor).
+type QuestionComparison struct {
+	CategoryKey   string   `json:"categoryKey"`
+	CategoryLabel string   `json:"categoryLabel"`
+	QuestionKey   string   `json:"questionKey"`
+	QuestionLabel string   `json:"questionLabel"`
+	ManagerScore  *float64 `json:"managerScore"`
+	SelfScore     *float64 `json:"selfScore"`
+	Delta         *float64 `json:"delta"`
+}
+
+// CompareScores monta a comparação pergunta a pergunta usando o template do relatório
+// do gerente, ordenada da maior para a menor diferença absoluta. Perguntas sem nota em
+// um dos lados ficam no fim, sem delta.
+func (t *EvaluationTemplate) CompareScores(managerScores, selfScores JSONB) []QuestionComparison {
+	comparisons := []QuestionComparison{}
+	for _, category := range t.Categories {
+		for _, question := range category.Questions {
+			item := QuestionComparison{
+				CategoryKey:   category.Key,
+				CategoryLabel: category.Label,
+				QuestionKey:   question.Key,
+				QuestionLabel: question.Label,
+				ManagerScore:  scoreValue(managerScores, question.Key),
+				SelfScore:     scoreValue(selfScores, question.Key),
+			}
+			if item.ManagerScore != nil && item.SelfScore != nil {
+				delta := roundScore(*item.ManagerScore - *item.SelfScore)
+				item.Delta = &delta
+			}
+			comparisons = append(comparisons, item)
+		}
+	}
+
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		a, b := comparisons[i].Delta, comparisons[j].Delta
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return math.Abs(*a) > math.Abs(*b)
+	})
+	return comparisons
+}
+
+func scoreValue(scores JSONB, key string) *float64 {
+	value, ok := scores[key].(float64)
+	if !ok {
+		return nil
+	}
+	return &value
+}
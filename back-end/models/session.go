@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Motivos de revogação de uma sessão
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedPasswordChange = "password_change"
+	SessionRevokedDeactivated    = "deactivated"
+	SessionRevokedRoleChange     = "role_change"
+	SessionRevokedCompanyChange  = "company_change"
+	SessionRevokedTokenReuse     = "refresh_token_reuse"
+	SessionRevokedByUser         = "user_revoked"
+	SessionRevokedByAdmin        = "admin_revoked"
+)
+
+// AuthSession é uma sessão de login; o access token só é aceito enquanto ela estiver ativa
+type AuthSession struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"userId" db:"user_id"`
+	UserAgent     *string    `json:"userAgent" db:"user_agent"`
+	IPAddress     *string    `json:"ipAddress" db:"ip_address"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	LastSeenAt    time.Time  `json:"lastSeenAt" db:"last_seen_at"`
+	ExpiresAt     time.Time  `json:"expiresAt" db:"expires_at"`
+	RevokedAt     *time.Time `json:"revokedAt" db:"revoked_at"`
+	RevokedReason *string    `json:"revokedReason" db:"revoked_reason"`
+
+	// Preenchidos na listagem de sessões
+	Device  string `json:"device" db:"-"`
+	Current bool   `json:"current" db:"-"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Validades do fluxo SSO: tempo para concluir o login no provedor e para o front-end
+// trocar o código de uso único pelos tokens
+const (
+	SSOLoginStateTTL = 10 * time.Minute
+	SSOLoginCodeTTL  = time.Minute
+)
+
+// CompanySSOConfig é o provedor OIDC da empresa. O client secret nunca é devolvido pela API.
+type CompanySSOConfig struct {
+	CompanyID           uuid.UUID      `json:"companyId" db:"company_id"`
+	Issuer              string         `json:"issuer" db:"issuer"`
+	ClientID            string         `json:"clientId" db:"client_id"`
+	ClientSecret        string         `json:"-" db:"client_secret"`
+	AllowedEmailDomains pq.StringArray `json:"allowedEmailDomains" db:"allowed_email_domains"`
+	DefaultRole         string         `json:"defaultRole" db:"default_role"`
+	IsEnabled           bool           `json:"isEnabled" db:"is_enabled"`
+	UpdatedBy           *uuid.UUID     `json:"updatedBy" db:"updated_by"`
+	CreatedAt           time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt           time.Time      `json:"updatedAt" db:"updated_at"`
+}
+
+// AllowsEmail indica se o domínio do email está liberado; lista vazia libera qualquer domínio
+func (c CompanySSOConfig) AllowsEmail(domain string) bool {
+	if len(c.AllowedEmailDomains) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedEmailDomains {
+		if allowed == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateCompanySSOConfigRequest cria ou substitui a configuração; ClientSecret vazio mantém o atual
+type UpdateCompanySSOConfigRequest struct {
+	Issuer              string   `json:"issuer" validate:"required,url"`
+	ClientID            string   `json:"clientId" validate:"required"`
+	ClientSecret        string   `json:"clientSecret"`
+	AllowedEmailDomains []string `json:"allowedEmailDomains" validate:"dive,fqdn"`
+	DefaultRole         string   `json:"defaultRole" validate:"required,oneof=manager user developer"`
+	IsEnabled           *bool    `json:"isEnabled"`
+}
+
+// SSOLoginState é um login SSO em andamento, do redirecionamento ao callback do provedor
+type SSOLoginState struct {
+	ID           uuid.UUID `db:"id"`
+	CompanyID    uuid.UUID `db:"company_id"`
+	Nonce        string    `db:"nonce"`
+	CodeVerifier string    `db:"code_verifier"`
+}
+
+type SSOExchangeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
@@ -0,0 +1,295 @@
+// Package oidc implementa o fluxo authorization code + PKCE do OpenID Connect usado no
+// login SSO por empresa: descoberta do provedor, troca do código e validação do ID token.
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// HTTPClient é usado em todas as chamadas ao provedor; pode ser trocado em testes
+var HTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// metadataTTL é por quanto tempo a descoberta e as chaves do provedor ficam em cache
+const metadataTTL = time.Hour
+
+// ProviderMetadata são os campos usados do documento /.well-known/openid-configuration
+type ProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Client representa a aplicação registrada no provedor de uma empresa
+type Client struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// IDTokenClaims são as claims do ID token usadas no provisionamento do usuário
+type IDTokenClaims struct {
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"`
+	Name          string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	TokenType        string `json:"token_type"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type cachedProvider struct {
+	metadata  *ProviderMetadata
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]*cachedProvider{}
+)
+
+// Discover busca (ou lê do cache) os metadados do provedor a partir do issuer
+func Discover(ctx context.Context, issuer string) (*ProviderMetadata, error) {
+	provider, err := loadProvider(ctx, issuer, false)
+	if err != nil {
+		return nil, err
+	}
+	return provider.metadata, nil
+}
+
+func loadProvider(ctx context.Context, issuer string, refreshKeys bool) (*cachedProvider, error) {
+	issuer = strings.TrimRight(issuer, "/")
+
+	cacheMu.Lock()
+	cached, ok := cache[issuer]
+	cacheMu.Unlock()
+	if ok && !refreshKeys && time.Since(cached.fetchedAt) < metadataTTL {
+		return cached, nil
+	}
+
+	var metadata ProviderMetadata
+	if err := getJSON(ctx, issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("descoberta do provedor falhou: %w", err)
+	}
+	if strings.TrimRight(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("issuer divergente na descoberta: %s", metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("documento de descoberta incompleto")
+	}
+
+	keys, err := fetchKeys(ctx, metadata.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &cachedProvider{metadata: &metadata, keys: keys, fetchedAt: time.Now()}
+	cacheMu.Lock()
+	cache[issuer] = provider
+	cacheMu.Unlock()
+	return provider, nil
+}
+
+// AuthCodeURL monta a URL de autorização com state, nonce e o desafio PKCE (S256)
+func (c Client) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	metadata, err := Discover(ctx, c.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", c.ClientID)
+	query.Set("redirect_uri", c.RedirectURL)
+	query.Set("scope", "openid email profile")
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange troca o código de autorização pelo ID token e devolve as claims já validadas
+func (c Client) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*IDTokenClaims, error) {
+	metadata, err := Discover(ctx, c.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("resposta inválida do token endpoint: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("token endpoint recusou o código: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("resposta sem id_token")
+	}
+
+	return c.VerifyIDToken(ctx, token.IDToken, nonce)
+}
+
+// VerifyIDToken valida assinatura, issuer, audience, expiração e nonce do ID token
+func (c Client) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDTokenClaims, error) {
+	issuer := strings.TrimRight(c.Issuer, "/")
+	provider, err := loadProvider(ctx, issuer, false)
+	if err != nil {
+		return nil, err
+	}
+
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if key := findKey(provider.keys, kid); key != nil {
+			return key, nil
+		}
+		// Chave desconhecida: o provedor pode ter feito rotação, recarrega uma vez
+		provider, err = loadProvider(ctx, issuer, true)
+		if err != nil {
+			return nil, err
+		}
+		if key := findKey(provider.keys, kid); key != nil {
+			return key, nil
+		}
+		return nil, fmt.Errorf("chave de assinatura desconhecida: %s", kid)
+	}
+
+	claims := &IDTokenClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, keyFunc,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(provider.metadata.Issuer),
+		jwt.WithAudience(c.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id_token inválido: %w", err)
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("nonce do id_token não confere")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("id_token sem subject")
+	}
+	return claims, nil
+}
+
+func findKey(keys map[string]*rsa.PublicKey, kid string) *rsa.PublicKey {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return keys[kid]
+}
+
+// fetchKeys lê as chaves RSA publicadas no JWKS do provedor
+func fetchKeys(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, jwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("falha ao buscar JWKS: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range jwks.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			continue
+		}
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS sem chaves RSA de assinatura")
+	}
+	return keys, nil
+}
+
+func getJSON(ctx context.Context, endpoint string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s respondeu %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// CodeChallenge calcula o code_challenge S256 do PKCE para o verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
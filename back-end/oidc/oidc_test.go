@@ -0,0 +1,188 @@
+package oidc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"tivix-performance-tracker-backend/oidc/oidctest"
+)
+
+const (
+	testClientID = "tracker-app"
+	testNonce    = "nonce-do-login"
+)
+
+func testClient(provider *oidctest.Provider) Client {
+	return Client{Issuer: provider.URL, ClientID: testClientID, RedirectURL: "http://localhost/callback"}
+}
+
+// validClaims são as claims de um ID token válido do provedor para o cliente de teste
+func validClaims(provider *oidctest.Provider) IDTokenClaims {
+	now := time.Now()
+	return IDTokenClaims{
+		Nonce: testNonce,
+		Email: "ana@acme.com",
+		Name:  "Ana",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    provider.URL,
+			Subject:   "usuario-123",
+			Audience:  jwt.ClaimStrings{testClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider := oidctest.NewProvider(t, "chave-1")
+	other := oidctest.NewProvider(t, "chave-1")
+
+	tests := []struct {
+		name    string
+		token   func(claims IDTokenClaims) string
+		nonce   string
+		wantErr bool
+	}{
+		{
+			name:  "token válido",
+			token: func(claims IDTokenClaims) string { return provider.Sign(t, claims) },
+			nonce: testNonce,
+		},
+		{
+			name: "audience de outro cliente",
+			token: func(claims IDTokenClaims) string {
+				claims.Audience = jwt.ClaimStrings{"outro-app"}
+				return provider.Sign(t, claims)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name:    "nonce diferente do login",
+			token:   func(claims IDTokenClaims) string { return provider.Sign(t, claims) },
+			nonce:   "nonce-de-outro-login",
+			wantErr: true,
+		},
+		{
+			name: "token expirado",
+			token: func(claims IDTokenClaims) string {
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Minute))
+				return provider.Sign(t, claims)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "token sem expiração",
+			token: func(claims IDTokenClaims) string {
+				claims.ExpiresAt = nil
+				return provider.Sign(t, claims)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "issuer de outro provedor",
+			token: func(claims IDTokenClaims) string {
+				claims.Issuer = other.URL
+				return provider.Sign(t, claims)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "sem subject",
+			token: func(claims IDTokenClaims) string {
+				claims.Subject = ""
+				return provider.Sign(t, claims)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "alg none",
+			token: func(claims IDTokenClaims) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+				token.Header["kid"] = "chave-1"
+				unsigned, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				if err != nil {
+					t.Fatalf("building unsigned token: %v", err)
+				}
+				return unsigned
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name:    "assinado por outra chave com o mesmo kid",
+			token:   func(claims IDTokenClaims) string { return other.Sign(t, claims) },
+			nonce:   testNonce,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := testClient(provider).VerifyIDToken(context.Background(), tt.token(validClaims(provider)), tt.nonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("VerifyIDToken accepted the token, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken: %v", err)
+			}
+			if claims.Subject != "usuario-123" || claims.Email != "ana@acme.com" {
+				t.Errorf("claims = %+v, want the signed subject and email", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRefetchesKeysAfterRotation(t *testing.T) {
+	provider := oidctest.NewProvider(t, "chave-1")
+	client := testClient(provider)
+	ctx := context.Background()
+
+	beforeRotation := provider.Sign(t, validClaims(provider))
+	if _, err := client.VerifyIDToken(ctx, beforeRotation, testNonce); err != nil {
+		t.Fatalf("VerifyIDToken before the rotation: %v", err)
+	}
+
+	// O kid novo não está no cache: as chaves são buscadas de novo uma única vez
+	provider.Rotate(t, "chave-2")
+	if _, err := client.VerifyIDToken(ctx, provider.Sign(t, validClaims(provider)), testNonce); err != nil {
+		t.Fatalf("VerifyIDToken after the rotation: %v", err)
+	}
+	if got := provider.JWKSFetches(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", got)
+	}
+
+	// A chave antiga saiu do JWKS e deixa de ser aceita
+	if _, err := client.VerifyIDToken(ctx, beforeRotation, testNonce); err == nil {
+		t.Error("VerifyIDToken accepted a kid no longer published, want an error")
+	}
+}
+
+func TestExchangeReturnsTheVerifiedClaims(t *testing.T) {
+	provider := oidctest.NewProvider(t, "chave-1")
+	client := testClient(provider)
+	provider.IssueCode("codigo-1", provider.Sign(t, validClaims(provider)))
+
+	claims, err := client.Exchange(context.Background(), "codigo-1", "verifier", testNonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Email != "ana@acme.com" {
+		t.Errorf("email = %q, want %q", claims.Email, "ana@acme.com")
+	}
+
+	// O código vale uma única vez no provedor
+	if _, err := client.Exchange(context.Background(), "codigo-1", "verifier", testNonce); err == nil {
+		t.Error("Exchange accepted a used code, want an error")
+	}
+}
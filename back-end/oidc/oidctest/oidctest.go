@@ -0,0 +1,128 @@
+// Package oidctest sobe um provedor OpenID Connect falso, com descoberta, JWKS e token
+// endpoint, para os testes do login SSO
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Provider é o provedor falso; URL é o issuer
+type Provider struct {
+	URL string
+
+	mu          sync.Mutex
+	kid         string
+	key         *rsa.PrivateKey
+	jwksFetches int
+	idTokens    map[string]string
+}
+
+// NewProvider sobe o provedor com uma chave de assinatura publicada sob o kid informado;
+// o servidor é encerrado ao fim do teste
+func NewProvider(t testing.TB, kid string) *Provider {
+	t.Helper()
+	p := &Provider{idTokens: map[string]string{}}
+	p.Rotate(t, kid)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.jwksFetches++
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []map[string]string{{
+			"kid": p.kid,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		idToken, ok := p.idTokens[r.FormValue("code")]
+		delete(p.idTokens, r.FormValue("code"))
+		p.mu.Unlock()
+
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	p.URL = server.URL
+	return p
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Rotate troca a chave de assinatura; o JWKS passa a publicar só a nova chave
+func (p *Provider) Rotate(t testing.TB, kid string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.kid, p.key = kid, key
+}
+
+// JWKSFetches conta quantas vezes o JWKS foi buscado
+func (p *Provider) JWKSFetches() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jwksFetches
+}
+
+// Sign assina as claims com RS256 e a chave publicada no momento
+func (p *Provider) Sign(t testing.TB, claims jwt.Claims) string {
+	t.Helper()
+	p.mu.Lock()
+	kid, key := p.kid, p.key
+	p.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return signed
+}
+
+// IssueCode registra o código de autorização que o token endpoint troca uma vez pelo ID token
+func (p *Provider) IssueCode(code, idToken string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.idTokens[code] = idToken
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// Os repositórios deste arquivo atendem ao login e às credenciais e não usam a transação de
+// escopo: sessões, tokens, 2FA e links de senha ficam fora do alcance do papel tivix_app, e
+// os fluxos públicos (login, SSO, convites, redefinição de senha) rodam antes de existir um
+// escopo. A implementação em PostgreSQL usa sempre a conexão DB, com transações próprias. Os
+// métodos que recebem um *models.SecurityEvent o gravam na mesma transação da alteração.
+
+// ErrRateLimited indica que o limite de envios do período foi atingido
+var ErrRateLimited = errors.New("limite de envios atingido")
+
+// ErrMFAEnabled indica um cadastro de 2FA que já foi confirmado
+var ErrMFAEnabled = errors.New("a autenticação em duas etapas já está ativa")
+
+// ErrInvalidCode indica um código de verificação que não confere
+var ErrInvalidCode = errors.New("código de verificação inválido")
+
+// ErrTokenReused indica um refresh token reapresentado; a sessão dele é encerrada
+var ErrTokenReused = errors.New("refresh token já utilizado")
+
+// ErrSessionExpired indica uma sessão encerrada ou expirada
+var ErrSessionExpired = errors.New("sessão encerrada ou expirada")
+
+// ErrInactiveUser indica uma conta desativada
+var ErrInactiveUser = errors.New("usuário inativo")
+
+// ErrOtherCompany indica uma conta existente em outra empresa
+var ErrOtherCompany = errors.New("conta de outra empresa")
+
+// AccountRepository acessa as contas sem escopo, para o login e o cadastro inicial
+type AccountRepository interface {
+	// Count conta os usuários de todas as empresas
+	Count(ctx context.Context) (int, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.User, error)
+	// GetByEmail ignora as contas de serviço, que não fazem login por senha
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
+	// Create grava a conta com o ID e as datas já preenchidos
+	Create(ctx context.Context, user *models.User) error
+	// SetPassword grava a senha e encerra todas as sessões do usuário; clearPasswordChange
+	// também desliga a troca obrigatória de senha
+	SetPassword(ctx context.Context, id uuid.UUID, hashedPassword string, clearPasswordChange bool) error
+}
+
+// StartedSession é uma sessão recém-aberta (ou renovada) com o refresh token em claro
+type StartedSession struct {
+	SessionID    uuid.UUID
+	RefreshToken string
+	User         models.User
+}
+
+// LoginThrottle é o histórico de falhas de login de um email
+type LoginThrottle struct {
+	FailedAttempts int
+	// LockedFor é o tempo restante do bloqueio, zero quando não há bloqueio
+	LockedFor time.Duration
+	// SinceLastFailure é o tempo desde a última falha
+	SinceLastFailure time.Duration
+}
+
+// LoginThrottleRepository guarda as falhas de login por email
+type LoginThrottleRepository interface {
+	// Get devolve o histórico do email; ErrNotFound quando não há falhas
+	Get(ctx context.Context, key string) (*LoginThrottle, error)
+	// RecordFailure soma uma falha e devolve o total. Em models.LoginLockoutAfter falhas o email
+	// é bloqueado por models.LoginLockoutDuration, o contador é zerado e lockEvent é gravado
+	// com failedAttempts nos detalhes.
+	RecordFailure(ctx context.Context, key string, lockEvent *models.SecurityEvent) (failedAttempts int, locked bool, err error)
+	// Clear remove as falhas e o bloqueio do email; event pode ser nil
+	Clear(ctx context.Context, key string, event *models.SecurityEvent) error
+}
+
+// PasswordResetRepository guarda os links de redefinição de senha (apenas o hash do token)
+type PasswordResetRepository interface {
+	// Create invalida os links anteriores do usuário e grava o novo, válido por
+	// models.PasswordResetTTL; ErrRateLimited quando o usuário já recebeu perHour links na
+	// última hora
+	Create(ctx context.Context, userID uuid.UUID, tokenHash, ip string, perHour int) error
+	// User devolve o dono de um link válido; ErrNotFound para links usados ou expirados
+	User(ctx context.Context, tokenHash string) (*models.User, error)
+	// Reset consome o link, grava a senha (desligando a troca obrigatória), invalida os demais
+	// links do usuário e encerra as sessões dele; ErrNotFound se o link deixou de valer
+	Reset(ctx context.Context, tokenHash, hashedPassword string, event *models.SecurityEvent) error
+}
+
+// MFARepository guarda o cadastro de 2FA, os códigos de recuperação (apenas hashes) e os
+// desafios do login em duas etapas
+type MFARepository interface {
+	// Get devolve o cadastro do usuário; ErrNotFound sem cadastro
+	Get(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error)
+	// Enabled indica se o usuário tem um cadastro confirmado
+	Enabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	RecoveryCodesRemaining(ctx context.Context, userID uuid.UUID) (int, error)
+	// Enroll grava um segredo pendente, substituindo um cadastro ainda não confirmado;
+	// ErrMFAEnabled se já houver um confirmado
+	Enroll(ctx context.Context, userID uuid.UUID, secret string) error
+	// Enable confirma o cadastro pendente com o código TOTP e troca os códigos de recuperação.
+	// ErrNotFound sem cadastro, ErrMFAEnabled se já confirmado, ErrInvalidCode se o código não
+	// confere.
+	Enable(ctx context.Context, userID uuid.UUID, code string, recoveryCodeHashes []string, event *models.SecurityEvent) error
+	// VerifyCode confere o código TOTP sem aceitar duas vezes o mesmo intervalo
+	VerifyCode(ctx context.Context, userID uuid.UUID, code string) (bool, error)
+	// UseRecoveryCode consome o código de recuperação; false se ele não existe ou já foi usado
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	// Remove apaga o cadastro, os códigos de recuperação e os desafios do usuário
+	Remove(ctx context.Context, userID uuid.UUID, event *models.SecurityEvent) error
+	// CreateChallenge grava o desafio do login, válido por models.MFAChallengeTTL
+	CreateChallenge(ctx context.Context, userID uuid.UUID, tokenHash string) error
+	// Challenge devolve o desafio ainda não usado, não expirado e com tentativas restantes;
+	// ErrNotFound caso contrário
+	Challenge(ctx context.Context, tokenHash string) (*models.MFAChallenge, error)
+	// FailChallenge soma uma tentativa errada ao desafio
+	FailChallenge(ctx context.Context, id uuid.UUID) error
+	// CompleteChallenge marca o desafio como usado; ErrNotFound se ele já foi usado
+	CompleteChallenge(ctx context.Context, id uuid.UUID, event *models.SecurityEvent) error
+}
+
+// SSOIdentity é a conta do provedor OIDC vinculada a um usuário
+type SSOIdentity struct {
+	Issuer  string
+	Subject string
+}
+
+// SSORepository guarda as configurações OIDC das empresas e o estado dos logins SSO. A
+// leitura da configuração não usa o escopo: ela serve ao login, antes da autenticação.
+type SSORepository interface {
+	// Config devolve a configuração da empresa; ErrNotFound quando não há
+	Config(ctx context.Context, companyID uuid.UUID) (*models.CompanySSOConfig, error)
+	// SaveConfig cria ou substitui a configuração e preenche as datas
+	SaveConfig(ctx context.Context, config *models.CompanySSOConfig) error
+	DeleteConfig(ctx context.Context, companyID uuid.UUID) error
+	// CompanyForDomain devolve a empresa ativa com SSO ligado que aceita o domínio de email
+	CompanyForDomain(ctx context.Context, domain string) (uuid.UUID, error)
+	// StartLogin grava o state (hash), o nonce e o verificador PKCE do login, válidos por
+	// models.SSOLoginStateTTL
+	StartLogin(ctx context.Context, companyID uuid.UUID, stateHash, nonce, codeVerifier string) error
+	// ConsumeState devolve o login do state e o invalida; ErrNotFound para states usados ou
+	// expirados
+	ConsumeState(ctx context.Context, stateHash string) (*models.SSOLoginState, error)
+	// IssueLoginCode associa ao login o usuário autenticado e o código de uso único
+	IssueLoginCode(ctx context.Context, loginID, userID uuid.UUID, codeHash string) error
+	// ExchangeLoginCode consome o código, válido por models.SSOLoginCodeTTL, e devolve o usuário
+	ExchangeLoginCode(ctx context.Context, codeHash string) (uuid.UUID, error)
+	// Provision devolve a conta vinculada à identidade, a conta com o email de newUser ou, sem
+	// nenhuma das duas, cria newUser (gravando event). A identidade fica vinculada à conta.
+	// ErrOtherCompany quando a conta encontrada é de outra empresa e ErrInactiveUser quando ela
+	// está desativada; nada é gravado nesses casos.
+	Provision(ctx context.Context, identity SSOIdentity, newUser *models.User, event *models.SecurityEvent) (*models.User, error)
+}
+
+// APITokenRepository guarda os tokens de API (apenas o hash do segredo)
+type APITokenRepository interface {
+	// List ordena os tokens do usuário pela criação, do mais recente
+	List(ctx context.Context, userID uuid.UUID) ([]models.APIToken, error)
+	// Get devolve o token ativo do dono; ErrNotFound se ele não existe ou foi revogado
+	Get(ctx context.Context, id, ownerID uuid.UUID) (*models.APIToken, error)
+	// Create grava o token com o ID informado e preenche as datas
+	Create(ctx context.Context, token *models.APIToken, tokenHash string, event *models.SecurityEvent) error
+	// Revoke revoga o token ativo; ErrNotFound se ele foi revogado nesse meio tempo
+	Revoke(ctx context.Context, token *models.APIToken, revokedBy uuid.UUID, event *models.SecurityEvent) error
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// ErrDuplicate indica um nome que já existe onde ele precisa ser único
+var ErrDuplicate = errors.New("registro duplicado")
+
+// CompanyRoleRepository acessa os papéis personalizados das empresas e suas atribuições
+type CompanyRoleRepository interface {
+	// List ordena os papéis pelo nome; companyID nil lista todas as empresas do escopo
+	List(ctx context.Context, companyID *uuid.UUID) ([]models.CompanyRole, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.CompanyRole, error)
+	// ForUser devolve o papel personalizado atribuído ao usuário, ou nil quando não há
+	ForUser(ctx context.Context, userID uuid.UUID) (*models.CompanyRole, error)
+	// Create grava o papel e preenche o ID; ErrDuplicate se o nome já existe na empresa
+	Create(ctx context.Context, role *models.CompanyRole) error
+	// Update grava nome, descrição e permissões; ErrDuplicate se o nome já existe na empresa
+	Update(ctx context.Context, role *models.CompanyRole) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Assign atribui o papel ao usuário ou, com roleID nil, remove a atribuição
+	Assign(ctx context.Context, userID uuid.UUID, roleID *uuid.UUID, assignedBy uuid.UUID) error
+}
+
+// ServiceAccountRepository acessa as contas de serviço e as contas de usuário delas
+type ServiceAccountRepository interface {
+	// List ordena as contas pelo nome; companyID nil lista todas as empresas do escopo
+	List(ctx context.Context, companyID *uuid.UUID) ([]models.ServiceAccount, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error)
+	// Create grava a conta de usuário (com o ID, o email e a senha de user) e a conta de serviço
+	Create(ctx context.Context, account *models.ServiceAccount, user *models.User) error
+	// Update grava nome, descrição, papel e status
+	Update(ctx context.Context, account *models.ServiceAccount) error
+	// Delete remove a conta de serviço e os tokens dela
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// SecurityPolicyRepository acessa as políticas de segurança das empresas
+type SecurityPolicyRepository interface {
+	// Get devolve a política da empresa; sem registro, a padrão, que não exige nada
+	Get(ctx context.Context, companyID uuid.UUID) (*models.CompanySecurityPolicy, error)
+	// Save cria ou substitui a política e preenche as datas
+	Save(ctx context.Context, policy *models.CompanySecurityPolicy) error
+}
+
+// InvitationFilter seleciona os convites listados
+type InvitationFilter struct {
+	CompanyID *uuid.UUID
+	Status    string
+}
+
+// InvitationRepository acessa os convites. A gestão respeita a empresa do escopo; Preview e
+// Accept servem ao convidado, ainda sem login, e localizam o convite apenas pelo token.
+type InvitationRepository interface {
+	// List ordena os convites pela criação, do mais recente
+	List(ctx context.Context, filter InvitationFilter) ([]models.UserInvitation, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.UserInvitation, error)
+	// Create grava a conta pendente (inativa) e o convite, que recebe os demais campos
+	Create(ctx context.Context, invitation *models.UserInvitation, pendingUser *models.User, tokenHash string) error
+	// Renew troca o link e renova a validade do convite; ErrNotFound se ele não está pendente
+	Renew(ctx context.Context, invitation *models.UserInvitation, tokenHash string) error
+	// Revoke cancela o convite pendente e remove a conta que aguardava a aceitação;
+	// ErrNotFound se ele não está mais pendente
+	Revoke(ctx context.Context, invitation *models.UserInvitation, revokedBy uuid.UUID) error
+	// EmailNames devolve o nome da empresa e o de quem convidou, usados no email
+	EmailNames(ctx context.Context, invitation *models.UserInvitation) (companyName, inviterName string, err error)
+	// Preview devolve o convite pendente e válido do token; ErrNotFound caso contrário
+	Preview(ctx context.Context, tokenHash string) (*models.InvitationPreview, error)
+	// Accept grava a senha, ativa a conta e marca o convite como aceito; ErrNotFound para
+	// convites usados, revogados ou expirados
+	Accept(ctx context.Context, tokenHash, hashedPassword string) (*models.User, error)
+}
+
+// AuditFilter seleciona os eventos de auditoria listados
+type AuditFilter struct {
+	// CompanyID nil lista todas as empresas do escopo
+	CompanyID  *uuid.UUID
+	EntityType string
+	EntityID   string
+	Action     string
+	Method     string
+	ActorID    *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	// BeforeID é o cursor: apenas eventos com ID menor
+	BeforeID int64
+	Limit    int
+}
@@ -0,0 +1,725 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// Os repositórios deste arquivo, como os de repository/accounts.go, ignoram o escopo
+
+type loginThrottle struct {
+	failedAttempts int
+	lastFailedAt   time.Time
+	lockedUntil    time.Time
+}
+
+type passwordReset struct {
+	userID    uuid.UUID
+	hash      string
+	createdAt time.Time
+	expiresAt time.Time
+	usedAt    *time.Time
+}
+
+type recoveryCode struct {
+	userID uuid.UUID
+	hash   string
+	used   bool
+}
+
+type mfaChallenge struct {
+	models.MFAChallenge
+	hash      string
+	expiresAt time.Time
+	used      bool
+}
+
+type ssoLogin struct {
+	models.SSOLoginState
+	stateHash   string
+	expiresAt   time.Time
+	completedAt *time.Time
+	userID      uuid.UUID
+	codeHash    string
+	codeUsed    bool
+}
+
+type identity struct {
+	repository.SSOIdentity
+	userID uuid.UUID
+}
+
+// recordSecurityEvent grava o evento de segurança, quando informado
+func (s *Store) recordSecurityEvent(event *models.SecurityEvent) {
+	if event == nil {
+		return
+	}
+	stored := *event
+	stored.ID = uuid.New()
+	stored.CreatedAt = s.now()
+	s.securityEvents = append(s.securityEvents, &stored)
+}
+
+// findLoginUser equivale a GetByEmail: ignora as contas de serviço
+func (s *Store) findLoginUser(email string) *models.User {
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) && s.findServiceAccount(user.ID) == nil {
+			return user
+		}
+	}
+	return nil
+}
+
+type accountRepository struct{ s *Store }
+
+func (r accountRepository) Count(ctx context.Context) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return len(r.s.users), nil
+}
+
+func (r accountRepository) Get(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user := r.s.findUser(id)
+	if user == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r accountRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user := r.s.findLoginUser(email)
+	if user == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r accountRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r accountRepository) Create(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := *user
+	r.s.users = append(r.s.users, &stored)
+	return nil
+}
+
+func (r accountRepository) SetPassword(ctx context.Context, id uuid.UUID, hashedPassword string, clearPasswordChange bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user := r.s.findUser(id)
+	if user == nil {
+		return repository.ErrNotFound
+	}
+	user.Password = hashedPassword
+	if clearPasswordChange {
+		user.NeedsPasswordChange = false
+	}
+	user.UpdatedAt = r.s.now()
+	r.s.revokeUserSessions(id, models.SessionRevokedPasswordChange)
+	return nil
+}
+
+type loginThrottleRepository struct{ s *Store }
+
+func (r loginThrottleRepository) Get(ctx context.Context, key string) (*repository.LoginThrottle, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.loginThrottles[key]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	now := r.s.clock()
+	throttle := repository.LoginThrottle{FailedAttempts: stored.failedAttempts}
+	if stored.lockedUntil.After(now) {
+		throttle.LockedFor = stored.lockedUntil.Sub(now)
+	}
+	if !stored.lastFailedAt.IsZero() {
+		throttle.SinceLastFailure = now.Sub(stored.lastFailedAt)
+	}
+	return &throttle, nil
+}
+
+func (r loginThrottleRepository) RecordFailure(ctx context.Context, key string, lockEvent *models.SecurityEvent) (int, bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.loginThrottles[key]
+	if !ok {
+		stored = &loginThrottle{}
+		r.s.loginThrottles[key] = stored
+	}
+	now := r.s.now()
+	stored.failedAttempts++
+	stored.lastFailedAt = now
+
+	failedAttempts := stored.failedAttempts
+	if failedAttempts < models.LoginLockoutAfter {
+		return failedAttempts, false, nil
+	}
+
+	stored.failedAttempts = 0
+	stored.lockedUntil = now.Add(models.LoginLockoutDuration)
+	if lockEvent != nil {
+		if lockEvent.Details == nil {
+			lockEvent.Details = models.JSONB{}
+		}
+		lockEvent.Details["failedAttempts"] = failedAttempts
+	}
+	r.s.recordSecurityEvent(lockEvent)
+	return failedAttempts, true, nil
+}
+
+func (r loginThrottleRepository) Clear(ctx context.Context, key string, event *models.SecurityEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.loginThrottles, key)
+	r.s.recordSecurityEvent(event)
+	return nil
+}
+
+type passwordResetRepository struct{ s *Store }
+
+// validReset devolve o link ainda não usado e não expirado do hash
+func (s *Store) validReset(tokenHash string) *passwordReset {
+	now := s.clock()
+	for _, reset := range s.passwordResets {
+		if reset.hash == tokenHash && reset.usedAt == nil && reset.expiresAt.After(now) {
+			return reset
+		}
+	}
+	return nil
+}
+
+// consumeResets invalida os links pendentes do usuário
+func (s *Store) consumeResets(userID uuid.UUID) {
+	now := s.now()
+	for _, reset := range s.passwordResets {
+		if reset.userID == userID && reset.usedAt == nil {
+			reset.usedAt = &now
+		}
+	}
+}
+
+func (r passwordResetRepository) Create(ctx context.Context, userID uuid.UUID, tokenHash, ip string, perHour int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	recent := 0
+	for _, reset := range r.s.passwordResets {
+		if reset.userID == userID && reset.createdAt.After(now.Add(-time.Hour)) {
+			recent++
+		}
+	}
+	if recent >= perHour {
+		return repository.ErrRateLimited
+	}
+
+	r.s.consumeResets(userID)
+	r.s.passwordResets = append(r.s.passwordResets, &passwordReset{
+		userID:    userID,
+		hash:      tokenHash,
+		createdAt: now,
+		expiresAt: now.Add(models.PasswordResetTTL),
+	})
+	return nil
+}
+
+func (r passwordResetRepository) User(ctx context.Context, tokenHash string) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	reset := r.s.validReset(tokenHash)
+	if reset == nil {
+		return nil, repository.ErrNotFound
+	}
+	user := r.s.findUser(reset.userID)
+	if user == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r passwordResetRepository) Reset(ctx context.Context, tokenHash, hashedPassword string, event *models.SecurityEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	reset := r.s.validReset(tokenHash)
+	if reset == nil {
+		return repository.ErrNotFound
+	}
+	user := r.s.findUser(reset.userID)
+	if user == nil {
+		return repository.ErrNotFound
+	}
+
+	r.s.consumeResets(user.ID)
+	user.Password = hashedPassword
+	user.NeedsPasswordChange = false
+	user.UpdatedAt = r.s.now()
+	r.s.revokeUserSessions(user.ID, models.SessionRevokedPasswordChange)
+	r.s.recordSecurityEvent(event)
+	return nil
+}
+
+type mfaRepository struct{ s *Store }
+
+// replaceRecoveryCodes descarta os códigos de recuperação anteriores e guarda os novos hashes
+func (s *Store) replaceRecoveryCodes(userID uuid.UUID, codeHashes []string) {
+	kept := s.recoveryCodes[:0]
+	for _, code := range s.recoveryCodes {
+		if code.userID != userID {
+			kept = append(kept, code)
+		}
+	}
+	s.recoveryCodes = kept
+	for _, hash := range codeHashes {
+		s.recoveryCodes = append(s.recoveryCodes, &recoveryCode{userID: userID, hash: hash})
+	}
+}
+
+func (r mfaRepository) Get(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	mfa, ok := r.s.userMFA[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	found := *mfa
+	return &found, nil
+}
+
+func (r mfaRepository) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	mfa, ok := r.s.userMFA[userID]
+	return ok && mfa.EnabledAt != nil, nil
+}
+
+func (r mfaRepository) RecoveryCodesRemaining(ctx context.Context, userID uuid.UUID) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	remaining := 0
+	for _, code := range r.s.recoveryCodes {
+		if code.userID == userID && !code.used {
+			remaining++
+		}
+	}
+	return remaining, nil
+}
+
+func (r mfaRepository) Enroll(ctx context.Context, userID uuid.UUID, secret string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if mfa, ok := r.s.userMFA[userID]; ok && mfa.EnabledAt != nil {
+		return repository.ErrMFAEnabled
+	}
+	r.s.userMFA[userID] = &models.UserMFA{Secret: secret}
+	return nil
+}
+
+func (r mfaRepository) Enable(ctx context.Context, userID uuid.UUID, code string, recoveryCodeHashes []string, event *models.SecurityEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	mfa, ok := r.s.userMFA[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if mfa.EnabledAt != nil {
+		return repository.ErrMFAEnabled
+	}
+
+	step, valid := utils.ValidateTOTP(mfa.Secret, code, r.s.clock(), mfa.LastUsedStep)
+	if !valid {
+		return repository.ErrInvalidCode
+	}
+	now := r.s.now()
+	mfa.EnabledAt = &now
+	mfa.LastUsedStep = step
+	r.s.replaceRecoveryCodes(userID, recoveryCodeHashes)
+	r.s.recordSecurityEvent(event)
+	return nil
+}
+
+func (r mfaRepository) VerifyCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	mfa, ok := r.s.userMFA[userID]
+	if !ok {
+		return false, nil
+	}
+	step, valid := utils.ValidateTOTP(mfa.Secret, code, r.s.clock(), mfa.LastUsedStep)
+	if valid {
+		mfa.LastUsedStep = step
+	}
+	return valid, nil
+}
+
+func (r mfaRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, code := range r.s.recoveryCodes {
+		if code.userID == userID && code.hash == codeHash && !code.used {
+			code.used = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.replaceRecoveryCodes(userID, codeHashes)
+	return nil
+}
+
+func (r mfaRepository) Remove(ctx context.Context, userID uuid.UUID, event *models.SecurityEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.replaceRecoveryCodes(userID, nil)
+	kept := r.s.mfaChallenges[:0]
+	for _, challenge := range r.s.mfaChallenges {
+		if challenge.UserID != userID {
+			kept = append(kept, challenge)
+		}
+	}
+	r.s.mfaChallenges = kept
+	delete(r.s.userMFA, userID)
+	r.s.recordSecurityEvent(event)
+	return nil
+}
+
+func (r mfaRepository) CreateChallenge(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.mfaChallenges = append(r.s.mfaChallenges, &mfaChallenge{
+		MFAChallenge: models.MFAChallenge{ID: uuid.New(), UserID: userID},
+		hash:         tokenHash,
+		expiresAt:    r.s.now().Add(models.MFAChallengeTTL),
+	})
+	return nil
+}
+
+func (r mfaRepository) Challenge(ctx context.Context, tokenHash string) (*models.MFAChallenge, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.clock()
+	for _, challenge := range r.s.mfaChallenges {
+		if challenge.hash == tokenHash && !challenge.used && challenge.expiresAt.After(now) &&
+			challenge.Attempts < models.MFAChallengeMaxTries {
+			found := challenge.MFAChallenge
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r mfaRepository) FailChallenge(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, challenge := range r.s.mfaChallenges {
+		if challenge.ID == id {
+			challenge.Attempts++
+		}
+	}
+	return nil
+}
+
+func (r mfaRepository) CompleteChallenge(ctx context.Context, id uuid.UUID, event *models.SecurityEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, challenge := range r.s.mfaChallenges {
+		if challenge.ID == id && !challenge.used {
+			challenge.used = true
+			r.s.recordSecurityEvent(event)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+// ssoRepository lê as configurações sem escopo, como no login; a gravação respeita a empresa
+// do escopo
+type ssoRepository struct{ s *Store }
+
+func (s *Store) findSSOConfig(companyID uuid.UUID) *models.CompanySSOConfig {
+	for _, config := range s.ssoConfigs {
+		if config.CompanyID == companyID {
+			return config
+		}
+	}
+	return nil
+}
+
+func (r ssoRepository) Config(ctx context.Context, companyID uuid.UUID) (*models.CompanySSOConfig, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	config := r.s.findSSOConfig(companyID)
+	if config == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := *config
+	return &found, nil
+}
+
+func (r ssoRepository) SaveConfig(ctx context.Context, config *models.CompanySSOConfig) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&config.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	config.UpdatedAt = now
+	if stored := r.s.findSSOConfig(config.CompanyID); stored != nil {
+		config.CreatedAt = stored.CreatedAt
+		*stored = *config
+		return nil
+	}
+	config.CreatedAt = now
+	stored := *config
+	r.s.ssoConfigs = append(r.s.ssoConfigs, &stored)
+	return nil
+}
+
+func (r ssoRepository) DeleteConfig(ctx context.Context, companyID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&companyID) || r.s.findSSOConfig(companyID) == nil {
+		return repository.ErrNotFound
+	}
+	kept := r.s.ssoConfigs[:0]
+	for _, config := range r.s.ssoConfigs {
+		if config.CompanyID != companyID {
+			kept = append(kept, config)
+		}
+	}
+	r.s.ssoConfigs = kept
+	return nil
+}
+
+func (r ssoRepository) CompanyForDomain(ctx context.Context, domain string) (uuid.UUID, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, config := range r.s.ssoConfigs {
+		company := r.s.findCompany(config.CompanyID)
+		if !config.IsEnabled || company == nil || !company.IsActive {
+			continue
+		}
+		for _, allowed := range config.AllowedEmailDomains {
+			if allowed == domain {
+				return config.CompanyID, nil
+			}
+		}
+	}
+	return uuid.Nil, repository.ErrNotFound
+}
+
+func (r ssoRepository) StartLogin(ctx context.Context, companyID uuid.UUID, stateHash, nonce, codeVerifier string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.ssoLogins = append(r.s.ssoLogins, &ssoLogin{
+		SSOLoginState: models.SSOLoginState{ID: uuid.New(), CompanyID: companyID, Nonce: nonce, CodeVerifier: codeVerifier},
+		stateHash:     stateHash,
+		expiresAt:     r.s.now().Add(models.SSOLoginStateTTL),
+	})
+	return nil
+}
+
+func (r ssoRepository) ConsumeState(ctx context.Context, stateHash string) (*models.SSOLoginState, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	for _, login := range r.s.ssoLogins {
+		if login.stateHash == stateHash && login.completedAt == nil && login.expiresAt.After(now) {
+			login.completedAt = &now
+			found := login.SSOLoginState
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r ssoRepository) IssueLoginCode(ctx context.Context, loginID, userID uuid.UUID, codeHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, login := range r.s.ssoLogins {
+		if login.ID == loginID {
+			login.userID = userID
+			login.codeHash = codeHash
+		}
+	}
+	return nil
+}
+
+func (r ssoRepository) ExchangeLoginCode(ctx context.Context, codeHash string) (uuid.UUID, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	validSince := r.s.clock().Add(-models.SSOLoginCodeTTL)
+	for _, login := range r.s.ssoLogins {
+		if login.codeHash == codeHash && !login.codeUsed && login.completedAt != nil && login.completedAt.After(validSince) {
+			login.codeUsed = true
+			return login.userID, nil
+		}
+	}
+	return uuid.Nil, repository.ErrNotFound
+}
+
+func (r ssoRepository) Provision(ctx context.Context, ssoIdentity repository.SSOIdentity, newUser *models.User, event *models.SecurityEvent) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var user *models.User
+	linked := false
+	for _, stored := range r.s.identities {
+		if stored.SSOIdentity == ssoIdentity {
+			user = r.s.findUser(stored.userID)
+			linked = true
+		}
+	}
+	created := false
+	if user == nil {
+		user = r.s.findLoginUser(newUser.Email)
+	}
+	if user == nil {
+		user = newUser
+		created = true
+	}
+
+	// Nada é gravado quando a conta não pode entrar
+	if user.CompanyID == nil || newUser.CompanyID == nil || *user.CompanyID != *newUser.CompanyID {
+		return nil, repository.ErrOtherCompany
+	}
+	if !user.IsActive {
+		return nil, repository.ErrInactiveUser
+	}
+
+	if created {
+		stored := *newUser
+		r.s.users = append(r.s.users, &stored)
+		r.s.recordSecurityEvent(event)
+	}
+	if !linked {
+		r.s.identities = append(r.s.identities, &identity{SSOIdentity: ssoIdentity, userID: user.ID})
+	}
+	found := *user
+	return &found, nil
+}
+
+type apiTokenRepository struct{ s *Store }
+
+func (r apiTokenRepository) List(ctx context.Context, userID uuid.UUID) ([]models.APIToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tokens := []models.APIToken{}
+	for _, stored := range r.s.apiTokens {
+		if stored.UserID == userID {
+			tokens = append(tokens, stored.APIToken)
+		}
+	}
+	sort.SliceStable(tokens, func(i, j int) bool { return tokens[i].CreatedAt.After(tokens[j].CreatedAt) })
+	return tokens, nil
+}
+
+// findActiveToken devolve o token não revogado do dono
+func (s *Store) findActiveToken(id, ownerID uuid.UUID) *apiToken {
+	for _, stored := range s.apiTokens {
+		if stored.ID == id && stored.UserID == ownerID && stored.RevokedAt == nil {
+			return stored
+		}
+	}
+	return nil
+}
+
+func (r apiTokenRepository) Get(ctx context.Context, id, ownerID uuid.UUID) (*models.APIToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.findActiveToken(id, ownerID)
+	if stored == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := stored.APIToken
+	return &found, nil
+}
+
+func (r apiTokenRepository) Create(ctx context.Context, token *models.APIToken, tokenHash string, event *models.SecurityEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	token.CreatedAt = r.s.now()
+	r.s.apiTokens = append(r.s.apiTokens, &apiToken{APIToken: *token, hash: tokenHash})
+	r.s.recordSecurityEvent(event)
+	return nil
+}
+
+func (r apiTokenRepository) Revoke(ctx context.Context, token *models.APIToken, revokedBy uuid.UUID, event *models.SecurityEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.findActiveToken(token.ID, token.UserID)
+	if stored == nil {
+		return repository.ErrNotFound
+	}
+	now := r.s.now()
+	stored.RevokedAt = &now
+	stored.RevokedBy = &revokedBy
+	*token = stored.APIToken
+	r.s.recordSecurityEvent(event)
+	return nil
+}
@@ -0,0 +1,619 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type serviceAccount struct {
+	userID      uuid.UUID
+	companyID   uuid.UUID
+	description string
+	createdBy   *uuid.UUID
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+type invitation struct {
+	models.UserInvitation
+	hash string
+}
+
+func (s *Store) findServiceAccount(userID uuid.UUID) *serviceAccount {
+	for _, account := range s.serviceAccounts {
+		if account.userID == userID {
+			return account
+		}
+	}
+	return nil
+}
+
+func (s *Store) findRole(id uuid.UUID) *models.CompanyRole {
+	for _, role := range s.companyRoles {
+		if role.ID == id {
+			return role
+		}
+	}
+	return nil
+}
+
+// assignedRole equivale a customRoleJoin: o papel atribuído, desde que da empresa do usuário
+func (s *Store) assignedRole(user *models.User) *models.CompanyRole {
+	roleID, ok := s.roleAssignments[user.ID]
+	if !ok {
+		return nil
+	}
+	role := s.findRole(roleID)
+	if role == nil || user.CompanyID == nil || role.CompanyID != *user.CompanyID {
+		return nil
+	}
+	return role
+}
+
+func (s *Store) findSecurityPolicy(companyID *uuid.UUID) *models.CompanySecurityPolicy {
+	if companyID == nil {
+		return nil
+	}
+	for _, policy := range s.securityPolicies {
+		if policy.CompanyID == *companyID {
+			return policy
+		}
+	}
+	return nil
+}
+
+// AddCompanyRole grava o papel personalizado, preenchendo id e datas quando vazios
+func (s *Store) AddCompanyRole(role *models.CompanyRole) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stamp(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	stored := *role
+	s.companyRoles = append(s.companyRoles, &stored)
+}
+
+// AssignRole atribui o papel personalizado ao usuário
+func (s *Store) AssignRole(userID, roleID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roleAssignments[userID] = roleID
+}
+
+type companyRoleRepository struct{ s *Store }
+
+// roleView devolve uma cópia do papel com a contagem de usuários atribuídos
+func (s *Store) roleView(role *models.CompanyRole) models.CompanyRole {
+	view := *role
+	view.UserCount = 0
+	for _, roleID := range s.roleAssignments {
+		if roleID == role.ID {
+			view.UserCount++
+		}
+	}
+	return view
+}
+
+// roleNameTaken indica se outro papel da empresa já usa o nome
+func (s *Store) roleNameTaken(role *models.CompanyRole) bool {
+	for _, stored := range s.companyRoles {
+		if stored.ID != role.ID && stored.CompanyID == role.CompanyID && stored.Name == role.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r companyRoleRepository) List(ctx context.Context, companyID *uuid.UUID) ([]models.CompanyRole, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	roles := []models.CompanyRole{}
+	for _, role := range r.s.companyRoles {
+		if !scope.OwnsCompany(&role.CompanyID) || (companyID != nil && role.CompanyID != *companyID) {
+			continue
+		}
+		roles = append(roles, r.s.roleView(role))
+	}
+	sort.SliceStable(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r companyRoleRepository) Get(ctx context.Context, id uuid.UUID) (*models.CompanyRole, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	role := r.s.findRole(id)
+	if role == nil || !repository.ScopeFrom(ctx).OwnsCompany(&role.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+	view := r.s.roleView(role)
+	return &view, nil
+}
+
+func (r companyRoleRepository) ForUser(ctx context.Context, userID uuid.UUID) (*models.CompanyRole, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	roleID, ok := r.s.roleAssignments[userID]
+	if !ok {
+		return nil, nil
+	}
+	role := r.s.findRole(roleID)
+	if role == nil || !repository.ScopeFrom(ctx).OwnsCompany(&role.CompanyID) {
+		return nil, nil
+	}
+	view := r.s.roleView(role)
+	return &view, nil
+}
+
+func (r companyRoleRepository) Create(ctx context.Context, role *models.CompanyRole) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&role.CompanyID) {
+		return repository.ErrNotFound
+	}
+	if r.s.roleNameTaken(role) {
+		return repository.ErrDuplicate
+	}
+	role.ID = uuid.Nil
+	role.CreatedAt = time.Time{}
+	role.UpdatedAt = time.Time{}
+	r.s.stamp(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	role.UpdatedBy = role.CreatedBy
+	stored := *role
+	r.s.companyRoles = append(r.s.companyRoles, &stored)
+	return nil
+}
+
+func (r companyRoleRepository) Update(ctx context.Context, role *models.CompanyRole) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.findRole(role.ID)
+	if stored == nil || !repository.ScopeFrom(ctx).OwnsCompany(&stored.CompanyID) {
+		return repository.ErrNotFound
+	}
+	candidate := *stored
+	candidate.Name = role.Name
+	if r.s.roleNameTaken(&candidate) {
+		return repository.ErrDuplicate
+	}
+	stored.Name = role.Name
+	stored.Description = role.Description
+	stored.Permissions = role.Permissions
+	stored.UpdatedBy = role.UpdatedBy
+	stored.UpdatedAt = r.s.now()
+	return nil
+}
+
+func (r companyRoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	role := r.s.findRole(id)
+	if role == nil || !repository.ScopeFrom(ctx).OwnsCompany(&role.CompanyID) {
+		return repository.ErrNotFound
+	}
+	kept := r.s.companyRoles[:0]
+	for _, stored := range r.s.companyRoles {
+		if stored.ID != id {
+			kept = append(kept, stored)
+		}
+	}
+	r.s.companyRoles = kept
+	for userID, roleID := range r.s.roleAssignments {
+		if roleID == id {
+			delete(r.s.roleAssignments, userID)
+		}
+	}
+	return nil
+}
+
+func (r companyRoleRepository) Assign(ctx context.Context, userID uuid.UUID, roleID *uuid.UUID, assignedBy uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if roleID == nil {
+		delete(r.s.roleAssignments, userID)
+		return nil
+	}
+	r.s.roleAssignments[userID] = *roleID
+	return nil
+}
+
+type serviceAccountRepository struct{ s *Store }
+
+// serviceAccountView monta a conta de serviço com os dados da conta de usuário
+func (s *Store) serviceAccountView(account *serviceAccount) models.ServiceAccount {
+	view := models.ServiceAccount{
+		ID:          account.userID,
+		CompanyID:   account.companyID,
+		Description: account.description,
+		CreatedBy:   account.createdBy,
+		CreatedAt:   account.createdAt,
+		UpdatedAt:   account.updatedAt,
+	}
+	if user := s.findUser(account.userID); user != nil {
+		view.Name = user.Name
+		view.Role = user.Role
+		view.IsActive = user.IsActive
+	}
+	now := s.clock()
+	for _, token := range s.apiTokens {
+		if token.UserID == account.userID && token.RevokedAt == nil && token.ExpiresAt.After(now) {
+			view.ActiveTokens++
+		}
+	}
+	return view
+}
+
+func (r serviceAccountRepository) List(ctx context.Context, companyID *uuid.UUID) ([]models.ServiceAccount, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	accounts := []models.ServiceAccount{}
+	for _, account := range r.s.serviceAccounts {
+		if !scope.OwnsCompany(&account.companyID) || (companyID != nil && account.companyID != *companyID) {
+			continue
+		}
+		accounts = append(accounts, r.s.serviceAccountView(account))
+	}
+	sort.SliceStable(accounts, func(i, j int) bool { return accounts[i].Name < accounts[j].Name })
+	return accounts, nil
+}
+
+func (r serviceAccountRepository) Get(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	account := r.s.findServiceAccount(id)
+	if account == nil || !repository.ScopeFrom(ctx).OwnsCompany(&account.companyID) {
+		return nil, repository.ErrNotFound
+	}
+	view := r.s.serviceAccountView(account)
+	return &view, nil
+}
+
+func (r serviceAccountRepository) Create(ctx context.Context, account *models.ServiceAccount, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&account.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	companyID := account.CompanyID
+	r.s.users = append(r.s.users, &models.User{
+		ID:        user.ID,
+		Email:     user.Email,
+		Password:  user.Password,
+		Name:      account.Name,
+		Role:      account.Role,
+		CompanyID: &companyID,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	r.s.serviceAccounts = append(r.s.serviceAccounts, &serviceAccount{
+		userID:      user.ID,
+		companyID:   account.CompanyID,
+		description: account.Description,
+		createdBy:   account.CreatedBy,
+		createdAt:   now,
+		updatedAt:   now,
+	})
+	return nil
+}
+
+func (r serviceAccountRepository) Update(ctx context.Context, account *models.ServiceAccount) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.findServiceAccount(account.ID)
+	if stored == nil || !repository.ScopeFrom(ctx).OwnsCompany(&stored.companyID) {
+		return repository.ErrNotFound
+	}
+	now := r.s.now()
+	stored.description = account.Description
+	stored.updatedAt = now
+	if user := r.s.findUser(account.ID); user != nil {
+		user.Name = account.Name
+		user.Role = account.Role
+		user.IsActive = account.IsActive
+		user.UpdatedAt = now
+	}
+	return nil
+}
+
+func (r serviceAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	account := r.s.findServiceAccount(id)
+	if account == nil || !repository.ScopeFrom(ctx).OwnsCompany(&account.companyID) {
+		return repository.ErrNotFound
+	}
+
+	keptAccounts := r.s.serviceAccounts[:0]
+	for _, stored := range r.s.serviceAccounts {
+		if stored.userID != id {
+			keptAccounts = append(keptAccounts, stored)
+		}
+	}
+	r.s.serviceAccounts = keptAccounts
+
+	keptUsers := r.s.users[:0]
+	for _, stored := range r.s.users {
+		if stored.ID != id {
+			keptUsers = append(keptUsers, stored)
+		}
+	}
+	r.s.users = keptUsers
+
+	keptTokens := r.s.apiTokens[:0]
+	for _, stored := range r.s.apiTokens {
+		if stored.UserID != id {
+			keptTokens = append(keptTokens, stored)
+		}
+	}
+	r.s.apiTokens = keptTokens
+	return nil
+}
+
+type securityPolicyRepository struct{ s *Store }
+
+func (r securityPolicyRepository) Get(ctx context.Context, companyID uuid.UUID) (*models.CompanySecurityPolicy, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&companyID) {
+		return nil, repository.ErrNotFound
+	}
+	if policy := r.s.findSecurityPolicy(&companyID); policy != nil {
+		found := *policy
+		return &found, nil
+	}
+	return &models.CompanySecurityPolicy{CompanyID: companyID}, nil
+}
+
+func (r securityPolicyRepository) Save(ctx context.Context, policy *models.CompanySecurityPolicy) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&policy.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	policy.UpdatedAt = now
+	if stored := r.s.findSecurityPolicy(&policy.CompanyID); stored != nil {
+		policy.CreatedAt = stored.CreatedAt
+		*stored = *policy
+		return nil
+	}
+	policy.CreatedAt = now
+	stored := *policy
+	r.s.securityPolicies = append(r.s.securityPolicies, &stored)
+	return nil
+}
+
+// invitationRepository respeita o escopo na gestão; Preview e Accept localizam o convite
+// apenas pelo token, como na implementação em PostgreSQL
+type invitationRepository struct{ s *Store }
+
+// invitationView devolve uma cópia do convite com Expired calculado
+func invitationView(stored *invitation, now time.Time) models.UserInvitation {
+	view := stored.UserInvitation
+	view.Expired = view.Status == models.InvitationPending && !view.ExpiresAt.After(now)
+	return view
+}
+
+// scopedInvitation devolve o convite da empresa do escopo
+func (s *Store) scopedInvitation(ctx context.Context, id uuid.UUID) *invitation {
+	for _, stored := range s.invitations {
+		if stored.ID == id && repository.ScopeFrom(ctx).OwnsCompany(&stored.CompanyID) {
+			return stored
+		}
+	}
+	return nil
+}
+
+// validInvitation devolve o convite pendente e não expirado do token
+func (s *Store) validInvitation(tokenHash string) *invitation {
+	now := s.clock()
+	for _, stored := range s.invitations {
+		if stored.hash == tokenHash && stored.Status == models.InvitationPending &&
+			stored.ExpiresAt.After(now) && stored.UserID != nil {
+			return stored
+		}
+	}
+	return nil
+}
+
+func (r invitationRepository) List(ctx context.Context, filter repository.InvitationFilter) ([]models.UserInvitation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	now := r.s.clock()
+	invitations := []models.UserInvitation{}
+	for _, stored := range r.s.invitations {
+		if !scope.OwnsCompany(&stored.CompanyID) {
+			continue
+		}
+		if filter.CompanyID != nil && stored.CompanyID != *filter.CompanyID {
+			continue
+		}
+		if filter.Status != "" && stored.Status != filter.Status {
+			continue
+		}
+		invitations = append(invitations, invitationView(stored, now))
+	}
+	sort.SliceStable(invitations, func(i, j int) bool { return invitations[i].CreatedAt.After(invitations[j].CreatedAt) })
+	return invitations, nil
+}
+
+func (r invitationRepository) Get(ctx context.Context, id uuid.UUID) (*models.UserInvitation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.scopedInvitation(ctx, id)
+	if stored == nil {
+		return nil, repository.ErrNotFound
+	}
+	view := invitationView(stored, r.s.clock())
+	return &view, nil
+}
+
+func (r invitationRepository) Create(ctx context.Context, created *models.UserInvitation, pendingUser *models.User, tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&created.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	companyID := created.CompanyID
+	pendingUser.ID = uuid.New()
+	r.s.users = append(r.s.users, &models.User{
+		ID:                  pendingUser.ID,
+		Email:               created.Email,
+		Password:            pendingUser.Password,
+		Name:                created.Name,
+		Role:                created.Role,
+		CompanyID:           &companyID,
+		NeedsPasswordChange: true,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	})
+
+	userID := pendingUser.ID
+	created.ID = uuid.New()
+	created.UserID = &userID
+	created.Status = models.InvitationPending
+	created.ExpiresAt = now.Add(models.InvitationTTL)
+	created.SendCount = 1
+	created.LastSentAt = now
+	created.CreatedAt = now
+	created.UpdatedAt = now
+	r.s.invitations = append(r.s.invitations, &invitation{UserInvitation: *created, hash: tokenHash})
+	return nil
+}
+
+func (r invitationRepository) Renew(ctx context.Context, renewed *models.UserInvitation, tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.scopedInvitation(ctx, renewed.ID)
+	if stored == nil || stored.Status != models.InvitationPending {
+		return repository.ErrNotFound
+	}
+	now := r.s.now()
+	stored.hash = tokenHash
+	stored.ExpiresAt = now.Add(models.InvitationTTL)
+	stored.SendCount++
+	stored.LastSentAt = now
+	stored.UpdatedAt = now
+	*renewed = invitationView(stored, now)
+	return nil
+}
+
+func (r invitationRepository) Revoke(ctx context.Context, revoked *models.UserInvitation, revokedBy uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.scopedInvitation(ctx, revoked.ID)
+	if stored == nil || stored.Status != models.InvitationPending {
+		return repository.ErrNotFound
+	}
+	now := r.s.now()
+	stored.Status = models.InvitationRevoked
+	stored.RevokedAt = &now
+	stored.RevokedBy = &revokedBy
+	stored.UpdatedAt = now
+
+	// A conta pendente nunca foi usada; sem o convite ela não tem como ser ativada
+	if stored.UserID != nil {
+		kept := r.s.users[:0]
+		for _, user := range r.s.users {
+			if user.ID != *stored.UserID || user.IsActive {
+				kept = append(kept, user)
+			}
+		}
+		r.s.users = kept
+	}
+	return nil
+}
+
+func (r invitationRepository) EmailNames(ctx context.Context, sent *models.UserInvitation) (string, string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	company := r.s.findCompany(sent.CompanyID)
+	if company == nil {
+		return "", "", repository.ErrNotFound
+	}
+	inviterName := "Um administrador"
+	if sent.InvitedBy != nil {
+		if inviter := r.s.findUser(*sent.InvitedBy); inviter != nil {
+			inviterName = inviter.Name
+		}
+	}
+	return company.Name, inviterName, nil
+}
+
+func (r invitationRepository) Preview(ctx context.Context, tokenHash string) (*models.InvitationPreview, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.validInvitation(tokenHash)
+	if stored == nil {
+		return nil, repository.ErrNotFound
+	}
+	preview := models.InvitationPreview{
+		Email:     stored.Email,
+		Name:      stored.Name,
+		Role:      stored.Role,
+		ExpiresAt: stored.ExpiresAt,
+	}
+	if company := r.s.findCompany(stored.CompanyID); company != nil {
+		preview.CompanyName = company.Name
+	}
+	return &preview, nil
+}
+
+func (r invitationRepository) Accept(ctx context.Context, tokenHash, hashedPassword string) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.validInvitation(tokenHash)
+	if stored == nil {
+		return nil, repository.ErrNotFound
+	}
+	user := r.s.findUser(*stored.UserID)
+	if user == nil {
+		return nil, repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	user.Password = hashedPassword
+	user.IsActive = true
+	user.NeedsPasswordChange = false
+	user.UpdatedAt = now
+	stored.Status = models.InvitationAccepted
+	stored.AcceptedAt = &now
+	stored.UpdatedAt = now
+
+	accepted := *user
+	return &accepted, nil
+}
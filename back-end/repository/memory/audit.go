@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"tivix-performance-tracker-backend/audit"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// auditRepository encadeia os eventos como audit.Record, uma cadeia por empresa
+type auditRepository struct{ s *Store }
+
+func (r auditRepository) Record(ctx context.Context, event *models.AuditEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	event.ChainKey = audit.ChainKey(event.CompanyID)
+	event.Seq = 1
+	event.PrevHash = models.AuditGenesisHash
+	for _, stored := range r.s.auditEvents {
+		if stored.ChainKey == event.ChainKey {
+			event.Seq = stored.Seq + 1
+			event.PrevHash = stored.Hash
+		}
+	}
+
+	event.ID = int64(len(r.s.auditEvents) + 1)
+	event.CreatedAt = r.s.clock().UTC().Truncate(time.Microsecond)
+	event.Hash = audit.ComputeHash(event)
+
+	stored := *event
+	r.s.auditEvents = append(r.s.auditEvents, &stored)
+	return nil
+}
+
+func (r auditRepository) List(ctx context.Context, filter repository.AuditFilter) ([]models.AuditEvent, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	events := []models.AuditEvent{}
+	for i := len(r.s.auditEvents) - 1; i >= 0 && (filter.Limit == 0 || len(events) < filter.Limit); i-- {
+		event := r.s.auditEvents[i]
+		if !scope.OwnsCompany(event.CompanyID) || !auditMatches(event, filter) {
+			continue
+		}
+		events = append(events, *event)
+	}
+	return events, nil
+}
+
+// auditMatches aplica os filtros da listagem, exceto o escopo
+func auditMatches(event *models.AuditEvent, filter repository.AuditFilter) bool {
+	switch {
+	case filter.CompanyID != nil && (event.CompanyID == nil || *event.CompanyID != *filter.CompanyID):
+		return false
+	case filter.EntityType != "" && event.EntityType != filter.EntityType:
+		return false
+	case filter.EntityID != "" && event.EntityID != filter.EntityID:
+		return false
+	case filter.Action != "" && event.Action != filter.Action:
+		return false
+	case filter.Method != "" && event.Method != filter.Method:
+		return false
+	case filter.ActorID != nil && (event.ActorID == nil || *event.ActorID != *filter.ActorID):
+		return false
+	case filter.From != nil && event.CreatedAt.Before(*filter.From):
+		return false
+	case filter.To != nil && !event.CreatedAt.Before(*filter.To):
+		return false
+	case filter.BeforeID != 0 && event.ID >= filter.BeforeID:
+		return false
+	}
+	return true
+}
+
+func (r auditRepository) Chain(ctx context.Context, chainKey string) ([]models.AuditEvent, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	events := []models.AuditEvent{}
+	for _, event := range r.s.auditEvents {
+		if event.ChainKey == chainKey && scope.OwnsCompany(event.CompanyID) {
+			events = append(events, *event)
+		}
+	}
+	return events, nil
+}
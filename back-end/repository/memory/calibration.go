@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type calibrationRepository struct{ s *Store }
+
+func (s *Store) findCalibrationSession(id uuid.UUID) *models.CalibrationSession {
+	for _, session := range s.calibrationSessions {
+		if session.ID == id {
+			return session
+		}
+	}
+	return nil
+}
+
+// sessionAdjustments devolve os ajustes da sessão na ordem em que foram propostos
+func (s *Store) sessionAdjustments(sessionID uuid.UUID) []*models.CalibrationAdjustment {
+	var adjustments []*models.CalibrationAdjustment
+	for _, adjustment := range s.calibrationAdjustments {
+		if adjustment.SessionID == sessionID {
+			adjustments = append(adjustments, adjustment)
+		}
+	}
+	return adjustments
+}
+
+func (r calibrationRepository) ListSessions(ctx context.Context, cycleID uuid.UUID) ([]models.CalibrationSession, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	sessions := []models.CalibrationSession{}
+	for _, session := range r.s.calibrationSessions {
+		if session.CycleID == cycleID && scope.OwnsCompany(&session.CompanyID) {
+			sessions = append(sessions, *session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].CreatedAt.After(sessions[j].CreatedAt) })
+	return sessions, nil
+}
+
+func (r calibrationRepository) GetSession(ctx context.Context, id uuid.UUID) (*models.CalibrationSession, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	session := r.s.findCalibrationSession(id)
+	if session == nil || !repository.ScopeFrom(ctx).OwnsCompany(&session.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+	found := *session
+	return &found, nil
+}
+
+func (r calibrationRepository) HasOpenSession(ctx context.Context, cycleID uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, session := range r.s.calibrationSessions {
+		if session.CycleID == cycleID && session.Status == models.CalibrationSessionOpen {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r calibrationRepository) CreateSession(ctx context.Context, session *models.CalibrationSession) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&session.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	session.ID = uuid.New()
+	session.CreatedAt = r.s.now()
+	session.UpdatedAt = session.CreatedAt
+	session.Status = models.CalibrationSessionOpen
+	stored := *session
+	r.s.calibrationSessions = append(r.s.calibrationSessions, &stored)
+	return nil
+}
+
+func (r calibrationRepository) Adjustments(ctx context.Context, sessionID uuid.UUID) ([]models.CalibrationAdjustment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	adjustments := []models.CalibrationAdjustment{}
+	for _, stored := range r.s.sessionAdjustments(sessionID) {
+		adjustment := *stored
+		report := r.s.findReport(adjustment.ReportID)
+		if report == nil {
+			continue
+		}
+		developer := r.s.findDeveloper(report.DeveloperID)
+		if developer == nil || !r.s.inUserTeams(scope, developer.TeamID) {
+			continue
+		}
+		adjustment.DeveloperID, adjustment.DeveloperName = developer.ID, developer.Name
+		adjustments = append(adjustments, adjustment)
+	}
+	sort.SliceStable(adjustments, func(i, j int) bool { return adjustments[i].DeveloperName < adjustments[j].DeveloperName })
+	return adjustments, nil
+}
+
+func (r calibrationRepository) Scores(ctx context.Context, cycle *models.ReviewCycle, sessionID uuid.UUID) ([]models.CalibrationScore, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	proposals := map[uuid.UUID]float64{}
+	for _, adjustment := range r.s.sessionAdjustments(sessionID) {
+		proposals[adjustment.ReportID] = adjustment.ProposedScore
+	}
+
+	scope := repository.ScopeFrom(ctx)
+	scores := []models.CalibrationScore{}
+	for _, report := range r.s.reports {
+		developer := r.s.findDeveloper(report.DeveloperID)
+		if report.Month != cycle.Month || !models.IsReportPublished(report.Status) || developer == nil ||
+			developer.CompanyID == nil || *developer.CompanyID != cycle.CompanyID || !inCycleTeams(cycle, developer.TeamID) ||
+			!r.s.inUserTeams(scope, developer.TeamID) {
+			continue
+		}
+
+		score := models.CalibrationScore{
+			ReportID:        report.ID,
+			DeveloperID:     developer.ID,
+			DeveloperName:   developer.Name,
+			TeamID:          developer.TeamID,
+			TeamName:        r.s.teamName(developer.TeamID),
+			Score:           report.WeightedAverageScore,
+			CalibratedScore: report.CalibratedScore,
+		}
+		score.EvaluatorID, score.EvaluatorName = r.s.cycleEvaluator(cycle.ID, developer.ID, report)
+		if proposed, ok := proposals[report.ID]; ok {
+			score.ProposedScore = &proposed
+		}
+		scores = append(scores, score)
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if less, equal := lessNullsLast(scores[i].EvaluatorName, scores[j].EvaluatorName); !equal {
+			return less
+		}
+		return scores[i].DeveloperName < scores[j].DeveloperName
+	})
+	return scores, nil
+}
+
+func (r calibrationRepository) CycleReport(ctx context.Context, cycle *models.ReviewCycle, reportID uuid.UUID) (*models.PerformanceReport, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	report := r.s.findReport(reportID)
+	if report == nil || report.Month != cycle.Month {
+		return nil, repository.ErrNotFound
+	}
+	developer := r.s.findDeveloper(report.DeveloperID)
+	if developer == nil || developer.CompanyID == nil || *developer.CompanyID != cycle.CompanyID ||
+		!r.s.inUserTeams(repository.ScopeFrom(ctx), developer.TeamID) {
+		return nil, repository.ErrNotFound
+	}
+	found := *report
+	return &found, nil
+}
+
+func (r calibrationRepository) Propose(ctx context.Context, adjustment *models.CalibrationAdjustment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	for _, stored := range r.s.sessionAdjustments(adjustment.SessionID) {
+		if stored.ReportID == adjustment.ReportID {
+			stored.OriginalScore = adjustment.OriginalScore
+			stored.ProposedScore = adjustment.ProposedScore
+			stored.Justification = adjustment.Justification
+			stored.ProposedBy = adjustment.ProposedBy
+			stored.UpdatedAt = now
+			adjustment.ID = stored.ID
+			return nil
+		}
+	}
+
+	adjustment.ID = uuid.New()
+	adjustment.CreatedAt = now
+	adjustment.UpdatedAt = now
+	stored := *adjustment
+	r.s.calibrationAdjustments = append(r.s.calibrationAdjustments, &stored)
+	return nil
+}
+
+func (r calibrationRepository) RemoveAdjustment(ctx context.Context, sessionID, reportID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	for i, adjustment := range r.s.calibrationAdjustments {
+		if adjustment.SessionID == sessionID && adjustment.ReportID == reportID {
+			if report := r.s.findReport(reportID); report == nil || !r.s.developerInTeams(scope, report.DeveloperID) {
+				return repository.ErrNotFound
+			}
+			r.s.calibrationAdjustments = append(r.s.calibrationAdjustments[:i], r.s.calibrationAdjustments[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r calibrationRepository) Commit(ctx context.Context, sessionID, actor uuid.UUID) (*models.CalibrationSession, []models.CalibrationAdjustment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	session := r.s.findCalibrationSession(sessionID)
+	if session == nil || !scope.OwnsCompany(&session.CompanyID) {
+		return nil, nil, repository.ErrNotFound
+	}
+	if session.Status != models.CalibrationSessionOpen {
+		return nil, nil, repository.ErrCalibrationCommitted
+	}
+
+	stored := r.s.sessionAdjustments(session.ID)
+	if len(stored) == 0 {
+		return nil, nil, repository.ErrNoAdjustments
+	}
+
+	// Sem transação, tudo é validado antes da primeira alteração
+	reports := make([]*models.PerformanceReport, len(stored))
+	for i, adjustment := range stored {
+		report := r.s.findReport(adjustment.ReportID)
+		if report == nil || !r.s.reportInCompany(scope, report) {
+			return nil, nil, repository.ErrNotFound
+		}
+		if developer := r.s.findDeveloper(report.DeveloperID); !r.s.developerVisible(scope, developer) {
+			return nil, nil, repository.ErrNotFound
+		}
+		// A proposta foi feita sobre uma nota que não existe mais
+		if report.WeightedAverageScore != adjustment.OriginalScore || !models.IsReportPublished(report.Status) {
+			return nil, nil, &repository.StaleAdjustmentError{ReportID: report.ID}
+		}
+		reports[i] = report
+	}
+
+	adjustments := []models.CalibrationAdjustment{}
+	for i, adjustment := range stored {
+		report := reports[i]
+		existing := *report
+		proposed, adjustmentID := adjustment.ProposedScore, adjustment.ID
+		report.CalibratedScore = &proposed
+		report.CalibrationAdjustmentID = &adjustmentID
+		report.UpdatedAt = r.s.now()
+
+		var companyID *uuid.UUID
+		if developer := r.s.findDeveloper(report.DeveloperID); developer != nil {
+			companyID = developer.CompanyID
+		}
+		r.s.recordRevision(report.ID, companyID, "calibrate", actor, &existing, report)
+		r.s.refreshLatestScore(report.DeveloperID)
+		adjustments = append(adjustments, *adjustment)
+	}
+
+	now := r.s.now()
+	session.Status = models.CalibrationSessionCommitted
+	session.CommittedAt = &now
+	session.CommittedBy = &actor
+	session.UpdatedAt = now
+	committed := *session
+	return &committed, adjustments, nil
+}
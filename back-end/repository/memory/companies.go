@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type companyRepository struct{ s *Store }
+
+// companyInScope devolve a empresa apenas se ela estiver no escopo
+func (r companyRepository) companyInScope(ctx context.Context, id uuid.UUID) *models.Company {
+	company := r.s.findCompany(id)
+	if company == nil || !repository.ScopeFrom(ctx).OwnsCompany(&company.ID) {
+		return nil
+	}
+	return company
+}
+
+func (r companyRepository) List(ctx context.Context, filter repository.CompanyFilter, page repository.PageRequest) (*repository.Page[models.Company], error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	var companies []models.Company
+	for _, company := range r.s.companies {
+		if !scope.OwnsCompany(&company.ID) {
+			continue
+		}
+		if filter.IsActive != nil && company.IsActive != *filter.IsActive {
+			continue
+		}
+		companies = append(companies, *company)
+	}
+
+	return paginate(companies, repository.CompanySorting, page), nil
+}
+
+func (r companyRepository) Get(ctx context.Context, id uuid.UUID) (*models.Company, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	company := r.companyInScope(ctx, id)
+	if company == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := *company
+	return &found, nil
+}
+
+func (r companyRepository) NameExists(ctx context.Context, name string, exceptID *uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, company := range r.s.companies {
+		if company.Name == name && (exceptID == nil || company.ID != *exceptID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r companyRepository) IsActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	company := r.companyInScope(ctx, id)
+	return company != nil && company.IsActive, nil
+}
+
+func (r companyRepository) Create(ctx context.Context, company *models.Company) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).AllCompanies {
+		return repository.ErrNotFound
+	}
+	stored := *company
+	r.s.companies = append(r.s.companies, &stored)
+	return nil
+}
+
+func (r companyRepository) Update(ctx context.Context, id uuid.UUID, update repository.CompanyUpdate) (*models.Company, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if update.Name == nil && update.Description == nil && update.IsActive == nil {
+		return nil, repository.ErrNoChanges
+	}
+
+	company := r.companyInScope(ctx, id)
+	if company == nil {
+		return nil, repository.ErrNotFound
+	}
+	if update.Name != nil {
+		company.Name = *update.Name
+	}
+	if update.Description != nil {
+		company.Description = *update.Description
+	}
+	if update.IsActive != nil {
+		company.IsActive = *update.IsActive
+	}
+	company.UpdatedAt = r.s.now()
+
+	updated := *company
+	return &updated, nil
+}
+
+func (r companyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.companyInScope(ctx, id) == nil {
+		return repository.ErrNotFound
+	}
+
+	kept := r.s.companies[:0]
+	for _, company := range r.s.companies {
+		if company.ID != id {
+			kept = append(kept, company)
+		}
+	}
+	r.s.companies = kept
+
+	// Mesmo efeito das chaves estrangeiras: times, desenvolvedores e templates da empresa saem
+	// junto e os usuários ficam sem empresa
+	ofCompany := func(companyID *uuid.UUID) bool { return companyID != nil && *companyID == id }
+	r.s.deleteTeams(func(team *models.Team) bool { return ofCompany(team.CompanyID) })
+	r.s.deleteDevelopers(func(developer *models.Developer) bool { return ofCompany(developer.CompanyID) })
+
+	keptTemplates := r.s.templates[:0]
+	for _, template := range r.s.templates {
+		if !ofCompany(template.CompanyID) {
+			keptTemplates = append(keptTemplates, template)
+		}
+	}
+	r.s.templates = keptTemplates
+
+	for _, user := range r.s.users {
+		if ofCompany(user.CompanyID) {
+			user.CompanyID = nil
+		}
+	}
+	return nil
+}
+
+func (r companyRepository) CountUsers(ctx context.Context, id uuid.UUID) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	count := 0
+	for _, user := range r.s.users {
+		if user.CompanyID != nil && *user.CompanyID == id {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r companyRepository) RecomputeScores(ctx context.Context, id uuid.UUID) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&id) {
+		return 0, repository.ErrNotFound
+	}
+
+	count := 0
+	for _, developer := range r.s.developers {
+		if developer.CompanyID != nil && *developer.CompanyID == id {
+			r.s.refreshLatestScore(developer.ID)
+			count++
+		}
+	}
+	return count, nil
+}
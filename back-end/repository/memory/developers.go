@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type developerRepository struct{ s *Store }
+
+// updateDeveloper aplica fn ao desenvolvedor do escopo e devolve uma cópia do registro atualizado
+func (r developerRepository) updateDeveloper(ctx context.Context, id uuid.UUID, fn func(developer *models.Developer)) (*models.Developer, error) {
+	developer := r.s.findDeveloper(id)
+	if developer == nil || !r.s.developerVisible(repository.ScopeFrom(ctx), developer) {
+		return nil, repository.ErrNotFound
+	}
+
+	fn(developer)
+	developer.UpdatedAt = r.s.now()
+	updated := *developer
+	return &updated, nil
+}
+
+func (r developerRepository) List(ctx context.Context, filter repository.DeveloperFilter, page repository.PageRequest) (*repository.Page[models.Developer], error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	var developers []models.Developer
+	for _, developer := range r.s.developers {
+		if !r.s.developerVisible(scope, developer) {
+			continue
+		}
+		if filter.TeamID != nil && (developer.TeamID == nil || *developer.TeamID != *filter.TeamID) {
+			continue
+		}
+		if filter.Role != "" && developer.Role != filter.Role {
+			continue
+		}
+		if !inScoreRange(developer.LatestPerformanceScore, filter.MinScore, filter.MaxScore) {
+			continue
+		}
+		if filter.ArchivedOnly && developer.ArchivedAt == nil {
+			continue
+		}
+		if !filter.ArchivedOnly && !filter.IncludeArchived && developer.ArchivedAt != nil {
+			continue
+		}
+		developers = append(developers, *developer)
+	}
+
+	return paginate(developers, repository.DeveloperSorting, page), nil
+}
+
+func (r developerRepository) Get(ctx context.Context, id uuid.UUID) (*models.Developer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	developer := r.s.findDeveloper(id)
+	if developer == nil || !r.s.developerVisible(repository.ScopeFrom(ctx), developer) {
+		return nil, repository.ErrNotFound
+	}
+	found := *developer
+	return &found, nil
+}
+
+func (r developerRepository) GetByUser(ctx context.Context, userID uuid.UUID) (*models.Developer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// O próprio vínculo dá acesso ao desenvolvedor, mesmo sem time; vale apenas a empresa
+	scope := repository.ScopeFrom(ctx)
+	for _, developer := range r.s.developers {
+		if developer.UserID != nil && *developer.UserID == userID && scope.OwnsCompany(developer.CompanyID) {
+			found := *developer
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r developerRepository) Create(ctx context.Context, developer *models.Developer) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	if !scope.OwnsCompany(developer.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	// Sem teams:all, o desenvolvedor precisa entrar em um dos times do usuário
+	if developer.TeamID != nil {
+		if err := r.s.teamInScope(scope, *developer.TeamID); err != nil {
+			return err
+		}
+	} else if !scope.AllTeams {
+		return repository.ErrNotFound
+	}
+
+	developer.ID = uuid.New()
+	developer.CreatedAt = r.s.now()
+	developer.UpdatedAt = developer.CreatedAt
+	stored := *developer
+	r.s.developers = append(r.s.developers, &stored)
+	return nil
+}
+
+func (r developerRepository) Update(ctx context.Context, id uuid.UUID, update repository.DeveloperUpdate) (*models.Developer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if update.TeamID != nil {
+		if err := r.s.teamInScope(repository.ScopeFrom(ctx), *update.TeamID); err != nil {
+			return nil, err
+		}
+	}
+	if update.Name == nil && update.Role == nil && update.TeamID == nil {
+		return nil, repository.ErrNoChanges
+	}
+
+	return r.updateDeveloper(ctx, id, func(developer *models.Developer) {
+		if update.Name != nil {
+			developer.Name = *update.Name
+		}
+		if update.Role != nil {
+			developer.Role = *update.Role
+		}
+		if update.TeamID != nil {
+			teamID := *update.TeamID
+			developer.TeamID = &teamID
+		}
+	})
+}
+
+func (r developerRepository) SetUser(ctx context.Context, id uuid.UUID, userID *uuid.UUID) (*models.Developer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.updateDeveloper(ctx, id, func(developer *models.Developer) {
+		developer.UserID = userID
+	})
+}
+
+func (r developerRepository) IsUserLinked(ctx context.Context, userID, exceptDeveloperID uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, developer := range r.s.developers {
+		if developer.UserID != nil && *developer.UserID == userID && developer.ID != exceptDeveloperID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r developerRepository) SetArchived(ctx context.Context, id uuid.UUID, archivedAt *time.Time) (*models.Developer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.updateDeveloper(ctx, id, func(developer *models.Developer) {
+		developer.ArchivedAt = archivedAt
+	})
+}
+
+func (r developerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	developer := r.s.findDeveloper(id)
+	if developer == nil || !r.s.developerVisible(repository.ScopeFrom(ctx), developer) {
+		return repository.ErrNotFound
+	}
+	r.s.deleteDevelopers(func(d *models.Developer) bool { return d.ID == id })
+	return nil
+}
+
+// deleteDevelopers remove os desenvolvedores selecionados e, em cascata, os relatórios deles
+func (s *Store) deleteDevelopers(match func(developer *models.Developer) bool) {
+	kept := s.developers[:0]
+	removed := map[uuid.UUID]bool{}
+	for _, developer := range s.developers {
+		if match(developer) {
+			removed[developer.ID] = true
+			continue
+		}
+		kept = append(kept, developer)
+	}
+	s.developers = kept
+	s.deleteReports(func(report *models.PerformanceReport) bool { return removed[report.DeveloperID] })
+}
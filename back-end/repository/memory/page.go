@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/repository"
+)
+
+// compareSortValues compara dois valores de um campo de ordenação (texto, número ou data)
+func compareSortValues(a, b interface{}) int {
+	switch av := a.(type) {
+	case float64:
+		bv := b.(float64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+		return 0
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	default:
+		return strings.Compare(a.(string), b.(string))
+	}
+}
+
+// compareKeys compara as chaves de ordenação (valor do campo e id) na direção informada
+func compareKeys(a interface{}, aID uuid.UUID, b interface{}, bID uuid.UUID, desc bool) int {
+	result := compareSortValues(a, b)
+	if result == 0 {
+		result = bytes.Compare(aID[:], bID[:])
+	}
+	if desc {
+		return -result
+	}
+	return result
+}
+
+// inScoreRange indica se a nota está no intervalo informado (inclusive)
+func inScoreRange(score float64, min, max *float64) bool {
+	return (min == nil || score >= *min) && (max == nil || score <= *max)
+}
+
+// paginate ordena os itens filtrados pelo campo da página, descarta os que vêm até o cursor e
+// corta a página, como a consulta paginada do PostgreSQL
+func paginate[T any](items []T, sorting repository.Sorting[T], page repository.PageRequest) *repository.Page[T] {
+	field, desc := sorting.Order(page)
+	value := sorting.Fields[field]
+	sort.SliceStable(items, func(i, j int) bool {
+		return compareKeys(value(items[i]), sorting.ID(items[i]), value(items[j]), sorting.ID(items[j]), desc) < 0
+	})
+
+	total := len(items)
+	if page.After != nil {
+		var zero T
+		after, _ := repository.ParseSortValue(value(zero), page.After.Value)
+		start := sort.Search(len(items), func(i int) bool {
+			return compareKeys(value(items[i]), sorting.ID(items[i]), after, page.After.ID, desc) > 0
+		})
+		items = items[start:]
+	}
+	if page.Limit > 0 && len(items) > page.Limit+1 {
+		items = items[:page.Limit+1]
+	}
+	return sorting.NewPage(items, total, page)
+}
@@ -0,0 +1,306 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type feedbackRoundRepository struct{ s *Store }
+
+// feedbackNomination guarda a indicação e, depois do envio, a resposta do par
+type feedbackNomination struct {
+	models.FeedbackNomination
+	response *models.PeerResponse
+}
+
+func (s *Store) findFeedbackRound(id uuid.UUID) *models.FeedbackRound {
+	for _, round := range s.feedbackRounds {
+		if round.ID == id {
+			return round
+		}
+	}
+	return nil
+}
+
+func (s *Store) findNomination(roundID, reviewerID uuid.UUID) *feedbackNomination {
+	for _, nomination := range s.feedbackNominations {
+		if nomination.RoundID == roundID && nomination.ReviewerID == reviewerID {
+			return nomination
+		}
+	}
+	return nil
+}
+
+// nominationView devolve a indicação com o nome do par
+func (s *Store) nominationView(nomination *feedbackNomination) models.FeedbackNomination {
+	view := nomination.FeedbackNomination
+	if user := s.findUser(view.ReviewerID); user != nil {
+		view.ReviewerName = user.Name
+	}
+	return view
+}
+
+// peerFeedbackSummary equivale a database.LoadPeerFeedbackSummary
+func (s *Store) peerFeedbackSummary(round *models.FeedbackRound) *models.PeerFeedbackSummary {
+	nominated := 0
+	responses := []models.PeerResponse{}
+	for _, nomination := range s.feedbackNominations {
+		if nomination.RoundID != round.ID {
+			continue
+		}
+		nominated++
+		if nomination.response != nil {
+			response := *nomination.response
+			response.ReviewerName = s.nominationView(nomination).ReviewerName
+			responses = append(responses, response)
+		}
+	}
+
+	summary := models.SummarizePeerFeedback(round, nominated, responses)
+	return &summary
+}
+
+func (r feedbackRoundRepository) List(ctx context.Context, filter repository.FeedbackRoundFilter) ([]models.FeedbackRound, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	rounds := []models.FeedbackRound{}
+	for _, round := range r.s.feedbackRounds {
+		if !scope.OwnsCompany(&round.CompanyID) || !r.s.developerInTeams(scope, round.DeveloperID) {
+			continue
+		}
+		if filter.DeveloperID != nil && round.DeveloperID != *filter.DeveloperID {
+			continue
+		}
+		if filter.Month != "" && round.Month != filter.Month {
+			continue
+		}
+		rounds = append(rounds, *round)
+	}
+
+	sort.SliceStable(rounds, func(i, j int) bool {
+		if rounds[i].Month != rounds[j].Month {
+			return rounds[i].Month > rounds[j].Month
+		}
+		return rounds[i].CreatedAt.After(rounds[j].CreatedAt)
+	})
+	return rounds, nil
+}
+
+func (r feedbackRoundRepository) Get(ctx context.Context, id uuid.UUID) (*models.FeedbackRound, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	round := r.s.findFeedbackRound(id)
+	if round == nil || !scope.OwnsCompany(&round.CompanyID) || !r.s.developerInTeams(scope, round.DeveloperID) {
+		return nil, repository.ErrNotFound
+	}
+	found := *round
+	return &found, nil
+}
+
+func (r feedbackRoundRepository) ExistsForMonth(ctx context.Context, developerID uuid.UUID, month string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, round := range r.s.feedbackRounds {
+		if round.DeveloperID == developerID && round.Month == month {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r feedbackRoundRepository) Create(ctx context.Context, round *models.FeedbackRound) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&round.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	round.ID = uuid.New()
+	round.CreatedAt = r.s.now()
+	round.UpdatedAt = round.CreatedAt
+	round.Status = models.FeedbackRoundOpen
+	stored := *round
+	r.s.feedbackRounds = append(r.s.feedbackRounds, &stored)
+	return nil
+}
+
+func (r feedbackRoundRepository) Close(ctx context.Context, round *models.FeedbackRound) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.findFeedbackRound(round.ID)
+	if stored == nil || !repository.ScopeFrom(ctx).OwnsCompany(&stored.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	stored.Status = models.FeedbackRoundClosed
+	stored.ClosedAt = &now
+	stored.UpdatedAt = now
+	*round = *stored
+	return nil
+}
+
+func (r feedbackRoundRepository) Summary(ctx context.Context, round *models.FeedbackRound) (*models.PeerFeedbackSummary, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.peerFeedbackSummary(round), nil
+}
+
+func (r feedbackRoundRepository) ForReport(ctx context.Context, report *models.PerformanceReport) (*models.PeerFeedbackSummary, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, round := range r.s.feedbackRounds {
+		if round.DeveloperID == report.DeveloperID && round.Month == report.Month {
+			return r.s.peerFeedbackSummary(round), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r feedbackRoundRepository) DeveloperAccount(ctx context.Context, companyID, developerID uuid.UUID) (*uuid.UUID, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	developer := r.s.findDeveloper(developerID)
+	if developer == nil || developer.CompanyID == nil || *developer.CompanyID != companyID {
+		return nil, repository.ErrNotFound
+	}
+	return developer.UserID, nil
+}
+
+func (r feedbackRoundRepository) Nominations(ctx context.Context, roundID uuid.UUID) ([]models.FeedbackNomination, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	nominations := []models.FeedbackNomination{}
+	for _, nomination := range r.s.feedbackNominations {
+		if nomination.RoundID == roundID {
+			nominations = append(nominations, r.s.nominationView(nomination))
+		}
+	}
+	sort.SliceStable(nominations, func(i, j int) bool { return nominations[i].ReviewerName < nominations[j].ReviewerName })
+	return nominations, nil
+}
+
+func (r feedbackRoundRepository) Nominate(ctx context.Context, roundID uuid.UUID, reviewerIDs []uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, reviewerID := range reviewerIDs {
+		if r.s.findNomination(roundID, reviewerID) != nil {
+			continue
+		}
+		r.s.feedbackNominations = append(r.s.feedbackNominations, &feedbackNomination{
+			FeedbackNomination: models.FeedbackNomination{
+				ID:         uuid.New(),
+				RoundID:    roundID,
+				ReviewerID: reviewerID,
+				CreatedAt:  r.s.now(),
+			},
+		})
+	}
+	return nil
+}
+
+func (r feedbackRoundRepository) Nomination(ctx context.Context, roundID, reviewerID uuid.UUID) (*models.FeedbackNomination, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	nomination := r.s.findNomination(roundID, reviewerID)
+	if nomination == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := r.s.nominationView(nomination)
+	return &found, nil
+}
+
+func (r feedbackRoundRepository) RemoveNomination(ctx context.Context, roundID, reviewerID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, nomination := range r.s.feedbackNominations {
+		if nomination.RoundID == roundID && nomination.ReviewerID == reviewerID {
+			r.s.feedbackNominations = append(r.s.feedbackNominations[:i], r.s.feedbackNominations[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r feedbackRoundRepository) Requests(ctx context.Context, reviewerID uuid.UUID) ([]models.FeedbackRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	requests := []models.FeedbackRequest{}
+	for _, nomination := range r.s.feedbackNominations {
+		if nomination.ReviewerID != reviewerID {
+			continue
+		}
+		round := r.s.findFeedbackRound(nomination.RoundID)
+		if round == nil {
+			continue
+		}
+		developer := r.s.findDeveloper(round.DeveloperID)
+		if developer == nil {
+			continue
+		}
+		requests = append(requests, models.FeedbackRequest{
+			RoundID:           round.ID,
+			DeveloperID:       developer.ID,
+			DeveloperName:     developer.Name,
+			Month:             round.Month,
+			Status:            round.Status,
+			TemplateVersionID: round.TemplateVersionID,
+			SubmittedAt:       nomination.SubmittedAt,
+		})
+	}
+
+	// Pendentes primeiro, depois do mês mais recente
+	sort.SliceStable(requests, func(i, j int) bool {
+		pendingI, pendingJ := requests[i].SubmittedAt == nil, requests[j].SubmittedAt == nil
+		if pendingI != pendingJ {
+			return pendingI
+		}
+		return requests[i].Month > requests[j].Month
+	})
+	return requests, nil
+}
+
+func (r feedbackRoundRepository) GetForReviewer(ctx context.Context, id, reviewerID uuid.UUID) (*models.FeedbackRound, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	round := r.s.findFeedbackRound(id)
+	if round == nil || r.s.findNomination(id, reviewerID) == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := *round
+	return &found, nil
+}
+
+func (r feedbackRoundRepository) SubmitResponse(ctx context.Context, roundID, reviewerID uuid.UUID, response models.PeerResponse) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	nomination := r.s.findNomination(roundID, reviewerID)
+	if nomination == nil {
+		return repository.ErrNotFound
+	}
+	now := r.s.now()
+	nomination.response = &response
+	nomination.SubmittedAt = &now
+	return nil
+}
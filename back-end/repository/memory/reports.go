@@ -0,0 +1,492 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type reportRepository struct{ s *Store }
+
+// reportSnapshot serializa o relatório no mesmo formato JSON retornado pela API
+func reportSnapshot(report *models.PerformanceReport) models.JSONB {
+	if report == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil
+	}
+
+	var snapshot models.JSONB
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// refreshLatestScore reproduz o trigger da migração 030: a pontuação do desenvolvedor é a nota
+// calibrada (ou a média ponderada) do relatório enviado com o mês mais recente, ou 0 sem
+// relatórios enviados
+func (s *Store) refreshLatestScore(developerID uuid.UUID) {
+	developer := s.findDeveloper(developerID)
+	if developer == nil {
+		return
+	}
+
+	var latest *models.PerformanceReport
+	for _, report := range s.reports {
+		if report.DeveloperID != developerID || !models.IsReportPublished(report.Status) {
+			continue
+		}
+		if latest == nil || report.Month > latest.Month ||
+			(report.Month == latest.Month && report.CreatedAt.After(latest.CreatedAt)) {
+			latest = report
+		}
+	}
+
+	developer.LatestPerformanceScore = 0
+	if latest != nil {
+		developer.LatestPerformanceScore = latest.WeightedAverageScore
+		if latest.CalibratedScore != nil {
+			developer.LatestPerformanceScore = *latest.CalibratedScore
+		}
+	}
+}
+
+// deleteReports remove os relatórios selecionados e recalcula a pontuação dos desenvolvedores
+func (s *Store) deleteReports(match func(report *models.PerformanceReport) bool) {
+	affected := map[uuid.UUID]bool{}
+	kept := s.reports[:0]
+	for _, report := range s.reports {
+		if match(report) {
+			affected[report.DeveloperID] = true
+			continue
+		}
+		kept = append(kept, report)
+	}
+	s.reports = kept
+
+	for developerID := range affected {
+		s.refreshLatestScore(developerID)
+	}
+}
+
+// listReports lista os relatórios visíveis no escopo que atendem ao filtro informado
+func (r reportRepository) listReports(ctx context.Context, match func(report *models.PerformanceReport) bool, less func(a, b *models.PerformanceReport) bool) []models.PerformanceReport {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	var reports []models.PerformanceReport
+	for _, report := range r.s.reports {
+		if match(report) && r.s.reportVisible(scope, report) {
+			reports = append(reports, *report)
+		}
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool { return less(&reports[i], &reports[j]) })
+	return reports
+}
+
+// byMonthDesc ordena por mês e data de criação, dos mais recentes para os mais antigos
+func byMonthDesc(a, b *models.PerformanceReport) bool {
+	if a.Month != b.Month {
+		return a.Month > b.Month
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+func (r reportRepository) List(ctx context.Context, filter repository.ReportFilter, page repository.PageRequest) (*repository.Page[models.PerformanceReport], error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	var reports []models.PerformanceReport
+	for _, report := range r.s.reports {
+		if !r.s.reportVisible(scope, report) {
+			continue
+		}
+		if filter.TeamID != nil {
+			developer := r.s.findDeveloper(report.DeveloperID)
+			if developer == nil || developer.TeamID == nil || *developer.TeamID != *filter.TeamID {
+				continue
+			}
+		}
+		if filter.DeveloperID != nil && report.DeveloperID != *filter.DeveloperID {
+			continue
+		}
+		if filter.Status != "" && report.Status != filter.Status {
+			continue
+		}
+		if (filter.MonthFrom != "" && report.Month < filter.MonthFrom) || (filter.MonthTo != "" && report.Month > filter.MonthTo) {
+			continue
+		}
+		if !inScoreRange(report.WeightedAverageScore, filter.MinScore, filter.MaxScore) {
+			continue
+		}
+		reports = append(reports, *report)
+	}
+
+	return paginate(reports, repository.ReportSorting, page), nil
+}
+
+func (r reportRepository) ListByDeveloper(ctx context.Context, developerID uuid.UUID) ([]models.PerformanceReport, error) {
+	return r.listReports(ctx, func(report *models.PerformanceReport) bool {
+		return report.DeveloperID == developerID
+	}, byMonthDesc), nil
+}
+
+func (r reportRepository) ListByMonth(ctx context.Context, month string) ([]models.PerformanceReport, error) {
+	return r.listReports(ctx, func(report *models.PerformanceReport) bool {
+		return report.Month == month
+	}, func(a, b *models.PerformanceReport) bool {
+		if a.WeightedAverageScore != b.WeightedAverageScore {
+			return a.WeightedAverageScore > b.WeightedAverageScore
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	}), nil
+}
+
+func (r reportRepository) Get(ctx context.Context, id uuid.UUID) (*models.PerformanceReport, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	report := r.s.findReport(id)
+	if report == nil || !r.s.reportVisible(repository.ScopeFrom(ctx), report) {
+		return nil, repository.ErrNotFound
+	}
+	found := *report
+	return &found, nil
+}
+
+func (r reportRepository) GetForMonth(ctx context.Context, developerID uuid.UUID, month string) (*models.PerformanceReport, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	report := r.s.reportForMonth(repository.ScopeFrom(ctx), developerID, month)
+	if report == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := *report
+	return &found, nil
+}
+
+// reportForMonth devolve o relatório do desenvolvedor no mês se ele for da empresa do escopo
+func (s *Store) reportForMonth(scope repository.Scope, developerID uuid.UUID, month string) *models.PerformanceReport {
+	for _, report := range s.reports {
+		if report.DeveloperID == developerID && report.Month == month && s.reportInCompany(scope, report) {
+			return report
+		}
+	}
+	return nil
+}
+
+// published lista os relatórios enviados do desenvolvedor na empresa do escopo, do mês mais recente
+func (r reportRepository) published(ctx context.Context, developerID uuid.UUID) []models.PerformanceReport {
+	scope := repository.ScopeFrom(ctx)
+	reports := []models.PerformanceReport{}
+	for _, report := range r.s.reports {
+		if report.DeveloperID == developerID && models.IsReportPublished(report.Status) && r.s.reportInCompany(scope, report) {
+			reports = append(reports, *report)
+		}
+	}
+	sort.SliceStable(reports, func(i, j int) bool { return byMonthDesc(&reports[i], &reports[j]) })
+	return reports
+}
+
+func (r reportRepository) ListPublished(ctx context.Context, developerID uuid.UUID) ([]models.PerformanceReport, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.published(ctx, developerID), nil
+}
+
+func (r reportRepository) Trend(ctx context.Context, developerID uuid.UUID, months int) ([]models.PerformanceTrendPoint, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	reports := r.published(ctx, developerID)
+	if len(reports) > months {
+		reports = reports[:months]
+	}
+
+	points := []models.PerformanceTrendPoint{}
+	for i := len(reports) - 1; i >= 0; i-- {
+		points = append(points, models.PerformanceTrendPoint{
+			Month:                reports[i].Month,
+			WeightedAverageScore: reports[i].WeightedAverageScore,
+			CalibratedScore:      reports[i].CalibratedScore,
+			CategoryScores:       reports[i].CategoryScores,
+		})
+	}
+	return points, nil
+}
+
+// publishedInScope lista os relatórios enviados da empresa e dos times do escopo
+func (r reportRepository) publishedInScope(ctx context.Context) []*models.PerformanceReport {
+	scope := repository.ScopeFrom(ctx)
+	var reports []*models.PerformanceReport
+	for _, report := range r.s.reports {
+		if models.IsReportPublished(report.Status) && r.s.reportInCompany(scope, report) && r.s.reportInTeams(scope, report) {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+func (r reportRepository) Months(ctx context.Context) ([]string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	seen := map[string]bool{}
+	var months []string
+	for _, report := range r.publishedInScope(ctx) {
+		if !seen[report.Month] {
+			seen[report.Month] = true
+			months = append(months, report.Month)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(months)))
+	return months, nil
+}
+
+func (r reportRepository) Stats(ctx context.Context) (*models.PerformanceStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stats := models.PerformanceStats{}
+	total := 0.0
+	for _, report := range r.publishedInScope(ctx) {
+		// Relatórios calibrados entram com a nota calibrada
+		score := report.WeightedAverageScore
+		if report.CalibratedScore != nil {
+			score = *report.CalibratedScore
+		}
+
+		if stats.TotalReports == 0 || score > stats.HighestScore {
+			stats.HighestScore = score
+		}
+		if stats.TotalReports == 0 || score < stats.LowestScore {
+			stats.LowestScore = score
+		}
+		total += score
+		stats.TotalReports++
+	}
+
+	if stats.TotalReports > 0 {
+		stats.AverageScore = math.Round(total/float64(stats.TotalReports)*100) / 100
+	}
+	return &stats, nil
+}
+
+func (r reportRepository) ExistsForMonth(ctx context.Context, developerID uuid.UUID, month string, exceptID *uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	for _, report := range r.s.reports {
+		if report.DeveloperID != developerID || report.Month != month {
+			continue
+		}
+		if exceptID != nil && report.ID == *exceptID {
+			continue
+		}
+		if r.s.reportInCompany(scope, report) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r reportRepository) Create(ctx context.Context, report *models.PerformanceReport) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	developer := r.s.findDeveloper(report.DeveloperID)
+	if developer == nil || !r.s.developerVisible(repository.ScopeFrom(ctx), developer) {
+		return repository.ErrNotFound
+	}
+
+	report.ID = uuid.New()
+	report.CreatedAt = r.s.now()
+	report.UpdatedAt = report.CreatedAt
+	if report.Status == "" {
+		report.Status = models.ReportStatusDraft
+	}
+	stored := *report
+	r.s.reports = append(r.s.reports, &stored)
+	r.s.refreshLatestScore(report.DeveloperID)
+	return nil
+}
+
+func (r reportRepository) LockForChange(ctx context.Context, id uuid.UUID) (*models.PerformanceReport, *uuid.UUID, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	report := r.s.findReport(id)
+	if report == nil {
+		return nil, nil, repository.ErrNotFound
+	}
+	var companyID *uuid.UUID
+	if developer := r.s.findDeveloper(report.DeveloperID); developer != nil {
+		companyID = developer.CompanyID
+	}
+
+	// Relatórios de outra empresa são tratados como inexistentes
+	if !repository.ScopeFrom(ctx).OwnsCompany(companyID) {
+		return nil, nil, repository.ErrNotFound
+	}
+	found := *report
+	return &found, companyID, nil
+}
+
+// updateReport aplica fn ao relatório da empresa do escopo e copia o resultado em report
+func (r reportRepository) updateReport(ctx context.Context, report *models.PerformanceReport, fn func(stored *models.PerformanceReport)) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.findReport(report.ID)
+	if stored == nil || !r.s.reportInCompany(repository.ScopeFrom(ctx), stored) {
+		return repository.ErrNotFound
+	}
+
+	fn(stored)
+	stored.UpdatedAt = r.s.now()
+	r.s.refreshLatestScore(stored.DeveloperID)
+	*report = *stored
+	return nil
+}
+
+func (r reportRepository) Update(ctx context.Context, report *models.PerformanceReport) error {
+	changes := *report
+	return r.updateReport(ctx, report, func(stored *models.PerformanceReport) {
+		stored.Month = changes.Month
+		stored.QuestionScores = changes.QuestionScores
+		stored.CategoryScores = changes.CategoryScores
+		stored.WeightedAverageScore = changes.WeightedAverageScore
+		stored.Highlights = changes.Highlights
+		stored.PointsToDevelop = changes.PointsToDevelop
+		stored.TemplateVersionID = changes.TemplateVersionID
+		stored.CalibratedScore = changes.CalibratedScore
+		stored.CalibrationAdjustmentID = changes.CalibrationAdjustmentID
+	})
+}
+
+func (r reportRepository) UpdateStatus(ctx context.Context, report *models.PerformanceReport, status string, actor uuid.UUID) error {
+	changes := *report
+	return r.updateReport(ctx, report, func(stored *models.PerformanceReport) {
+		now := r.s.now()
+		stored.Status = status
+		stored.CategoryScores = changes.CategoryScores
+		stored.WeightedAverageScore = changes.WeightedAverageScore
+		stored.TemplateVersionID = changes.TemplateVersionID
+
+		// Cada status tem sua própria data e autor
+		switch status {
+		case models.ReportStatusSubmitted:
+			stored.SubmittedAt, stored.SubmittedBy = &now, &actor
+		case models.ReportStatusAcknowledged:
+			stored.AcknowledgedAt, stored.AcknowledgedBy = &now, &actor
+		case models.ReportStatusLocked:
+			stored.LockedAt, stored.LockedBy = &now, &actor
+		}
+	})
+}
+
+func (r reportRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	report := r.s.findReport(id)
+	if report == nil || !r.s.reportInCompany(repository.ScopeFrom(ctx), report) {
+		return repository.ErrNotFound
+	}
+	r.s.deleteReports(func(report *models.PerformanceReport) bool { return report.ID == id })
+	return nil
+}
+
+func (r reportRepository) LockByDeveloper(ctx context.Context, developerID uuid.UUID) ([]models.PerformanceReport, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	var reports []models.PerformanceReport
+	for _, report := range r.s.reports {
+		if report.DeveloperID == developerID && r.s.reportInCompany(scope, report) {
+			reports = append(reports, *report)
+		}
+	}
+	return reports, nil
+}
+
+func (r reportRepository) DeleteByDeveloper(ctx context.Context, developerID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	r.s.deleteReports(func(report *models.PerformanceReport) bool {
+		return report.DeveloperID == developerID && r.s.reportInCompany(scope, report)
+	})
+	return nil
+}
+
+// recordRevision grava a próxima revisão do relatório
+func (s *Store) recordRevision(reportID uuid.UUID, companyID *uuid.UUID, action string, changedBy uuid.UUID, oldReport, newReport *models.PerformanceReport) {
+	revision := 1
+	for _, stored := range s.revisions {
+		if stored.ReportID == reportID && stored.Revision >= revision {
+			revision = stored.Revision + 1
+		}
+	}
+
+	s.revisions = append(s.revisions, &models.PerformanceReportRevision{
+		ID:        uuid.New(),
+		ReportID:  reportID,
+		Revision:  revision,
+		Action:    action,
+		CompanyID: companyID,
+		ChangedBy: &changedBy,
+		OldData:   reportSnapshot(oldReport),
+		NewData:   reportSnapshot(newReport),
+		CreatedAt: s.now(),
+	})
+}
+
+func (r reportRepository) RecordRevision(ctx context.Context, reportID uuid.UUID, companyID *uuid.UUID, action string, changedBy uuid.UUID, oldReport, newReport *models.PerformanceReport) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.recordRevision(reportID, companyID, action, changedBy, oldReport, newReport)
+	return nil
+}
+
+func (r reportRepository) Revisions(ctx context.Context, reportID uuid.UUID) ([]models.PerformanceReportRevision, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	revisions := []models.PerformanceReportRevision{}
+	for _, stored := range r.s.revisions {
+		if stored.ReportID != reportID || !scope.OwnsCompany(stored.CompanyID) {
+			continue
+		}
+		revision := *stored
+		if revision.ChangedBy != nil {
+			if user := r.s.findUser(*revision.ChangedBy); user != nil {
+				name := user.Name
+				revision.ChangedByName = &name
+			}
+		}
+		revisions = append(revisions, revision)
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type reviewCycleRepository struct{ s *Store }
+
+type cycleAssignment struct {
+	cycleID uuid.UUID
+	models.ReviewCycleAssignment
+}
+
+// copyCycle devolve uma cópia do ciclo com a própria lista de times
+func copyCycle(cycle *models.ReviewCycle) models.ReviewCycle {
+	found := *cycle
+	found.TeamIDs = append([]uuid.UUID{}, cycle.TeamIDs...)
+	return found
+}
+
+func (s *Store) findReviewCycle(id uuid.UUID) *models.ReviewCycle {
+	for _, cycle := range s.reviewCycles {
+		if cycle.ID == id {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// inCycleTeams equivale a cycleTeamsFilter: ciclos sem times incluem todos
+func inCycleTeams(cycle *models.ReviewCycle, teamID *uuid.UUID) bool {
+	if len(cycle.TeamIDs) == 0 {
+		return true
+	}
+	if teamID == nil {
+		return false
+	}
+	for _, id := range cycle.TeamIDs {
+		if id == *teamID {
+			return true
+		}
+	}
+	return false
+}
+
+// cycleEvaluator devolve o avaliador definido no ciclo para o desenvolvedor ou, sem um, quem
+// enviou o relatório
+func (s *Store) cycleEvaluator(cycleID, developerID uuid.UUID, report *models.PerformanceReport) (*uuid.UUID, *string) {
+	var evaluatorID *uuid.UUID
+	for _, assignment := range s.cycleAssignments {
+		if assignment.cycleID == cycleID && assignment.DeveloperID == developerID {
+			id := assignment.EvaluatorID
+			evaluatorID = &id
+		}
+	}
+	if evaluatorID == nil && report != nil {
+		evaluatorID = report.SubmittedBy
+	}
+	if evaluatorID == nil {
+		return nil, nil
+	}
+	if user := s.findUser(*evaluatorID); user != nil {
+		name := user.Name
+		return evaluatorID, &name
+	}
+	return evaluatorID, nil
+}
+
+// teamName devolve o nome do time, ou nil sem time
+func (s *Store) teamName(teamID *uuid.UUID) *string {
+	if teamID == nil {
+		return nil
+	}
+	if team := s.findTeam(*teamID); team != nil {
+		name := team.Name
+		return &name
+	}
+	return nil
+}
+
+// lessNullsLast ordena nomes opcionais com os vazios por último
+func lessNullsLast(a, b *string) (less, equal bool) {
+	switch {
+	case a == nil && b == nil:
+		return false, true
+	case a == nil || b == nil:
+		return b == nil, false
+	default:
+		return *a < *b, *a == *b
+	}
+}
+
+func (r reviewCycleRepository) List(ctx context.Context, status string) ([]models.ReviewCycle, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	cycles := []models.ReviewCycle{}
+	for _, cycle := range r.s.reviewCycles {
+		if scope.OwnsCompany(&cycle.CompanyID) && (status == "" || cycle.Status == status) {
+			cycles = append(cycles, copyCycle(cycle))
+		}
+	}
+	sort.SliceStable(cycles, func(i, j int) bool { return cycles[i].Month > cycles[j].Month })
+	return cycles, nil
+}
+
+func (r reviewCycleRepository) Get(ctx context.Context, id uuid.UUID) (*models.ReviewCycle, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	cycle := r.s.findReviewCycle(id)
+	if cycle == nil || !repository.ScopeFrom(ctx).OwnsCompany(&cycle.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+	found := copyCycle(cycle)
+	return &found, nil
+}
+
+func (r reviewCycleRepository) ForMonth(ctx context.Context, companyID *uuid.UUID, month string) (*models.ReviewCycle, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if companyID == nil || !repository.ScopeFrom(ctx).OwnsCompany(companyID) {
+		return nil, nil
+	}
+	for _, cycle := range r.s.reviewCycles {
+		if cycle.CompanyID == *companyID && cycle.Month == month {
+			found := copyCycle(cycle)
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r reviewCycleRepository) Create(ctx context.Context, cycle *models.ReviewCycle) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(&cycle.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	cycle.ID = uuid.New()
+	cycle.CreatedAt = r.s.now()
+	cycle.UpdatedAt = cycle.CreatedAt
+	cycle.Status = models.ReviewCyclePlanned
+
+	// Times repetidos são ignorados, como o ON CONFLICT do PostgreSQL
+	seen := map[uuid.UUID]bool{}
+	teamIDs := []uuid.UUID{}
+	for _, teamID := range cycle.TeamIDs {
+		if !seen[teamID] {
+			seen[teamID] = true
+			teamIDs = append(teamIDs, teamID)
+		}
+	}
+	cycle.TeamIDs = teamIDs
+
+	stored := copyCycle(cycle)
+	r.s.reviewCycles = append(r.s.reviewCycles, &stored)
+	return nil
+}
+
+func (r reviewCycleRepository) UpdateStatus(ctx context.Context, cycle *models.ReviewCycle, status string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.findReviewCycle(cycle.ID)
+	if stored == nil || !repository.ScopeFrom(ctx).OwnsCompany(&stored.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	stored.Status = status
+	stored.UpdatedAt = now
+	switch status {
+	case models.ReviewCycleOpen:
+		stored.OpenedAt = &now
+	case models.ReviewCycleClosed:
+		stored.ClosedAt = &now
+	}
+	*cycle = copyCycle(stored)
+	return nil
+}
+
+func (r reviewCycleRepository) Assignments(ctx context.Context, cycleID uuid.UUID) ([]models.ReviewCycleAssignment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	assignments := []models.ReviewCycleAssignment{}
+	for _, assignment := range r.s.cycleAssignments {
+		if assignment.cycleID == cycleID {
+			assignments = append(assignments, assignment.ReviewCycleAssignment)
+		}
+	}
+	return assignments, nil
+}
+
+func (r reviewCycleRepository) IsEligibleEvaluator(ctx context.Context, companyID, userID uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user := r.s.findUser(userID)
+	if user == nil || !user.IsActive || user.CompanyID == nil || *user.CompanyID != companyID {
+		return false, nil
+	}
+	switch user.Role {
+	case "admin", "company_admin", "manager":
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r reviewCycleRepository) Assign(ctx context.Context, cycleID uuid.UUID, assignments []models.ReviewCycleAssignment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, assignment := range assignments {
+		replaced := false
+		for _, stored := range r.s.cycleAssignments {
+			if stored.cycleID == cycleID && stored.DeveloperID == assignment.DeveloperID {
+				stored.EvaluatorID = assignment.EvaluatorID
+				replaced = true
+			}
+		}
+		if !replaced {
+			r.s.cycleAssignments = append(r.s.cycleAssignments, &cycleAssignment{cycleID: cycleID, ReviewCycleAssignment: assignment})
+		}
+	}
+	return nil
+}
+
+func (r reviewCycleRepository) Completion(ctx context.Context, cycle *models.ReviewCycle) ([]models.CycleDeveloperStatus, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	statuses := []models.CycleDeveloperStatus{}
+	for _, developer := range r.s.developers {
+		if developer.CompanyID == nil || *developer.CompanyID != cycle.CompanyID || developer.ArchivedAt != nil ||
+			!inCycleTeams(cycle, developer.TeamID) || !r.s.inUserTeams(scope, developer.TeamID) {
+			continue
+		}
+
+		status := models.CycleDeveloperStatus{
+			DeveloperID:   developer.ID,
+			DeveloperName: developer.Name,
+			TeamID:        developer.TeamID,
+			TeamName:      r.s.teamName(developer.TeamID),
+		}
+		var report *models.PerformanceReport
+		for _, stored := range r.s.reports {
+			if stored.DeveloperID == developer.ID && stored.Month == cycle.Month {
+				report = stored
+			}
+		}
+		if report != nil {
+			id, reportStatus := report.ID, report.Status
+			status.ReportID, status.ReportStatus = &id, &reportStatus
+		}
+		status.EvaluatorID, status.EvaluatorName = r.s.cycleEvaluator(cycle.ID, developer.ID, report)
+		statuses = append(statuses, status)
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if less, equal := lessNullsLast(statuses[i].TeamName, statuses[j].TeamName); !equal {
+			return less
+		}
+		return statuses[i].DeveloperName < statuses[j].DeveloperName
+	})
+	return statuses, nil
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// As funções abaixo reproduzem os filtros de database/scope.go e esperam s.mu travado
+
+// userTeams devolve os times do usuário: os atribuídos e os dos desenvolvedores vinculados à conta
+func (s *Store) userTeams(userID uuid.UUID) map[uuid.UUID]bool {
+	teams := map[uuid.UUID]bool{}
+	for _, manager := range s.teamManagers {
+		if manager.UserID == userID {
+			teams[manager.TeamID] = true
+		}
+	}
+	for _, developer := range s.developers {
+		if developer.UserID != nil && *developer.UserID == userID && developer.TeamID != nil {
+			teams[*developer.TeamID] = true
+		}
+	}
+	return teams
+}
+
+// inUserTeams indica se o time está entre os do usuário do escopo (sempre, com AllTeams)
+func (s *Store) inUserTeams(scope repository.Scope, teamID *uuid.UUID) bool {
+	if scope.AllTeams {
+		return true
+	}
+	return teamID != nil && s.userTeams(scope.UserID)[*teamID]
+}
+
+// teamAccessible equivale a teamScopeFilter: empresa do escopo e, sem AllTeams, times do usuário
+func (s *Store) teamAccessible(scope repository.Scope, team *models.Team) bool {
+	return scope.OwnsCompany(team.CompanyID) && s.inUserTeams(scope, &team.ID)
+}
+
+// teamInScope devolve repository.ErrNotFound se o time não existe ou não está acessível
+func (s *Store) teamInScope(scope repository.Scope, teamID uuid.UUID) error {
+	team := s.findTeam(teamID)
+	if team == nil || !s.teamAccessible(scope, team) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// developerVisible equivale a developerScopeFilter
+func (s *Store) developerVisible(scope repository.Scope, developer *models.Developer) bool {
+	return scope.OwnsCompany(developer.CompanyID) && s.inUserTeams(scope, developer.TeamID)
+}
+
+// developerInTeams equivale a developerTeamFilter
+func (s *Store) developerInTeams(scope repository.Scope, developerID uuid.UUID) bool {
+	if scope.AllTeams {
+		return true
+	}
+	developer := s.findDeveloper(developerID)
+	return developer != nil && s.inUserTeams(scope, developer.TeamID)
+}
+
+// reportInCompany equivale a reportCompanyFilter: o desenvolvedor do relatório é da empresa do escopo
+func (s *Store) reportInCompany(scope repository.Scope, report *models.PerformanceReport) bool {
+	developer := s.findDeveloper(report.DeveloperID)
+	return developer != nil && scope.OwnsCompany(developer.CompanyID)
+}
+
+// reportInTeams equivale a reportTeamFilter
+func (s *Store) reportInTeams(scope repository.Scope, report *models.PerformanceReport) bool {
+	if scope.AllTeamReports {
+		return true
+	}
+	developer := s.findDeveloper(report.DeveloperID)
+	return developer != nil && developer.TeamID != nil && s.userTeams(scope.UserID)[*developer.TeamID]
+}
+
+// reportVisible equivale a reportScopeFilter: rascunho, empresa e times
+func (s *Store) reportVisible(scope repository.Scope, report *models.PerformanceReport) bool {
+	if !scope.Drafts && report.Status == models.ReportStatusDraft {
+		return false
+	}
+	return s.reportInCompany(scope, report) && s.reportInTeams(scope, report)
+}
+
+func (s *Store) findCompany(id uuid.UUID) *models.Company {
+	for _, company := range s.companies {
+		if company.ID == id {
+			return company
+		}
+	}
+	return nil
+}
+
+func (s *Store) findUser(id uuid.UUID) *models.User {
+	for _, user := range s.users {
+		if user.ID == id {
+			return user
+		}
+	}
+	return nil
+}
+
+func (s *Store) findTeam(id uuid.UUID) *models.Team {
+	for _, team := range s.teams {
+		if team.ID == id {
+			return team
+		}
+	}
+	return nil
+}
+
+func (s *Store) findDeveloper(id uuid.UUID) *models.Developer {
+	for _, developer := range s.developers {
+		if developer.ID == id {
+			return developer
+		}
+	}
+	return nil
+}
+
+func (s *Store) findReport(id uuid.UUID) *models.PerformanceReport {
+	for _, report := range s.reports {
+		if report.ID == id {
+			return report
+		}
+	}
+	return nil
+}
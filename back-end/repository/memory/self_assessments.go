@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type selfAssessmentRepository struct{ s *Store }
+
+// assessmentInCompany equivale a reportCompanyFilter aplicado à autoavaliação
+func (s *Store) assessmentInCompany(scope repository.Scope, developerID uuid.UUID) bool {
+	developer := s.findDeveloper(developerID)
+	return developer != nil && scope.OwnsCompany(developer.CompanyID)
+}
+
+func (r selfAssessmentRepository) ListByDeveloper(ctx context.Context, developerID uuid.UUID) ([]models.SelfAssessment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	assessments := []models.SelfAssessment{}
+	if !r.s.assessmentInCompany(repository.ScopeFrom(ctx), developerID) {
+		return assessments, nil
+	}
+	for _, assessment := range r.s.selfAssessments {
+		if assessment.DeveloperID == developerID {
+			assessments = append(assessments, *assessment)
+		}
+	}
+	sort.SliceStable(assessments, func(i, j int) bool { return assessments[i].Month > assessments[j].Month })
+	return assessments, nil
+}
+
+func (r selfAssessmentRepository) Get(ctx context.Context, developerID uuid.UUID, month string) (*models.SelfAssessment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !r.s.assessmentInCompany(repository.ScopeFrom(ctx), developerID) {
+		return nil, repository.ErrNotFound
+	}
+	for _, assessment := range r.s.selfAssessments {
+		if assessment.DeveloperID == developerID && assessment.Month == month {
+			found := *assessment
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r selfAssessmentRepository) Save(ctx context.Context, assessment *models.SelfAssessment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !r.s.assessmentInCompany(repository.ScopeFrom(ctx), assessment.DeveloperID) {
+		return repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	for _, stored := range r.s.selfAssessments {
+		if stored.DeveloperID == assessment.DeveloperID && stored.Month == assessment.Month {
+			stored.TemplateVersionID = assessment.TemplateVersionID
+			stored.QuestionScores = assessment.QuestionScores
+			stored.CategoryScores = assessment.CategoryScores
+			stored.WeightedAverageScore = assessment.WeightedAverageScore
+			stored.Comments = assessment.Comments
+			stored.SubmittedBy = assessment.SubmittedBy
+			stored.UpdatedAt = now
+			*assessment = *stored
+			return nil
+		}
+	}
+
+	assessment.ID = uuid.New()
+	assessment.CreatedAt = now
+	assessment.UpdatedAt = now
+	stored := *assessment
+	r.s.selfAssessments = append(r.s.selfAssessments, &stored)
+	return nil
+}
@@ -0,0 +1,242 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// sessionRepository monta o dono da credencial como a implementação em PostgreSQL: papel
+// personalizado da mesma empresa e exigência de 2FA da política da empresa
+type sessionRepository struct{ s *Store }
+
+// sessionUser monta o dono da credencial com os dados atuais da conta
+func (s *Store) sessionUser(userID uuid.UUID) (*repository.SessionUser, error) {
+	user := s.findUser(userID)
+	if user == nil {
+		return nil, repository.ErrNotFound
+	}
+
+	permissions := models.RolePermissions[user.Role]
+	if role := s.assignedRole(user); role != nil {
+		permissions = role.Permissions
+	}
+
+	requiresMFA := false
+	if policy := s.findSecurityPolicy(user.CompanyID); policy != nil && policy.RequireMFA {
+		mfa := s.userMFA[user.ID]
+		requiresMFA = (mfa == nil || mfa.EnabledAt == nil) && models.RoleRequiresMFA(user.Role)
+	}
+
+	return &repository.SessionUser{
+		UserID:                user.ID,
+		Email:                 user.Email,
+		Role:                  user.Role,
+		CompanyID:             user.CompanyID,
+		IsActive:              user.IsActive,
+		NeedsPasswordChange:   user.NeedsPasswordChange,
+		MFAEnrollmentRequired: requiresMFA,
+		Permissions:           permissions,
+	}, nil
+}
+
+// activeSession indica se a sessão não foi encerrada nem expirou
+func activeSession(session *models.AuthSession, now time.Time) bool {
+	return session.RevokedAt == nil && session.ExpiresAt.After(now)
+}
+
+func (s *Store) findSession(id uuid.UUID) *models.AuthSession {
+	for _, stored := range s.sessions {
+		if stored.ID == id {
+			return stored
+		}
+	}
+	return nil
+}
+
+// revoke encerra a sessão com o motivo informado
+func (s *Store) revoke(session *models.AuthSession, reason string) {
+	now := s.now()
+	session.RevokedAt = &now
+	session.RevokedReason = &reason
+}
+
+// revokeSession encerra a sessão, se ainda ativa
+func (s *Store) revokeSession(id uuid.UUID, reason string) {
+	if session := s.findSession(id); session != nil && session.RevokedAt == nil {
+		s.revoke(session, reason)
+	}
+}
+
+// issueRefreshToken gera um novo refresh token para a sessão e guarda apenas o hash
+func (s *Store) issueRefreshToken(sessionID uuid.UUID, ttl time.Duration) (string, error) {
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	s.refreshTokens = append(s.refreshTokens, &refreshToken{
+		id:        uuid.New(),
+		sessionID: sessionID,
+		hash:      hash,
+		expiresAt: s.now().Add(ttl),
+	})
+	return token, nil
+}
+
+func (r sessionRepository) Start(ctx context.Context, user *models.User, userAgent, ip string, ttl time.Duration) (*repository.StartedSession, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	session := &models.AuthSession{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		UserAgent:  &userAgent,
+		IPAddress:  &ip,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	token, err := r.s.issueRefreshToken(session.ID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	r.s.sessions = append(r.s.sessions, session)
+
+	return &repository.StartedSession{SessionID: session.ID, RefreshToken: token, User: *user}, nil
+}
+
+func (r sessionRepository) Refresh(ctx context.Context, tokenHash, userAgent, ip string, ttl time.Duration) (*repository.StartedSession, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var stored *refreshToken
+	for _, token := range r.s.refreshTokens {
+		if token.hash == tokenHash {
+			stored = token
+		}
+	}
+	if stored == nil {
+		return nil, repository.ErrNotFound
+	}
+	session := r.s.findSession(stored.sessionID)
+	if session == nil {
+		return nil, repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	if stored.usedAt != nil {
+		r.s.revokeSession(session.ID, models.SessionRevokedTokenReuse)
+		return nil, repository.ErrTokenReused
+	}
+	if !stored.expiresAt.After(now) || !activeSession(session, now) {
+		return nil, repository.ErrSessionExpired
+	}
+
+	user := r.s.findUser(session.UserID)
+	if user == nil {
+		return nil, repository.ErrNotFound
+	}
+	if !user.IsActive {
+		return nil, repository.ErrInactiveUser
+	}
+
+	stored.usedAt = &now
+	token, err := r.s.issueRefreshToken(session.ID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(ttl)
+	session.IPAddress = &ip
+	session.UserAgent = &userAgent
+
+	return &repository.StartedSession{SessionID: session.ID, RefreshToken: token, User: *user}, nil
+}
+
+func (r sessionRepository) Revoke(ctx context.Context, sessionID uuid.UUID, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.revokeSession(sessionID, reason)
+	return nil
+}
+
+func (r sessionRepository) RevokeOwn(ctx context.Context, sessionID, userID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	session := r.s.findSession(sessionID)
+	if session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return repository.ErrNotFound
+	}
+	r.s.revoke(session, models.SessionRevokedByUser)
+	return nil
+}
+
+func (r sessionRepository) RevokeAll(ctx context.Context, userID uuid.UUID, reason string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.revokeUserSessions(userID, reason), nil
+}
+
+func (r sessionRepository) ListActive(ctx context.Context, userID uuid.UUID) ([]models.AuthSession, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.clock()
+	sessions := []models.AuthSession{}
+	for _, stored := range r.s.sessions {
+		if stored.UserID == userID && activeSession(stored, now) {
+			sessions = append(sessions, *stored)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (r sessionRepository) ActiveSession(ctx context.Context, sessionID, userID uuid.UUID) (*repository.SessionUser, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	session := r.s.findSession(sessionID)
+	if session == nil || session.UserID != userID || !activeSession(session, r.s.clock()) {
+		return nil, repository.ErrNotFound
+	}
+	return r.s.sessionUser(userID)
+}
+
+func (r sessionRepository) APITokenUser(ctx context.Context, token, ip string) (*repository.SessionUser, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	hash := utils.HashToken(token)
+	now := r.s.clock()
+	for _, stored := range r.s.apiTokens {
+		if stored.hash != hash || stored.RevokedAt != nil || !stored.ExpiresAt.After(now) {
+			continue
+		}
+		user, err := r.s.sessionUser(stored.UserID)
+		if err != nil {
+			return nil, err
+		}
+		// Contas de serviço não têm segundo fator; a política vale apenas para tokens pessoais
+		if r.s.findServiceAccount(stored.UserID) != nil {
+			user.MFAEnrollmentRequired = false
+		}
+		tokenID := stored.ID
+		user.APITokenID = &tokenID
+		user.APITokenScopes = stored.Scopes
+		stored.LastUsedAt = &now
+		stored.LastUsedIP = &ip
+		return user, nil
+	}
+	return nil, repository.ErrNotFound
+}
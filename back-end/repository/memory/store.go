@@ -0,0 +1,318 @@
+// Package memory implementa os repositórios em memória, com as mesmas regras de escopo da
+// implementação em PostgreSQL. É usado nos testes, que sobem as rotas sem banco de dados.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+	"tivix-performance-tracker-backend/utils"
+)
+
+// Store guarda os registros de todos os repositórios. Os métodos Add* semeiam os dados dos
+// testes diretamente, sem passar pelo escopo.
+type Store struct {
+	mu sync.Mutex
+
+	companies              []*models.Company
+	users                  []*models.User
+	teams                  []*models.Team
+	teamManagers           []*models.TeamManager
+	developers             []*models.Developer
+	reports                []*models.PerformanceReport
+	revisions              []*models.PerformanceReportRevision
+	templates              []*models.EvaluationTemplate
+	reviewCycles           []*models.ReviewCycle
+	cycleAssignments       []*cycleAssignment
+	calibrationSessions    []*models.CalibrationSession
+	calibrationAdjustments []*models.CalibrationAdjustment
+	feedbackRounds         []*models.FeedbackRound
+	feedbackNominations    []*feedbackNomination
+	selfAssessments        []*models.SelfAssessment
+	companyRoles           []*models.CompanyRole
+	roleAssignments        map[uuid.UUID]uuid.UUID
+	serviceAccounts        []*serviceAccount
+	securityPolicies       []*models.CompanySecurityPolicy
+	invitations            []*invitation
+	sessions               []*models.AuthSession
+	refreshTokens          []*refreshToken
+	loginThrottles         map[string]*loginThrottle
+	passwordResets         []*passwordReset
+	userMFA                map[uuid.UUID]*models.UserMFA
+	recoveryCodes          []*recoveryCode
+	mfaChallenges          []*mfaChallenge
+	ssoConfigs             []*models.CompanySSOConfig
+	ssoLogins              []*ssoLogin
+	identities             []*identity
+	apiTokens              []*apiToken
+	securityEvents         []*models.SecurityEvent
+	auditEvents            []*models.AuditEvent
+
+	lastTime time.Time
+	// elapsed é o tempo simulado por Advance, somado ao relógio de todos os repositórios
+	elapsed time.Duration
+}
+
+type apiToken struct {
+	models.APIToken
+	hash string
+}
+
+type refreshToken struct {
+	id        uuid.UUID
+	sessionID uuid.UUID
+	hash      string
+	expiresAt time.Time
+	usedAt    *time.Time
+}
+
+// NewStore devolve um armazenamento vazio
+func NewStore() *Store {
+	return &Store{
+		roleAssignments: map[uuid.UUID]uuid.UUID{},
+		loginThrottles:  map[string]*loginThrottle{},
+		userMFA:         map[uuid.UUID]*models.UserMFA{},
+	}
+}
+
+// Repositories devolve os repositórios apoiados neste armazenamento
+func (s *Store) Repositories() *repository.Repositories {
+	return &repository.Repositories{
+		Developers:       developerRepository{s},
+		Teams:            teamRepository{s},
+		Reports:          reportRepository{s},
+		Users:            userRepository{s},
+		Companies:        companyRepository{s},
+		Templates:        templateRepository{s},
+		ReviewCycles:     reviewCycleRepository{s},
+		Calibration:      calibrationRepository{s},
+		FeedbackRounds:   feedbackRoundRepository{s},
+		SelfAssessments:  selfAssessmentRepository{s},
+		Roles:            companyRoleRepository{s},
+		ServiceAccounts:  serviceAccountRepository{s},
+		SecurityPolicies: securityPolicyRepository{s},
+		Invitations:      invitationRepository{s},
+		Accounts:         accountRepository{s},
+		Sessions:         sessionRepository{s},
+		LoginThrottles:   loginThrottleRepository{s},
+		PasswordResets:   passwordResetRepository{s},
+		MFA:              mfaRepository{s},
+		SSO:              ssoRepository{s},
+		APITokens:        apiTokenRepository{s},
+		Audit:            auditRepository{s},
+		Transactions:     transactor{},
+	}
+}
+
+// clock é o relógio dos repositórios: a hora atual mais o tempo simulado por Advance
+func (s *Store) clock() time.Time {
+	return time.Now().Add(s.elapsed)
+}
+
+// Advance simula a passagem do tempo para bloqueios, atrasos e expirações dos repositórios;
+// os access tokens continuam usando o relógio real
+func (s *Store) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elapsed += d
+}
+
+// now devolve horários estritamente crescentes, para que as ordenações por data de criação
+// sejam determinísticas mesmo com registros criados no mesmo instante
+func (s *Store) now() time.Time {
+	now := s.clock()
+	if !now.After(s.lastTime) {
+		now = s.lastTime.Add(time.Microsecond)
+	}
+	s.lastTime = now
+	return now
+}
+
+// stamp preenche id e datas de criação e atualização ainda vazios
+func (s *Store) stamp(id *uuid.UUID, createdAt, updatedAt *time.Time) {
+	if *id == uuid.Nil {
+		*id = uuid.New()
+	}
+	if createdAt.IsZero() {
+		*createdAt = s.now()
+	}
+	if updatedAt.IsZero() {
+		*updatedAt = *createdAt
+	}
+}
+
+// AddCompany grava a empresa, preenchendo id e datas quando vazios
+func (s *Store) AddCompany(company *models.Company) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stamp(&company.ID, &company.CreatedAt, &company.UpdatedAt)
+	stored := *company
+	s.companies = append(s.companies, &stored)
+}
+
+// AddUser grava o usuário, preenchendo id e datas quando vazios
+func (s *Store) AddUser(user *models.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stamp(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	stored := *user
+	s.users = append(s.users, &stored)
+}
+
+// AddTeam grava o time, preenchendo id e datas quando vazios
+func (s *Store) AddTeam(team *models.Team) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stamp(&team.ID, &team.CreatedAt, &team.UpdatedAt)
+	stored := *team
+	s.teams = append(s.teams, &stored)
+}
+
+// AssignTeam atribui o time ao usuário, como POST /teams/:id/managers
+func (s *Store) AssignTeam(userID, teamID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assignTeam(userID, teamID, userID)
+}
+
+// AddDeveloper grava o desenvolvedor, preenchendo id e datas quando vazios
+func (s *Store) AddDeveloper(developer *models.Developer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stamp(&developer.ID, &developer.CreatedAt, &developer.UpdatedAt)
+	stored := *developer
+	s.developers = append(s.developers, &stored)
+}
+
+// AddReport grava o relatório (em rascunho quando o status está vazio) e atualiza a
+// pontuação mais recente do desenvolvedor
+func (s *Store) AddReport(report *models.PerformanceReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stamp(&report.ID, &report.CreatedAt, &report.UpdatedAt)
+	if report.Status == "" {
+		report.Status = models.ReportStatusDraft
+	}
+	stored := *report
+	s.reports = append(s.reports, &stored)
+	s.refreshLatestScore(report.DeveloperID)
+}
+
+// AddTemplate grava a versão do template, preenchendo os ids vazios
+func (s *Store) AddTemplate(template *models.EvaluationTemplate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stamp(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+	if template.VersionID == uuid.Nil {
+		template.VersionID = uuid.New()
+	}
+	for i := range template.Categories {
+		if template.Categories[i].ID == uuid.Nil {
+			template.Categories[i].ID = uuid.New()
+		}
+		for j := range template.Categories[i].Questions {
+			if template.Categories[i].Questions[j].ID == uuid.Nil {
+				template.Categories[i].Questions[j].ID = uuid.New()
+			}
+		}
+	}
+	stored := *template
+	s.templates = append(s.templates, &stored)
+}
+
+// StartSession abre uma sessão para o usuário, como o login, e devolve o id usado no JWT
+func (s *Store) StartSession(userID uuid.UUID) uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	id := uuid.New()
+	s.sessions = append(s.sessions, &models.AuthSession{
+		ID:         id,
+		UserID:     userID,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(time.Hour),
+	})
+	return id
+}
+
+// RevokeSession encerra a sessão, como o logout
+func (s *Store) RevokeSession(sessionID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revokeSession(sessionID, models.SessionRevokedLogout)
+}
+
+// UserSessions devolve as sessões do usuário, ativas ou encerradas, na ordem de abertura
+func (s *Store) UserSessions(userID uuid.UUID) []models.AuthSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := []models.AuthSession{}
+	for _, session := range s.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions
+}
+
+// AddAPIToken registra um token de API do usuário com os escopos informados
+func (s *Store) AddAPIToken(userID uuid.UUID, token string, scopes []string) uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	id := uuid.New()
+	s.apiTokens = append(s.apiTokens, &apiToken{
+		APIToken: models.APIToken{
+			ID:        id,
+			UserID:    userID,
+			Name:      "token",
+			Prefix:    token[:min(len(token), 8)],
+			Scopes:    scopes,
+			ExpiresAt: now.Add(time.Hour),
+			CreatedBy: &userID,
+			CreatedAt: now,
+		},
+		hash: utils.HashToken(token),
+	})
+	return id
+}
+
+// SecurityEvents devolve os eventos de segurança gravados, na ordem de gravação
+func (s *Store) SecurityEvents() []models.SecurityEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := []models.SecurityEvent{}
+	for _, event := range s.securityEvents {
+		events = append(events, *event)
+	}
+	return events
+}
+
+// AuditEvents devolve os eventos de auditoria gravados, na ordem de gravação
+func (s *Store) AuditEvents() []models.AuditEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := []models.AuditEvent{}
+	for _, event := range s.auditEvents {
+		events = append(events, *event)
+	}
+	return events
+}
+
+// transactor não isola nada: as alterações são aplicadas na hora e Rollback não as desfaz
+type transactor struct{}
+
+type noopTx struct{}
+
+func (noopTx) Commit() error   { return nil }
+func (noopTx) Rollback() error { return nil }
+
+func (transactor) Begin(ctx context.Context, readOnly bool) (context.Context, repository.Tx, error) {
+	return ctx, noopTx{}, nil
+}
@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type teamRepository struct{ s *Store }
+
+// assignTeam grava a atribuição do time ao usuário, ignorando atribuições repetidas
+func (s *Store) assignTeam(userID, teamID, assignedBy uuid.UUID) {
+	for _, manager := range s.teamManagers {
+		if manager.UserID == userID && manager.TeamID == teamID {
+			return
+		}
+	}
+	s.teamManagers = append(s.teamManagers, &models.TeamManager{
+		UserID:     userID,
+		TeamID:     teamID,
+		AssignedBy: &assignedBy,
+		AssignedAt: s.now(),
+	})
+}
+
+// unassignTeams remove as atribuições selecionadas e devolve quantas foram removidas
+func (s *Store) unassignTeams(match func(manager *models.TeamManager) bool) int {
+	kept := s.teamManagers[:0]
+	for _, manager := range s.teamManagers {
+		if !match(manager) {
+			kept = append(kept, manager)
+		}
+	}
+	removed := len(s.teamManagers) - len(kept)
+	s.teamManagers = kept
+	return removed
+}
+
+// deleteTeams remove os times selecionados, desvinculando os desenvolvedores e as atribuições
+func (s *Store) deleteTeams(match func(team *models.Team) bool) []uuid.UUID {
+	removed := map[uuid.UUID]bool{}
+	kept := s.teams[:0]
+	for _, team := range s.teams {
+		if match(team) {
+			removed[team.ID] = true
+			continue
+		}
+		kept = append(kept, team)
+	}
+	s.teams = kept
+
+	unassignedDevelopers := []uuid.UUID{}
+	for _, developer := range s.developers {
+		if developer.TeamID != nil && removed[*developer.TeamID] {
+			developer.TeamID = nil
+			unassignedDevelopers = append(unassignedDevelopers, developer.ID)
+		}
+	}
+	s.unassignTeams(func(manager *models.TeamManager) bool { return removed[manager.TeamID] })
+	return unassignedDevelopers
+}
+
+func (r teamRepository) List(ctx context.Context, page repository.PageRequest) (*repository.Page[models.Team], error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	var teams []models.Team
+	for _, team := range r.s.teams {
+		if scope.OwnsCompany(team.CompanyID) {
+			teams = append(teams, *team)
+		}
+	}
+
+	return paginate(teams, repository.TeamSorting, page), nil
+}
+
+func (r teamRepository) Get(ctx context.Context, id uuid.UUID) (*models.Team, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	team := r.s.findTeam(id)
+	if team == nil || !repository.ScopeFrom(ctx).OwnsCompany(team.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+	found := *team
+	return &found, nil
+}
+
+func (r teamRepository) GetAccessible(ctx context.Context, id uuid.UUID) (*models.Team, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	team := r.s.findTeam(id)
+	if team == nil || !r.s.teamAccessible(repository.ScopeFrom(ctx), team) {
+		return nil, repository.ErrNotFound
+	}
+	found := *team
+	return &found, nil
+}
+
+func (r teamRepository) Create(ctx context.Context, team *models.Team) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	if !scope.OwnsCompany(team.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	team.ID = uuid.New()
+	team.CreatedAt = r.s.now()
+	team.UpdatedAt = team.CreatedAt
+	stored := *team
+	r.s.teams = append(r.s.teams, &stored)
+
+	// Sem teams:all, quem cria o time é atribuído a ele para continuar enxergando-o
+	if !scope.AllTeams {
+		r.s.assignTeam(scope.UserID, team.ID, scope.UserID)
+	}
+	return nil
+}
+
+func (r teamRepository) Update(ctx context.Context, id uuid.UUID, update repository.TeamUpdate) (*models.Team, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if update.Name == nil && update.Description == nil && update.Color == nil {
+		return nil, repository.ErrNoChanges
+	}
+
+	team := r.s.findTeam(id)
+	if team == nil || !r.s.teamAccessible(repository.ScopeFrom(ctx), team) {
+		return nil, repository.ErrNotFound
+	}
+	if update.Name != nil {
+		team.Name = *update.Name
+	}
+	if update.Description != nil {
+		team.Description = *update.Description
+	}
+	if update.Color != nil {
+		team.Color = *update.Color
+	}
+	team.UpdatedAt = r.s.now()
+
+	updated := *team
+	return &updated, nil
+}
+
+func (r teamRepository) Delete(ctx context.Context, id uuid.UUID) (*models.Team, []uuid.UUID, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	team := r.s.findTeam(id)
+	if team == nil || !r.s.teamAccessible(repository.ScopeFrom(ctx), team) {
+		return nil, nil, repository.ErrNotFound
+	}
+	deleted := *team
+
+	unassignedDevelopers := r.s.deleteTeams(func(t *models.Team) bool { return t.ID == id })
+	return &deleted, unassignedDevelopers, nil
+}
+
+func (r teamRepository) ListManagers(ctx context.Context, teamID uuid.UUID) ([]models.TeamManager, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	managers := []models.TeamManager{}
+	if r.s.teamInScope(repository.ScopeFrom(ctx), teamID) != nil {
+		return managers, nil
+	}
+
+	for _, manager := range r.s.teamManagers {
+		user := r.s.findUser(manager.UserID)
+		if manager.TeamID != teamID || user == nil {
+			continue
+		}
+		listed := *manager
+		listed.Name = user.Name
+		listed.Email = user.Email
+		listed.Role = user.Role
+		managers = append(managers, listed)
+	}
+
+	sort.SliceStable(managers, func(i, j int) bool { return managers[i].Name < managers[j].Name })
+	return managers, nil
+}
+
+func (r teamRepository) IsEligibleManager(ctx context.Context, teamID, userID uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user := r.s.findUser(userID)
+	team := r.s.findTeam(teamID)
+	if user == nil || team == nil || !r.s.teamAccessible(repository.ScopeFrom(ctx), team) {
+		return false, nil
+	}
+
+	// Admins e desenvolvedores não recebem times: os primeiros já veem tudo e os
+	// outros acessam apenas os próprios relatórios
+	sameCompany := user.CompanyID != nil && team.CompanyID != nil && *user.CompanyID == *team.CompanyID
+	switch user.Role {
+	case "company_admin", "manager", "user":
+		return sameCompany, nil
+	}
+	return false, nil
+}
+
+func (r teamRepository) AddManager(ctx context.Context, teamID, userID, assignedBy uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if err := r.s.teamInScope(repository.ScopeFrom(ctx), teamID); err != nil {
+		return err
+	}
+	r.s.assignTeam(userID, teamID, assignedBy)
+	return nil
+}
+
+func (r teamRepository) RemoveManager(ctx context.Context, teamID, userID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if err := r.s.teamInScope(repository.ScopeFrom(ctx), teamID); err != nil {
+		return err
+	}
+	removed := r.s.unassignTeams(func(manager *models.TeamManager) bool {
+		return manager.UserID == userID && manager.TeamID == teamID
+	})
+	if removed == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
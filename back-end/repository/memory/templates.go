@@ -0,0 +1,286 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+// templateRepository guarda cada versão como uma entrada de s.templates, com o id do template
+// repetido entre as versões
+type templateRepository struct{ s *Store }
+
+// templateVisible equivale ao filtro de leitura do PostgreSQL: globais para todos, os de
+// empresa apenas para a própria
+func templateVisible(scope repository.Scope, template *models.EvaluationTemplate) bool {
+	return template.CompanyID == nil || scope.OwnsCompany(template.CompanyID)
+}
+
+// latestTemplate devolve a versão mais recente do template, ou nil
+func (s *Store) latestTemplate(id uuid.UUID) *models.EvaluationTemplate {
+	var latest *models.EvaluationTemplate
+	for _, template := range s.templates {
+		if template.ID == id && (latest == nil || template.Version > latest.Version) {
+			latest = template
+		}
+	}
+	return latest
+}
+
+// writableTemplate devolve a versão mais recente se o escopo puder alterar o template
+func (s *Store) writableTemplate(scope repository.Scope, id uuid.UUID) (*models.EvaluationTemplate, error) {
+	latest := s.latestTemplate(id)
+	if latest == nil || !scope.OwnsCompany(latest.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+	return latest, nil
+}
+
+// addTemplateVersion grava a versão seguinte à mais recente, copiando os dados do template
+func (s *Store) addTemplateVersion(current *models.EvaluationTemplate, version repository.TemplateVersion) {
+	next := *current
+	next.VersionID = uuid.New()
+	next.Version = current.Version + 1
+	next.ScoreMin = version.ScoreMin
+	next.ScoreMax = version.ScoreMax
+	next.Categories = []models.EvaluationCategory{}
+	for i, request := range version.Categories {
+		category := models.EvaluationCategory{
+			ID:        uuid.New(),
+			Key:       request.Key,
+			Label:     request.Label,
+			Weight:    request.Weight,
+			Position:  i + 1,
+			Questions: []models.EvaluationQuestion{},
+		}
+		for j, question := range request.Questions {
+			category.Questions = append(category.Questions, models.EvaluationQuestion{
+				ID:       uuid.New(),
+				Key:      question.Key,
+				Label:    question.Label,
+				Weight:   question.Weight,
+				Position: j + 1,
+			})
+		}
+		next.Categories = append(next.Categories, category)
+	}
+	s.templates = append(s.templates, &next)
+}
+
+// activateTemplate marca o template como o único ativo da empresa, em todas as versões
+func (s *Store) activateTemplate(companyID, id uuid.UUID) {
+	for _, template := range s.templates {
+		if template.CompanyID != nil && *template.CompanyID == companyID {
+			template.IsActive = template.ID == id
+		}
+	}
+}
+
+func (r templateRepository) Version(ctx context.Context, versionID uuid.UUID) (*models.EvaluationTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, template := range r.s.templates {
+		if template.VersionID == versionID && templateVisible(repository.ScopeFrom(ctx), template) {
+			found := *template
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r templateRepository) Active(ctx context.Context, companyID *uuid.UUID) (*models.EvaluationTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if companyID != nil && !repository.ScopeFrom(ctx).OwnsCompany(companyID) {
+		return nil, repository.ErrNotFound
+	}
+
+	// O template próprio da empresa vem antes do global; entre versões, a mais recente
+	var active *models.EvaluationTemplate
+	for _, template := range r.s.templates {
+		own := template.CompanyID != nil && companyID != nil && *template.CompanyID == *companyID
+		if !template.IsActive || (!own && template.CompanyID != nil) {
+			continue
+		}
+		if active == nil {
+			active = template
+			continue
+		}
+		activeOwn := active.CompanyID != nil
+		if (own && !activeOwn) || (own == activeOwn && template.Version > active.Version) {
+			active = template
+		}
+	}
+
+	if active == nil {
+		return nil, repository.ErrNotFound
+	}
+	found := *active
+	return &found, nil
+}
+
+func (r templateRepository) Latest(ctx context.Context, id uuid.UUID) (*models.EvaluationTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	latest := r.s.latestTemplate(id)
+	if latest == nil || !templateVisible(repository.ScopeFrom(ctx), latest) {
+		return nil, repository.ErrNotFound
+	}
+	found := *latest
+	return &found, nil
+}
+
+func (r templateRepository) ListByCompany(ctx context.Context, companyID uuid.UUID) ([]models.EvaluationTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	templates := []models.EvaluationTemplate{}
+	if !repository.ScopeFrom(ctx).OwnsCompany(&companyID) {
+		return templates, nil
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for _, template := range r.s.templates {
+		if template.CompanyID == nil || *template.CompanyID != companyID || seen[template.ID] {
+			continue
+		}
+		seen[template.ID] = true
+		templates = append(templates, *r.s.latestTemplate(template.ID))
+	}
+
+	sort.SliceStable(templates, func(i, j int) bool {
+		if templates[i].IsActive != templates[j].IsActive {
+			return templates[i].IsActive
+		}
+		return templates[i].CreatedAt.After(templates[j].CreatedAt)
+	})
+	return templates, nil
+}
+
+func (r templateRepository) Create(ctx context.Context, template *models.EvaluationTemplate, version repository.TemplateVersion, activate bool) (*models.EvaluationTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(template.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+
+	base := models.EvaluationTemplate{
+		ID:          uuid.New(),
+		Name:        template.Name,
+		Description: template.Description,
+		CompanyID:   template.CompanyID,
+	}
+	base.CreatedAt = r.s.now()
+	base.UpdatedAt = base.CreatedAt
+	r.s.addTemplateVersion(&base, version)
+	if activate && template.CompanyID != nil {
+		r.s.activateTemplate(*template.CompanyID, base.ID)
+	}
+
+	created := *r.s.latestTemplate(base.ID)
+	return &created, nil
+}
+
+func (r templateRepository) Update(ctx context.Context, id uuid.UUID, update repository.TemplateUpdate) (*models.EvaluationTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if update.Name == nil && update.Description == nil && update.Version == nil {
+		return nil, repository.ErrNoChanges
+	}
+
+	current, err := r.s.writableTemplate(repository.ScopeFrom(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.s.now()
+	for _, template := range r.s.templates {
+		if template.ID != id {
+			continue
+		}
+		if update.Name != nil {
+			template.Name = *update.Name
+		}
+		if update.Description != nil {
+			template.Description = *update.Description
+		}
+		template.UpdatedAt = now
+	}
+	if update.Version != nil {
+		r.s.addTemplateVersion(current, *update.Version)
+	}
+
+	updated := *r.s.latestTemplate(id)
+	return &updated, nil
+}
+
+func (r templateRepository) Activate(ctx context.Context, companyID, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, err := r.s.writableTemplate(repository.ScopeFrom(ctx), id); err != nil {
+		return err
+	}
+	r.s.activateTemplate(companyID, id)
+	return nil
+}
+
+func (r templateRepository) InUse(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	versions := map[uuid.UUID]bool{}
+	for _, template := range r.s.templates {
+		if template.ID == id {
+			versions[template.VersionID] = true
+		}
+	}
+	for _, report := range r.s.reports {
+		if report.TemplateVersionID != nil && versions[*report.TemplateVersionID] {
+			return true, nil
+		}
+	}
+	for _, cycle := range r.s.reviewCycles {
+		if versions[cycle.TemplateVersionID] {
+			return true, nil
+		}
+	}
+	for _, assessment := range r.s.selfAssessments {
+		if versions[assessment.TemplateVersionID] {
+			return true, nil
+		}
+	}
+	for _, round := range r.s.feedbackRounds {
+		if versions[round.TemplateVersionID] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r templateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, err := r.s.writableTemplate(repository.ScopeFrom(ctx), id); err != nil {
+		return err
+	}
+
+	kept := r.s.templates[:0]
+	for _, template := range r.s.templates {
+		if template.ID != id {
+			kept = append(kept, template)
+		}
+	}
+	r.s.templates = kept
+	return nil
+}
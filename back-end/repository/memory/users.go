@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
+)
+
+type userRepository struct{ s *Store }
+
+// revokeUserSessions encerra as sessões ativas do usuário com o motivo informado e devolve
+// quantas foram encerradas
+func (s *Store) revokeUserSessions(userID uuid.UUID, reason string) int64 {
+	var revoked int64
+	for _, stored := range s.sessions {
+		if stored.UserID == userID && stored.RevokedAt == nil {
+			s.revoke(stored, reason)
+			revoked++
+		}
+	}
+	return revoked
+}
+
+func (r userRepository) List(ctx context.Context, filter repository.UserFilter, page repository.PageRequest) (*repository.Page[models.User], error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	var users []models.User
+	for _, user := range r.s.users {
+		if !scope.OwnsCompany(user.CompanyID) {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if filter.IsActive != nil && user.IsActive != *filter.IsActive {
+			continue
+		}
+		users = append(users, *user)
+	}
+
+	return paginate(users, repository.UserSorting, page), nil
+}
+
+func (r userRepository) Get(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	user := r.s.findUser(id)
+	// O próprio usuário sempre está no escopo, mesmo sem empresa
+	if user == nil || (id != scope.UserID && !scope.OwnsCompany(user.CompanyID)) {
+		return nil, repository.ErrNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r userRepository) EmailExists(ctx context.Context, email string, exceptID *uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if user.Email == email && (exceptID == nil || user.ID != *exceptID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r userRepository) Create(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !repository.ScopeFrom(ctx).OwnsCompany(user.CompanyID) {
+		return repository.ErrNotFound
+	}
+	stored := *user
+	r.s.users = append(r.s.users, &stored)
+	return nil
+}
+
+func (r userRepository) Update(ctx context.Context, id uuid.UUID, update repository.UserUpdate) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	scope := repository.ScopeFrom(ctx)
+	if update.CompanyID != nil && !scope.OwnsCompany(update.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+	if update.Name == nil && update.Email == nil && update.Role == nil && update.CompanyID == nil && update.IsActive == nil {
+		return nil, repository.ErrNoChanges
+	}
+
+	user := r.s.findUser(id)
+	if user == nil || !scope.OwnsCompany(user.CompanyID) {
+		return nil, repository.ErrNotFound
+	}
+	if update.Name != nil {
+		user.Name = *update.Name
+	}
+	if update.Email != nil {
+		user.Email = *update.Email
+	}
+	if update.Role != nil {
+		user.Role = *update.Role
+	}
+	if update.CompanyID != nil {
+		companyID := *update.CompanyID
+		user.CompanyID = &companyID
+	}
+	if update.IsActive != nil {
+		user.IsActive = *update.IsActive
+	}
+	user.UpdatedAt = r.s.now()
+
+	if update.RevokeSessions != "" {
+		r.s.revokeUserSessions(id, update.RevokeSessions)
+	}
+
+	updated := *user
+	return &updated, nil
+}
+
+func (r userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user := r.s.findUser(id)
+	if user == nil || !repository.ScopeFrom(ctx).OwnsCompany(user.CompanyID) {
+		return repository.ErrNotFound
+	}
+
+	kept := r.s.users[:0]
+	for _, stored := range r.s.users {
+		if stored.ID != id {
+			kept = append(kept, stored)
+		}
+	}
+	r.s.users = kept
+
+	// Mesmo efeito das chaves estrangeiras: sessões, tokens e atribuições saem junto e os
+	// desenvolvedores perdem o vínculo
+	keptSessions := r.s.sessions[:0]
+	for _, stored := range r.s.sessions {
+		if stored.UserID != id {
+			keptSessions = append(keptSessions, stored)
+		}
+	}
+	r.s.sessions = keptSessions
+
+	keptTokens := r.s.apiTokens[:0]
+	for _, stored := range r.s.apiTokens {
+		if stored.UserID != id {
+			keptTokens = append(keptTokens, stored)
+		}
+	}
+	r.s.apiTokens = keptTokens
+
+	r.s.unassignTeams(func(manager *models.TeamManager) bool { return manager.UserID == id })
+	for _, developer := range r.s.developers {
+		if developer.UserID != nil && *developer.UserID == id {
+			developer.UserID = nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// ErrInvalidCursor indica um cursor malformado ou gerado para outra ordenação
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// PageRequest pede uma página de uma listagem. Limit zero devolve todos os itens; Sort vazio
+// usa a ordenação padrão da listagem.
+type PageRequest struct {
+	Limit int
+	Sort  string
+	Desc  bool
+	// After é o cursor devolvido na página anterior
+	After *Cursor
+}
+
+// Page é uma página de resultados. Total conta todos os itens que atendem aos filtros e
+// NextCursor fica vazio na última página.
+type Page[T any] struct {
+	Items      []T
+	Total      int
+	NextCursor string
+}
+
+// Cursor aponta para o último item de uma página pela chave de ordenação (valor do campo e id)
+type Cursor struct {
+	Sort  string    `json:"s"`
+	Desc  bool      `json:"d,omitempty"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// String codifica o cursor no formato opaco usado no parâmetro cursor
+func (c Cursor) String() string {
+	payload, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// ParseCursor decodifica o parâmetro cursor
+func ParseCursor(value string) (*Cursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// SortValue formata o valor de um campo de ordenação como gravado no cursor
+func SortValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return value.(string)
+	}
+}
+
+// ParseSortValue lê o valor do cursor com o mesmo tipo do exemplo informado
+func ParseSortValue(example interface{}, value string) (interface{}, error) {
+	switch example.(type) {
+	case float64:
+		return strconv.ParseFloat(value, 64)
+	case time.Time:
+		return time.Parse(time.RFC3339Nano, value)
+	default:
+		return value, nil
+	}
+}
+
+// Sorting descreve a ordenação de uma listagem: os campos aceitos no parâmetro sort (com o
+// valor de cada um no item) e o padrão. O id desempata itens com o mesmo valor.
+type Sorting[T any] struct {
+	Fields      map[string]func(T) interface{}
+	Default     string
+	DefaultDesc bool
+	ID          func(T) uuid.UUID
+}
+
+// Order devolve o campo e a direção da página, aplicando o padrão quando Sort está vazio
+func (s Sorting[T]) Order(page PageRequest) (string, bool) {
+	if page.Sort == "" {
+		return s.Default, s.DefaultDesc
+	}
+	return page.Sort, page.Desc
+}
+
+// Allows indica se o campo pode ser usado no parâmetro sort
+func (s Sorting[T]) Allows(field string) bool {
+	_, ok := s.Fields[field]
+	return ok
+}
+
+// CheckCursor verifica se o cursor foi gerado para a ordenação da página e se o valor tem o
+// tipo do campo
+func (s Sorting[T]) CheckCursor(page PageRequest) error {
+	if page.After == nil {
+		return nil
+	}
+	sort, desc := s.Order(page)
+	if page.After.Sort != sort || page.After.Desc != desc {
+		return ErrInvalidCursor
+	}
+	var zero T
+	if _, err := ParseSortValue(s.Fields[sort](zero), page.After.Value); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// NewPage monta a página a partir dos itens já ordenados e posicionados após o cursor. Quem
+// lista busca Limit+1 itens: o excedente indica que há uma próxima página.
+func (s Sorting[T]) NewPage(items []T, total int, page PageRequest) *Page[T] {
+	result := &Page[T]{Items: items, Total: total}
+	if page.Limit <= 0 || len(items) <= page.Limit {
+		return result
+	}
+
+	result.Items = items[:page.Limit]
+	last := result.Items[page.Limit-1]
+	sort, desc := s.Order(page)
+	result.NextCursor = Cursor{
+		Sort:  sort,
+		Desc:  desc,
+		Value: SortValue(s.Fields[sort](last)),
+		ID:    s.ID(last),
+	}.String()
+	return result
+}
+
+// archivedAt ordena os desenvolvedores ativos antes dos arquivados (data zero)
+func archivedAt(d models.Developer) interface{} {
+	if d.ArchivedAt == nil {
+		return time.Time{}
+	}
+	return *d.ArchivedAt
+}
+
+// Ordenações aceitas pelas listagens paginadas
+var (
+	DeveloperSorting = Sorting[models.Developer]{
+		Fields: map[string]func(models.Developer) interface{}{
+			"name":                   func(d models.Developer) interface{} { return d.Name },
+			"role":                   func(d models.Developer) interface{} { return d.Role },
+			"latestPerformanceScore": func(d models.Developer) interface{} { return d.LatestPerformanceScore },
+			"createdAt":              func(d models.Developer) interface{} { return d.CreatedAt },
+			"archivedAt":             archivedAt,
+		},
+		Default:     "createdAt",
+		DefaultDesc: true,
+		ID:          func(d models.Developer) uuid.UUID { return d.ID },
+	}
+
+	ReportSorting = Sorting[models.PerformanceReport]{
+		Fields: map[string]func(models.PerformanceReport) interface{}{
+			"month":                func(r models.PerformanceReport) interface{} { return r.Month },
+			"weightedAverageScore": func(r models.PerformanceReport) interface{} { return r.WeightedAverageScore },
+			"createdAt":            func(r models.PerformanceReport) interface{} { return r.CreatedAt },
+		},
+		Default:     "month",
+		DefaultDesc: true,
+		ID:          func(r models.PerformanceReport) uuid.UUID { return r.ID },
+	}
+
+	TeamSorting = Sorting[models.Team]{
+		Fields: map[string]func(models.Team) interface{}{
+			"name":      func(t models.Team) interface{} { return t.Name },
+			"createdAt": func(t models.Team) interface{} { return t.CreatedAt },
+		},
+		Default:     "createdAt",
+		DefaultDesc: true,
+		ID:          func(t models.Team) uuid.UUID { return t.ID },
+	}
+
+	UserSorting = Sorting[models.User]{
+		Fields: map[string]func(models.User) interface{}{
+			"name":      func(u models.User) interface{} { return u.Name },
+			"email":     func(u models.User) interface{} { return u.Email },
+			"role":      func(u models.User) interface{} { return u.Role },
+			"createdAt": func(u models.User) interface{} { return u.CreatedAt },
+		},
+		Default:     "createdAt",
+		DefaultDesc: true,
+		ID:          func(u models.User) uuid.UUID { return u.ID },
+	}
+
+	CompanySorting = Sorting[models.Company]{
+		Fields: map[string]func(models.Company) interface{}{
+			"name":      func(c models.Company) interface{} { return c.Name },
+			"createdAt": func(c models.Company) interface{} { return c.CreatedAt },
+		},
+		Default: "name",
+		ID:      func(c models.Company) uuid.UUID { return c.ID },
+	}
+)
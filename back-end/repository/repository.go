@@ -0,0 +1,281 @@
+// Package repository define o acesso a dados usado pelos handlers e pelos middlewares de
+// autenticação e auditoria. Todas as operações recebem o contexto da requisição e respeitam o
+// Scope gravado nele: registros fora do escopo são tratados como inexistentes (ErrNotFound).
+// As exceções são os repositórios de login e credenciais (accounts.go), que servem a fluxos
+// anteriores à autenticação. As listagens são paginadas por cursor (PageRequest e Page). A
+// implementação em PostgreSQL fica no pacote database e a em memória, usada nos testes, no
+// pacote repository/memory.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// ErrNotFound indica que o registro não existe ou está fora do escopo do usuário
+var ErrNotFound = errors.New("registro não encontrado")
+
+// ErrNoChanges indica uma atualização sem nenhum campo preenchido
+var ErrNoChanges = errors.New("nenhum campo para atualizar")
+
+// Repositories agrupa os repositórios injetados nas rotas
+type Repositories struct {
+	Developers       DeveloperRepository
+	Teams            TeamRepository
+	Reports          ReportRepository
+	Users            UserRepository
+	Companies        CompanyRepository
+	Templates        TemplateRepository
+	ReviewCycles     ReviewCycleRepository
+	Calibration      CalibrationRepository
+	FeedbackRounds   FeedbackRoundRepository
+	SelfAssessments  SelfAssessmentRepository
+	Roles            CompanyRoleRepository
+	ServiceAccounts  ServiceAccountRepository
+	SecurityPolicies SecurityPolicyRepository
+	Invitations      InvitationRepository
+	Accounts         AccountRepository
+	Sessions         SessionRepository
+	LoginThrottles   LoginThrottleRepository
+	PasswordResets   PasswordResetRepository
+	MFA              MFARepository
+	SSO              SSORepository
+	APITokens        APITokenRepository
+	Audit            AuditRepository
+	Transactions     Transactor
+}
+
+// Tx é uma transação aberta por um Transactor
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// Transactor abre a transação da requisição com o escopo do contexto. As chamadas aos
+// repositórios feitas com o contexto devolvido rodam dentro dela.
+type Transactor interface {
+	Begin(ctx context.Context, readOnly bool) (context.Context, Tx, error)
+}
+
+// DeveloperFilter seleciona os desenvolvedores listados
+type DeveloperFilter struct {
+	TeamID          *uuid.UUID
+	Role            string
+	IncludeArchived bool
+	// ArchivedOnly lista apenas os arquivados
+	ArchivedOnly bool
+	// MinScore e MaxScore limitam a pontuação mais recente (inclusive)
+	MinScore *float64
+	MaxScore *float64
+}
+
+// DeveloperUpdate traz os campos alterados de um desenvolvedor (nil mantém o valor)
+type DeveloperUpdate struct {
+	Name   *string
+	Role   *string
+	TeamID *uuid.UUID
+}
+
+// DeveloperRepository acessa os desenvolvedores. Sem AllTeams, apenas os desenvolvedores dos
+// times do usuário estão no escopo.
+type DeveloperRepository interface {
+	List(ctx context.Context, filter DeveloperFilter, page PageRequest) (*Page[models.Developer], error)
+	Get(ctx context.Context, id uuid.UUID) (*models.Developer, error)
+	// GetByUser devolve o desenvolvedor vinculado à conta, dentro da empresa do escopo
+	GetByUser(ctx context.Context, userID uuid.UUID) (*models.Developer, error)
+	Create(ctx context.Context, developer *models.Developer) error
+	Update(ctx context.Context, id uuid.UUID, update DeveloperUpdate) (*models.Developer, error)
+	// SetUser vincula (ou, com userID nil, desvincula) a conta de usuário do desenvolvedor
+	SetUser(ctx context.Context, id uuid.UUID, userID *uuid.UUID) (*models.Developer, error)
+	// IsUserLinked indica se a conta já está vinculada a outro desenvolvedor
+	IsUserLinked(ctx context.Context, userID, exceptDeveloperID uuid.UUID) (bool, error)
+	// SetArchived arquiva (archivedAt preenchido) ou restaura o desenvolvedor
+	SetArchived(ctx context.Context, id uuid.UUID, archivedAt *time.Time) (*models.Developer, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// TeamUpdate traz os campos alterados de um time (nil mantém o valor)
+type TeamUpdate struct {
+	Name        *string
+	Description *string
+	Color       *string
+}
+
+// TeamRepository acessa os times e os usuários atribuídos a eles. Leituras alcançam todos os
+// times da empresa; alterações exigem que o time esteja entre os do usuário (sem AllTeams).
+type TeamRepository interface {
+	List(ctx context.Context, page PageRequest) (*Page[models.Team], error)
+	Get(ctx context.Context, id uuid.UUID) (*models.Team, error)
+	// GetAccessible devolve o time apenas se ele estiver entre os times do usuário
+	GetAccessible(ctx context.Context, id uuid.UUID) (*models.Team, error)
+	Create(ctx context.Context, team *models.Team) error
+	Update(ctx context.Context, id uuid.UUID, update TeamUpdate) (*models.Team, error)
+	// Delete exclui o time e devolve os desenvolvedores que ficaram sem time
+	Delete(ctx context.Context, id uuid.UUID) (*models.Team, []uuid.UUID, error)
+	ListManagers(ctx context.Context, teamID uuid.UUID) ([]models.TeamManager, error)
+	// IsEligibleManager indica se o usuário é da empresa do time e tem um papel que recebe times
+	IsEligibleManager(ctx context.Context, teamID, userID uuid.UUID) (bool, error)
+	AddManager(ctx context.Context, teamID, userID, assignedBy uuid.UUID) error
+	RemoveManager(ctx context.Context, teamID, userID uuid.UUID) error
+}
+
+// ReportFilter seleciona os relatórios listados
+type ReportFilter struct {
+	TeamID      *uuid.UUID
+	DeveloperID *uuid.UUID
+	Status      string
+	// MonthFrom e MonthTo limitam o mês de referência (YYYY-MM, inclusive)
+	MonthFrom string
+	MonthTo   string
+	// MinScore e MaxScore limitam a nota ponderada (inclusive)
+	MinScore *float64
+	MaxScore *float64
+}
+
+// ReportRepository acessa os relatórios de performance, o histórico de revisões e os dados
+// usados no cálculo das notas. Rascunhos ficam fora do escopo sem Drafts.
+type ReportRepository interface {
+	List(ctx context.Context, filter ReportFilter, page PageRequest) (*Page[models.PerformanceReport], error)
+	ListByDeveloper(ctx context.Context, developerID uuid.UUID) ([]models.PerformanceReport, error)
+	// ListByMonth ordena os relatórios do mês pela nota, da maior para a menor
+	ListByMonth(ctx context.Context, month string) ([]models.PerformanceReport, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.PerformanceReport, error)
+	// GetForMonth devolve o relatório do desenvolvedor no mês, inclusive rascunhos. Apenas a
+	// empresa é verificada, como em LockForChange.
+	GetForMonth(ctx context.Context, developerID uuid.UUID, month string) (*models.PerformanceReport, error)
+	// ListPublished lista os relatórios enviados do desenvolvedor, do mês mais recente ao mais
+	// antigo. Apenas a empresa é verificada: serve ao próprio desenvolvedor.
+	ListPublished(ctx context.Context, developerID uuid.UUID) ([]models.PerformanceReport, error)
+	// Trend devolve os últimos meses enviados do desenvolvedor em ordem cronológica
+	Trend(ctx context.Context, developerID uuid.UUID, months int) ([]models.PerformanceTrendPoint, error)
+	// Months e Stats consideram apenas relatórios enviados
+	Months(ctx context.Context) ([]string, error)
+	Stats(ctx context.Context) (*models.PerformanceStats, error)
+	ExistsForMonth(ctx context.Context, developerID uuid.UUID, month string, exceptID *uuid.UUID) (bool, error)
+	Create(ctx context.Context, report *models.PerformanceReport) error
+	// LockForChange carrega o relatório com bloqueio de linha e a empresa do desenvolvedor.
+	// Apenas a empresa é verificada: o acesso ao time fica a cargo de quem altera.
+	LockForChange(ctx context.Context, id uuid.UUID) (*models.PerformanceReport, *uuid.UUID, error)
+	// Update grava mês, notas, textos, template e calibração do relatório
+	Update(ctx context.Context, report *models.PerformanceReport) error
+	// UpdateStatus grava o novo status com data e autor, além das notas calculadas no envio
+	UpdateStatus(ctx context.Context, report *models.PerformanceReport, status string, actor uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// LockByDeveloper carrega com bloqueio de linha todos os relatórios do desenvolvedor
+	LockByDeveloper(ctx context.Context, developerID uuid.UUID) ([]models.PerformanceReport, error)
+	DeleteByDeveloper(ctx context.Context, developerID uuid.UUID) error
+	RecordRevision(ctx context.Context, reportID uuid.UUID, companyID *uuid.UUID, action string, changedBy uuid.UUID, oldReport, newReport *models.PerformanceReport) error
+	Revisions(ctx context.Context, reportID uuid.UUID) ([]models.PerformanceReportRevision, error)
+}
+
+// UserUpdate traz os campos alterados de um usuário (nil mantém o valor)
+type UserUpdate struct {
+	Name      *string
+	Email     *string
+	Role      *string
+	CompanyID *uuid.UUID
+	IsActive  *bool
+	// RevokeSessions, quando preenchido, encerra as sessões do usuário com esse motivo
+	RevokeSessions string
+}
+
+// UserFilter seleciona os usuários listados
+type UserFilter struct {
+	Role     string
+	IsActive *bool
+}
+
+// UserRepository acessa as contas de usuário (exceto as de contas de serviço na listagem)
+type UserRepository interface {
+	List(ctx context.Context, filter UserFilter, page PageRequest) (*Page[models.User], error)
+	Get(ctx context.Context, id uuid.UUID) (*models.User, error)
+	// EmailExists verifica o email em todas as empresas, já que ele identifica o login
+	EmailExists(ctx context.Context, email string, exceptID *uuid.UUID) (bool, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, id uuid.UUID, update UserUpdate) (*models.User, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// CompanyUpdate traz os campos alterados de uma empresa (nil mantém o valor)
+type CompanyUpdate struct {
+	Name        *string
+	Description *string
+	IsActive    *bool
+}
+
+// CompanyFilter seleciona as empresas listadas
+type CompanyFilter struct {
+	IsActive *bool
+}
+
+// CompanyRepository acessa as empresas; sem AllCompanies apenas a própria empresa está no escopo
+type CompanyRepository interface {
+	List(ctx context.Context, filter CompanyFilter, page PageRequest) (*Page[models.Company], error)
+	Get(ctx context.Context, id uuid.UUID) (*models.Company, error)
+	// NameExists verifica o nome em todas as empresas
+	NameExists(ctx context.Context, name string, exceptID *uuid.UUID) (bool, error)
+	IsActive(ctx context.Context, id uuid.UUID) (bool, error)
+	Create(ctx context.Context, company *models.Company) error
+	Update(ctx context.Context, id uuid.UUID, update CompanyUpdate) (*models.Company, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	CountUsers(ctx context.Context, id uuid.UUID) (int, error)
+	// RecomputeScores recalcula a pontuação mais recente dos desenvolvedores da empresa
+	RecomputeScores(ctx context.Context, id uuid.UUID) (int, error)
+}
+
+// SessionUser é o dono de uma sessão ou de um token de API, com os dados atuais da conta
+type SessionUser struct {
+	UserID              uuid.UUID
+	Email               string
+	Role                string
+	CompanyID           *uuid.UUID
+	IsActive            bool
+	NeedsPasswordChange bool
+	// MFAEnrollmentRequired indica que a política da empresa exige 2FA e o usuário não o cadastrou
+	MFAEnrollmentRequired bool
+	// Permissions são as do papel personalizado atribuído ou as do papel padrão
+	Permissions []string
+	// Preenchidos apenas quando a credencial é um token de API
+	APITokenID     *uuid.UUID
+	APITokenScopes []string
+}
+
+// SessionRepository abre as sessões de login e valida as credenciais das requisições
+// autenticadas. Não usa o Scope: é ele que fornece os dados a partir dos quais o escopo é
+// montado.
+type SessionRepository interface {
+	// Start abre uma sessão, válida por ttl, com o primeiro refresh token
+	Start(ctx context.Context, user *models.User, userAgent, ip string, ttl time.Duration) (*StartedSession, error)
+	// Refresh troca o refresh token por um novo e prolonga a sessão por ttl. ErrNotFound para tokens
+	// desconhecidos, ErrTokenReused para tokens já trocados (a sessão é encerrada),
+	// ErrSessionExpired para sessões encerradas e ErrInactiveUser para contas desativadas.
+	Refresh(ctx context.Context, tokenHash, userAgent, ip string, ttl time.Duration) (*StartedSession, error)
+	Revoke(ctx context.Context, sessionID uuid.UUID, reason string) error
+	// RevokeOwn encerra a sessão ativa do usuário; ErrNotFound para sessões de outro usuário
+	RevokeOwn(ctx context.Context, sessionID, userID uuid.UUID) error
+	// RevokeAll encerra as sessões ativas do usuário e devolve quantas foram encerradas
+	RevokeAll(ctx context.Context, userID uuid.UUID, reason string) (int64, error)
+	// ListActive lista as sessões ativas do usuário, da mais recente
+	ListActive(ctx context.Context, userID uuid.UUID) ([]models.AuthSession, error)
+
+	// ActiveSession devolve o dono da sessão; ErrNotFound para sessões revogadas ou expiradas
+	ActiveSession(ctx context.Context, sessionID, userID uuid.UUID) (*SessionUser, error)
+	// APITokenUser devolve o dono do token de API e registra o uso; ErrNotFound para tokens
+	// inexistentes, revogados ou expirados
+	APITokenUser(ctx context.Context, token, ip string) (*SessionUser, error)
+}
+
+// AuditRepository grava e consulta os eventos da trilha de auditoria
+type AuditRepository interface {
+	// Record grava o evento no fim da cadeia da empresa
+	Record(ctx context.Context, event *models.AuditEvent) error
+	// List ordena os eventos do mais recente ao mais antigo
+	List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, error)
+	// Chain devolve todos os eventos da cadeia na ordem da sequência
+	Chain(ctx context.Context, chainKey string) ([]models.AuditEvent, error)
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// ErrCalibrationCommitted indica uma sessão de calibração que já foi confirmada
+var ErrCalibrationCommitted = errors.New("sessão de calibração já foi confirmada")
+
+// ErrNoAdjustments indica a confirmação de uma sessão sem ajustes propostos
+var ErrNoAdjustments = errors.New("nenhum ajuste proposto nesta sessão")
+
+// StaleAdjustmentError indica um ajuste proposto sobre uma nota que mudou depois da proposta
+type StaleAdjustmentError struct {
+	ReportID uuid.UUID
+}
+
+func (e *StaleAdjustmentError) Error() string {
+	return "o relatório " + e.ReportID.String() + " foi alterado depois da proposta de ajuste"
+}
+
+// ReviewCycleRepository acessa os ciclos de avaliação, os times incluídos e os avaliadores
+type ReviewCycleRepository interface {
+	// List ordena os ciclos pelo mês, do mais recente; status vazio lista todos
+	List(ctx context.Context, status string) ([]models.ReviewCycle, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.ReviewCycle, error)
+	// ForMonth devolve o ciclo da empresa no mês, ou nil quando não existe
+	ForMonth(ctx context.Context, companyID *uuid.UUID, month string) (*models.ReviewCycle, error)
+	// Create grava o ciclo com os times de TeamIDs
+	Create(ctx context.Context, cycle *models.ReviewCycle) error
+	// UpdateStatus grava o novo status e a data de abertura ou de encerramento
+	UpdateStatus(ctx context.Context, cycle *models.ReviewCycle, status string) error
+	Assignments(ctx context.Context, cycleID uuid.UUID) ([]models.ReviewCycleAssignment, error)
+	// IsEligibleEvaluator indica se o usuário está ativo na empresa com um papel que avalia
+	IsEligibleEvaluator(ctx context.Context, companyID, userID uuid.UUID) (bool, error)
+	// Assign define (ou substitui) o avaliador de cada desenvolvedor
+	Assign(ctx context.Context, cycleID uuid.UUID, assignments []models.ReviewCycleAssignment) error
+	// Completion lista os desenvolvedores ativos dos times do ciclo (e do escopo) com o
+	// relatório do mês, ordenados por time e nome
+	Completion(ctx context.Context, cycle *models.ReviewCycle) ([]models.CycleDeveloperStatus, error)
+}
+
+// CalibrationRepository acessa as sessões de calibração e os ajustes propostos
+type CalibrationRepository interface {
+	ListSessions(ctx context.Context, cycleID uuid.UUID) ([]models.CalibrationSession, error)
+	GetSession(ctx context.Context, id uuid.UUID) (*models.CalibrationSession, error)
+	// HasOpenSession indica se o ciclo já tem uma sessão aberta
+	HasOpenSession(ctx context.Context, cycleID uuid.UUID) (bool, error)
+	CreateSession(ctx context.Context, session *models.CalibrationSession) error
+	// Adjustments lista os ajustes da sessão nos times do escopo pelo nome do desenvolvedor
+	Adjustments(ctx context.Context, sessionID uuid.UUID) ([]models.CalibrationAdjustment, error)
+	// Scores lista os relatórios enviados do ciclo nos times do escopo com a proposta da
+	// sessão, por avaliador
+	Scores(ctx context.Context, cycle *models.ReviewCycle, sessionID uuid.UUID) ([]models.CalibrationScore, error)
+	// CycleReport devolve o relatório do mês do ciclo de um desenvolvedor da empresa do ciclo
+	// e dos times do escopo
+	CycleReport(ctx context.Context, cycle *models.ReviewCycle, reportID uuid.UUID) (*models.PerformanceReport, error)
+	// Propose cria ou substitui a proposta da sessão para o relatório e preenche o ID
+	Propose(ctx context.Context, adjustment *models.CalibrationAdjustment) error
+	RemoveAdjustment(ctx context.Context, sessionID, reportID uuid.UUID) error
+	// Commit aplica os ajustes aos relatórios, com uma revisão "calibrate" de cada um, e
+	// confirma a sessão. Com ErrCalibrationCommitted, ErrNoAdjustments ou *StaleAdjustmentError
+	// nada é alterado; ajuste de relatório fora dos times do escopo resulta em ErrNotFound.
+	// Devolve a sessão confirmada e os ajustes aplicados.
+	Commit(ctx context.Context, sessionID, actor uuid.UUID) (*models.CalibrationSession, []models.CalibrationAdjustment, error)
+}
+
+// FeedbackRoundFilter seleciona as rodadas de feedback listadas
+type FeedbackRoundFilter struct {
+	DeveloperID *uuid.UUID
+	Month       string
+}
+
+// FeedbackRoundRepository acessa as rodadas de feedback 360, as indicações de pares e as respostas
+type FeedbackRoundRepository interface {
+	// List ordena as rodadas pelo mês e pela criação, das mais recentes. List e Get só
+	// alcançam rodadas de desenvolvedores dos times do escopo.
+	List(ctx context.Context, filter FeedbackRoundFilter) ([]models.FeedbackRound, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.FeedbackRound, error)
+	ExistsForMonth(ctx context.Context, developerID uuid.UUID, month string) (bool, error)
+	Create(ctx context.Context, round *models.FeedbackRound) error
+	// Close encerra a rodada; novas respostas deixam de ser aceitas
+	Close(ctx context.Context, round *models.FeedbackRound) error
+	// Summary agrega as respostas enviadas da rodada
+	Summary(ctx context.Context, round *models.FeedbackRound) (*models.PeerFeedbackSummary, error)
+	// ForReport devolve o agregado da rodada do desenvolvedor e mês do relatório, ou nil
+	// quando não houve rodada
+	ForReport(ctx context.Context, report *models.PerformanceReport) (*models.PeerFeedbackSummary, error)
+	// DeveloperAccount devolve a conta vinculada ao desenvolvedor da empresa (nil sem conta);
+	// ErrNotFound quando ele não é da empresa
+	DeveloperAccount(ctx context.Context, companyID, developerID uuid.UUID) (*uuid.UUID, error)
+	// Nominations lista os pares indicados pelo nome
+	Nominations(ctx context.Context, roundID uuid.UUID) ([]models.FeedbackNomination, error)
+	// Nominate indica os usuários como pares; indicações repetidas são ignoradas
+	Nominate(ctx context.Context, roundID uuid.UUID, reviewerIDs []uuid.UUID) error
+	Nomination(ctx context.Context, roundID, reviewerID uuid.UUID) (*models.FeedbackNomination, error)
+	RemoveNomination(ctx context.Context, roundID, reviewerID uuid.UUID) error
+	// Requests lista as rodadas em que o usuário foi indicado, as pendentes primeiro
+	Requests(ctx context.Context, reviewerID uuid.UUID) ([]models.FeedbackRequest, error)
+	// GetForReviewer devolve a rodada apenas se o usuário foi indicado nela
+	GetForReviewer(ctx context.Context, id, reviewerID uuid.UUID) (*models.FeedbackRound, error)
+	// SubmitResponse grava (ou substitui) a resposta do par
+	SubmitResponse(ctx context.Context, roundID, reviewerID uuid.UUID, response models.PeerResponse) error
+}
+
+// SelfAssessmentRepository acessa as autoavaliações; apenas a empresa do desenvolvedor é
+// verificada, o acesso a ele fica a cargo de quem consulta
+type SelfAssessmentRepository interface {
+	// ListByDeveloper ordena as autoavaliações pelo mês, da mais recente
+	ListByDeveloper(ctx context.Context, developerID uuid.UUID) ([]models.SelfAssessment, error)
+	Get(ctx context.Context, developerID uuid.UUID, month string) (*models.SelfAssessment, error)
+	// Save cria ou substitui a autoavaliação do desenvolvedor no mês
+	Save(ctx context.Context, assessment *models.SelfAssessment) error
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Scope é o alcance de dados do usuário da requisição. Os repositórios aplicam o escopo em
+// todas as consultas, então os handlers não montam filtros de empresa ou de time.
+type Scope struct {
+	UserID    uuid.UUID
+	CompanyID *uuid.UUID
+	// AllCompanies libera os dados de todas as empresas (companies:all)
+	AllCompanies bool
+	// AllTeams libera os times e desenvolvedores de toda a empresa (teams:all); sem ela, apenas
+	// os times atribuídos ao usuário e os dos desenvolvedores vinculados à conta
+	AllTeams bool
+	// AllTeamReports libera os relatórios de toda a empresa (reports:read com teams:all)
+	AllTeamReports bool
+	// Drafts inclui os relatórios em rascunho (reports:read:drafts)
+	Drafts bool
+}
+
+type scopeKey struct{}
+
+// WithScope devolve um contexto com o escopo informado
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFrom devolve o escopo do contexto. Sem escopo o valor zero não enxerga nenhuma empresa.
+func ScopeFrom(ctx context.Context) Scope {
+	scope, _ := ctx.Value(scopeKey{}).(Scope)
+	return scope
+}
+
+// OwnsCompany indica se o escopo alcança a empresa informada
+func (s Scope) OwnsCompany(companyID *uuid.UUID) bool {
+	if s.AllCompanies {
+		return true
+	}
+	return s.CompanyID != nil && companyID != nil && *s.CompanyID == *companyID
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// TemplateVersion é o conteúdo de uma nova versão de template
+type TemplateVersion struct {
+	ScoreMin   float64
+	ScoreMax   float64
+	Categories []models.EvaluationCategoryRequest
+}
+
+// TemplateUpdate traz os campos alterados de um template (nil mantém o valor). Version, quando
+// preenchida, publica uma nova versão; as anteriores continuam intactas.
+type TemplateUpdate struct {
+	Name        *string
+	Description *string
+	Version     *TemplateVersion
+}
+
+// TemplateRepository acessa os templates de avaliação e suas versões. Templates globais são
+// visíveis a todos, mas só podem ser alterados com AllCompanies; os de empresa ficam restritos
+// à própria empresa.
+type TemplateRepository interface {
+	// Version devolve uma versão específica, usada também por relatórios históricos
+	Version(ctx context.Context, versionID uuid.UUID) (*models.EvaluationTemplate, error)
+	// Active devolve o template ativo da empresa ou, sem um próprio, o global
+	Active(ctx context.Context, companyID *uuid.UUID) (*models.EvaluationTemplate, error)
+	// Latest devolve a versão mais recente do template
+	Latest(ctx context.Context, id uuid.UUID) (*models.EvaluationTemplate, error)
+	// ListByCompany devolve a versão mais recente de cada template da empresa, o ativo primeiro
+	ListByCompany(ctx context.Context, companyID uuid.UUID) ([]models.EvaluationTemplate, error)
+	// Create grava o template com a primeira versão e, com activate, o torna o ativo da empresa
+	Create(ctx context.Context, template *models.EvaluationTemplate, version TemplateVersion, activate bool) (*models.EvaluationTemplate, error)
+	Update(ctx context.Context, id uuid.UUID, update TemplateUpdate) (*models.EvaluationTemplate, error)
+	// Activate torna o template o único ativo da empresa
+	Activate(ctx context.Context, companyID, id uuid.UUID) error
+	// InUse indica se alguma versão do template já foi usada em relatórios, ciclos de
+	// avaliação, autoavaliações ou rodadas de feedback
+	InUse(ctx context.Context, id uuid.UUID) (bool, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,80 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+func TestCommitCalibrationRejectsStaleOriginalScore(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	report := f.addReport(f.acmeDev, "2025-06", models.ReportStatusSubmitted)
+
+	var cycle models.ReviewCycle
+	decode(t, f.expect(fiber.StatusCreated, "POST", "/api/v1/review-cycles", token, fiber.Map{
+		"name": "Ciclo de junho", "month": "2025-06", "opensAt": "2025-06-01", "closesAt": "2025-06-30",
+	}), &cycle)
+	f.expect(fiber.StatusOK, "POST", "/api/v1/review-cycles/"+cycle.ID.String()+"/open", token, nil)
+
+	var session models.CalibrationSession
+	decode(t, f.expect(fiber.StatusCreated, "POST", "/api/v1/review-cycles/"+cycle.ID.String()+"/calibration-sessions", token, fiber.Map{
+		"name": "Calibração de junho",
+	}), &session)
+
+	sessionPath := "/api/v1/calibration-sessions/" + session.ID.String()
+	adjustmentPath := sessionPath + "/adjustments/" + report.ID.String()
+	adjustment := fiber.Map{"proposedScore": 8, "justification": "Entregas acima do esperado no trimestre"}
+	f.expect(fiber.StatusOK, "PUT", adjustmentPath, token, adjustment)
+
+	// A nota original muda depois da proposta: confirmar aplicaria o ajuste sobre outra avaliação
+	f.expect(fiber.StatusOK, "PUT", "/api/v1/performance-reports/"+report.ID.String(), token, fiber.Map{
+		"questionScores": models.JSONB{"code_quality": 10.0, "delivery": 9.0, "communication": 6.0},
+	})
+	f.expect(fiber.StatusConflict, "POST", sessionPath+"/commit", token, nil)
+
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/performance-reports/"+report.ID.String(), token, nil), &report)
+	if report.CalibratedScore != nil {
+		t.Fatalf("calibratedScore = %v after a rejected commit, want nil", *report.CalibratedScore)
+	}
+
+	// Refeita sobre a nota atual, a proposta é aplicada
+	f.expect(fiber.StatusOK, "PUT", adjustmentPath, token, adjustment)
+	f.expect(fiber.StatusOK, "POST", sessionPath+"/commit", token, nil)
+
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/performance-reports/"+report.ID.String(), token, nil), &report)
+	if report.CalibratedScore == nil || *report.CalibratedScore != 8 {
+		t.Errorf("calibratedScore = %v, want 8", report.CalibratedScore)
+	}
+}
+
+func TestLatestScoreFollowsTheCalibratedScore(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	report := f.addReport(f.acmeDev, "2025-06", models.ReportStatusSubmitted)
+
+	var cycle models.ReviewCycle
+	decode(t, f.expect(fiber.StatusCreated, "POST", "/api/v1/review-cycles", token, fiber.Map{
+		"name": "Ciclo de junho", "month": "2025-06", "opensAt": "2025-06-01", "closesAt": "2025-06-30",
+	}), &cycle)
+	f.expect(fiber.StatusOK, "POST", "/api/v1/review-cycles/"+cycle.ID.String()+"/open", token, nil)
+
+	var session models.CalibrationSession
+	decode(t, f.expect(fiber.StatusCreated, "POST", "/api/v1/review-cycles/"+cycle.ID.String()+"/calibration-sessions", token, fiber.Map{
+		"name": "Calibração de junho",
+	}), &session)
+	sessionPath := "/api/v1/calibration-sessions/" + session.ID.String()
+	f.expect(fiber.StatusOK, "PUT", sessionPath+"/adjustments/"+report.ID.String(), token, fiber.Map{
+		"proposedScore": 8.5, "justification": "Entregas acima do esperado no trimestre",
+	})
+	f.expect(fiber.StatusOK, "POST", sessionPath+"/commit", token, nil)
+
+	// A pontuação mais recente acompanha a nota calibrada, como as estatísticas
+	var developer models.Developer
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/developers/"+f.acmeDev.ID.String(), token, nil), &developer)
+	if developer.LatestPerformanceScore != 8.5 {
+		t.Errorf("latest score = %v, want the calibrated 8.5", developer.LatestPerformanceScore)
+	}
+}
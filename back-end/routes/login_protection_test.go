@@ -0,0 +1,113 @@
+package routes_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/utils"
+)
+
+const lockedMessage = "Conta bloqueada temporariamente por excesso de tentativas. Tente novamente mais tarde"
+
+// loginAttempt tenta o login a partir de um IP próprio, para que o limite de requisições por IP
+// não interfira no contador de falhas, que é por email
+func (e *testEnv) loginAttempt(attempt int, email, password string) (int, apiResponse) {
+	e.t.Helper()
+	ip := fmt.Sprintf("198.51.100.%d", attempt+1)
+	return e.requestFrom(ip, "POST", "/api/v1/auth/login", "", fiber.Map{"email": email, "password": password})
+}
+
+// failLogins erra a senha n vezes, esperando o atraso progressivo entre as tentativas
+func (e *testEnv) failLogins(n int, email string) {
+	e.t.Helper()
+	for i := 0; i < n; i++ {
+		e.store.Advance(models.LoginMaxDelay)
+		if status, resp := e.loginAttempt(i, email, "senha-Errada-123"); status != fiber.StatusUnauthorized {
+			e.t.Fatalf("failed login %d: status %d, want %d (message: %q)", i+1, status, fiber.StatusUnauthorized, resp.Message)
+		}
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	tests := []struct {
+		name      string
+		knownUser bool
+	}{
+		{"email cadastrado", true},
+		{"email desconhecido", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTenantFixture(t)
+			email := "ninguem@example.com"
+			if tt.knownUser {
+				email = f.addPasswordUser("manager", &f.acme.ID).Email
+			}
+
+			// Depois de LoginDelayAfter falhas a próxima tentativa precisa esperar
+			f.failLogins(models.LoginDelayAfter, email)
+			status, resp := f.loginAttempt(models.LoginDelayAfter, email, testPassword)
+			if status != fiber.StatusTooManyRequests || resp.Message != "Muitas tentativas de login. Aguarde antes de tentar novamente" {
+				t.Fatalf("login during the delay = %d %q, want %d", status, resp.Message, fiber.StatusTooManyRequests)
+			}
+
+			f.failLogins(models.LoginLockoutAfter-models.LoginDelayAfter, email)
+
+			// Bloqueada, a conta recusa até a senha correta, vinda de qualquer IP
+			f.store.Advance(models.LoginMaxDelay)
+			status, resp = f.loginAttempt(models.LoginLockoutAfter, email, testPassword)
+			if status != fiber.StatusLocked || resp.Message != lockedMessage {
+				t.Fatalf("login while locked = %d %q, want %d %q", status, resp.Message, fiber.StatusLocked, lockedMessage)
+			}
+
+			f.store.Advance(models.LoginLockoutDuration)
+			want := fiber.StatusUnauthorized
+			if tt.knownUser {
+				want = fiber.StatusOK
+			}
+			if status, resp := f.loginAttempt(models.LoginLockoutAfter+1, email, testPassword); status != want {
+				t.Errorf("login after the lockout = %d %q, want %d", status, resp.Message, want)
+			}
+		})
+	}
+}
+
+func TestSuccessfulLoginClearsFailures(t *testing.T) {
+	f := newTenantFixture(t)
+	user := f.addPasswordUser("manager", &f.acme.ID)
+
+	f.failLogins(models.LoginLockoutAfter-1, user.Email)
+	f.store.Advance(models.LoginMaxDelay)
+	f.passwordLogin(user.Email, testPassword)
+
+	// Sem o login bem-sucedido esta falha bloquearia a conta
+	f.failLogins(1, user.Email)
+	if status, resp := f.loginAttempt(1, user.Email, testPassword); status != fiber.StatusOK {
+		t.Errorf("login after a cleared failure count = %d %q, want %d", status, resp.Message, fiber.StatusOK)
+	}
+}
+
+func TestPasswordResetClearsLockout(t *testing.T) {
+	f := newTenantFixture(t)
+	user := f.addPasswordUser("manager", &f.acme.ID)
+	f.failLogins(models.LoginLockoutAfter, user.Email)
+
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("generating token: %v", err)
+	}
+	if err := f.store.Repositories().PasswordResets.Create(context.Background(), user.ID, hash, "198.51.100.200", 1); err != nil {
+		t.Fatalf("creating password reset: %v", err)
+	}
+
+	newPassword := "outra-Senha-456"
+	f.expect(fiber.StatusOK, "POST", "/api/v1/auth/reset-password", "", fiber.Map{"token": token, "newPassword": newPassword})
+	if status, resp := f.loginAttempt(0, user.Email, newPassword); status != fiber.StatusOK {
+		t.Errorf("login after the password reset = %d %q, want %d", status, resp.Message, fiber.StatusOK)
+	}
+}
@@ -0,0 +1,214 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// developerNames segue os cursores da listagem e devolve os nomes na ordem das páginas
+func (f *tenantFixture) developerNames(token, query string) ([]string, int) {
+	f.t.Helper()
+	var names []string
+	pages := 0
+	path := "/api/v1/developers?" + query
+	for {
+		resp := f.expect(fiber.StatusOK, "GET", path, token, nil)
+		var developers []models.Developer
+		decode(f.t, resp, &developers)
+		for _, developer := range developers {
+			names = append(names, developer.Name)
+		}
+		pages++
+		if resp.NextCursor == nil {
+			return names, pages
+		}
+		path = "/api/v1/developers?" + query + "&cursor=" + url.QueryEscape(*resp.NextCursor)
+	}
+}
+
+func TestDevelopersArePaginatedByCursor(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	for _, name := range []string{"Eva", "Caio", "Davi"} {
+		f.store.AddDeveloper(&models.Developer{Name: name, Role: "Backend", TeamID: &f.acmeTeam.ID, CompanyID: &f.acme.ID})
+	}
+
+	resp := f.expect(fiber.StatusOK, "GET", "/api/v1/developers?limit=2&sort=name", token, nil)
+	if resp.Total != 5 || resp.NextCursor == nil {
+		t.Fatalf("total = %d, nextCursor = %v; want 5 and a cursor", resp.Total, resp.NextCursor)
+	}
+
+	names, pages := f.developerNames(token, "limit=2&sort=name")
+	if want := []string{"Ana", "Bruno", "Caio", "Davi", "Eva"}; !reflect.DeepEqual(names, want) || pages != 3 {
+		t.Errorf("names = %v in %d pages, want %v in 3", names, pages, want)
+	}
+
+	names, _ = f.developerNames(token, "limit=3&sort=name&order=desc")
+	if want := []string{"Eva", "Davi", "Caio", "Bruno", "Ana"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+
+	// Sem limit a página padrão traz todos os cinco, mais recentes primeiro
+	names, pages = f.developerNames(token, "")
+	if want := []string{"Davi", "Caio", "Eva", "Bruno", "Ana"}; !reflect.DeepEqual(names, want) || pages != 1 {
+		t.Errorf("names = %v in %d pages, want %v in 1", names, pages, want)
+	}
+}
+
+func TestDeveloperFilters(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	archivedAt := time.Now()
+	f.store.AddDeveloper(&models.Developer{Name: "Eva", Role: "Frontend", TeamID: &f.acmeTeam.ID, CompanyID: &f.acme.ID, ArchivedAt: &archivedAt})
+	f.addReport(f.acmeDev, "2025-01", models.ReportStatusSubmitted)
+
+	cases := map[string][]string{
+		"teamId=" + f.acmeOther.ID.String():  {"Bruno"},
+		"role=Backend":                       {"Ana"},
+		"minScore=6&maxScore=8":              {"Ana"},
+		"maxScore=1":                         {"Bruno"},
+		"archived=true":                      {"Eva"},
+		"archived=all":                       {"Ana", "Bruno", "Eva"},
+		"includeArchived=true&role=Frontend": {"Eva"},
+	}
+	for query, want := range cases {
+		names, _ := f.developerNames(token, "sort=name&"+query)
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("%s: names = %v, want %v", query, names, want)
+		}
+	}
+}
+
+func TestInvalidPageParameters(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+
+	resp := f.expect(fiber.StatusOK, "GET", "/api/v1/developers?limit=1&sort=name", token, nil)
+	cursor := url.QueryEscape(*resp.NextCursor)
+
+	for _, query := range []string{
+		"limit=0",
+		"limit=-5",
+		"limit=201",
+		"limit=dez",
+		"sort=password",
+		"sort=name&order=up",
+		"cursor=not-a-cursor",
+		"sort=role&cursor=" + cursor,
+		"sort=name&order=desc&cursor=" + cursor,
+		"minScore=abc",
+		"minScore=8&maxScore=2",
+		"archived=maybe",
+		"teamId=123",
+	} {
+		f.expect(fiber.StatusBadRequest, "GET", "/api/v1/developers?"+query, token, nil)
+	}
+	resp = f.expect(fiber.StatusBadRequest, "GET", "/api/v1/developers?limit=500", token, nil)
+	if !strings.Contains(resp.Message, "limit") {
+		t.Errorf("message = %q, want it to name limit", resp.Message)
+	}
+	f.expect(fiber.StatusBadRequest, "GET", "/api/v1/performance-reports?fromMonth=2025-13", token, nil)
+	f.expect(fiber.StatusBadRequest, "GET", "/api/v1/performance-reports?status=archived", token, nil)
+	f.expect(fiber.StatusBadRequest, "GET", "/api/v1/auth/users?role=owner", token, nil)
+	f.expect(fiber.StatusBadRequest, "GET", "/api/v1/companies?isActive=sim", token, nil)
+	f.expect(fiber.StatusBadRequest, "GET", "/api/v1/teams?sort=color", token, nil)
+}
+
+func TestReportFiltersAndSorting(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	for i, score := range []float64{6, 9, 4, 8} {
+		report := models.PerformanceReport{
+			DeveloperID:          f.acmeDev.ID,
+			Month:                fmt.Sprintf("2025-0%d", i+1),
+			QuestionScores:       scores(),
+			WeightedAverageScore: score,
+			Status:               models.ReportStatusSubmitted,
+		}
+		f.store.AddReport(&report)
+	}
+	f.addReport(f.acmeOtherDev, "2025-02", models.ReportStatusDraft)
+	f.addReport(f.globexDev, "2025-02", models.ReportStatusSubmitted)
+
+	months := func(query string) ([]string, int) {
+		t.Helper()
+		resp := f.expect(fiber.StatusOK, "GET", "/api/v1/performance-reports?"+query, token, nil)
+		var reports []models.PerformanceReport
+		decode(t, resp, &reports)
+		months := []string{}
+		for _, report := range reports {
+			months = append(months, report.Month)
+		}
+		return months, resp.Total
+	}
+
+	cases := []struct {
+		query string
+		want  []string
+		total int
+	}{
+		{"", []string{"2025-04", "2025-03", "2025-02", "2025-02", "2025-01"}, 5},
+		{"fromMonth=2025-02&toMonth=2025-03&developerId=" + f.acmeDev.ID.String(), []string{"2025-03", "2025-02"}, 2},
+		{"sort=weightedAverageScore&order=desc&limit=2", []string{"2025-02", "2025-04"}, 5},
+		{"minScore=5&maxScore=8&teamId=" + f.acmeTeam.ID.String() + "&sort=month", []string{"2025-01", "2025-04"}, 2},
+		{"status=draft", []string{"2025-02"}, 1},
+	}
+	for _, tc := range cases {
+		got, total := months(tc.query)
+		if !reflect.DeepEqual(got, tc.want) || total != tc.total {
+			t.Errorf("%q: months = %v (total %d), want %v (total %d)", tc.query, got, total, tc.want, tc.total)
+		}
+	}
+}
+
+func TestUserTeamAndCompanyListsArePaginated(t *testing.T) {
+	f := newTenantFixture(t)
+	admin := f.adminToken()
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	inactive := models.User{Email: "inativo@example.com", Name: "Inativo", Role: "manager", CompanyID: &f.acme.ID}
+	f.store.AddUser(&inactive)
+	f.store.AddCompany(&models.Company{Name: "Initech", IsActive: false})
+
+	resp := f.expect(fiber.StatusOK, "GET", "/api/v1/auth/users?isActive=false", token, nil)
+	var users []models.User
+	decode(t, resp, &users)
+	if len(users) != 1 || users[0].ID != inactive.ID || resp.Total != 1 {
+		t.Errorf("inactive users = %+v", users)
+	}
+	resp = f.expect(fiber.StatusOK, "GET", "/api/v1/auth/users?role=company_admin", token, nil)
+	if resp.Total != 1 {
+		t.Errorf("company admins total = %d, want 1", resp.Total)
+	}
+
+	resp = f.expect(fiber.StatusOK, "GET", "/api/v1/teams?sort=name&limit=1", token, nil)
+	var teams []models.Team
+	decode(t, resp, &teams)
+	if len(teams) != 1 || teams[0].Name != "Mobile" || resp.Total != 2 || resp.NextCursor == nil {
+		t.Fatalf("first team page = %+v (total %d)", teams, resp.Total)
+	}
+	resp = f.expect(fiber.StatusOK, "GET", "/api/v1/teams?sort=name&limit=1&cursor="+url.QueryEscape(*resp.NextCursor), token, nil)
+	decode(t, resp, &teams)
+	if len(teams) != 1 || teams[0].Name != "Plataforma" || resp.NextCursor != nil {
+		t.Errorf("second team page = %+v", teams)
+	}
+
+	resp = f.expect(fiber.StatusOK, "GET", "/api/v1/companies?isActive=true", admin, nil)
+	var companies []models.Company
+	decode(t, resp, &companies)
+	if len(companies) != 2 || companies[0].Name != "Acme" || companies[1].Name != "Globex" {
+		t.Errorf("active companies = %+v", companies)
+	}
+	resp = f.expect(fiber.StatusOK, "GET", "/api/v1/companies?sort=createdAt&order=desc&limit=1", admin, nil)
+	decode(t, resp, &companies)
+	if len(companies) != 1 || companies[0].Name != "Initech" || resp.Total != 3 {
+		t.Errorf("newest company = %+v (total %d)", companies, resp.Total)
+	}
+}
@@ -0,0 +1,161 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/mail"
+	"tivix-performance-tracker-backend/models"
+)
+
+const invalidResetMessage = "Link de redefinição inválido ou expirado"
+
+// mailbox guarda as mensagens enviadas durante o teste
+type mailbox chan mail.Message
+
+func (m mailbox) Send(msg mail.Message) error {
+	m <- msg
+	return nil
+}
+
+// captureMail troca o transporte de email por uma caixa de entrada até o fim do teste
+func captureMail(t *testing.T) mailbox {
+	t.Helper()
+	box := make(mailbox, 10)
+	mail.SetTransport(box)
+	t.Cleanup(func() { mail.SetTransport(mail.LogTransport{}) })
+	return box
+}
+
+var resetLinkToken = regexp.MustCompile(`\?token=(\S+)`)
+
+// receive espera o próximo email para o destinatário e devolve o token do link
+func (m mailbox) receive(t *testing.T, to string) string {
+	t.Helper()
+	select {
+	case msg := <-m:
+		if msg.To != to {
+			t.Fatalf("email sent to %q, want %q", msg.To, to)
+		}
+		match := resetLinkToken.FindStringSubmatch(msg.Body)
+		if match == nil {
+			t.Fatalf("email body has no link token: %q", msg.Body)
+		}
+		token, err := url.QueryUnescape(match[1])
+		if err != nil {
+			t.Fatalf("unescaping token: %v", err)
+		}
+		return token
+	case <-time.After(time.Second):
+		t.Fatalf("no email sent to %q", to)
+		return ""
+	}
+}
+
+// forgotPassword pede o link de redefinição a partir de um IP próprio, fora do limite por IP
+func (e *testEnv) forgotPassword(attempt int, email string) (int, apiResponse) {
+	e.t.Helper()
+	ip := fmt.Sprintf("203.0.113.%d", attempt+1)
+	return e.requestFrom(ip, "POST", "/api/v1/auth/forgot-password", "", fiber.Map{"email": email})
+}
+
+func TestForgotPasswordRespondsTheSameForEveryEmail(t *testing.T) {
+	f := newTenantFixture(t)
+	box := captureMail(t)
+	user := f.addPasswordUser("manager", &f.acme.ID)
+
+	wantStatus, want := f.forgotPassword(0, user.Email)
+	box.receive(t, user.Email)
+
+	tests := []struct {
+		name  string
+		email string
+	}{
+		{"email desconhecido", "ninguem@example.com"},
+		{"segundo link", user.Email},
+		{"terceiro link", user.Email},
+		{"limite de links por hora", user.Email},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := f.forgotPassword(i+1, tt.email)
+			if status != wantStatus || !reflect.DeepEqual(resp, want) {
+				t.Errorf("response = %d %+v, want %d %+v", status, resp, wantStatus, want)
+			}
+		})
+	}
+
+	// Só os links dentro do limite foram enviados
+	box.receive(t, user.Email)
+	box.receive(t, user.Email)
+	select {
+	case msg := <-box:
+		t.Errorf("unexpected email to %q beyond the hourly limit", msg.To)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestResetTokenWorksOnce(t *testing.T) {
+	f := newTenantFixture(t)
+	box := captureMail(t)
+	user := f.addPasswordUser("manager", &f.acme.ID)
+
+	f.forgotPassword(0, user.Email)
+	token := box.receive(t, user.Email)
+
+	newPassword := "outra-Senha-456"
+	f.expect(fiber.StatusOK, "POST", "/api/v1/auth/reset-password", "", fiber.Map{"token": token, "newPassword": newPassword})
+	resp := f.expect(fiber.StatusBadRequest, "POST", "/api/v1/auth/reset-password", "", fiber.Map{"token": token, "newPassword": "mais-uma-Senha-789"})
+	if resp.Message != invalidResetMessage {
+		t.Errorf("message = %q, want %q", resp.Message, invalidResetMessage)
+	}
+
+	f.passwordLogin(user.Email, newPassword)
+}
+
+func TestResetTokenExpires(t *testing.T) {
+	f := newTenantFixture(t)
+	box := captureMail(t)
+	user := f.addPasswordUser("manager", &f.acme.ID)
+
+	f.forgotPassword(0, user.Email)
+	token := box.receive(t, user.Email)
+
+	f.store.Advance(models.PasswordResetTTL)
+	resp := f.expect(fiber.StatusBadRequest, "POST", "/api/v1/auth/reset-password", "", fiber.Map{"token": token, "newPassword": "outra-Senha-456"})
+	if resp.Message != invalidResetMessage {
+		t.Errorf("message = %q, want %q", resp.Message, invalidResetMessage)
+	}
+
+	f.passwordLogin(user.Email, testPassword)
+}
+
+func TestPasswordResetRevokesEverySession(t *testing.T) {
+	f := newTenantFixture(t)
+	box := captureMail(t)
+	user := f.addPasswordUser("manager", &f.acme.ID)
+	sessions := []models.LoginResponse{
+		f.passwordLogin(user.Email, testPassword),
+		f.passwordLogin(user.Email, testPassword),
+	}
+
+	f.forgotPassword(0, user.Email)
+	token := box.receive(t, user.Email)
+	f.expect(fiber.StatusOK, "POST", "/api/v1/auth/reset-password", "", fiber.Map{"token": token, "newPassword": "outra-Senha-456"})
+
+	for _, session := range sessions {
+		f.expect(fiber.StatusUnauthorized, "GET", "/api/v1/auth/profile", session.Token, nil)
+		f.expect(fiber.StatusUnauthorized, "POST", "/api/v1/auth/refresh", "", fiber.Map{"refreshToken": session.RefreshToken})
+	}
+	for _, session := range f.store.UserSessions(user.ID) {
+		if session.RevokedReason == nil || *session.RevokedReason != models.SessionRevokedPasswordChange {
+			t.Errorf("session %s revoked for %v, want %s", session.ID, session.RevokedReason, models.SessionRevokedPasswordChange)
+		}
+	}
+}
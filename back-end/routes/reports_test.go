@@ -0,0 +1,298 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+func TestCreateReportScoresOnTheServer(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+
+	resp := f.expect(fiber.StatusCreated, "POST", "/api/v1/performance-reports", token, fiber.Map{
+		"developerId":          f.acmeDev.ID,
+		"month":                "2025-02",
+		"questionScores":       scores(),
+		"weightedAverageScore": 10,
+	})
+	var report models.PerformanceReport
+	decode(t, resp, &report)
+	if report.Status != models.ReportStatusSubmitted || report.WeightedAverageScore != 7 {
+		t.Errorf("report = %+v, want submitted with score 7", report)
+	}
+
+	// O relatório enviado passa a ser a pontuação mais recente do desenvolvedor
+	var developer models.Developer
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/developers/"+f.acmeDev.ID.String(), token, nil), &developer)
+	if developer.LatestPerformanceScore != 7 {
+		t.Errorf("latest score = %v, want 7", developer.LatestPerformanceScore)
+	}
+
+	// Um relatório por desenvolvedor e mês
+	f.expect(fiber.StatusBadRequest, "POST", "/api/v1/performance-reports", token, fiber.Map{
+		"developerId": f.acmeDev.ID, "month": "2025-02", "questionScores": scores(),
+	})
+}
+
+func TestCreateReportValidatesScores(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	path := "/api/v1/performance-reports"
+
+	f.expect(fiber.StatusBadRequest, "POST", path, token, fiber.Map{
+		"developerId": f.acmeDev.ID, "month": "2025-02", "questionScores": models.JSONB{"code_quality": 9.0},
+	})
+	f.expect(fiber.StatusBadRequest, "POST", path, token, fiber.Map{
+		"developerId": f.acmeDev.ID, "month": "2025-02",
+		"questionScores": models.JSONB{"code_quality": 9.0, "delivery": 7.0, "communication": 5.0, "unknown": 1.0},
+	})
+	f.expect(fiber.StatusBadRequest, "POST", path, token, fiber.Map{
+		"developerId": f.acmeDev.ID, "month": "2025-02",
+		"questionScores": models.JSONB{"code_quality": 11.0, "delivery": 7.0, "communication": 5.0},
+	})
+
+	// Rascunhos aceitam notas parciais
+	f.expect(fiber.StatusCreated, "POST", path, token, fiber.Map{
+		"developerId": f.acmeDev.ID, "month": "2025-02", "status": "draft",
+		"questionScores": models.JSONB{"code_quality": 9.0},
+	})
+
+	// Desenvolvedores de outra empresa não recebem relatórios
+	f.expect(fiber.StatusBadRequest, "POST", path, token, fiber.Map{
+		"developerId": f.globexDev.ID, "month": "2025-02", "questionScores": scores(),
+	})
+}
+
+func TestReportMonthMustBeYearMonth(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	path := "/api/v1/performance-reports"
+	draft := f.addReport(f.acmeDev, "2025-02", models.ReportStatusDraft)
+
+	for _, month := range []string{"2025-2", "abc", "2025-13", "25-02", "2025-02-01", "2025/02"} {
+		resp := f.expect(fiber.StatusBadRequest, "POST", path, token, fiber.Map{
+			"developerId": f.acmeOtherDev.ID, "month": month, "questionScores": scores(),
+		})
+		if resp.Message != "Mês inválido (use YYYY-MM)" {
+			t.Errorf("create %q: message = %q", month, resp.Message)
+		}
+		f.expect(fiber.StatusBadRequest, "PUT", path+"/"+draft.ID.String(), token, fiber.Map{"month": month})
+	}
+
+	f.expect(fiber.StatusOK, "PUT", path+"/"+draft.ID.String(), token, fiber.Map{"month": "2025-03"})
+}
+
+func TestClosedReviewCycleRejectsReports(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	path := "/api/v1/performance-reports"
+	movedDraft := f.addReport(f.acmeDev, "2025-05", models.ReportStatusDraft)
+	cycleDraft := f.addReport(f.acmeOtherDev, "2025-06", models.ReportStatusDraft)
+
+	var cycle models.ReviewCycle
+	decode(t, f.expect(fiber.StatusCreated, "POST", "/api/v1/review-cycles", token, fiber.Map{
+		"name": "Ciclo de junho", "month": "2025-06", "opensAt": "2025-06-01", "closesAt": "2025-06-30",
+	}), &cycle)
+	f.expect(fiber.StatusOK, "POST", "/api/v1/review-cycles/"+cycle.ID.String()+"/open", token, nil)
+	f.expect(fiber.StatusOK, "POST", "/api/v1/review-cycles/"+cycle.ID.String()+"/close", token, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"criação no mês encerrado", "POST", path, fiber.Map{"developerId": f.acmeDev.ID, "month": "2025-06", "questionScores": scores()}},
+		{"rascunho movido para o mês encerrado", "PUT", path + "/" + movedDraft.ID.String(), fiber.Map{"month": "2025-06"}},
+		{"envio de rascunho do mês encerrado", "POST", path + "/" + cycleDraft.ID.String() + "/submit", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := f.expect(fiber.StatusConflict, tt.method, tt.path, token, tt.body)
+			if resp.Message != "O ciclo de avaliação deste mês já foi encerrado" {
+				t.Errorf("message = %q, want the closed cycle message", resp.Message)
+			}
+		})
+	}
+
+	// Os rascunhos continuam como estavam, e os meses sem ciclo seguem livres
+	var report models.PerformanceReport
+	decode(t, f.expect(fiber.StatusOK, "GET", path+"/"+movedDraft.ID.String(), token, nil), &report)
+	if report.Month != "2025-05" {
+		t.Errorf("moved draft month = %s, want 2025-05", report.Month)
+	}
+	decode(t, f.expect(fiber.StatusOK, "GET", path+"/"+cycleDraft.ID.String(), token, nil), &report)
+	if report.Status != models.ReportStatusDraft {
+		t.Errorf("cycle draft status = %s, want draft", report.Status)
+	}
+	f.expect(fiber.StatusOK, "PUT", path+"/"+movedDraft.ID.String(), token, fiber.Map{"month": "2025-07"})
+}
+
+func TestDraftsRequirePermission(t *testing.T) {
+	f := newTenantFixture(t)
+	viewer, viewerToken := f.addUser("user", &f.acme.ID)
+	f.store.AssignTeam(viewer.ID, f.acmeTeam.ID)
+	_, managerToken := f.addUser("company_admin", &f.acme.ID)
+	draft := f.addReport(f.acmeDev, "2025-03", models.ReportStatusDraft)
+	submitted := f.addReport(f.acmeDev, "2025-02", models.ReportStatusSubmitted)
+
+	var reports []models.PerformanceReport
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/performance-reports", viewerToken, nil), &reports)
+	if len(reports) != 1 || reports[0].ID != submitted.ID {
+		t.Errorf("viewer listed %+v, want only the submitted report", reports)
+	}
+	f.expect(fiber.StatusNotFound, "GET", "/api/v1/performance-reports/"+draft.ID.String(), viewerToken, nil)
+
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/performance-reports", managerToken, nil), &reports)
+	if len(reports) != 2 {
+		t.Errorf("manager listed %d reports, want 2", len(reports))
+	}
+
+	// Rascunhos também ficam fora dos meses e das estatísticas
+	var months []string
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/performance-reports/months", managerToken, nil), &months)
+	if len(months) != 1 || months[0] != "2025-02" {
+		t.Errorf("months = %v, want [2025-02]", months)
+	}
+	var stats models.PerformanceStats
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/performance-reports/stats", managerToken, nil), &stats)
+	if stats.TotalReports != 1 || stats.AverageScore != 7 {
+		t.Errorf("stats = %+v, want 1 report averaging 7", stats)
+	}
+}
+
+func TestReportWorkflow(t *testing.T) {
+	f := newTenantFixture(t)
+	_, managerToken := f.addUser("company_admin", &f.acme.ID)
+	developerUser, developerToken := f.addUser("developer", &f.acme.ID)
+	f.expect(fiber.StatusOK, "PUT", "/api/v1/developers/"+f.acmeDev.ID.String()+"/user", managerToken, fiber.Map{
+		"userId": developerUser.ID,
+	})
+
+	resp := f.expect(fiber.StatusCreated, "POST", "/api/v1/performance-reports", managerToken, fiber.Map{
+		"developerId": f.acmeDev.ID, "month": "2025-04", "status": "draft", "questionScores": scores(),
+	})
+	var report models.PerformanceReport
+	decode(t, resp, &report)
+	path := "/api/v1/performance-reports/" + report.ID.String()
+
+	// A ciência só vale para relatórios enviados
+	f.expect(fiber.StatusNotFound, "POST", "/api/v1/me/reports/"+report.ID.String()+"/acknowledge", developerToken, nil)
+	f.expect(fiber.StatusConflict, "POST", path+"/lock", managerToken, nil)
+
+	decode(t, f.expect(fiber.StatusOK, "POST", path+"/submit", managerToken, nil), &report)
+	if report.Status != models.ReportStatusSubmitted || report.WeightedAverageScore != 7 || report.SubmittedAt == nil {
+		t.Fatalf("submitted report = %+v", report)
+	}
+	f.expect(fiber.StatusConflict, "POST", path+"/submit", managerToken, nil)
+
+	// Enviado ainda pode ser editado
+	f.expect(fiber.StatusOK, "PUT", path, managerToken, fiber.Map{"highlights": "Boa entrega"})
+
+	decode(t, f.expect(fiber.StatusOK, "POST", "/api/v1/me/reports/"+report.ID.String()+"/acknowledge", developerToken, nil), &report)
+	if report.Status != models.ReportStatusAcknowledged || report.AcknowledgedBy == nil || *report.AcknowledgedBy != developerUser.ID {
+		t.Fatalf("acknowledged report = %+v", report)
+	}
+
+	// Depois da ciência o conteúdo fica congelado
+	f.expect(fiber.StatusConflict, "PUT", path, managerToken, fiber.Map{"highlights": "Alterado"})
+	f.expect(fiber.StatusConflict, "DELETE", path, managerToken, nil)
+
+	decode(t, f.expect(fiber.StatusOK, "POST", path+"/lock", managerToken, nil), &report)
+	if report.Status != models.ReportStatusLocked {
+		t.Fatalf("locked report = %+v", report)
+	}
+
+	var revisions []models.PerformanceReportRevision
+	decode(t, f.expect(fiber.StatusOK, "GET", path+"/revisions", managerToken, nil), &revisions)
+	actions := []string{}
+	for _, revision := range revisions {
+		actions = append(actions, revision.Action)
+	}
+	want := []string{"create", "submit", "update", "acknowledge", "lock"}
+	if len(actions) != len(want) {
+		t.Fatalf("revision actions = %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Fatalf("revision actions = %v, want %v", actions, want)
+		}
+	}
+}
+
+func TestDeveloperCannotAcknowledgeOthersReports(t *testing.T) {
+	f := newTenantFixture(t)
+	developerUser, developerToken := f.addUser("developer", &f.acme.ID)
+	f.acmeDev.UserID = &developerUser.ID
+	linked := models.Developer{Name: "Diego", Role: "Frontend", TeamID: &f.acmeTeam.ID, CompanyID: &f.acme.ID, UserID: &developerUser.ID}
+	f.store.AddDeveloper(&linked)
+	other := f.addReport(f.acmeDev, "2025-05", models.ReportStatusSubmitted)
+	own := f.addReport(linked, "2025-05", models.ReportStatusSubmitted)
+
+	f.expect(fiber.StatusNotFound, "POST", "/api/v1/me/reports/"+other.ID.String()+"/acknowledge", developerToken, nil)
+	f.expect(fiber.StatusOK, "POST", "/api/v1/me/reports/"+own.ID.String()+"/acknowledge", developerToken, nil)
+
+	// Sem reports:read o desenvolvedor não usa as rotas gerais de relatórios
+	f.expect(fiber.StatusForbidden, "GET", "/api/v1/performance-reports", developerToken, nil)
+}
+
+func TestReportsOfOtherCompaniesAreHidden(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	foreign := f.addReport(f.globexDev, "2025-06", models.ReportStatusSubmitted)
+	f.addReport(f.acmeDev, "2025-06", models.ReportStatusSubmitted)
+
+	path := "/api/v1/performance-reports/" + foreign.ID.String()
+	f.expect(fiber.StatusNotFound, "GET", path, token, nil)
+	f.expect(fiber.StatusNotFound, "PUT", path, token, fiber.Map{"highlights": "Invadido"})
+	f.expect(fiber.StatusNotFound, "DELETE", path, token, nil)
+	f.expect(fiber.StatusNotFound, "POST", path+"/lock", token, nil)
+
+	var reports []models.PerformanceReport
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/performance-reports/month/2025-06", token, nil), &reports)
+	if len(reports) != 1 || reports[0].DeveloperID != f.acmeDev.ID {
+		t.Errorf("month reports = %+v, want only Acme's", reports)
+	}
+}
+
+func TestReportChangesAreAuditedWithBefore(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	report := f.addReport(f.acmeDev, "2025-05", models.ReportStatusDraft)
+	path := "/api/v1/performance-reports/" + report.ID.String()
+
+	f.expect(fiber.StatusOK, "PUT", path, token, fiber.Map{"highlights": "Entregou a migração"})
+	f.expect(fiber.StatusOK, "DELETE", path, token, nil)
+
+	events := f.store.AuditEvents()
+	if len(events) != 2 {
+		t.Fatalf("got %d audit events, want 2", len(events))
+	}
+	// Before guarda o relatório como estava antes de cada alteração
+	cases := []struct {
+		action     string
+		highlights string
+	}{
+		{models.AuditActionUpdate, report.Highlights},
+		{models.AuditActionDelete, "Entregou a migração"},
+	}
+	for i, tc := range cases {
+		event := events[i]
+		if event.Action != tc.action || event.EntityType != "performance-reports" || event.EntityID != report.ID.String() {
+			t.Errorf("event %d = %s %s %s, want %s performance-reports %s", i, event.Action, event.EntityType, event.EntityID, tc.action, report.ID)
+		}
+		if event.Before == nil || event.Before["id"] != report.ID.String() || event.Before["highlights"] != tc.highlights {
+			t.Errorf("%s before = %v, want highlights %q", tc.action, event.Before, tc.highlights)
+		}
+	}
+
+	if events[0].After == nil || events[0].After["highlights"] != "Entregou a migração" {
+		t.Errorf("update after = %v, want the new highlights", events[0].After)
+	}
+	if events[1].After != nil {
+		t.Errorf("delete after = %v, want nil", events[1].After)
+	}
+}
@@ -0,0 +1,50 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+func TestResetToDefaultRoleRequiresItsPermissions(t *testing.T) {
+	f := newTenantFixture(t)
+
+	narrow := models.CompanyRole{
+		CompanyID:   f.acme.ID,
+		Name:        "Gestor de papéis",
+		Permissions: []string{models.PermRolesManage, models.PermUsersRead, models.PermReportsRead},
+	}
+	f.store.AddCompanyRole(&narrow)
+	restricted := models.CompanyRole{
+		CompanyID:   f.acme.ID,
+		Name:        "Leitor",
+		Permissions: []string{models.PermReportsRead},
+	}
+	f.store.AddCompanyRole(&restricted)
+
+	actor, _ := f.addUser("manager", &f.acme.ID)
+	f.store.AssignRole(actor.ID, narrow.ID)
+	actorToken := f.login(actor)
+	target, _ := f.addUser("manager", &f.acme.ID)
+	f.store.AssignRole(target.ID, restricted.ID)
+
+	// Voltar ao papel padrão daria ao gerente roles:manage, users:write e audit:read
+	path := "/api/v1/auth/users/" + target.ID.String() + "/role"
+	resp := f.expect(fiber.StatusForbidden, "PUT", path, actorToken, fiber.Map{"roleId": nil})
+	if resp.Message == "" {
+		t.Error("message is empty, want the missing permission")
+	}
+
+	var roles []models.CompanyRole
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/auth/roles", actorToken, nil), &roles)
+	for _, role := range roles {
+		if role.ID == restricted.ID && role.UserCount != 1 {
+			t.Errorf("restricted role has %d users after the rejected reset, want 1", role.UserCount)
+		}
+	}
+
+	_, adminToken := f.addUser("company_admin", &f.acme.ID)
+	f.expect(fiber.StatusOK, "PUT", path, adminToken, fiber.Map{"roleId": nil})
+}
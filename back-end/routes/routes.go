@@ -1,84 +1,220 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"tivix-performance-tracker-backend/handlers"
 	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository"
 )
 
-func SetupRoutes(app *fiber.App) {
+func SetupRoutes(app *fiber.App, repos *repository.Repositories) {
 	// Grupo principal da API
 	api := app.Group("/api/v1")
 
+	// Acesso a dados usado pelos handlers
+	api.Use(middleware.InjectRepositories(repos))
+
+	// Trilha de auditoria de todas as alterações (POST/PUT/DELETE)
+	api.Use(middleware.AuditMiddleware())
+
 	// Rotas públicas de autenticação
 	auth := api.Group("/auth")
-	auth.Post("/login", handlers.Login)
+	auth.Post("/login", middleware.RateLimitMiddleware(10, time.Minute), handlers.Login)
+	auth.Post("/refresh", handlers.RefreshToken)
+	auth.Post("/mfa/verify", middleware.RateLimitMiddleware(10, time.Minute), handlers.VerifyMFAChallenge)
+	auth.Post("/forgot-password", middleware.RateLimitMiddleware(5, time.Minute), handlers.ForgotPassword)
+	auth.Post("/reset-password", middleware.RateLimitMiddleware(10, time.Minute), handlers.ResetPassword)
+	auth.Get("/invitations/accept", middleware.RateLimitMiddleware(20, time.Minute), handlers.GetInvitationByToken)
+	auth.Post("/invitations/accept", middleware.RateLimitMiddleware(10, time.Minute), handlers.AcceptInvitation)
+	auth.Get("/sso/discover", middleware.RateLimitMiddleware(20, time.Minute), handlers.DiscoverSSO)
+	auth.Get("/sso/callback", handlers.SSOCallback)
+	auth.Get("/sso/:companyId/start", middleware.RateLimitMiddleware(20, time.Minute), handlers.StartSSOLogin)
+	auth.Post("/sso/exchange", middleware.RateLimitMiddleware(10, time.Minute), handlers.ExchangeSSOCode)
 
 	// Rotas de inicialização do sistema
 	init := api.Group("/init")
 	init.Get("/check", handlers.CheckInitialization)
-	init.Post("/admin", handlers.CreateAdminUser)
+	init.Post("/admin", middleware.RateLimitMiddleware(5, time.Minute), handlers.CreateAdminUser)
 
 	// Rotas protegidas de autenticação - requerem token válido
 	authProtected := api.Group("/auth", middleware.AuthMiddleware())
 	authProtected.Get("/profile", handlers.GetProfile)
-	authProtected.Post("/refresh", handlers.RefreshToken)
+	authProtected.Post("/logout", handlers.Logout)
+	authProtected.Get("/sessions", handlers.ListMySessions)
+	authProtected.Delete("/sessions/:id", handlers.RevokeMySession)
 	authProtected.Post("/set-new-password", handlers.SetNewPassword)
 	authProtected.Post("/change-password", handlers.ChangePassword)
+	authProtected.Get("/mfa", handlers.GetMFAStatus)
+	authProtected.Post("/mfa/enroll", handlers.EnrollMFA)
+	authProtected.Post("/mfa/enable", handlers.EnableMFA)
+	authProtected.Post("/mfa/disable", handlers.DisableMFA)
+	authProtected.Post("/mfa/recovery-codes", handlers.RegenerateRecoveryCodes)
+	authProtected.Get("/api-tokens", middleware.CheckMFAEnrollmentMiddleware(), handlers.ListMyAPITokens)
+	authProtected.Post("/api-tokens", middleware.CheckMFAEnrollmentMiddleware(), middleware.RequirePermission(models.PermAPITokensCreate), handlers.CreateMyAPIToken)
+	authProtected.Delete("/api-tokens/:id", middleware.CheckMFAEnrollmentMiddleware(), handlers.RevokeMyAPIToken)
 
-	// Rotas admin e manager - para gerenciamento de usuários e empresas
-	adminAndManagerAuth := authProtected.Group("/", middleware.ManagerOrAdminMiddleware())
-	adminAndManagerAuth.Post("/create-user", handlers.CreateUser)
-	adminAndManagerAuth.Get("/users", handlers.ListUsers)
-	adminAndManagerAuth.Put("/users/:id", handlers.UpdateUser)
-	adminAndManagerAuth.Delete("/users/:id", handlers.DeleteUser)
+	// Rotas de gestão de usuários - cada rota exige a permissão correspondente. As rotas acima
+	// lidam com as credenciais do próprio usuário e ficam fora da transação com escopo de empresa
+	authProtected.Get("/permissions", handlers.ListPermissions)
+	adminAndManagerAuth := authProtected.Group("/", middleware.CheckMFAEnrollmentMiddleware(), middleware.TenantScope())
+	adminAndManagerAuth.Post("/create-user", middleware.RequirePermission(models.PermUsersWrite), handlers.CreateUser)
+	adminAndManagerAuth.Get("/invitations", middleware.RequirePermission(models.PermUsersWrite), handlers.ListInvitations)
+	adminAndManagerAuth.Post("/invitations", middleware.RequirePermission(models.PermUsersWrite), handlers.CreateInvitation)
+	adminAndManagerAuth.Post("/invitations/:id/resend", middleware.RequirePermission(models.PermUsersWrite), handlers.ResendInvitation)
+	adminAndManagerAuth.Delete("/invitations/:id", middleware.RequirePermission(models.PermUsersWrite), handlers.RevokeInvitation)
+	adminAndManagerAuth.Get("/service-accounts", middleware.RequirePermission(models.PermServiceAccountsManage), handlers.ListServiceAccounts)
+	adminAndManagerAuth.Post("/service-accounts", middleware.RequirePermission(models.PermServiceAccountsManage), handlers.CreateServiceAccount)
+	adminAndManagerAuth.Put("/service-accounts/:id", middleware.RequirePermission(models.PermServiceAccountsManage), handlers.UpdateServiceAccount)
+	adminAndManagerAuth.Delete("/service-accounts/:id", middleware.RequirePermission(models.PermServiceAccountsManage), handlers.DeleteServiceAccount)
+	adminAndManagerAuth.Get("/service-accounts/:id/tokens", middleware.RequirePermission(models.PermServiceAccountsManage), handlers.ListServiceAccountTokens)
+	adminAndManagerAuth.Post("/service-accounts/:id/tokens", middleware.RequirePermission(models.PermServiceAccountsManage), handlers.CreateServiceAccountToken)
+	adminAndManagerAuth.Delete("/service-accounts/:id/tokens/:tokenId", middleware.RequirePermission(models.PermServiceAccountsManage), handlers.RevokeServiceAccountToken)
+	adminAndManagerAuth.Get("/roles", middleware.RequirePermission(models.PermRolesManage), handlers.ListCompanyRoles)
+	adminAndManagerAuth.Post("/roles", middleware.RequirePermission(models.PermRolesManage), handlers.CreateCompanyRole)
+	adminAndManagerAuth.Put("/roles/:id", middleware.RequirePermission(models.PermRolesManage), handlers.UpdateCompanyRole)
+	adminAndManagerAuth.Delete("/roles/:id", middleware.RequirePermission(models.PermRolesManage), handlers.DeleteCompanyRole)
+	adminAndManagerAuth.Put("/users/:id/role", middleware.RequirePermission(models.PermRolesManage), handlers.AssignUserRole)
+	adminAndManagerAuth.Get("/users", middleware.RequirePermission(models.PermUsersRead), handlers.ListUsers)
+	adminAndManagerAuth.Put("/users/:id", middleware.RequirePermission(models.PermUsersWrite), handlers.UpdateUser)
+	adminAndManagerAuth.Delete("/users/:id", middleware.RequirePermission(models.PermUsersWrite), handlers.DeleteUser)
+	adminAndManagerAuth.Get("/users/:id/sessions", middleware.RequirePermission(models.PermUsersSecurity), handlers.ListUserSessions)
+	adminAndManagerAuth.Delete("/users/:id/sessions", middleware.RequirePermission(models.PermUsersSecurity), handlers.TerminateUserSessions)
+	adminAndManagerAuth.Post("/users/:id/unlock", middleware.RequirePermission(models.PermUsersSecurity), handlers.UnlockUser)
+	adminAndManagerAuth.Delete("/users/:id/mfa", middleware.RequirePermission(models.PermUsersSecurity), handlers.ResetUserMFA)
 	
 	// Rota para listar empresas - gerentes e admins podem acessar
-	companiesListAuth := api.Group("/companies", middleware.AuthMiddleware(), middleware.ManagerOrAdminMiddleware())
-	companiesListAuth.Get("/", handlers.GetAllCompanies)
-	
+	companiesListAuth := api.Group("/companies", middleware.AuthMiddleware(), middleware.CheckMFAEnrollmentMiddleware(), middleware.TenantScope())
+	companiesListAuth.Get("/", middleware.RequirePermission(models.PermCompaniesRead), handlers.GetAllCompanies)
+
+	// Templates de avaliação por empresa - admins e gerentes da própria empresa
+	// (registradas antes do grupo admin para não exigirem companies:manage)
+	companiesListAuth.Get("/:id/templates", middleware.RequirePermission(models.PermTemplatesWrite), handlers.ListCompanyTemplates)
+	companiesListAuth.Post("/:id/templates", middleware.RequirePermission(models.PermTemplatesWrite), handlers.CreateCompanyTemplate)
+	companiesListAuth.Get("/:id/templates/:templateId", middleware.RequirePermission(models.PermTemplatesWrite), handlers.GetCompanyTemplate)
+	companiesListAuth.Put("/:id/templates/:templateId", middleware.RequirePermission(models.PermTemplatesWrite), handlers.UpdateCompanyTemplate)
+	companiesListAuth.Delete("/:id/templates/:templateId", middleware.RequirePermission(models.PermTemplatesWrite), handlers.DeleteCompanyTemplate)
+	companiesListAuth.Post("/:id/templates/:templateId/activate", middleware.RequirePermission(models.PermTemplatesWrite), handlers.ActivateCompanyTemplate)
+
 	// Rotas admin apenas - para gerenciamento de empresas (diretamente no API, não no auth)
-	companiesAdminAuth := api.Group("/companies", middleware.AuthMiddleware(), middleware.AdminOnlyMiddleware())
+	companiesAdminAuth := api.Group("/companies", middleware.AuthMiddleware(), middleware.CheckMFAEnrollmentMiddleware(), middleware.RequirePermission(models.PermCompaniesManage), middleware.TenantScope())
 	companiesAdminAuth.Post("/", handlers.CreateCompany)
 	companiesAdminAuth.Get("/:id", handlers.GetCompanyByID)
 	companiesAdminAuth.Put("/:id", handlers.UpdateCompany)
 	companiesAdminAuth.Delete("/:id", handlers.DeleteCompany)
+	companiesAdminAuth.Post("/:id/recompute-scores", handlers.RecomputeCompanyScores)
+	companiesAdminAuth.Get("/:id/security-policy", handlers.GetCompanySecurityPolicy)
+	companiesAdminAuth.Put("/:id/security-policy", handlers.UpdateCompanySecurityPolicy)
+	companiesAdminAuth.Get("/:id/sso", handlers.GetCompanySSOConfig)
+	companiesAdminAuth.Put("/:id/sso", handlers.UpdateCompanySSOConfig)
+	companiesAdminAuth.Delete("/:id/sso", handlers.DeleteCompanySSOConfig)
 
-	// Middleware para todas as rotas protegidas - verifica se precisa trocar senha e empresa
-	protectedWithPasswordCheck := api.Group("/", middleware.AuthMiddleware(), middleware.CheckPasswordChangeMiddleware(), middleware.CompanyAccessMiddleware())
+	// Middleware para todas as rotas protegidas - verifica se precisa trocar senha e empresa e
+	// abre a transação com escopo de empresa (RLS) usada pelos repositórios
+	protectedWithPasswordCheck := api.Group("/", middleware.AuthMiddleware(), middleware.CheckPasswordChangeMiddleware(), middleware.CheckMFAEnrollmentMiddleware(), middleware.CompanyAccessMiddleware(), middleware.TenantScope())
 
 	// Rotas de times - protegidas
-	teams := protectedWithPasswordCheck.Group("/teams")
+	teams := protectedWithPasswordCheck.Group("/teams", middleware.RequirePermission(models.PermTeamsRead))
 	teams.Get("/", handlers.GetAllTeams)
 	teams.Get("/:id", handlers.GetTeamByID)
-	teams.Post("/", middleware.ManagerOrAdminMiddleware(), handlers.CreateTeam)
-	teams.Put("/:id", middleware.ManagerOrAdminMiddleware(), handlers.UpdateTeam)
-	teams.Delete("/:id", middleware.AdminOnlyMiddleware(), handlers.DeleteTeam)
+	teams.Post("/", middleware.RequirePermission(models.PermTeamsWrite), handlers.CreateTeam)
+	teams.Put("/:id", middleware.RequirePermission(models.PermTeamsWrite), handlers.UpdateTeam)
+	teams.Delete("/:id", middleware.RequirePermission(models.PermTeamsDelete), handlers.DeleteTeam)
+	teams.Get("/:id/managers", handlers.GetTeamManagers)
+	teams.Post("/:id/managers", middleware.RequirePermission(models.PermTeamsWrite), handlers.AssignTeamManager)
+	teams.Delete("/:id/managers/:userId", middleware.RequirePermission(models.PermTeamsWrite), handlers.RemoveTeamManager)
 
 	// Rotas de desenvolvedores - protegidas
-	developers := protectedWithPasswordCheck.Group("/developers")
+	developers := protectedWithPasswordCheck.Group("/developers", middleware.RequirePermission(models.PermDevelopersRead))
 	developers.Get("/", handlers.GetAllDevelopers)
 	developers.Get("/archived", handlers.GetArchivedDevelopers)
 	developers.Get("/:id", handlers.GetDeveloperByID)
-	developers.Post("/", middleware.ManagerOrAdminMiddleware(), handlers.CreateDeveloper)
-	developers.Put("/:id", middleware.ManagerOrAdminMiddleware(), handlers.UpdateDeveloper)
-	developers.Put("/:id/archive", middleware.ManagerOrAdminMiddleware(), handlers.ArchiveDeveloper)
-	developers.Delete("/:id", middleware.ManagerOrAdminMiddleware(), handlers.DeleteDeveloper)
+	developers.Post("/", middleware.RequirePermission(models.PermDevelopersWrite), handlers.CreateDeveloper)
+	developers.Put("/:id", middleware.RequirePermission(models.PermDevelopersWrite), handlers.UpdateDeveloper)
+	developers.Put("/:id/archive", middleware.RequirePermission(models.PermDevelopersArchive), handlers.ArchiveDeveloper)
+	developers.Put("/:id/user", middleware.RequirePermission(models.PermDevelopersWrite), handlers.LinkDeveloperUser)
+	developers.Get("/:id/self-assessments", middleware.RequirePermission(models.PermSelfAssessmentsRead), handlers.GetDeveloperSelfAssessments)
+	developers.Get("/:id/self-assessments/:month", middleware.RequirePermission(models.PermSelfAssessmentsRead), handlers.GetDeveloperSelfAssessment)
+	developers.Get("/:id/self-assessments/:month/comparison", middleware.RequirePermission(models.PermSelfAssessmentsRead), handlers.GetDeveloperSelfAssessmentComparison)
+	developers.Delete("/:id", middleware.RequirePermission(models.PermDevelopersDelete), handlers.DeleteDeveloper)
 
 	// Rotas de desenvolvedores por time - protegidas
 	teams.Get("/:teamId/developers", handlers.GetDevelopersByTeam)
 
 	// Rotas de relatórios de performance - protegidas
-	reports := protectedWithPasswordCheck.Group("/performance-reports")
+	reports := protectedWithPasswordCheck.Group("/performance-reports", middleware.RequirePermission(models.PermReportsRead, models.PermReportsReadOwnTeam))
 	reports.Get("/", handlers.GetAllPerformanceReports)
 	reports.Get("/months", handlers.GetAvailableMonths)
 	reports.Get("/stats", handlers.GetPerformanceStats)
 	reports.Get("/:id", handlers.GetPerformanceReportByID)
-	reports.Post("/", middleware.ManagerOrAdminMiddleware(), handlers.CreatePerformanceReport)
+	reports.Post("/", middleware.RequirePermission(models.PermReportsWrite), handlers.CreatePerformanceReport)
+	reports.Put("/:id", middleware.RequirePermission(models.PermReportsWrite), handlers.UpdatePerformanceReport)
+	reports.Delete("/:id", middleware.RequirePermission(models.PermReportsWrite), handlers.DeletePerformanceReport)
+	reports.Get("/:id/revisions", middleware.RequirePermission(models.PermReportsRevisions), handlers.GetPerformanceReportRevisions)
+	reports.Get("/:id/revisions/diff", middleware.RequirePermission(models.PermReportsRevisions), handlers.GetPerformanceReportRevisionDiff)
+
+	// Fluxo de status dos relatórios - permissões são verificadas por transição
+	reports.Post("/:id/submit", handlers.SubmitPerformanceReport)
+	reports.Post("/:id/acknowledge", handlers.AcknowledgePerformanceReport)
+	reports.Post("/:id/lock", handlers.LockPerformanceReport)
 
 	// Rotas de relatórios por desenvolvedor - protegidas
-	developers.Get("/:developerId/reports", handlers.GetPerformanceReportsByDeveloper)
+	developers.Get("/:developerId/reports", middleware.RequirePermission(models.PermReportsRead, models.PermReportsReadOwnTeam), handlers.GetPerformanceReportsByDeveloper)
 
 	// Rotas de relatórios por mês - protegidas
 	reports.Get("/month/:month", handlers.GetPerformanceReportsByMonth)
+
+	// Rotas de templates de avaliação - protegidas
+	templates := protectedWithPasswordCheck.Group("/evaluation-templates", middleware.RequirePermission(models.PermTemplatesRead))
+	templates.Get("/active", handlers.GetActiveEvaluationTemplate)
+	templates.Get("/versions/:versionId", handlers.GetEvaluationTemplateVersion)
+	templates.Post("/:id/versions", middleware.RequirePermission(models.PermCompaniesManage), handlers.CreateEvaluationTemplateVersion)
+
+	// Rotas de feedback 360 - gerentes abrem rodadas e indicam pares
+	feedbackRounds := protectedWithPasswordCheck.Group("/feedback-rounds", middleware.RequirePermission(models.PermReviewsManage))
+	feedbackRounds.Get("/", handlers.ListFeedbackRounds)
+	feedbackRounds.Post("/", handlers.CreateFeedbackRound)
+	feedbackRounds.Get("/:id", handlers.GetFeedbackRound)
+	feedbackRounds.Post("/:id/nominations", handlers.NominatePeers)
+	feedbackRounds.Delete("/:id/nominations/:userId", handlers.RemovePeerNomination)
+	feedbackRounds.Post("/:id/close", handlers.CloseFeedbackRound)
+
+	// Rotas de ciclos de avaliação - prazos e acompanhamento de conclusão por empresa
+	reviewCycles := protectedWithPasswordCheck.Group("/review-cycles", middleware.RequirePermission(models.PermReviewsManage))
+	reviewCycles.Get("/", handlers.ListReviewCycles)
+	reviewCycles.Post("/", handlers.CreateReviewCycle)
+	reviewCycles.Get("/:id", handlers.GetReviewCycle)
+	reviewCycles.Post("/:id/open", handlers.OpenReviewCycle)
+	reviewCycles.Post("/:id/close", handlers.CloseReviewCycle)
+	reviewCycles.Put("/:id/assignments", handlers.AssignReviewCycleEvaluators)
+	reviewCycles.Get("/:id/completion", handlers.GetReviewCycleCompletion)
+	reviewCycles.Get("/:id/calibration-sessions", handlers.ListCalibrationSessions)
+	reviewCycles.Post("/:id/calibration-sessions", handlers.CreateCalibrationSession)
+
+	// Rotas de calibração - ajustes de nota entre avaliadores de um ciclo
+	calibration := protectedWithPasswordCheck.Group("/calibration-sessions", middleware.RequirePermission(models.PermCalibrationManage))
+	calibration.Get("/:id", handlers.GetCalibrationSession)
+	calibration.Get("/:id/distribution", handlers.GetCalibrationDistribution)
+	calibration.Put("/:id/adjustments/:reportId", handlers.ProposeCalibrationAdjustment)
+	calibration.Delete("/:id/adjustments/:reportId", handlers.RemoveCalibrationAdjustment)
+	calibration.Post("/:id/commit", handlers.CommitCalibrationSession)
+
+	// Trilha de auditoria - admins e gerentes da empresa
+	auditEvents := protectedWithPasswordCheck.Group("/audit", middleware.RequirePermission(models.PermAuditRead))
+	auditEvents.Get("/", handlers.ListAuditEvents)
+	auditEvents.Get("/verify", handlers.VerifyAuditChain)
+
+	// Rotas do próprio desenvolvedor - retornam apenas os relatórios vinculados ao usuário logado
+	me := protectedWithPasswordCheck.Group("/me")
+	me.Get("/reports", handlers.GetMyReports)
+	me.Get("/trend", handlers.GetMyTrend)
+	me.Post("/reports/:id/acknowledge", handlers.AcknowledgePerformanceReport)
+	me.Get("/self-assessments", handlers.GetMySelfAssessments)
+	me.Get("/self-assessments/:month", handlers.GetMySelfAssessment)
+	me.Put("/self-assessments/:month", handlers.SubmitMySelfAssessment)
+	me.Get("/self-assessments/:month/comparison", handlers.GetMySelfAssessmentComparison)
+	me.Get("/feedback-requests", handlers.GetMyFeedbackRequests)
+	me.Put("/feedback-requests/:roundId", handlers.SubmitMyPeerFeedback)
 }
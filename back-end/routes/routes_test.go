@@ -0,0 +1,319 @@
+package routes_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/middleware"
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/repository/memory"
+	"tivix-performance-tracker-backend/routes"
+)
+
+// testEnv sobe as rotas da API sobre os repositórios em memória
+type testEnv struct {
+	t     *testing.T
+	app   *fiber.App
+	store *memory.Store
+}
+
+// apiResponse cobre os dois formatos de resposta da API ({error, message} e {status, message})
+// e os campos das listagens paginadas
+type apiResponse struct {
+	Success    bool            `json:"success"`
+	Error      bool            `json:"error"`
+	Status     string          `json:"status"`
+	Message    string          `json:"message"`
+	Data       json.RawMessage `json:"data"`
+	Total      int             `json:"total"`
+	NextCursor *string         `json:"nextCursor"`
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	store := memory.NewStore()
+	// O IP vem de X-Forwarded-For para que os testes simulem clientes diferentes
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	routes.SetupRoutes(app, store.Repositories())
+	return &testEnv{t: t, app: app, store: store}
+}
+
+// addUser grava um usuário ativo e devolve o access token de uma sessão aberta para ele
+func (e *testEnv) addUser(role string, companyID *uuid.UUID) (models.User, string) {
+	e.t.Helper()
+	user := models.User{
+		Email:     role + "-" + uuid.NewString()[:8] + "@example.com",
+		Name:      role,
+		Role:      role,
+		CompanyID: companyID,
+		IsActive:  true,
+	}
+	e.store.AddUser(&user)
+	return user, e.login(user)
+}
+
+// login abre uma sessão para o usuário e devolve o access token
+func (e *testEnv) login(user models.User) string {
+	e.t.Helper()
+	token, err := middleware.GenerateJWT(user, e.store.StartSession(user.ID))
+	if err != nil {
+		e.t.Fatalf("generating token: %v", err)
+	}
+	return token
+}
+
+// request envia a requisição com o token (quando informado) e o corpo em JSON
+func (e *testEnv) request(method, path, token string, body interface{}) (int, apiResponse) {
+	e.t.Helper()
+	return e.requestFrom("", method, path, token, body)
+}
+
+// requestFrom envia a requisição como se viesse do IP informado, que tem o próprio limite
+// de requisições por minuto
+func (e *testEnv) requestFrom(ip, method, path, token string, body interface{}) (int, apiResponse) {
+	e.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			e.t.Fatalf("encoding body: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if ip != "" {
+		req.Header.Set(fiber.HeaderXForwardedFor, ip)
+	}
+
+	resp, err := e.app.Test(req, -1)
+	if err != nil {
+		e.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded apiResponse
+	raw, _ := io.ReadAll(resp.Body)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			e.t.Fatalf("%s %s: decoding %q: %v", method, path, raw, err)
+		}
+	}
+	return resp.StatusCode, decoded
+}
+
+// expect envia a requisição e falha o teste se o status for diferente do esperado
+func (e *testEnv) expect(status int, method, path, token string, body interface{}) apiResponse {
+	e.t.Helper()
+	got, resp := e.request(method, path, token, body)
+	if got != status {
+		e.t.Fatalf("%s %s: status %d, want %d (message: %q)", method, path, got, status, resp.Message)
+	}
+	return resp
+}
+
+// decode lê o campo data da resposta
+func decode(t *testing.T, resp apiResponse, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(resp.Data, v); err != nil {
+		t.Fatalf("decoding data %s: %v", resp.Data, err)
+	}
+}
+
+// tenantFixture tem duas empresas com times, desenvolvedores e o template global de avaliação
+type tenantFixture struct {
+	*testEnv
+
+	acme, globex          models.Company
+	acmeTeam, acmeOther   models.Team
+	globexTeam            models.Team
+	acmeDev, acmeOtherDev models.Developer
+	globexDev             models.Developer
+}
+
+func newTenantFixture(t *testing.T) *tenantFixture {
+	t.Helper()
+	f := &tenantFixture{testEnv: newTestEnv(t)}
+
+	f.acme = models.Company{Name: "Acme", IsActive: true}
+	f.globex = models.Company{Name: "Globex", IsActive: true}
+	f.store.AddCompany(&f.acme)
+	f.store.AddCompany(&f.globex)
+
+	f.acmeTeam = models.Team{Name: "Plataforma", CompanyID: &f.acme.ID}
+	f.acmeOther = models.Team{Name: "Mobile", CompanyID: &f.acme.ID}
+	f.globexTeam = models.Team{Name: "Dados", CompanyID: &f.globex.ID}
+	f.store.AddTeam(&f.acmeTeam)
+	f.store.AddTeam(&f.acmeOther)
+	f.store.AddTeam(&f.globexTeam)
+
+	f.acmeDev = models.Developer{Name: "Ana", Role: "Backend", TeamID: &f.acmeTeam.ID, CompanyID: &f.acme.ID}
+	f.acmeOtherDev = models.Developer{Name: "Bruno", Role: "Mobile", TeamID: &f.acmeOther.ID, CompanyID: &f.acme.ID}
+	f.globexDev = models.Developer{Name: "Carla", Role: "Dados", TeamID: &f.globexTeam.ID, CompanyID: &f.globex.ID}
+	f.store.AddDeveloper(&f.acmeDev)
+	f.store.AddDeveloper(&f.acmeOtherDev)
+	f.store.AddDeveloper(&f.globexDev)
+
+	f.store.AddTemplate(&models.EvaluationTemplate{
+		Name:     "Padrão",
+		IsActive: true,
+		Version:  1,
+		ScoreMin: models.DefaultScoreMin,
+		ScoreMax: models.DefaultScoreMax,
+		Categories: []models.EvaluationCategory{
+			{Key: "technical", Label: "Técnico", Weight: 2, Questions: []models.EvaluationQuestion{
+				{Key: "code_quality", Label: "Qualidade de código", Weight: 1},
+				{Key: "delivery", Label: "Entregas", Weight: 1},
+			}},
+			{Key: "collaboration", Label: "Colaboração", Weight: 1, Questions: []models.EvaluationQuestion{
+				{Key: "communication", Label: "Comunicação", Weight: 1},
+			}},
+		},
+	})
+	return f
+}
+
+// adminToken cria um admin global e devolve o token dele
+func (f *tenantFixture) adminToken() string {
+	f.t.Helper()
+	_, token := f.addUser("admin", nil)
+	return token
+}
+
+// scores são notas completas para o template da fixture: técnico 8, colaboração 5, média 7
+func scores() models.JSONB {
+	return models.JSONB{"code_quality": 9.0, "delivery": 7.0, "communication": 5.0}
+}
+
+// addReport grava um relatório com as notas de scores() no status informado
+func (f *tenantFixture) addReport(developer models.Developer, month, status string) models.PerformanceReport {
+	f.t.Helper()
+	report := models.PerformanceReport{
+		DeveloperID:          developer.ID,
+		Month:                month,
+		QuestionScores:       scores(),
+		CategoryScores:       models.JSONB{"technical": 8.0, "collaboration": 5.0},
+		WeightedAverageScore: 7,
+		Status:               status,
+	}
+	f.store.AddReport(&report)
+	return report
+}
+
+func TestAuthRequiresValidToken(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.expect(fiber.StatusUnauthorized, "GET", "/api/v1/developers", "", nil)
+	env.expect(fiber.StatusUnauthorized, "GET", "/api/v1/developers", "not-a-jwt", nil)
+
+	// Tokens de API desconhecidos também são recusados
+	env.expect(fiber.StatusUnauthorized, "GET", "/api/v1/developers", models.APITokenBrand+"unknown", nil)
+}
+
+func TestRevokedSessionIsRejected(t *testing.T) {
+	f := newTenantFixture(t)
+	user, token := f.addUser("company_admin", &f.acme.ID)
+
+	f.expect(fiber.StatusOK, "GET", "/api/v1/developers", token, nil)
+
+	f.store.RevokeSession(sessionOf(t, token))
+	resp := f.expect(fiber.StatusUnauthorized, "GET", "/api/v1/developers", token, nil)
+	if resp.Message != "Sessão encerrada ou expirada" {
+		t.Errorf("message = %q", resp.Message)
+	}
+
+	// Um novo login continua funcionando
+	f.expect(fiber.StatusOK, "GET", "/api/v1/developers", f.login(user), nil)
+}
+
+func TestInactiveUserIsRejected(t *testing.T) {
+	f := newTenantFixture(t)
+	user := models.User{Email: "inactive@example.com", Role: "company_admin", CompanyID: &f.acme.ID, IsActive: false}
+	f.store.AddUser(&user)
+
+	f.expect(fiber.StatusForbidden, "GET", "/api/v1/developers", f.login(user), nil)
+}
+
+func TestDeactivationEndsSessions(t *testing.T) {
+	f := newTenantFixture(t)
+	adminToken := f.adminToken()
+	user, userToken := f.addUser("manager", &f.acme.ID)
+	f.store.AssignTeam(user.ID, f.acmeTeam.ID)
+
+	f.expect(fiber.StatusOK, "GET", "/api/v1/developers", userToken, nil)
+	f.expect(fiber.StatusOK, "PUT", "/api/v1/auth/users/"+user.ID.String(), adminToken, fiber.Map{"isActive": false})
+	f.expect(fiber.StatusUnauthorized, "GET", "/api/v1/developers", userToken, nil)
+}
+
+func TestPermissionsAreEnforced(t *testing.T) {
+	f := newTenantFixture(t)
+	user, token := f.addUser("user", &f.acme.ID)
+	f.store.AssignTeam(user.ID, f.acmeTeam.ID)
+
+	f.expect(fiber.StatusOK, "GET", "/api/v1/developers", token, nil)
+	f.expect(fiber.StatusForbidden, "POST", "/api/v1/developers", token, fiber.Map{
+		"name": "Novo", "role": "Backend", "teamId": f.acmeTeam.ID,
+	})
+	f.expect(fiber.StatusForbidden, "DELETE", "/api/v1/developers/"+f.acmeDev.ID.String(), token, nil)
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	f := newTenantFixture(t)
+	user, _ := f.addUser("company_admin", &f.acme.ID)
+	token := models.APITokenBrand + "test_secret"
+	f.store.AddAPIToken(user.ID, token, []string{"developers:read"})
+
+	f.expect(fiber.StatusOK, "GET", "/api/v1/developers", token, nil)
+	f.expect(fiber.StatusForbidden, "GET", "/api/v1/teams", token, nil)
+	f.expect(fiber.StatusForbidden, "POST", "/api/v1/developers", token, fiber.Map{"name": "Novo", "role": "Backend"})
+}
+
+func TestMutationsAreAudited(t *testing.T) {
+	f := newTenantFixture(t)
+	admin, token := f.addUser("company_admin", &f.acme.ID)
+
+	resp := f.expect(fiber.StatusCreated, "POST", "/api/v1/teams", token, fiber.Map{"name": "Infra"})
+	var team models.Team
+	decode(t, resp, &team)
+
+	events := f.store.AuditEvents()
+	if len(events) != 1 {
+		t.Fatalf("got %d audit events, want 1", len(events))
+	}
+	event := events[0]
+	if event.ActorID == nil || *event.ActorID != admin.ID {
+		t.Errorf("actor = %v, want %s", event.ActorID, admin.ID)
+	}
+	if event.CompanyID == nil || *event.CompanyID != f.acme.ID {
+		t.Errorf("company = %v, want %s", event.CompanyID, f.acme.ID)
+	}
+	if event.EntityType != "teams" || event.StatusCode != fiber.StatusCreated || event.Seq != 1 {
+		t.Errorf("event = %+v", event)
+	}
+
+	// Leituras não entram na trilha
+	f.expect(fiber.StatusOK, "GET", "/api/v1/teams/"+team.ID.String(), token, nil)
+	if got := len(f.store.AuditEvents()); got != 1 {
+		t.Errorf("got %d audit events after GET, want 1", got)
+	}
+}
+
+// sessionOf lê o id da sessão gravado no access token
+func sessionOf(t *testing.T, token string) uuid.UUID {
+	t.Helper()
+	claims, err := middleware.ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("validating token: %v", err)
+	}
+	return claims.SessionID
+}
@@ -0,0 +1,52 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+func TestSelfAssessmentComparisonHidesDraftReports(t *testing.T) {
+	f := newTenantFixture(t)
+	devUser, devToken := f.addUser("developer", &f.acme.ID)
+	developer := models.Developer{Name: "Daniel", Role: "Frontend", TeamID: &f.acmeTeam.ID, CompanyID: &f.acme.ID, UserID: &devUser.ID}
+	f.store.AddDeveloper(&developer)
+
+	f.addReport(developer, "2025-04", models.ReportStatusDraft)
+	f.expect(fiber.StatusOK, "PUT", "/api/v1/me/self-assessments/2025-04", devToken, fiber.Map{
+		"questionScores": models.JSONB{"code_quality": 8.0, "delivery": 8.0, "communication": 8.0},
+	})
+
+	reader := models.CompanyRole{
+		CompanyID:   f.acme.ID,
+		Name:        "Leitor de autoavaliações",
+		Permissions: []string{models.PermDevelopersRead, models.PermSelfAssessmentsRead, models.PermReportsRead},
+	}
+	f.store.AddCompanyRole(&reader)
+	readerUser, _ := f.addUser("manager", &f.acme.ID)
+	f.store.AssignRole(readerUser.ID, reader.ID)
+	f.store.AssignTeam(readerUser.ID, f.acmeTeam.ID)
+	_, adminToken := f.addUser("company_admin", &f.acme.ID)
+
+	developerPath := "/api/v1/developers/" + developer.ID.String() + "/self-assessments/2025-04/comparison"
+	tests := []struct {
+		name       string
+		token      string
+		path       string
+		wantStatus int
+	}{
+		{"sem permissão para rascunhos", f.login(readerUser), developerPath, fiber.StatusNotFound},
+		{"com permissão para rascunhos", adminToken, developerPath, fiber.StatusOK},
+		{"o próprio desenvolvedor", devToken, "/api/v1/me/self-assessments/2025-04/comparison", fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := f.expect(tt.wantStatus, "GET", tt.path, tt.token, nil)
+			if tt.wantStatus == fiber.StatusNotFound && resp.Message != "Relatório do gerente não encontrado para este mês" {
+				t.Errorf("message = %q, want the missing report message", resp.Message)
+			}
+		})
+	}
+}
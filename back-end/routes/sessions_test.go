@@ -0,0 +1,102 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+const testPassword = "senha-Forte-123"
+
+// addPasswordUser grava um usuário ativo com a senha testPassword, para os fluxos de /auth/login
+func (e *testEnv) addPasswordUser(role string, companyID *uuid.UUID) models.User {
+	e.t.Helper()
+	user := models.User{
+		Email:     role + "-" + uuid.NewString()[:8] + "@example.com",
+		Name:      role,
+		Role:      role,
+		CompanyID: companyID,
+		IsActive:  true,
+	}
+	if err := user.HashPassword(testPassword); err != nil {
+		e.t.Fatalf("hashing password: %v", err)
+	}
+	e.store.AddUser(&user)
+	return user
+}
+
+// passwordLogin faz login por senha e devolve o par de tokens da sessão aberta
+func (e *testEnv) passwordLogin(email, password string) models.LoginResponse {
+	e.t.Helper()
+	var session models.LoginResponse
+	decode(e.t, e.expect(fiber.StatusOK, "POST", "/api/v1/auth/login", "", fiber.Map{
+		"email": email, "password": password,
+	}), &session)
+	if session.Token == "" || session.RefreshToken == "" {
+		e.t.Fatalf("login response = %+v, want a token pair", session)
+	}
+	return session
+}
+
+// refresh troca o refresh token e devolve o novo par
+func (e *testEnv) refresh(refreshToken string) models.LoginResponse {
+	e.t.Helper()
+	var session models.LoginResponse
+	decode(e.t, e.expect(fiber.StatusOK, "POST", "/api/v1/auth/refresh", "", fiber.Map{
+		"refreshToken": refreshToken,
+	}), &session)
+	return session
+}
+
+func TestRefreshRotatesTheTokenPair(t *testing.T) {
+	f := newTenantFixture(t)
+	user := f.addPasswordUser("manager", &f.acme.ID)
+	first := f.passwordLogin(user.Email, testPassword)
+
+	second := f.refresh(first.RefreshToken)
+	if second.RefreshToken == first.RefreshToken || second.Token == "" {
+		t.Fatalf("refresh returned %+v, want a new pair", second)
+	}
+	f.expect(fiber.StatusOK, "GET", "/api/v1/auth/profile", second.Token, nil)
+
+	// O refresh token seguinte continua válido
+	f.refresh(second.RefreshToken)
+}
+
+func TestReusedRefreshTokenRevokesTheSession(t *testing.T) {
+	f := newTenantFixture(t)
+	user := f.addPasswordUser("manager", &f.acme.ID)
+	first := f.passwordLogin(user.Email, testPassword)
+	second := f.refresh(first.RefreshToken)
+
+	// Reapresentar o token já usado indica roubo: a sessão inteira é encerrada
+	resp := f.expect(fiber.StatusUnauthorized, "POST", "/api/v1/auth/refresh", "", fiber.Map{"refreshToken": first.RefreshToken})
+	if resp.Message != "Refresh token já utilizado. Faça login novamente" {
+		t.Errorf("message = %q, want the reuse message", resp.Message)
+	}
+	f.expect(fiber.StatusUnauthorized, "POST", "/api/v1/auth/refresh", "", fiber.Map{"refreshToken": second.RefreshToken})
+	f.expect(fiber.StatusUnauthorized, "GET", "/api/v1/auth/profile", second.Token, nil)
+
+	sessions := f.store.UserSessions(user.ID)
+	if len(sessions) != 1 || sessions[0].RevokedReason == nil || *sessions[0].RevokedReason != models.SessionRevokedTokenReuse {
+		t.Fatalf("sessions = %+v, want one revoked for %s", sessions, models.SessionRevokedTokenReuse)
+	}
+
+	// Uma sessão aberta depois não é afetada
+	other := f.passwordLogin(user.Email, testPassword)
+	f.refresh(other.RefreshToken)
+}
+
+func TestLogoutInvalidatesTheRefreshToken(t *testing.T) {
+	f := newTenantFixture(t)
+	user := f.addPasswordUser("manager", &f.acme.ID)
+	session := f.passwordLogin(user.Email, testPassword)
+
+	f.expect(fiber.StatusOK, "POST", "/api/v1/auth/logout", session.Token, nil)
+
+	f.expect(fiber.StatusUnauthorized, "POST", "/api/v1/auth/refresh", "", fiber.Map{"refreshToken": session.RefreshToken})
+	f.expect(fiber.StatusUnauthorized, "GET", "/api/v1/auth/profile", session.Token, nil)
+}
@@ -0,0 +1,106 @@
+package routes_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+	"tivix-performance-tracker-backend/oidc"
+	"tivix-performance-tracker-backend/oidc/oidctest"
+)
+
+const ssoClientID = "tracker-app"
+
+// redirect faz o GET e devolve o destino do redirecionamento
+func (e *testEnv) redirect(path string) *url.URL {
+	e.t.Helper()
+	resp, err := e.app.Test(httptest.NewRequest("GET", path, nil), -1)
+	if err != nil {
+		e.t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusFound {
+		e.t.Fatalf("GET %s: status %d, want %d", path, resp.StatusCode, fiber.StatusFound)
+	}
+	location, err := url.Parse(resp.Header.Get(fiber.HeaderLocation))
+	if err != nil {
+		e.t.Fatalf("GET %s: parsing location: %v", path, err)
+	}
+	return location
+}
+
+// ssoLogin faz o login SSO na empresa com a identidade informada e devolve os parâmetros do
+// retorno ao front-end (code ou error)
+func (e *testEnv) ssoLogin(provider *oidctest.Provider, companyID uuid.UUID, subject, email string) url.Values {
+	e.t.Helper()
+	authorize := e.redirect("/api/v1/auth/sso/" + companyID.String() + "/start").Query()
+
+	now := time.Now()
+	provider.IssueCode("codigo-"+subject, provider.Sign(e.t, oidc.IDTokenClaims{
+		Nonce: authorize.Get("nonce"),
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    provider.URL,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{ssoClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	}))
+
+	query := url.Values{"code": {"codigo-" + subject}, "state": {authorize.Get("state")}}
+	return e.redirect("/api/v1/auth/sso/callback?" + query.Encode()).Query()
+}
+
+func TestSSOLoginRejectsUsersFromOtherCompanies(t *testing.T) {
+	f := newTenantFixture(t)
+	provider := oidctest.NewProvider(t, "chave-1")
+	f.expect(fiber.StatusOK, "PUT", "/api/v1/companies/"+f.acme.ID.String()+"/sso", f.adminToken(), fiber.Map{
+		"issuer": provider.URL, "clientId": ssoClientID, "clientSecret": "segredo", "defaultRole": "developer",
+	})
+
+	acmeUser, _ := f.addUser("manager", &f.acme.ID)
+	globexUser, _ := f.addUser("manager", &f.globex.ID)
+
+	tests := []struct {
+		name      string
+		user      models.User
+		wantError string
+	}{
+		{"usuário da própria empresa", acmeUser, ""},
+		{"usuário de outra empresa", globexUser, "company_mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := f.ssoLogin(provider, f.acme.ID, tt.user.ID.String(), tt.user.Email)
+			if got := result.Get("error"); got != tt.wantError {
+				t.Fatalf("callback error = %q, want %q", got, tt.wantError)
+			}
+			if tt.wantError != "" {
+				if result.Get("code") != "" {
+					t.Error("callback returned a login code for a rejected user")
+				}
+				return
+			}
+
+			var session models.LoginResponse
+			decode(t, f.expect(fiber.StatusOK, "POST", "/api/v1/auth/sso/exchange", "", fiber.Map{"code": result.Get("code")}), &session)
+			if session.User.ID != tt.user.ID {
+				t.Errorf("logged in as %s, want %s", session.User.ID, tt.user.ID)
+			}
+		})
+	}
+
+	// A conta da outra empresa continua onde estava
+	var profile models.User
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/auth/profile", f.login(globexUser), nil), &profile)
+	if profile.CompanyID == nil || *profile.CompanyID != f.globex.ID {
+		t.Errorf("company = %v after the rejected SSO login, want %s", profile.CompanyID, f.globex.ID)
+	}
+}
@@ -0,0 +1,50 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// createCompanyTemplate cria e ativa um template de uma pergunta para a empresa
+func (f *tenantFixture) createCompanyTemplate(token string) models.EvaluationTemplate {
+	f.t.Helper()
+	resp := f.expect(fiber.StatusCreated, "POST", "/api/v1/companies/"+f.acme.ID.String()+"/templates", token, fiber.Map{
+		"name":     "Engenharia",
+		"activate": true,
+		"categories": []fiber.Map{{
+			"key": "technical", "label": "Técnico", "weight": 1,
+			"questions": []fiber.Map{{"key": "delivery", "label": "Entregas", "weight": 1}},
+		}},
+	})
+	var template models.EvaluationTemplate
+	decode(f.t, resp, &template)
+	return template
+}
+
+func TestDeleteUnusedCompanyTemplate(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	template := f.createCompanyTemplate(token)
+
+	path := "/api/v1/companies/" + f.acme.ID.String() + "/templates/" + template.ID.String()
+	f.expect(fiber.StatusOK, "DELETE", path, token, nil)
+	f.expect(fiber.StatusNotFound, "GET", path, token, nil)
+}
+
+func TestDeleteCompanyTemplateUsedByReviewCycle(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+	template := f.createCompanyTemplate(token)
+
+	// O ciclo fixa a versão ativa do template, mesmo sem nenhum relatório criado
+	f.expect(fiber.StatusCreated, "POST", "/api/v1/review-cycles", token, fiber.Map{
+		"name": "Ciclo de junho", "month": "2025-06", "opensAt": "2025-06-01", "closesAt": "2025-06-30",
+	})
+
+	path := "/api/v1/companies/" + f.acme.ID.String() + "/templates/" + template.ID.String()
+	f.expect(fiber.StatusConflict, "DELETE", path, token, nil)
+	f.expect(fiber.StatusOK, "GET", path, token, nil)
+}
@@ -0,0 +1,133 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"tivix-performance-tracker-backend/models"
+)
+
+// developerIDs devolve os ids listados na resposta
+func developerIDs(t *testing.T, resp apiResponse) map[uuid.UUID]bool {
+	t.Helper()
+	var developers []models.Developer
+	decode(t, resp, &developers)
+	ids := map[uuid.UUID]bool{}
+	for _, developer := range developers {
+		ids[developer.ID] = true
+	}
+	return ids
+}
+
+func TestDevelopersAreIsolatedByCompany(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("company_admin", &f.acme.ID)
+
+	ids := developerIDs(t, f.expect(fiber.StatusOK, "GET", "/api/v1/developers", token, nil))
+	if len(ids) != 2 || !ids[f.acmeDev.ID] || !ids[f.acmeOtherDev.ID] {
+		t.Errorf("listed developers = %v, want only Acme's", ids)
+	}
+
+	// Registros de outra empresa são tratados como inexistentes, inclusive em alterações
+	path := "/api/v1/developers/" + f.globexDev.ID.String()
+	f.expect(fiber.StatusNotFound, "GET", path, token, nil)
+	f.expect(fiber.StatusNotFound, "PUT", path, token, fiber.Map{"name": "Invadido"})
+	f.expect(fiber.StatusNotFound, "PUT", path+"/archive", token, fiber.Map{"archive": true})
+	f.expect(fiber.StatusNotFound, "DELETE", path, token, nil)
+	f.expect(fiber.StatusNotFound, "GET", "/api/v1/teams/"+f.globexTeam.ID.String()+"/developers", token, nil)
+
+	// O time informado precisa ser da empresa do usuário
+	f.expect(fiber.StatusBadRequest, "POST", "/api/v1/developers", token, fiber.Map{
+		"name": "Novo", "role": "Backend", "teamId": f.globexTeam.ID,
+	})
+
+	resp := f.expect(fiber.StatusOK, "GET", "/api/v1/developers/"+f.globexDev.ID.String(), f.adminToken(), nil)
+	var developer models.Developer
+	decode(t, resp, &developer)
+	if developer.Name != f.globexDev.Name {
+		t.Errorf("developer = %+v", developer)
+	}
+}
+
+func TestAdminSeesAllCompanies(t *testing.T) {
+	f := newTenantFixture(t)
+	token := f.adminToken()
+
+	ids := developerIDs(t, f.expect(fiber.StatusOK, "GET", "/api/v1/developers", token, nil))
+	if len(ids) != 3 {
+		t.Errorf("admin listed %d developers, want 3", len(ids))
+	}
+
+	var companies []models.Company
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/companies", token, nil), &companies)
+	if len(companies) != 2 {
+		t.Errorf("admin listed %d companies, want 2", len(companies))
+	}
+}
+
+func TestManagerIsLimitedToAssignedTeams(t *testing.T) {
+	f := newTenantFixture(t)
+	manager, token := f.addUser("manager", &f.acme.ID)
+	f.store.AssignTeam(manager.ID, f.acmeTeam.ID)
+	visible := f.addReport(f.acmeDev, "2025-01", models.ReportStatusSubmitted)
+	hidden := f.addReport(f.acmeOtherDev, "2025-01", models.ReportStatusSubmitted)
+
+	ids := developerIDs(t, f.expect(fiber.StatusOK, "GET", "/api/v1/developers", token, nil))
+	if len(ids) != 1 || !ids[f.acmeDev.ID] {
+		t.Errorf("listed developers = %v, want only the assigned team's", ids)
+	}
+	f.expect(fiber.StatusNotFound, "GET", "/api/v1/developers/"+f.acmeOtherDev.ID.String(), token, nil)
+
+	// Times de toda a empresa continuam listados, mas só os atribuídos aceitam alterações
+	var teams []models.Team
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/teams", token, nil), &teams)
+	if len(teams) != 2 {
+		t.Errorf("listed %d teams, want 2", len(teams))
+	}
+	f.expect(fiber.StatusOK, "PUT", "/api/v1/teams/"+f.acmeTeam.ID.String(), token, fiber.Map{"name": "Core"})
+	f.expect(fiber.StatusNotFound, "PUT", "/api/v1/teams/"+f.acmeOther.ID.String(), token, fiber.Map{"name": "Core"})
+
+	var reports []models.PerformanceReport
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/performance-reports", token, nil), &reports)
+	if len(reports) != 1 || reports[0].ID != visible.ID {
+		t.Errorf("listed reports = %+v, want only %s", reports, visible.ID)
+	}
+	f.expect(fiber.StatusNotFound, "GET", "/api/v1/performance-reports/"+hidden.ID.String(), token, nil)
+
+	// O desenvolvedor precisa entrar em um dos times do gerente
+	f.expect(fiber.StatusForbidden, "POST", "/api/v1/developers", token, fiber.Map{
+		"name": "Novo", "role": "Backend", "teamId": f.acmeOther.ID,
+	})
+	f.expect(fiber.StatusCreated, "POST", "/api/v1/developers", token, fiber.Map{
+		"name": "Novo", "role": "Backend", "teamId": f.acmeTeam.ID,
+	})
+}
+
+func TestUsersAndCompaniesAreIsolated(t *testing.T) {
+	f := newTenantFixture(t)
+	admin, token := f.addUser("company_admin", &f.acme.ID)
+	outsider, _ := f.addUser("manager", &f.globex.ID)
+
+	var users []models.User
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/auth/users", token, nil), &users)
+	if len(users) != 1 || users[0].ID != admin.ID {
+		t.Errorf("listed users = %+v, want only the Acme admin", users)
+	}
+	f.expect(fiber.StatusNotFound, "PUT", "/api/v1/auth/users/"+outsider.ID.String(), token, fiber.Map{"name": "Invadido"})
+	f.expect(fiber.StatusNotFound, "DELETE", "/api/v1/auth/users/"+outsider.ID.String(), token, nil)
+
+	var companies []models.Company
+	decode(t, f.expect(fiber.StatusOK, "GET", "/api/v1/companies", token, nil), &companies)
+	if len(companies) != 1 || companies[0].ID != f.acme.ID {
+		t.Errorf("listed companies = %+v, want only Acme", companies)
+	}
+}
+
+func TestUserWithoutCompanyIsRejected(t *testing.T) {
+	f := newTenantFixture(t)
+	_, token := f.addUser("manager", nil)
+
+	f.expect(fiber.StatusForbidden, "GET", "/api/v1/developers", token, nil)
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+)
+
+// FieldChange descreve a alteração de um campo entre duas versões de um documento JSON
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// DiffJSON compara dois documentos JSON decodificados e retorna os campos alterados.
+// Objetos aninhados são comparados campo a campo, usando caminhos no formato "a.b".
+func DiffJSON(oldDoc, newDoc map[string]interface{}) []FieldChange {
+	changes := []FieldChange{}
+	diffJSON("", oldDoc, newDoc, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+func diffJSON(prefix string, oldDoc, newDoc map[string]interface{}, changes *[]FieldChange) {
+	keys := make(map[string]bool)
+	for key := range oldDoc {
+		keys[key] = true
+	}
+	for key := range newDoc {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		oldValue, newValue := oldDoc[key], newDoc[key]
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			diffJSON(path, oldMap, newMap, changes)
+			continue
+		}
+
+		if !reflect.DeepEqual(oldValue, newValue) {
+			*changes = append(*changes, FieldChange{Field: path, Old: oldValue, New: newValue})
+		}
+	}
+}
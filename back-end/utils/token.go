@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateOpaqueToken gera um token aleatório para ser entregue ao cliente e o hash
+// SHA-256 que deve ser guardado no banco no lugar do token
+func GenerateOpaqueToken() (token, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashToken(token), nil
+}
+
+// HashToken calcula o hash usado para procurar um token opaco no banco
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIToken gera um token de API no formato <prefixo da marca><id público>_<segredo>.
+// O prefixo devolvido identifica o token em listagens sem expor o segredo; apenas o hash
+// do token completo deve ser guardado.
+func GenerateAPIToken(brand string) (token, prefix, hash string, err error) {
+	publicID := make([]byte, 6)
+	if _, err := rand.Read(publicID); err != nil {
+		return "", "", "", err
+	}
+	secret, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	prefix = brand + hex.EncodeToString(publicID)
+	token = prefix + "_" + secret
+	return token, prefix, HashToken(token), nil
+}
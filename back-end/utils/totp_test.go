@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+// rfcSecret é o segredo ASCII "12345678901234567890" dos vetores de teste da RFC 6238
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", tt.unix, err)
+		}
+		if code != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, code, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := TOTPStep(now)
+	code := func(step int64) string {
+		value, err := TOTPCode(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	tests := []struct {
+		name         string
+		secret       string
+		code         string
+		lastUsedStep int64
+		wantStep     int64
+		wantOK       bool
+	}{
+		{name: "passo atual", secret: rfcSecret, code: code(current), wantStep: current, wantOK: true},
+		{name: "com espaços", secret: rfcSecret, code: " " + code(current)[:3] + " " + code(current)[3:], wantStep: current, wantOK: true},
+		{name: "passo anterior dentro da tolerância", secret: rfcSecret, code: code(current - 1), wantStep: current - 1, wantOK: true},
+		{name: "passo seguinte dentro da tolerância", secret: rfcSecret, code: code(current + 1), wantStep: current + 1, wantOK: true},
+		{name: "fora da tolerância", secret: rfcSecret, code: code(current - 2)},
+		{name: "código já usado", secret: rfcSecret, code: code(current), lastUsedStep: current},
+		{name: "passo anterior ao último usado", secret: rfcSecret, code: code(current - 1), lastUsedStep: current - 1},
+		{name: "tamanho errado", secret: rfcSecret, code: code(current)[:5]},
+		{name: "código errado", secret: rfcSecret, code: "000000"},
+		{name: "segredo inválido", secret: "não é base32", code: code(current)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, now, tt.lastUsedStep)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+
+	for _, count := range []int{0, 1, 10} {
+		codes, err := GenerateRecoveryCodes(count)
+		if err != nil {
+			t.Fatalf("GenerateRecoveryCodes(%d): %v", count, err)
+		}
+		if len(codes) != count {
+			t.Fatalf("GenerateRecoveryCodes(%d) returned %d codes", count, len(codes))
+		}
+
+		seen := map[string]bool{}
+		for _, code := range codes {
+			if !format.MatchString(code) {
+				t.Errorf("code %q does not match xxxxx-xxxxx", code)
+			}
+			if seen[code] {
+				t.Errorf("code %q generated twice", code)
+			}
+			seen[code] = true
+			if NormalizeRecoveryCode(code) != code {
+				t.Errorf("NormalizeRecoveryCode(%q) = %q, want it unchanged", code, NormalizeRecoveryCode(code))
+			}
+		}
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"abcde-fghij", "abcde-fghij"},
+		{"ABCDE-FGHIJ", "abcde-fghij"},
+		{"  abcde-fghij\n", "abcde-fghij"},
+		{"abcdefghij", "abcde-fghij"},
+		{"ABCDE FGHIJ", "abcde-fghij"},
+		{"abc de fgh ij", "abcde-fghij"},
+		{"abcde", "abcde"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRecoveryCode(tt.code); got != tt.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
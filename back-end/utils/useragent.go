@@ -0,0 +1,49 @@
+package utils
+
+import "strings"
+
+// DescribeDevice resume um User-Agent em "navegador em sistema" para exibição na lista
+// de sessões. Não pretende ser um parser completo; agentes desconhecidos viram "Desconhecido".
+func DescribeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Desconhecido"
+	}
+
+	browser := firstMatch(userAgent, [][2]string{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+		{"PostmanRuntime/", "Postman"},
+	})
+	system := firstMatch(userAgent, [][2]string{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	})
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " em " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Desconhecido"
+	}
+}
+
+func firstMatch(value string, candidates [][2]string) string {
+	for _, candidate := range candidates {
+		if strings.Contains(value, candidate[0]) {
+			return candidate[1]
+		}
+	}
+	return ""
+}
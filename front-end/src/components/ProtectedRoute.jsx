@@ -4,7 +4,7 @@ import { tokenUtils } from "../services/api";
 const ProtectedRoute = ({ children, requiredRole }) => {
   const token = tokenUtils.get();
 
-  if (!token || !tokenUtils.isValid()) {
+  if (!token || !tokenUtils.hasSession()) {
     return <Navigate to="/login" replace />;
   }
 
@@ -9,13 +9,13 @@ export const useAuth = () => {
 
   useEffect(() => {
     const checkAuth = async () => {
-      if (!tokenUtils.isValid()) {
+      if (!tokenUtils.hasSession()) {
         logout();
         navigate('/login', { replace: true });
         return;
       }
 
-      if (!user && tokenUtils.isValid()) {
+      if (!user && tokenUtils.hasSession()) {
         try {
           await loadUserProfile();
         } catch (error) {
@@ -31,7 +31,7 @@ export const useAuth = () => {
 
   return {
     user,
-    isAuthenticated: isAuthenticated && tokenUtils.isValid(),
+    isAuthenticated: isAuthenticated && tokenUtils.hasSession(),
     logout: () => {
       logout();
       navigate('/login', { replace: true });
@@ -28,7 +28,7 @@ export const useInitializeApp = () => {
           return;
         }
 
-        if (!tokenUtils.isValid()) {
+        if (!tokenUtils.hasSession()) {
           navigate("/login", { replace: true });
           setLoading(false);
           return;
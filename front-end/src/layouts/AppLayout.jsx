@@ -24,8 +24,8 @@ const AppLayout = ({ children }) => {
   const { darkMode, toggleDarkMode, user, logout } = useAppStore();
   const navigate = useNavigate();
 
-  const handleLogout = () => {
-    logout();
+  const handleLogout = async () => {
+    await logout();
     window.location.href = "/login";
   };
 
@@ -22,9 +22,14 @@ const Login = () => {
   });
   const [error, setError] = useState("");
   const [loading, setLoading] = useState(false);
+  // Desafio de 2FA devolvido pelo login; enquanto existir, o formulário pede o código
+  const [challenge, setChallenge] = useState(null);
+  const [code, setCode] = useState("");
+  const [useRecoveryCode, setUseRecoveryCode] = useState(false);
 
   const navigate = useNavigate();
   const login = useAppStore((state) => state.login);
+  const verifyMFA = useAppStore((state) => state.verifyMFA);
 
   const handleSubmit = async (e) => {
     e.preventDefault();
@@ -32,7 +37,11 @@ const Login = () => {
     setError("");
 
     try {
-      await login(credentials);
+      const result = await login(credentials);
+      if (result?.mfaRequired) {
+        setChallenge(result);
+        return;
+      }
       navigate("/", { replace: true });
     } catch (error) {
       setError(error.message || "Erro ao fazer login");
@@ -41,6 +50,31 @@ const Login = () => {
     }
   };
 
+  const handleVerify = async (e) => {
+    e.preventDefault();
+    setLoading(true);
+    setError("");
+
+    try {
+      await verifyMFA({
+        challengeToken: challenge.challengeToken,
+        ...(useRecoveryCode ? { recoveryCode: code } : { code }),
+      });
+      navigate("/", { replace: true });
+    } catch (error) {
+      setError(error.message || "Código de verificação inválido");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const restartLogin = () => {
+    setChallenge(null);
+    setCode("");
+    setUseRecoveryCode(false);
+    setError("");
+  };
+
   const handleChange = (field) => (e) => {
     setCredentials((prev) => ({
       ...prev,
@@ -71,35 +105,91 @@ const Login = () => {
             </Alert>
           )}
 
-          <form onSubmit={handleSubmit}>
-            <Stack spacing="md">
-              <TextInput
-                label="Email"
-                placeholder="seu.email@tivix.com"
-                value={credentials.email}
-                onChange={handleChange("email")}
-                required
-                type="email"
-              />
-
-              <PasswordInput
-                label="Senha"
-                placeholder="Sua senha"
-                value={credentials.password}
-                onChange={handleChange("password")}
-                required
-              />
-
-              <Button
-                type="submit"
-                fullWidth
-                loading={loading}
-                leftSection={<IconLogin size={16} />}
-              >
-                Entrar
-              </Button>
-            </Stack>
-          </form>
+          {challenge ? (
+            <form onSubmit={handleVerify}>
+              <Stack spacing="md">
+                <TextInput
+                  label={
+                    useRecoveryCode
+                      ? "Código de recuperação"
+                      : "Código de verificação"
+                  }
+                  description={
+                    useRecoveryCode
+                      ? "Use um dos códigos guardados ao ativar o 2FA"
+                      : "Informe o código do aplicativo autenticador"
+                  }
+                  placeholder={useRecoveryCode ? "xxxxx-xxxxx" : "123456"}
+                  value={code}
+                  onChange={(e) => setCode(e.target.value)}
+                  autoComplete="one-time-code"
+                  required
+                  autoFocus
+                />
+
+                <Button
+                  type="submit"
+                  fullWidth
+                  loading={loading}
+                  leftSection={<IconLogin size={16} />}
+                >
+                  Verificar
+                </Button>
+
+                <Anchor
+                  component="button"
+                  type="button"
+                  size="sm"
+                  onClick={() => {
+                    setUseRecoveryCode((value) => !value);
+                    setCode("");
+                  }}
+                >
+                  {useRecoveryCode
+                    ? "Usar o código do aplicativo"
+                    : "Usar um código de recuperação"}
+                </Anchor>
+                <Anchor
+                  component="button"
+                  type="button"
+                  size="sm"
+                  onClick={restartLogin}
+                >
+                  Voltar ao login
+                </Anchor>
+              </Stack>
+            </form>
+          ) : (
+            <form onSubmit={handleSubmit}>
+              <Stack spacing="md">
+                <TextInput
+                  label="Email"
+                  placeholder="seu.email@tivix.com"
+                  value={credentials.email}
+                  onChange={handleChange("email")}
+                  required
+                  type="email"
+                />
+
+                <PasswordInput
+                  label="Senha"
+                  placeholder="Sua senha"
+                  value={credentials.password}
+                  onChange={handleChange("password")}
+                  required
+                />
+
+                <Button
+                  type="submit"
+                  fullWidth
+                  loading={loading}
+                  leftSection={<IconLogin size={16} />}
+                >
+                  Entrar
+                </Button>
+              </Stack>
+            </form>
+          )}
 
           <Text ta="center" size="sm" c="dimmed" mt="md">
             Esqueceu sua senha? Entre em contato com o administrador do sistema.
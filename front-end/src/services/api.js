@@ -5,7 +5,22 @@ const API_BASE_URL = `${
 export const tokenUtils = {
   get: () => localStorage.getItem("token"),
   set: (token) => localStorage.setItem("token", token),
-  remove: () => localStorage.removeItem("token"),
+  getRefresh: () => localStorage.getItem("refreshToken"),
+  remove: () => {
+    localStorage.removeItem("token");
+    localStorage.removeItem("refreshToken");
+  },
+
+  // Guarda o par de tokens de uma sessão aberta ou renovada
+  setSession: ({ token, refreshToken }) => {
+    localStorage.setItem("token", token);
+    if (refreshToken) {
+      localStorage.setItem("refreshToken", refreshToken);
+    }
+  },
+
+  // Há sessão enquanto o access token é válido ou ainda pode ser renovado
+  hasSession: () => tokenUtils.isValid() || !!tokenUtils.getRefresh(),
 
   isValid: () => {
     const token = tokenUtils.get();
@@ -68,8 +83,51 @@ export const hasPermission = (action, resource) => {
   return false;
 };
 
-const apiRequest = async (endpoint, options = {}) => {
+// Rotas de autenticação cujo 401 é uma resposta do formulário (senha ou código errado),
+// não uma sessão expirada
+const PUBLIC_AUTH_ENDPOINTS = ["/auth/login", "/auth/refresh", "/auth/mfa/verify"];
+
+let refreshing = null;
+
+// Troca o refresh token por um novo par (rotação). Requisições simultâneas compartilham a
+// mesma renovação: reapresentar um refresh token já usado revogaria a sessão inteira.
+const refreshSession = () => {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refreshToken = tokenUtils.getRefresh();
+      if (!refreshToken) return false;
+
+      try {
+        const response = await fetch(`${API_BASE_URL}/auth/refresh`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ refreshToken }),
+        });
+        if (!response.ok) {
+          tokenUtils.remove();
+          return false;
+        }
+        const data = await response.json();
+        tokenUtils.setSession(data.data);
+        return true;
+      } catch {
+        return false;
+      }
+    })().finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+};
+
+const apiRequest = async (endpoint, options = {}, retried = false) => {
   const url = `${API_BASE_URL}${endpoint}`;
+  const publicAuth = PUBLIC_AUTH_ENDPOINTS.includes(endpoint);
+
+  // Access token vencido: renova antes de enviar, em vez de esperar o 401
+  if (!publicAuth && !tokenUtils.isValid() && tokenUtils.getRefresh()) {
+    await refreshSession();
+  }
 
   const token = tokenUtils.get();
   const headers = {
@@ -89,7 +147,11 @@ const apiRequest = async (endpoint, options = {}) => {
   try {
     const response = await fetch(url, config);
 
-    if (response.status === 401) {
+    if (response.status === 401 && !publicAuth) {
+      // Uma única nova tentativa com o par de tokens renovado
+      if (!retried && (await refreshSession())) {
+        return apiRequest(endpoint, options, true);
+      }
       tokenUtils.remove();
       window.location.href = "/login";
       throw new Error("Sessão expirada. Faça login novamente.");
@@ -108,13 +170,38 @@ const apiRequest = async (endpoint, options = {}) => {
   }
 };
 
+// Listagens paginadas: segue o nextCursor até a última página e devolve todos os itens em data
+const apiRequestAllPages = async (endpoint) => {
+  const separator = endpoint.includes("?") ? "&" : "?";
+  const pageEndpoint = `${endpoint}${separator}limit=200`;
+
+  let response = await apiRequest(pageEndpoint);
+  const data = [...(response.data || [])];
+  while (response.nextCursor) {
+    response = await apiRequest(
+      `${pageEndpoint}&cursor=${encodeURIComponent(response.nextCursor)}`
+    );
+    data.push(...(response.data || []));
+  }
+
+  return { ...response, data, nextCursor: null };
+};
+
 export const authAPI = {
+  // Com 2FA ativo, devolve { mfaRequired, challengeToken } em vez dos tokens
   login: (credentials) =>
     apiRequest("/auth/login", {
       method: "POST",
       body: JSON.stringify(credentials),
     }),
 
+  // Conclui o login com o código do autenticador ou um código de recuperação
+  verifyMFA: (verification) =>
+    apiRequest("/auth/mfa/verify", {
+      method: "POST",
+      body: JSON.stringify(verification),
+    }),
+
   register: (userData) =>
     apiRequest("/auth/register", {
       method: "POST",
@@ -151,13 +238,21 @@ export const authAPI = {
     }),
 
   // Listar usuários (Admin e Manager)
-  getUsers: () => apiRequest("/auth/users"),
+  getUsers: () => apiRequestAllPages("/auth/users"),
 
   profile: () => apiRequest("/auth/profile"),
 
-  logout: () => {
-    tokenUtils.remove();
-    window.location.href = "/login";
+  // Encerra a sessão no servidor, invalidando o refresh token, e limpa os tokens locais
+  logout: async () => {
+    try {
+      if (tokenUtils.hasSession()) {
+        await apiRequest("/auth/logout", { method: "POST" }, true);
+      }
+    } catch (error) {
+      console.error("Logout error:", error);
+    } finally {
+      tokenUtils.remove();
+    }
   },
 };
 
@@ -172,7 +267,7 @@ export const initAPI = {
 };
 
 export const teamsAPI = {
-  getAll: () => apiRequest("/teams"),
+  getAll: () => apiRequestAllPages("/teams"),
 
   getById: (id) => apiRequest(`/teams/${id}`),
 
@@ -198,7 +293,7 @@ export const teamsAPI = {
 
 export const developersAPI = {
   getAll: (includeArchived = false) =>
-    apiRequest(`/developers?includeArchived=${includeArchived}`),
+    apiRequestAllPages(`/developers?includeArchived=${includeArchived}`),
 
   getArchived: () => apiRequest("/developers/archived"),
 
@@ -231,7 +326,7 @@ export const developersAPI = {
 };
 
 export const performanceReportsAPI = {
-  getAll: () => apiRequest("/performance-reports"),
+  getAll: () => apiRequestAllPages("/performance-reports"),
 
   getById: (id) => apiRequest(`/performance-reports/${id}`),
 
@@ -249,7 +344,7 @@ export const performanceReportsAPI = {
 };
 
 export const companiesAPI = {
-  getAll: () => apiRequest("/companies"),
+  getAll: () => apiRequestAllPages("/companies"),
 
   getById: (id) => apiRequest(`/companies/${id}`),
 
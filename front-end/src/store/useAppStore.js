@@ -23,8 +23,14 @@ const useAppStore = create(
           set({ loading: true, error: null });
           const response = await api.auth.login(credentials);
 
+          // 2FA ativo: o login só termina em verifyMFA, com o código do autenticador
+          if (response.data?.mfaRequired) {
+            set({ loading: false });
+            return response.data;
+          }
+
           if (response.data?.token) {
-            tokenUtils.set(response.data.token);
+            tokenUtils.setSession(response.data);
             set({
               user: response.data.user,
               isAuthenticated: true,
@@ -39,13 +45,34 @@ const useAppStore = create(
         }
       },
 
+      verifyMFA: async (verification) => {
+        try {
+          set({ loading: true, error: null });
+          const response = await api.auth.verifyMFA(verification);
+
+          if (response.data?.token) {
+            tokenUtils.setSession(response.data);
+            set({
+              user: response.data.user,
+              isAuthenticated: true,
+              loading: false,
+            });
+            return response.data;
+          }
+        } catch (error) {
+          console.error("MFA verification error:", error);
+          set({ error: error.message, loading: false });
+          throw error;
+        }
+      },
+
       register: async (userData) => {
         try {
           set({ loading: true, error: null });
           const response = await api.auth.register(userData);
 
           if (response.data?.token) {
-            tokenUtils.set(response.data.token);
+            tokenUtils.setSession(response.data);
             set({
               user: response.data.user,
               isAuthenticated: true,
@@ -81,7 +108,7 @@ const useAppStore = create(
           const response = await api.auth.setNewPassword(passwordData);
           
           if (response.data?.token) {
-            tokenUtils.set(response.data.token);
+            tokenUtils.setSession(response.data);
             set({
               user: response.data.user,
               isAuthenticated: true,
@@ -230,8 +257,8 @@ const useAppStore = create(
         }
       },
 
-      logout: () => {
-        tokenUtils.remove();
+      logout: async () => {
+        await api.auth.logout();
         set({
           user: null,
           isAuthenticated: false,
@@ -246,7 +273,7 @@ const useAppStore = create(
 
       loadUserProfile: async () => {
         try {
-          if (!tokenUtils.isValid()) {
+          if (!tokenUtils.hasSession()) {
             get().logout();
             return;
           }
@@ -310,7 +337,7 @@ const useAppStore = create(
       },
 
       initializeStore: async () => {
-        if (tokenUtils.isValid()) {
+        if (tokenUtils.hasSession()) {
           await get().loadUserProfile();
         }
 